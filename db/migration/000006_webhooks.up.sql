@@ -0,0 +1,30 @@
+CREATE TABLE "webhooks" (
+  "id" uuid PRIMARY KEY DEFAULT (gen_random_uuid ()),
+  "budget_id" uuid NOT NULL,
+  "url" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "events" varchar[] NOT NULL,
+  "active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" uuid PRIMARY KEY DEFAULT (gen_random_uuid ()),
+  "webhook_id" uuid NOT NULL,
+  "event" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "redelivery" boolean NOT NULL DEFAULT false,
+  "attempts" int NOT NULL DEFAULT 0,
+  "status_code" int,
+  "error" varchar,
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "webhooks" ("budget_id");
+
+CREATE INDEX ON "webhook_deliveries" ("webhook_id");
+
+ALTER TABLE "webhooks" ADD FOREIGN KEY ("budget_id") REFERENCES "budgets" ("id");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("webhook_id") REFERENCES "webhooks" ("id");
@@ -0,0 +1,14 @@
+CREATE TABLE "personal_access_tokens" (
+  "id" uuid PRIMARY KEY DEFAULT (gen_random_uuid ()),
+  "username" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "personal_access_tokens" ("username");
+
+ALTER TABLE "personal_access_tokens" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,4 @@
+ALTER TABLE "sessions" DROP COLUMN IF EXISTS "scopes";
+ALTER TABLE "sessions" DROP COLUMN IF EXISTS "client_id";
+DROP TABLE IF EXISTS "oauth_authorization_codes";
+DROP TABLE IF EXISTS "oauth_clients";
@@ -0,0 +1,36 @@
+CREATE TABLE "oauth_clients" (
+  "id" uuid PRIMARY KEY DEFAULT (gen_random_uuid ()),
+  "owner_username" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "secret_hash" varchar,
+  "redirect_uris" varchar[] NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "oauth_authorization_codes" (
+  "code_hash" varchar PRIMARY KEY,
+  "client_id" uuid NOT NULL,
+  "username" varchar NOT NULL,
+  "redirect_uri" varchar NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "code_challenge" varchar NOT NULL,
+  "used" boolean NOT NULL DEFAULT false,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "sessions" ADD COLUMN "client_id" uuid;
+ALTER TABLE "sessions" ADD COLUMN "scopes" varchar[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX ON "oauth_clients" ("owner_username");
+
+CREATE INDEX ON "oauth_authorization_codes" ("client_id");
+
+ALTER TABLE "oauth_clients" ADD FOREIGN KEY ("owner_username") REFERENCES "users" ("username");
+
+ALTER TABLE "oauth_authorization_codes" ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
+
+ALTER TABLE "oauth_authorization_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "sessions" ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
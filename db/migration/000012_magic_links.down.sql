@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "magic_links";
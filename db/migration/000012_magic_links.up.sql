@@ -0,0 +1,13 @@
+CREATE TABLE "magic_links" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "nonce_hash" varchar NOT NULL,
+  "used" boolean NOT NULL DEFAULT false,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "magic_links" ("username");
+
+ALTER TABLE "magic_links" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
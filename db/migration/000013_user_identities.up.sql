@@ -0,0 +1,14 @@
+CREATE TABLE "user_identities" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "issuer" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "email" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "user_identities" ("issuer", "subject");
+
+CREATE INDEX ON "user_identities" ("username");
+
+ALTER TABLE "user_identities" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,15 @@
+CREATE TABLE "email_changes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "old_email" varchar NOT NULL,
+  "new_email" varchar NOT NULL,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "cancel_token_hash" varchar UNIQUE NOT NULL,
+  "used" boolean NOT NULL DEFAULT false,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "email_changes" ("username");
+
+ALTER TABLE "email_changes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
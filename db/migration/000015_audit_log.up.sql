@@ -0,0 +1,14 @@
+-- Actions of administrators. There is no foreign key on the target, so that the entries outlive deleted users.
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "admin_username" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "target_username" varchar NOT NULL,
+  "details" varchar NOT NULL DEFAULT '',
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_log" ("target_username");
+
+CREATE INDEX ON "audit_log" ("created_at");
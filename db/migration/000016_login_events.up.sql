@@ -0,0 +1,14 @@
+CREATE TABLE "login_events" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "success" boolean NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "session_id" uuid,
+  "revoke_token_hash" varchar UNIQUE,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "login_events" ("username", "created_at");
+
+ALTER TABLE "login_events" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,12 @@
+DROP VIEW IF EXISTS transactions_view;
+CREATE VIEW transactions_view AS
+select
+	trans.id, trans.account_id, acc.name "account_name", acc.budget_id, trans.date, trans.payee_id, p.name "payee_name", trans.category_id, c.name "category_name", trans.memo, trans.amount, trans.approved, trans.cleared, trans.reconciled
+from transactions trans, accounts acc, payees p, categories c
+where trans.account_id = acc.id
+and trans.payee_id = p.id
+and trans.category_id = c.id;
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "on_budget";
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_type_check";
+UPDATE "accounts" SET "type" = 'lineofcredit' WHERE "type" = 'line_of_credit';
@@ -0,0 +1,41 @@
+-- Transactions of tracking accounts have no category. Only the types known to be tracking accounts lose theirs: the
+-- unknown types become tracking accounts below, but keep their categories in case the type was meant otherwise.
+UPDATE "transactions" SET "category_id" = NULL
+WHERE "account_id" IN (
+  SELECT "id" FROM "accounts"
+  WHERE regexp_replace(lower("type"), '[^a-z]', '', 'g') IN ('mortgage', 'autoloan', 'liability', 'loan', 'asset')
+);
+
+-- Map the free-form types to the fixed set of account types.
+-- Anything unknown becomes a tracking account, so that it does not change what can be budgeted.
+UPDATE "accounts" SET "type" = CASE regexp_replace(lower("type"), '[^a-z]', '', 'g')
+  WHEN 'checking' THEN 'checking'
+  WHEN 'savings' THEN 'savings'
+  WHEN 'cash' THEN 'cash'
+  WHEN 'creditcard' THEN 'credit_card'
+  WHEN 'lineofcredit' THEN 'line_of_credit'
+  WHEN 'mortgage' THEN 'mortgage'
+  WHEN 'autoloan' THEN 'auto_loan'
+  WHEN 'liability' THEN 'liability'
+  WHEN 'loan' THEN 'liability'
+  ELSE 'asset'
+END;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_type_check" CHECK ("type" IN (
+  'checking', 'savings', 'cash', 'credit_card', 'line_of_credit', 'mortgage', 'auto_loan', 'asset', 'liability'
+));
+
+-- The transactions of on-budget accounts are budgeted in categories, tracking accounts only follow a balance
+ALTER TABLE "accounts" ADD COLUMN "on_budget" boolean NOT NULL GENERATED ALWAYS AS (
+  "type" IN ('checking', 'savings', 'cash', 'credit_card', 'line_of_credit')
+) STORED;
+
+-- Transactions of tracking accounts have no category
+DROP VIEW IF EXISTS transactions_view;
+CREATE VIEW transactions_view AS
+select
+	trans.id, trans.account_id, acc.name "account_name", acc.budget_id, trans.date, trans.payee_id, p.name "payee_name", trans.category_id, c.name "category_name", trans.memo, trans.amount, trans.approved, trans.cleared, trans.reconciled
+from transactions trans
+join accounts acc on trans.account_id = acc.id
+join payees p on trans.payee_id = p.id
+left join categories c on trans.category_id = c.id;
@@ -0,0 +1,17 @@
+-- Rows of the up migration, and the ones created since for new accounts. Payees and categories which
+-- transactions use are kept as plain payees and categories, so that no transaction is lost.
+DELETE FROM "payees" p
+WHERE p."transfer_account_id" IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM "transactions" t WHERE t."payee_id" = p."id");
+
+DELETE FROM "categories" c
+WHERE c."payment_account_id" IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM "transactions" t WHERE t."category_id" = c."id");
+
+DELETE FROM "category_groups" g
+WHERE g."name" = 'Credit Card Payments'
+  AND NOT EXISTS (SELECT 1 FROM "categories" c WHERE c."category_group_id" = g."id");
+
+ALTER TABLE "payees" DROP COLUMN IF EXISTS "transfer_account_id";
+ALTER TABLE "categories" DROP COLUMN IF EXISTS "payment_account_id";
+ALTER TABLE "categories" DROP COLUMN IF EXISTS "assigned";
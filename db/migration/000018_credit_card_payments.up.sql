@@ -0,0 +1,28 @@
+-- Amount assigned to each category
+ALTER TABLE "categories" ADD COLUMN "assigned" int NOT NULL DEFAULT 0;
+
+-- Categories holding the money to pay a credit card account
+ALTER TABLE "categories" ADD COLUMN "payment_account_id" uuid UNIQUE;
+ALTER TABLE "categories" ADD FOREIGN KEY ("payment_account_id") REFERENCES "accounts" ("id") ON DELETE SET NULL;
+
+-- Payees of transfers to an account
+ALTER TABLE "payees" ADD COLUMN "transfer_account_id" uuid UNIQUE;
+ALTER TABLE "payees" ADD FOREIGN KEY ("transfer_account_id") REFERENCES "accounts" ("id") ON DELETE SET NULL;
+
+INSERT INTO "payees" ("budget_id", "name", "transfer_account_id")
+SELECT "budget_id", 'Transfer : ' || "name", "id" FROM "accounts";
+
+INSERT INTO "category_groups" ("budget_id", "name")
+SELECT DISTINCT a."budget_id", 'Credit Card Payments' FROM "accounts" a
+WHERE a."type" = 'credit_card' AND NOT EXISTS (
+  SELECT 1 FROM "category_groups" g WHERE g."budget_id" = a."budget_id" AND g."name" = 'Credit Card Payments'
+);
+
+INSERT INTO "categories" ("category_group_id", "name", "payment_account_id")
+SELECT g."id", a."name", a."id" FROM "accounts" a
+JOIN (
+  SELECT DISTINCT ON ("budget_id") "id", "budget_id" FROM "category_groups"
+  WHERE "name" = 'Credit Card Payments'
+  ORDER BY "budget_id", "id"
+) g ON g."budget_id" = a."budget_id"
+WHERE a."type" = 'credit_card';
@@ -0,0 +1,20 @@
+-- Loan details of mortgage, auto loan and liability accounts
+CREATE TABLE "loans" (
+  "account_id" uuid PRIMARY KEY,
+  "principal" int NOT NULL,
+  -- annual interest rate, in percent
+  "interest_rate" double precision NOT NULL,
+  "term_months" int NOT NULL,
+  "start_date" date NOT NULL,
+  "payment_day" int NOT NULL,
+  -- paid every month on top of the scheduled payment
+  "extra_payment" int NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "loans_principal_check" CHECK ("principal" > 0),
+  CONSTRAINT "loans_interest_rate_check" CHECK ("interest_rate" >= 0 AND "interest_rate" <= 100),
+  CONSTRAINT "loans_term_months_check" CHECK ("term_months" > 0),
+  CONSTRAINT "loans_payment_day_check" CHECK ("payment_day" BETWEEN 1 AND 28),
+  CONSTRAINT "loans_extra_payment_check" CHECK ("extra_payment" >= 0)
+);
+
+ALTER TABLE "loans" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
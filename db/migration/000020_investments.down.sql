@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "security_prices";
+DROP TABLE IF EXISTS "investment_transactions";
+
+UPDATE "accounts" SET "type" = 'asset' WHERE "type" = 'investment';
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_type_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_type_check" CHECK ("type" IN (
+  'checking', 'savings', 'cash', 'credit_card', 'line_of_credit', 'mortgage', 'auto_loan', 'asset', 'liability'
+));
@@ -0,0 +1,40 @@
+-- Investment accounts are tracking accounts holding securities
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_type_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_type_check" CHECK ("type" IN (
+  'checking', 'savings', 'cash', 'credit_card', 'line_of_credit', 'mortgage', 'auto_loan', 'asset', 'liability', 'investment'
+));
+
+-- Buys, sells and dividends of the securities of investment accounts. The holdings of an account are computed from them.
+CREATE TABLE "investment_transactions" (
+  "id" uuid PRIMARY KEY DEFAULT (gen_random_uuid()),
+  "account_id" uuid NOT NULL,
+  "date" date NOT NULL,
+  "type" varchar NOT NULL,
+  "symbol" varchar NOT NULL,
+  -- number of shares bought or sold, zero for dividends
+  "quantity" double precision NOT NULL DEFAULT 0,
+  -- cash paid for a buy, received for a sell or a dividend
+  "amount" int NOT NULL,
+  "memo" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "investment_transactions_type_check" CHECK ("type" IN ('buy', 'sell', 'dividend')),
+  CONSTRAINT "investment_transactions_quantity_check" CHECK ("quantity" >= 0),
+  CONSTRAINT "investment_transactions_amount_check" CHECK ("amount" >= 0)
+);
+
+CREATE INDEX ON "investment_transactions" ("account_id", "date");
+
+ALTER TABLE "investment_transactions" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+-- Price of a share of a security at the end of a day
+CREATE TABLE "security_prices" (
+  "budget_id" uuid NOT NULL,
+  "symbol" varchar NOT NULL,
+  "date" date NOT NULL,
+  "price" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("budget_id", "symbol", "date"),
+  CONSTRAINT "security_prices_price_check" CHECK ("price" >= 0)
+);
+
+ALTER TABLE "security_prices" ADD FOREIGN KEY ("budget_id") REFERENCES "budgets" ("id") ON DELETE CASCADE;
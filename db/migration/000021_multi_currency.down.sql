@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "exchange_rates";
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "transfer_amount";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "currency_code";
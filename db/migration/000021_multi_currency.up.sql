@@ -0,0 +1,22 @@
+-- Accounts have their own currency, which starts as the one of their budget
+ALTER TABLE "accounts" ADD COLUMN "currency_code" varchar(3);
+UPDATE "accounts" a SET "currency_code" = b."currency_code" FROM "budgets" b WHERE a."budget_id" = b."id";
+ALTER TABLE "accounts" ALTER COLUMN "currency_code" SET NOT NULL;
+
+-- Amount received by the other account of a transfer between currencies, in its currency
+ALTER TABLE "transactions" ADD COLUMN "transfer_amount" int;
+
+-- Rate to convert an amount in a currency into another: one unit of from_currency is worth rate units of to_currency
+CREATE TABLE "exchange_rates" (
+  "budget_id" uuid NOT NULL,
+  "date" date NOT NULL,
+  "from_currency" varchar(3) NOT NULL,
+  "to_currency" varchar(3) NOT NULL,
+  "rate" double precision NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("budget_id", "from_currency", "to_currency", "date"),
+  CONSTRAINT "exchange_rates_rate_check" CHECK ("rate" > 0),
+  CONSTRAINT "exchange_rates_currencies_check" CHECK ("from_currency" <> "to_currency")
+);
+
+ALTER TABLE "exchange_rates" ADD FOREIGN KEY ("budget_id") REFERENCES "budgets" ("id") ON DELETE CASCADE;
@@ -0,0 +1,62 @@
+-- Amounts become 64-bit milliunits: thousandths of the unit of their currency, whatever the decimals of its minor unit.
+-- Stored amounts are in the minor unit of their currency, so they are scaled by this factor.
+CREATE FUNCTION "milliunits_per_minor_unit" ("currency_code" varchar) RETURNS numeric AS $$
+  SELECT CASE
+    WHEN "currency_code" IN (
+      'BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG', 'RWF', 'UGX', 'UYI', 'VND', 'VUV', 'XAF', 'XOF', 'XPF'
+    ) THEN 1000
+    WHEN "currency_code" IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 1
+    WHEN "currency_code" IN ('CLF', 'UYW') THEN 0.1
+    ELSE 10
+  END
+$$ LANGUAGE sql IMMUTABLE;
+
+DROP VIEW IF EXISTS transactions_view;
+
+ALTER TABLE "accounts"
+  ALTER COLUMN "balance" TYPE bigint,
+  ALTER COLUMN "cleared_balance" TYPE bigint,
+  ALTER COLUMN "uncleared_balance" TYPE bigint;
+UPDATE "accounts" SET
+  "balance" = round("balance" * milliunits_per_minor_unit("currency_code")),
+  "cleared_balance" = round("cleared_balance" * milliunits_per_minor_unit("currency_code")),
+  "uncleared_balance" = round("uncleared_balance" * milliunits_per_minor_unit("currency_code"));
+
+ALTER TABLE "transactions"
+  ALTER COLUMN "amount" TYPE bigint,
+  ALTER COLUMN "transfer_amount" TYPE bigint;
+UPDATE "transactions" t SET "amount" = round(t."amount" * milliunits_per_minor_unit(a."currency_code"))
+FROM "accounts" a WHERE t."account_id" = a."id";
+UPDATE "transactions" t SET "transfer_amount" = round(t."transfer_amount" * milliunits_per_minor_unit(a."currency_code"))
+FROM "payees" p, "accounts" a
+WHERE t."transfer_amount" IS NOT NULL AND t."payee_id" = p."id" AND p."transfer_account_id" = a."id";
+
+ALTER TABLE "categories" ALTER COLUMN "assigned" TYPE bigint;
+UPDATE "categories" c SET "assigned" = round(c."assigned" * milliunits_per_minor_unit(b."currency_code"))
+FROM "category_groups" g, "budgets" b WHERE c."category_group_id" = g."id" AND g."budget_id" = b."id";
+
+ALTER TABLE "loans"
+  ALTER COLUMN "principal" TYPE bigint,
+  ALTER COLUMN "extra_payment" TYPE bigint;
+UPDATE "loans" l SET
+  "principal" = round(l."principal" * milliunits_per_minor_unit(a."currency_code")),
+  "extra_payment" = round(l."extra_payment" * milliunits_per_minor_unit(a."currency_code"))
+FROM "accounts" a WHERE l."account_id" = a."id";
+
+ALTER TABLE "investment_transactions" ALTER COLUMN "amount" TYPE bigint;
+UPDATE "investment_transactions" i SET "amount" = round(i."amount" * milliunits_per_minor_unit(a."currency_code"))
+FROM "accounts" a WHERE i."account_id" = a."id";
+
+-- Prices are in the currency of the budget, which investment accounts are in
+ALTER TABLE "security_prices" ALTER COLUMN "price" TYPE bigint;
+UPDATE "security_prices" s SET "price" = round(s."price" * milliunits_per_minor_unit(b."currency_code"))
+FROM "budgets" b WHERE s."budget_id" = b."id";
+
+-- Amounts are in the currency of their account
+CREATE VIEW transactions_view AS
+select
+	trans.id, trans.account_id, acc.name "account_name", acc.budget_id, trans.date, trans.payee_id, p.name "payee_name", trans.category_id, c.name "category_name", trans.memo, trans.amount, trans.approved, trans.cleared, trans.reconciled, acc.currency_code
+from transactions trans
+join accounts acc on trans.account_id = acc.id
+join payees p on trans.payee_id = p.id
+left join categories c on trans.category_id = c.id;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "two_factor_challenges";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "totp_last_step";
@@ -0,0 +1,16 @@
+-- Time step of the last TOTP code accepted from each user, so that a code is only accepted once
+ALTER TABLE "users" ADD COLUMN "totp_last_step" bigint NOT NULL DEFAULT 0;
+
+-- Second login steps started by a password, which allow a few attempts at a code and are used once
+CREATE TABLE "two_factor_challenges" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "used" boolean NOT NULL DEFAULT false,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "two_factor_challenges" ("username");
+
+ALTER TABLE "two_factor_challenges" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
@@ -10,9 +10,10 @@ INSERT INTO accounts (
     budget_id,
     name,
     type,
-    balance
+    balance,
+    currency_code
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetBudgetAccount :one
@@ -38,3 +39,17 @@ DELETE FROM accounts WHERE id = $1;
 
 -- name: DeleteAccounts :exec
 DELETE FROM accounts WHERE budget_id = $1;
+
+-- name: GetAccountChangesAfter :many
+-- What the transactions after a date changed in the balance of each account of a budget. Transfers change the account
+-- of the transaction and the account of its payee, which gets the transfer amount between currencies.
+SELECT c.account_id::uuid AS account_id, SUM(c.amount)::bigint AS amount FROM (
+    SELECT t.account_id, t.amount FROM transactions t
+    JOIN accounts a ON t.account_id = a.id
+    WHERE a.budget_id = sqlc.arg(budget_id) AND t.date > sqlc.arg(date)
+    UNION ALL
+    SELECT p.transfer_account_id, COALESCE(t.transfer_amount, -t.amount) FROM transactions t
+    JOIN payees p ON t.payee_id = p.id
+    WHERE p.budget_id = sqlc.arg(budget_id) AND p.transfer_account_id IS NOT NULL AND t.date > sqlc.arg(date)
+) c
+GROUP BY c.account_id;
@@ -0,0 +1,16 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+    admin_username,
+    action,
+    target_username,
+    details,
+    client_ip
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetAuditLogs :many
+SELECT * FROM audit_log
+WHERE sqlc.arg(target_username)::varchar = '' OR target_username = sqlc.arg(target_username)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
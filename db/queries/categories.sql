@@ -13,8 +13,31 @@ INSERT INTO categories (
 )
 RETURNING *;
 
+-- name: GetBudgetCategories :many
+SELECT c.* FROM categories c, category_groups g
+WHERE c.category_group_id = g.id AND g.budget_id = $1;
+
+-- name: GetBudgetCategory :one
+SELECT c.* FROM categories c, category_groups g
+WHERE c.category_group_id = g.id AND c.id = $1 AND g.budget_id = $2;
+
+-- name: CreatePaymentCategory :one
+INSERT INTO categories (
+    category_group_id,
+    name,
+    payment_account_id
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
 -- name: UpdateCategory :one
-UPDATE categories SET name = $1 WHERE id = $2 RETURNING *;
+UPDATE categories
+SET
+    name = COALESCE(sqlc.narg(name), name),
+    assigned = COALESCE(sqlc.narg(assigned), assigned)
+WHERE id = sqlc.arg(id)
+RETURNING *;
 
 -- name: DeleteCategory :exec
 DELETE FROM categories WHERE id = $1;
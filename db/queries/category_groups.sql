@@ -4,6 +4,11 @@ SELECT * FROM category_groups WHERE budget_id = $1;
 -- name: GetCategoryGroup :one
 SELECT * FROM category_groups WHERE id = $1;
 
+-- name: GetCategoryGroupByName :one
+SELECT * FROM category_groups WHERE budget_id = $1 AND name = $2
+ORDER BY id
+LIMIT 1;
+
 -- name: CreateCategoryGroup :one
 INSERT INTO category_groups (
     budget_id,
@@ -0,0 +1,26 @@
+-- name: CreateEmailChange :one
+INSERT INTO email_changes (
+    username,
+    old_email,
+    new_email,
+    token_hash,
+    cancel_token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetEmailChangeByHash :one
+SELECT * FROM email_changes WHERE token_hash = $1;
+
+-- name: GetEmailChangeByCancelHash :one
+SELECT * FROM email_changes WHERE cancel_token_hash = $1;
+
+-- name: UseEmailChange :execrows
+UPDATE email_changes SET used = true WHERE id = $1 AND used = false;
+
+-- name: UseEmailChanges :exec
+UPDATE email_changes SET used = true WHERE username = $1 AND used = false;
+
+-- name: DeleteEmailChanges :exec
+DELETE FROM email_changes WHERE username = $1;
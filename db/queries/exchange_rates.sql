@@ -0,0 +1,24 @@
+-- name: GetExchangeRates :many
+SELECT * FROM exchange_rates
+WHERE budget_id = $1
+  AND (sqlc.narg(from_currency)::varchar IS NULL OR from_currency = sqlc.narg(from_currency))
+  AND (sqlc.narg(to_currency)::varchar IS NULL OR to_currency = sqlc.narg(to_currency))
+ORDER BY from_currency, to_currency, date;
+
+-- name: GetExchangeRatesUntil :many
+-- The rates on or before a date, or all of them
+SELECT * FROM exchange_rates
+WHERE budget_id = $1 AND (sqlc.narg(date)::date IS NULL OR date <= sqlc.narg(date))
+ORDER BY date;
+
+-- name: UpsertExchangeRate :batchexec
+INSERT INTO exchange_rates (
+    budget_id,
+    date,
+    from_currency,
+    to_currency,
+    rate
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (budget_id, from_currency, to_currency, date) DO UPDATE SET rate = EXCLUDED.rate;
@@ -0,0 +1,44 @@
+-- name: GetInvestmentTransactions :many
+SELECT * FROM investment_transactions WHERE account_id = $1
+ORDER BY date, created_at;
+
+-- name: GetBudgetInvestmentTransactions :many
+SELECT t.* FROM investment_transactions t
+JOIN accounts a ON t.account_id = a.id
+WHERE a.budget_id = $1 AND t.date <= sqlc.arg(date)
+ORDER BY t.date, t.created_at;
+
+-- name: CreateInvestmentTransaction :one
+INSERT INTO investment_transactions (
+    account_id,
+    date,
+    type,
+    symbol,
+    quantity,
+    amount,
+    memo
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetSecurityPrices :many
+SELECT * FROM security_prices
+WHERE budget_id = $1 AND (sqlc.narg(symbol)::varchar IS NULL OR symbol = sqlc.narg(symbol))
+ORDER BY symbol, date;
+
+-- name: GetLatestSecurityPrices :many
+-- The last price of each security on or before a date
+SELECT DISTINCT ON (symbol) * FROM security_prices
+WHERE budget_id = $1 AND date <= sqlc.arg(date)
+ORDER BY symbol, date DESC;
+
+-- name: UpsertSecurityPrice :exec
+INSERT INTO security_prices (
+    budget_id,
+    symbol,
+    date,
+    price
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (budget_id, symbol, date) DO UPDATE SET price = EXCLUDED.price;
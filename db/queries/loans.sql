@@ -0,0 +1,35 @@
+-- name: GetLoan :one
+SELECT * FROM loans WHERE account_id = $1;
+
+-- name: UpsertLoan :one
+INSERT INTO loans (
+    account_id,
+    principal,
+    interest_rate,
+    term_months,
+    start_date,
+    payment_day,
+    extra_payment
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (account_id) DO UPDATE SET
+    principal = EXCLUDED.principal,
+    interest_rate = EXCLUDED.interest_rate,
+    term_months = EXCLUDED.term_months,
+    start_date = EXCLUDED.start_date,
+    payment_day = EXCLUDED.payment_day,
+    extra_payment = EXCLUDED.extra_payment
+RETURNING *;
+
+-- name: DeleteLoan :exec
+DELETE FROM loans WHERE account_id = $1;
+
+-- name: GetLoanPayments :many
+-- Transfers paying a loan account: outflows of other accounts to its transfer payee, and inflows recorded on the loan
+-- account from a transfer payee.
+SELECT t.* FROM transactions t
+JOIN payees p ON t.payee_id = p.id
+WHERE (p.transfer_account_id = sqlc.arg(account_id)::uuid AND t.account_id <> sqlc.arg(account_id)::uuid AND t.amount < 0)
+   OR (t.account_id = sqlc.arg(account_id)::uuid AND p.transfer_account_id IS NOT NULL AND t.amount > 0)
+ORDER BY t.date, t.id;
@@ -0,0 +1,38 @@
+-- name: CreateLoginEvent :one
+INSERT INTO login_events (
+    username,
+    success,
+    user_agent,
+    client_ip,
+    session_id
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetLoginEvents :many
+SELECT * FROM login_events
+WHERE username = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+
+-- name: GetLoginDevice :one
+SELECT
+    EXISTS (
+        SELECT 1 FROM login_events l
+        WHERE l.username = sqlc.arg(username) AND l.success = true
+    ) AS has_logged_in,
+    EXISTS (
+        SELECT 1 FROM login_events l
+        WHERE l.username = sqlc.arg(username) AND l.success = true
+        AND l.user_agent = sqlc.arg(user_agent) AND l.client_ip = sqlc.arg(client_ip)
+    ) AS is_known;
+
+-- name: SetLoginEventRevokeToken :execrows
+UPDATE login_events SET revoke_token_hash = $2
+WHERE session_id = $1;
+
+-- name: GetLoginEventByRevokeHash :one
+SELECT * FROM login_events WHERE revoke_token_hash = $1;
+
+-- name: DeleteLoginEvents :exec
+DELETE FROM login_events WHERE username = $1;
@@ -0,0 +1,18 @@
+-- name: CreateMagicLink :one
+INSERT INTO magic_links (
+    username,
+    token_hash,
+    nonce_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetMagicLinkByHash :one
+SELECT * FROM magic_links WHERE token_hash = $1;
+
+-- name: UseMagicLink :execrows
+UPDATE magic_links SET used = true WHERE id = $1 AND used = false;
+
+-- name: DeleteMagicLinks :exec
+DELETE FROM magic_links WHERE username = $1;
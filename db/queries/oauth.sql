@@ -0,0 +1,47 @@
+-- name: GetOAuthClients :many
+SELECT * FROM oauth_clients WHERE owner_username = $1 ORDER BY created_at;
+
+-- name: GetOAuthClient :one
+SELECT * FROM oauth_clients WHERE id = $1;
+
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (
+    owner_username,
+    name,
+    secret_hash,
+    redirect_uris,
+    scopes
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: DeleteOAuthClient :exec
+DELETE FROM oauth_clients WHERE id = $1;
+
+-- name: DeleteOAuthClients :exec
+DELETE FROM oauth_clients WHERE owner_username = $1;
+
+-- name: GetOAuthAuthorizationCode :one
+SELECT * FROM oauth_authorization_codes WHERE code_hash = $1;
+
+-- name: CreateOAuthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (
+    code_hash,
+    client_id,
+    username,
+    redirect_uri,
+    scopes,
+    code_challenge,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: UseOAuthAuthorizationCode :execrows
+UPDATE oauth_authorization_codes SET used = true WHERE code_hash = $1 AND used = false;
+
+-- name: DeleteOAuthAuthorizationCodes :exec
+DELETE FROM oauth_authorization_codes WHERE client_id = $1;
+
+-- name: DeleteUserOAuthAuthorizationCodes :exec
+DELETE FROM oauth_authorization_codes WHERE username = $1;
@@ -0,0 +1,20 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (
+    username,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetPasswordResetByHash :one
+SELECT * FROM password_resets WHERE token_hash = $1;
+
+-- name: UsePasswordReset :execrows
+UPDATE password_resets SET used = true WHERE id = $1 AND used = false;
+
+-- name: UsePasswordResets :exec
+UPDATE password_resets SET used = true WHERE username = $1 AND used = false;
+
+-- name: DeletePasswordResets :exec
+DELETE FROM password_resets WHERE username = $1;
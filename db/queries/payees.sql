@@ -12,8 +12,20 @@ INSERT INTO payees (
     $1, $2
 ) RETURNING *;
 
+-- name: CreateTransferPayee :one
+INSERT INTO payees (
+    budget_id,
+    name,
+    transfer_account_id
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
 -- name: UpdatePayee :one
 UPDATE payees SET name = $1 WHERE budget_id = $2 AND id = $3 RETURNING *;
 
 -- name: DeletePayee :exec
 DELETE FROM payees WHERE budget_id = $1 AND id = $2;
+
+-- name: DeletePayees :exec
+DELETE FROM payees WHERE budget_id = $1;
@@ -0,0 +1,25 @@
+-- name: GetPersonalAccessTokens :many
+SELECT * FROM personal_access_tokens WHERE username = $1 ORDER BY created_at;
+
+-- name: GetPersonalAccessTokenByHash :one
+SELECT * FROM personal_access_tokens WHERE token_hash = $1;
+
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (
+    username,
+    name,
+    token_hash,
+    scopes,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: UpdatePersonalAccessTokenLastUsed :exec
+UPDATE personal_access_tokens SET last_used_at = now() WHERE id = $1;
+
+-- name: DeletePersonalAccessToken :execrows
+DELETE FROM personal_access_tokens WHERE id = $1 AND username = $2;
+
+-- name: DeletePersonalAccessTokens :exec
+DELETE FROM personal_access_tokens WHERE username = $1;
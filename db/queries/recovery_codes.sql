@@ -0,0 +1,14 @@
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (
+    username,
+    code_hash
+) VALUES (
+    $1, $2
+);
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes SET used_at = now()
+WHERE username = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes WHERE username = $1;
@@ -2,6 +2,11 @@
 -- name: GetSession :one
 SELECT * FROM sessions WHERE id = $1;
 
+-- name: GetUserSessions :many
+SELECT * FROM sessions
+WHERE username = $1 AND is_blocked = false AND expires_at > now()
+ORDER BY created_at DESC;
+
 -- name: CreateSession :one
 INSERT INTO sessions (
     id,
@@ -10,10 +15,25 @@ INSERT INTO sessions (
     user_agent,
     client_ip,
     is_blocked,
-    expires_at
+    expires_at,
+    client_id,
+    scopes
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8, COALESCE(sqlc.arg(scopes)::varchar[], '{}')
 ) RETURNING *;
 
 -- name: DeleteUserSessions :exec
 DELETE from sessions WHERE username = $1;
+
+-- name: DeleteClientSessions :exec
+DELETE from sessions WHERE client_id = $1;
+
+-- name: BlockUserSessions :exec
+UPDATE sessions SET is_blocked = true WHERE username = $1;
+
+-- name: BlockSession :execrows
+UPDATE sessions SET is_blocked = true WHERE id = $1 AND username = $2 AND is_blocked = false;
+
+-- name: RotateSessionRefreshToken :execrows
+UPDATE sessions SET refresh_token = sqlc.arg(new_refresh_token)
+WHERE id = sqlc.arg(id) AND refresh_token = sqlc.arg(refresh_token) AND is_blocked = false;
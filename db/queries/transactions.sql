@@ -22,9 +22,10 @@ INSERT INTO transactions (
     memo,
     amount,
     cleared,
-    reconciled
+    reconciled,
+    transfer_amount
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 ) RETURNING *;
 
 -- name: UpdateTransaction :one
@@ -44,3 +45,15 @@ RETURNING *;
 
 -- name: DeleteTransaction :exec
 DELETE FROM transactions WHERE id = $1;
+
+-- name: DeleteBudgetTransactions :exec
+DELETE FROM transactions WHERE account_id IN (SELECT id FROM accounts WHERE budget_id = $1);
+
+-- name: GetCurrencyTransfers :many
+-- Transfers between accounts in different currencies
+SELECT t.id, t.date, t.account_id, p.transfer_account_id::uuid AS transfer_account_id, t.amount, t.transfer_amount::bigint AS transfer_amount
+FROM transactions t
+JOIN accounts a ON t.account_id = a.id
+JOIN payees p ON t.payee_id = p.id
+WHERE a.budget_id = $1 AND t.transfer_amount IS NOT NULL AND p.transfer_account_id IS NOT NULL
+ORDER BY t.date, t.id;
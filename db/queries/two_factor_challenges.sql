@@ -0,0 +1,17 @@
+-- name: CreateTwoFactorChallenge :exec
+INSERT INTO two_factor_challenges (
+    id,
+    username,
+    expires_at
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: AttemptTwoFactorChallenge :one
+-- Counts an attempt at a challenge. Returns no rows once it is used, expired or out of attempts.
+UPDATE two_factor_challenges SET attempts = attempts + 1
+WHERE id = sqlc.arg(id) AND NOT used AND expires_at > now() AND attempts < sqlc.arg(max_attempts)::int
+RETURNING *;
+
+-- name: UseTwoFactorChallenge :execrows
+UPDATE two_factor_challenges SET used = true WHERE id = $1 AND NOT used;
@@ -0,0 +1,18 @@
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+    username,
+    issuer,
+    subject,
+    email
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities WHERE issuer = $1 AND subject = $2;
+
+-- name: GetUserIdentities :many
+SELECT * FROM user_identities WHERE username = $1 ORDER BY created_at;
+
+-- name: DeleteUserIdentities :exec
+DELETE FROM user_identities WHERE username = $1;
@@ -33,3 +33,35 @@ RETURNING *;
 
 -- name: DeleteUser :exec
 DELETE FROM users WHERE username = $1;
+
+-- name: UpdateUserTOTPSecret :exec
+UPDATE users SET totp_secret = $2, totp_enabled = false WHERE username = $1;
+
+-- name: EnableUserTOTP :exec
+UPDATE users SET totp_enabled = true WHERE username = $1;
+
+-- name: DisableUserTOTP :exec
+UPDATE users SET totp_secret = NULL, totp_enabled = false WHERE username = $1;
+
+-- name: UpdateUserTOTPLastStep :execrows
+-- Only moves forward, so that a TOTP code used by a concurrent request is not accepted twice
+UPDATE users SET totp_last_step = sqlc.arg(step) WHERE username = sqlc.arg(username) AND totp_last_step < sqlc.arg(step);
+
+-- name: UpdateUserPasswordHash :exec
+-- Only replaces the old hash, so that a concurrent password change is not overwritten
+UPDATE users SET password = sqlc.arg(new_password) WHERE username = sqlc.arg(username) AND password = sqlc.arg(old_password);
+
+-- name: ListUsers :many
+-- The search is a LIKE pattern matched against the username and the email
+SELECT * FROM users
+WHERE sqlc.arg(search)::varchar = '' OR username ILIKE sqlc.arg(search) OR email ILIKE sqlc.arg(search)
+ORDER BY username
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountUsers :one
+SELECT count(*) FROM users
+WHERE sqlc.arg(search)::varchar = '' OR username ILIKE sqlc.arg(search) OR email ILIKE sqlc.arg(search);
+
+-- name: AddUserRole :execrows
+UPDATE users SET roles = array_append(roles, sqlc.arg(role)::varchar)
+WHERE username = sqlc.arg(username) AND NOT (sqlc.arg(role)::varchar = ANY(roles));
@@ -0,0 +1,73 @@
+-- name: GetWebhooks :many
+SELECT * FROM webhooks WHERE budget_id = $1 ORDER BY created_at;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks WHERE budget_id = $1 AND id = $2;
+
+-- name: GetWebhookById :one
+SELECT * FROM webhooks WHERE id = $1;
+
+-- name: GetSubscribedWebhooks :many
+SELECT * FROM webhooks
+WHERE budget_id = $1 AND active = true AND sqlc.arg(event)::varchar = ANY(events);
+
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+    budget_id,
+    url,
+    secret,
+    events
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UpdateWebhook :one
+UPDATE webhooks
+SET
+    url = COALESCE(sqlc.narg(url), url),
+    events = COALESCE(sqlc.narg(events), events),
+    active = COALESCE(sqlc.narg(active), active)
+WHERE id = $1 AND budget_id = $2
+RETURNING *;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks WHERE id = $1;
+
+-- name: DeleteWebhooks :exec
+DELETE FROM webhooks WHERE budget_id = $1;
+
+-- name: GetWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries WHERE id = $1;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+    webhook_id,
+    event,
+    payload,
+    redelivery
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UpdateWebhookDeliveryResult :one
+UPDATE webhook_deliveries
+SET
+    attempts = attempts + 1,
+    status_code = $2,
+    error = $3,
+    delivered_at = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWebhookDeliveries :exec
+DELETE FROM webhook_deliveries WHERE webhook_id = $1;
+
+-- name: DeleteBudgetWebhookDeliveries :exec
+DELETE FROM webhook_deliveries
+WHERE webhook_id IN (SELECT id FROM webhooks WHERE budget_id = $1);
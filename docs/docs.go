@@ -21,26 +21,48 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/budgets": {
+        "/admin/audit_log": {
             "get": {
-                "description": "List all budgets.",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "List the actions of administrators, the latest first, optionally only those on a user. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Budget"
+                    "Admin"
+                ],
+                "summary": "Get the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only the actions on this user",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page, at most 100",
+                        "name": "page_size",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List budgets",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/db.CreateBudgetParams"
+                                "$ref": "#/definitions/AuditLogEntry"
                             }
                         }
                     },
@@ -50,6 +72,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -57,35 +91,48 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new budget.",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "List the users, by username. The search matches part of the username or the email. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Budget"
+                    "Admin"
                 ],
-                "summary": "Create budget",
+                "summary": "List users",
                 "parameters": [
                     {
-                        "description": "Create a budget",
-                        "name": "account",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/db.CreateBudgetParams"
-                        }
+                        "type": "string",
+                        "description": "Part of the username or email",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Users per page, at most 100",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.CreateBudgetParams"
+                            "$ref": "#/definitions/AdminUserList"
                         }
                     },
                     "400": {
@@ -94,6 +141,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -101,30 +160,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a budget.",
+            }
+        },
+        "/admin/users/{username}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get a user. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Budget"
+                    "Admin"
                 ],
-                "summary": "Delete budget",
+                "summary": "Get a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Budget ID",
-                        "name": "budget_id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "budget deleted",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/AdminUser"
                         }
                     },
                     "400": {
@@ -133,6 +199,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -140,26 +224,35 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/budgets/:budget_id": {
-            "get": {
-                "description": "Get the details of a budget.",
-                "consumes": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "Delete a user and everything they own, like the deletion of an account by its user. Administrators cannot delete themselves here.\nRequires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Budget"
+                    "Admin"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get budget",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "user has been deleted",
                         "schema": {
-                            "$ref": "#/definitions/DetailedBudgetResponse"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -168,6 +261,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -177,36 +288,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/budgets/{budget_id}/accounts": {
-            "get": {
-                "description": "List all accounts associated with a budget.",
-                "consumes": [
-                    "application/json"
+        "/admin/users/{username}/2fa": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "Disable the two-factor authentication of a user and delete their recovery codes, e.g. when they lost their device and their codes.\nRequires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Admin"
                 ],
-                "summary": "List all budgeting accounts",
+                "summary": "Reset the two-factor authentication of a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Budget ID",
-                        "name": "budget_id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "two-factor authentication reset",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/db.CreateAccountParams"
-                            }
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -215,6 +325,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -228,42 +350,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a budgeting account.",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/admin/users/{username}/sessions": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "Log out a user from all their sessions and revoke their personal access tokens. The refresh and access tokens of the sessions,\nand the personal access tokens, stop working immediately. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Admin"
                 ],
-                "summary": "Create a budgeting account",
+                "summary": "Block the sessions of a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Budget ID",
-                        "name": "budget_id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Account details",
-                        "name": "account",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.updateAccountRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "sessions blocked",
                         "schema": {
-                            "$ref": "#/definitions/db.CreateAccountParams"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -272,6 +389,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -281,40 +416,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/budgets/{budget_id}/accounts/{account_id}": {
-            "get": {
-                "description": "Get the details of an account associated with a budget.",
-                "consumes": [
-                    "application/json"
+        "/admin/users/{username}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "Lift the lockout of a user after too many failed logins, and clear their failed logins. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Admin"
                 ],
-                "summary": "Get a single budgeting account",
+                "summary": "Unlock a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Budget ID",
-                        "name": "budget_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Account ID",
-                        "name": "account_id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "user unlocked",
                         "schema": {
-                            "$ref": "#/definitions/db.CreateAccountParams"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -323,60 +453,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/api.HTTPError"
                         }
-                    }
-                }
-            },
-            "put": {
-                "description": "Update a budgeting account.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Accounts"
-                ],
-                "summary": "Update a budgeting account",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Budget ID",
-                        "name": "budget_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Account ID",
-                        "name": "account_id",
-                        "in": "path",
-                        "required": true
                     },
-                    {
-                        "description": "Account details",
-                        "name": "account",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.updateAccountRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/db.UpdateAccountParams"
+                            "$ref": "#/definitions/api.HTTPError"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.HTTPError"
                         }
@@ -388,37 +478,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a budgeting account.",
+            }
+        },
+        "/admin/users/{username}/verify_email": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Mark the email of a user as verified, e.g. when the verification email does not reach them. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Admin"
                 ],
-                "summary": "Delete a budgeting account",
+                "summary": "Verify the email of a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Budget ID",
-                        "name": "budget_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Account ID",
-                        "name": "account_id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "budgeting account deleted",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/AdminUser"
                         }
                     },
                     "400": {
@@ -427,6 +517,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -436,30 +544,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/budgets/{budget_id}/categories": {
+        "/budgets": {
             "get": {
-                "description": "List all categories in a budget grouped by category group",
+                "description": "List all budgets.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
-                ],
-                "summary": "Get category groups and categories",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Budget ID",
-                        "name": "budget_id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Budget"
                 ],
+                "summary": "List budgets",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.categoryResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.CreateBudgetParams"
+                            }
                         }
                     },
                     "400": {
@@ -468,12 +573,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/api.HTTPError"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -481,11 +580,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/budgets/{budget_id}/categories/{category_group_id}": {
+            },
             "post": {
-                "description": "Create a budgeting category.",
+                "description": "Create a new budget.",
                 "consumes": [
                     "application/json"
                 ],
@@ -493,31 +590,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
+                    "Budget"
                 ],
-                "summary": "Create a budgeting category",
+                "summary": "Create budget",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Budget ID",
-                        "name": "budget_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Category Group ID",
-                        "name": "category_group_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Category details",
+                        "description": "Create a budget",
                         "name": "account",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.categoryRqst"
+                            "$ref": "#/definitions/db.CreateBudgetParams"
                         }
                     }
                 ],
@@ -525,7 +608,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Category"
+                            "$ref": "#/definitions/db.CreateBudgetParams"
                         }
                     },
                     "400": {
@@ -541,21 +624,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/budgets/{budget_id}/categories/{category_id}": {
-            "put": {
-                "description": "Update a budgeting category.",
-                "consumes": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "Delete a budget.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
+                    "Budget"
                 ],
-                "summary": "Update a budgeting category",
+                "summary": "Delete budget",
                 "parameters": [
                     {
                         "type": "string",
@@ -563,29 +641,13 @@ const docTemplate = `{
                         "name": "budget_id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Category ID",
-                        "name": "category_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Category details",
-                        "name": "account",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.categoryRqst"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "budget deleted",
                         "schema": {
-                            "$ref": "#/definitions/db.Category"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -601,9 +663,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a category.",
+            }
+        },
+        "/budgets/:budget_id": {
+            "get": {
+                "description": "Get the details of a budget, with the money ready to assign: the inflows of on-budget accounts without category,\nconverted into the currency of the budget, less the money assigned to categories.",
                 "consumes": [
                     "application/json"
                 ],
@@ -611,30 +675,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
-                ],
-                "summary": "Delete a category",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Budget ID",
-                        "name": "budget_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Category ID",
-                        "name": "category_id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Budget"
                 ],
+                "summary": "Get budget",
                 "responses": {
                     "200": {
-                        "description": "category deleted",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/DetailedBudgetResponse"
                         }
                     },
                     "400": {
@@ -643,12 +691,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/api.HTTPError"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -658,16 +700,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/budgets/{budget_id}/category-groups": {
+        "/budgets/{budget_id}/accounts": {
             "get": {
-                "description": "List all category groups associated with a budget.",
+                "description": "List all accounts associated with a budget.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
+                    "Accounts"
                 ],
-                "summary": "List all budgeting category groups",
+                "summary": "List all budgeting accounts",
                 "parameters": [
                     {
                         "type": "string",
@@ -683,7 +728,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/db.CategoryGroup"
+                                "$ref": "#/definitions/Account"
                             }
                         }
                     },
@@ -708,7 +753,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a budgeting category group.",
+                "description": "Create a budgeting account. Checking, savings, cash, credit card and line of credit accounts are on budget: their transactions are budgeted in categories.\nMortgage, auto loan, asset, liability and investment accounts are tracking accounts, which only follow a balance.\nEvery account gets a payee for the transfers to it, and credit card accounts a payment category.\nAccounts are in the currency of the budget unless another one is given, which cannot be changed later.\nInvestment accounts are in the currency of the budget, which the prices of securities are in.\nAmounts are in milliunits, thousandths of the unit of the currency, or decimals in the currency in the fields ending with _decimal.",
                 "consumes": [
                     "application/json"
                 ],
@@ -716,9 +761,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
+                    "Accounts"
                 ],
-                "summary": "Create a budgeting category group",
+                "summary": "Create a budgeting account",
                 "parameters": [
                     {
                         "type": "string",
@@ -728,12 +773,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Category group details",
+                        "description": "Account details",
                         "name": "account",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.categoryGroupRqst"
+                            "$ref": "#/definitions/api.accountRequest"
                         }
                     }
                 ],
@@ -741,7 +786,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.CategoryGroup"
+                            "$ref": "#/definitions/Account"
                         }
                     },
                     "400": {
@@ -759,9 +804,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/budgets/{budget_id}/category-groups/{category_group_id}": {
-            "put": {
-                "description": "Update a budgeting category group.",
+        "/budgets/{budget_id}/accounts/{account_id}": {
+            "get": {
+                "description": "Get the details of an account associated with a budget.",
                 "consumes": [
                     "application/json"
                 ],
@@ -769,9 +814,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
+                    "Accounts"
                 ],
-                "summary": "Update a budgeting category group",
+                "summary": "Get a single budgeting account",
                 "parameters": [
                     {
                         "type": "string",
@@ -782,18 +827,67 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Category Group ID",
-                        "name": "category_group_id",
+                        "description": "Account ID",
+                        "name": "account_id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Category group details",
-                        "name": "account",
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Account"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a budgeting account. The type can only be changed to another on-budget type, or another tracking type, and credit cards keep their type.\nOnly accounts in the currency of the budget can become investment accounts.\nBalances are in milliunits, thousandths of the unit of the currency, or decimals in the currency in the fields ending with _decimal.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Update a budgeting account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Account details",
+                        "name": "account",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.categoryGroupRqst"
+                            "$ref": "#/definitions/api.updateAccountRequest"
                         }
                     }
                 ],
@@ -801,7 +895,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.CategoryGroup"
+                            "$ref": "#/definitions/Account"
                         }
                     },
                     "400": {
@@ -819,17 +913,62 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a budgeting category group and all categories in the group.",
-                "consumes": [
+                "description": "Delete a budgeting account.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Delete a budgeting account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "budgeting account deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/accounts/{account_id}/holdings": {
+            "get": {
+                "description": "List the securities an investment account holds on a date, valued at their last price on or before the date.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
+                    "Investments"
                 ],
-                "summary": "Delete a category group",
+                "summary": "List the holdings of an investment account",
                 "parameters": [
                     {
                         "type": "string",
@@ -840,17 +979,26 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Category Group ID",
-                        "name": "category_group_id",
+                        "description": "Account ID",
+                        "name": "account_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date of the holdings (YYYY-MM-DD), today if left out",
+                        "name": "date",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "category group deleted",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/Holding"
+                            }
                         }
                     },
                     "400": {
@@ -874,16 +1022,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/budgets/{budget_id}/payees": {
+        "/budgets/{budget_id}/accounts/{account_id}/investment-transactions": {
             "get": {
-                "description": "Get all payees",
+                "description": "List the buys, sells and dividends of an investment account.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Payees"
+                    "Investments"
                 ],
-                "summary": "List payees",
+                "summary": "List the investment transactions of an account",
                 "parameters": [
                     {
                         "type": "string",
@@ -891,6 +1039,13 @@ const docTemplate = `{
                         "name": "budget_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -899,7 +1054,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/db.Payee"
+                                "$ref": "#/definitions/db.InvestmentTransaction"
                             }
                         }
                     },
@@ -924,7 +1079,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a spending recipient.",
+                "description": "Record a buy, sell or dividend of a security in an investment account. Buys and sells need a quantity,\nand an account can only sell the shares it holds on the date of the sale and does not sell later. The amount is paid from, or into, the cash of the account.\nIt is in milliunits, thousandths of the unit of the currency, or a decimal in the currency with amount_decimal.",
                 "consumes": [
                     "application/json"
                 ],
@@ -932,9 +1087,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payees"
+                    "Investments"
                 ],
-                "summary": "Create a payee",
+                "summary": "Create an investment transaction",
                 "parameters": [
                     {
                         "type": "string",
@@ -944,12 +1099,19 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Payee details",
-                        "name": "account",
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Investment transaction",
+                        "name": "transaction",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.payeeRqst"
+                            "$ref": "#/definitions/api.investmentTransactionRequest"
                         }
                     }
                 ],
@@ -957,7 +1119,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Payee"
+                            "$ref": "#/definitions/db.InvestmentTransaction"
                         }
                     },
                     "400": {
@@ -966,6 +1128,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -975,16 +1143,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/budgets/{budget_id}/payees/{payee_id}": {
+        "/budgets/{budget_id}/accounts/{account_id}/loan": {
             "get": {
-                "description": "Get a payee by id",
+                "description": "Get the loan details of a mortgage, auto loan or liability account, with its amortization schedule.\nThe transfers to the account are its payments, grouped by the payment period they fall in. The interest of a period accrues once, and payments pay it before the principal.\nThe schedule projects the payments left from the period after the last payment, with the extra payment, until the payoff date.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Payees"
+                    "Accounts"
                 ],
-                "summary": "Get payee",
+                "summary": "Get the loan details of an account",
                 "parameters": [
                     {
                         "type": "string",
@@ -995,8 +1163,8 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Payee ID",
-                        "name": "payee_id",
+                        "description": "Account ID",
+                        "name": "account_id",
                         "in": "path",
                         "required": true
                     }
@@ -1005,7 +1173,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Payee"
+                            "$ref": "#/definitions/Loan"
                         }
                     },
                     "400": {
@@ -1029,7 +1197,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update a payee.",
+                "description": "Set the loan details of a mortgage, auto loan or liability account. Payments are due every month from the month after the start date.\nAmounts are in milliunits, thousandths of the unit of the currency, or decimals in the currency in the fields ending with _decimal.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1037,9 +1205,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payees"
+                    "Accounts"
                 ],
-                "summary": "Update a payee",
+                "summary": "Set the loan details of an account",
                 "parameters": [
                     {
                         "type": "string",
@@ -1050,18 +1218,18 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Payee ID",
-                        "name": "Payee_id",
+                        "description": "Account ID",
+                        "name": "account_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Payee details",
-                        "name": "account",
+                        "description": "Loan details",
+                        "name": "loan",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.payeeRqst"
+                            "$ref": "#/definitions/api.loanRequest"
                         }
                     }
                 ],
@@ -1069,7 +1237,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Payee"
+                            "$ref": "#/definitions/Loan"
                         }
                     },
                     "400": {
@@ -1093,17 +1261,14 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a payee.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete the loan details of an account. The account and its transactions are kept.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Payees"
+                    "Accounts"
                 ],
-                "summary": "Delete a payee",
+                "summary": "Delete the loan details of an account",
                 "parameters": [
                     {
                         "type": "string",
@@ -1114,15 +1279,15 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Payee ID",
-                        "name": "Payee_id",
+                        "description": "Account ID",
+                        "name": "account_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "payee deleted",
+                        "description": "loan details deleted",
                         "schema": {
                             "type": "string"
                         }
@@ -1148,19 +1313,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/budgets/{budget_id}/transactions": {
+        "/budgets/{budget_id}/categories": {
             "get": {
-                "description": "List all transactions across all accounts in the budget.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List all categories in a budget grouped by category group, with their balances.\nCredit card accounts have a payment category, which receives the money of the spending on the card that categories could cover.\nOverspending is split between cash overspending, which already left on-budget accounts, and credit overspending, which became credit card debt.\nThe transactions of accounts in other currencies are converted into the currency of the budget at the rates of their dates.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Categories"
                 ],
-                "summary": "List all transactions",
+                "summary": "Get category groups and categories",
                 "parameters": [
                     {
                         "type": "string",
@@ -1176,7 +1338,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/db.TransactionsView"
+                                "$ref": "#/definitions/api.categoryResponse"
                             }
                         }
                     },
@@ -1199,9 +1361,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/budgets/{budget_id}/categories/{category_group_id}": {
             "post": {
-                "description": "Create a transaction.",
+                "description": "Create a budgeting category.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1211,7 +1375,7 @@ const docTemplate = `{
                 "tags": [
                     "Categories"
                 ],
-                "summary": "Create a transaction",
+                "summary": "Create a budgeting category",
                 "parameters": [
                     {
                         "type": "string",
@@ -1221,12 +1385,19 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Transaction details",
-                        "name": "transaction",
+                        "type": "string",
+                        "description": "Category Group ID",
+                        "name": "category_group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Category details",
+                        "name": "account",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/TransactionRequest"
+                            "$ref": "#/definitions/api.categoryRqst"
                         }
                     }
                 ],
@@ -1234,7 +1405,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.TransactionsView"
+                            "$ref": "#/definitions/db.Category"
                         }
                     },
                     "400": {
@@ -1243,12 +1414,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/api.HTTPError"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1258,9 +1423,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/budgets/{budget_id}/transactions/{transaction_id}": {
-            "get": {
-                "description": "Get the details of a transaction",
+        "/budgets/{budget_id}/categories/{category_id}": {
+            "put": {
+                "description": "Rename a budgeting category, or change the amount assigned to it.\nThe amount assigned is in milliunits, thousandths of the unit of the currency of the budget, or a decimal in the currency with assigned_decimal.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1268,9 +1433,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Categories"
                 ],
-                "summary": "Get a transaction",
+                "summary": "Update a budgeting category",
                 "parameters": [
                     {
                         "type": "string",
@@ -1281,17 +1446,26 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Transaction ID",
-                        "name": "transaction_id",
+                        "description": "Category ID",
+                        "name": "category_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Category details",
+                        "name": "account",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.updateCategoryRqst"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/TransactionResponse"
+                            "$ref": "#/definitions/db.Category"
                         }
                     },
                     "400": {
@@ -1300,12 +1474,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/api.HTTPError"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1313,11 +1481,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/renew_token": {
-            "post": {
-                "description": "Renew access token using a refresh token.",
+            },
+            "delete": {
+                "description": "Delete a category.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1325,29 +1491,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Security"
+                    "Categories"
                 ],
-                "summary": "Renew token",
+                "summary": "Delete a category",
                 "parameters": [
                     {
-                        "description": "Refresh token",
-                        "name": "refresh_token",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/RenewTokenRequest"
-                        }
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "category_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "category deleted",
                         "schema": {
-                            "$ref": "#/definitions/RenewTokenResponse"
+                            "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/api.HTTPError"
                         }
@@ -1367,37 +1538,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/user": {
-            "put": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Update the authenticated user's account.",
+        "/budgets/{budget_id}/category-groups": {
+            "get": {
+                "description": "List all category groups associated with a budget.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Categories"
                 ],
-                "summary": "Update user",
+                "summary": "List all budgeting category groups",
                 "parameters": [
                     {
-                        "description": "Update account",
-                        "name": "account",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/UpdateUserRequest"
-                        }
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/UserResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.CategoryGroup"
+                            }
                         }
                     },
                     "400": {
@@ -1406,12 +1573,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/api.HTTPError"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1427,7 +1588,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a new user account. An email is sent asking the user to verify their email.",
+                "description": "Create a budgeting category group.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1435,25 +1596,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Categories"
                 ],
-                "summary": "Create user",
+                "summary": "Create a budgeting category group",
                 "parameters": [
                     {
-                        "description": "Create a new user",
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Category group details",
                         "name": "account",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/CreateUserRequest"
+                            "$ref": "#/definitions/api.categoryGroupRqst"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/UserResponse"
+                            "$ref": "#/definitions/db.CategoryGroup"
                         }
                     },
                     "400": {
@@ -1462,14 +1630,62 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.HTTPError"
                         }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/category-groups/{category_group_id}": {
+            "put": {
+                "description": "Update a budgeting category group.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Update a budgeting category group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    {
+                        "type": "string",
+                        "description": "Category Group ID",
+                        "name": "category_group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Category group details",
+                        "name": "account",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.categoryGroupRqst"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.CategoryGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/api.HTTPError"
                         }
@@ -1483,28 +1699,42 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
+                "description": "Delete a budgeting category group and all categories in the group.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Delete the authenticated user's account.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Categories"
+                ],
+                "summary": "Delete a category group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category Group ID",
+                        "name": "category_group_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Delete user",
                 "responses": {
                     "200": {
-                        "description": "user has been deleted",
+                        "description": "category group deleted",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/api.HTTPError"
                         }
@@ -1523,52 +1753,4254 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "CreateUserRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "password",
-                "username"
+        },
+        "/budgets/{budget_id}/exchange-rates": {
+            "get": {
+                "description": "List the exchange rates of a budget, optionally between two currencies.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange rates"
+                ],
+                "summary": "List exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency converted from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency converted to",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ExchangeRate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Set exchange rates on dates, replacing the rates already set for the same currencies and date.\nAmounts are converted at the last rate on or before their date, the other way around with the inverse rate,\nor through a third currency when there is no rate between two currencies.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange rates"
+                ],
+                "summary": "Set exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Exchange rates",
+                        "name": "rates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.exchangeRatesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "exchange rates saved",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/exchange-rates/import": {
+            "post": {
+                "description": "Import the euro reference rates of the European Central Bank, from a file such as eurofxref-daily.xml or eurofxref-hist.xml.\nThe file is imported whole or not at all, and can be up to 16 MB with up to 200000 rates.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange rates"
+                ],
+                "summary": "Import exchange rates from an ECB file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "ECB XML file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "exchange rates imported",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/payees": {
+            "get": {
+                "description": "Get all payees",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payees"
+                ],
+                "summary": "List payees",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.Payee"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a spending recipient.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payees"
+                ],
+                "summary": "Create a payee",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payee details",
+                        "name": "account",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.payeeRqst"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Payee"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/payees/{payee_id}": {
+            "get": {
+                "description": "Get a payee by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payees"
+                ],
+                "summary": "Get payee",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payee ID",
+                        "name": "payee_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Payee"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a payee.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payees"
+                ],
+                "summary": "Update a payee",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payee ID",
+                        "name": "Payee_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payee details",
+                        "name": "account",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.payeeRqst"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Payee"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a payee.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payees"
+                ],
+                "summary": "Delete a payee",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payee ID",
+                        "name": "Payee_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "payee deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/prices": {
+            "get": {
+                "description": "List the price history of the securities of a budget, optionally of a single symbol.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Investments"
+                ],
+                "summary": "List the prices of securities",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Symbol of the security",
+                        "name": "symbol",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.SecurityPrice"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Set the prices of securities on dates, replacing the prices already set for the same symbol and date.\nPrices are in milliunits of the currency of the budget, which investment accounts are in, or decimals in the currency in price_decimal.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Investments"
+                ],
+                "summary": "Set prices of securities",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Prices",
+                        "name": "prices",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.securityPricesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "prices saved",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/prices/import": {
+            "post": {
+                "description": "Import prices from a CSV file with a header row naming the symbol, date (YYYY-MM-DD) and price columns.\nPrices are in milliunits, thousandths of the unit of the currency of the budget, or decimals in the currency in a price_decimal column instead.\nThe file is imported whole or not at all.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Investments"
+                ],
+                "summary": "Import prices of securities from a CSV file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "prices imported",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/reports/fx-gains": {
+            "get": {
+                "description": "List the transfers between accounts in different currencies. Both sides are converted into the currency of the budget\nat the rates of the date of the transfer, and the difference between what was received and what was sent is the gain or loss.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get the exchange gains and losses of a budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/FXGains"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/reports/net-worth": {
+            "get": {
+                "description": "Get the net worth of a budget on a date: the balances of its accounts, plus the market value of the holdings of investment accounts.\nThe balances on a past date leave out the transactions after it. The balance of an investment account is its cash: buys are paid from it, sells and dividends paid into it.\nHoldings are valued at the last price of each security on or before the date. Accounts with a positive total are assets, the others liabilities.\nThe totals are in the currency of the budget, at the exchange rates of the date. Prices are in the currency of the budget, which investment accounts are in.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get the net worth of a budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date of the net worth (YYYY-MM-DD), today if left out",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/NetWorth"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/transactions": {
+            "get": {
+                "description": "List all transactions across all accounts in the budget.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "List all transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/TransactionView"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a transaction. Outflows of on-budget accounts need a category, those of tracking accounts have none.\nInflows of on-budget accounts without category are ready to assign.\nTransfers use the transfer payee of the other account, and have no category between on-budget accounts. Transfers to a credit card are payments.\nTransfers to an account in another currency need the amount in its currency, the difference with the exchange rate being a gain or loss.\nAmounts are in milliunits, thousandths of the unit of the currency, or decimals in the currency in the fields ending with _decimal.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Create a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transaction details",
+                        "name": "transaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CreatedTransaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/transactions/{transaction_id}": {
+            "get": {
+                "description": "Get the details of a transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Get a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "transaction_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List all webhooks of a budget.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/WebhookResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Subscribe a URL to budget events. The response contains the secret used to sign deliveries, which is not shown again. Events: transaction.created, account.reconciled and category.overspent; transaction.updated is not available until transactions can be updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Create a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook details",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/webhooks/{webhook_id}": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update the URL, the events or the status of a webhook. transaction.updated cannot be subscribed to until transactions can be updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Update a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook details",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete a webhook and its delivery log.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "webhook deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/webhooks/{webhook_id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the most recent deliveries of a webhook, including the status code returned by the receiver.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/WebhookDeliveryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{budget_id}/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Send a previous delivery again. A new entry is added to the delivery log.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Redeliver a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/WebhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/email_change/cancel": {
+            "get": {
+                "description": "Serve the page of the cancel link sent to the current address: a form which asks to confirm, and posts the token to /email_change/cancel.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Cancel email change page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token of the cancel link",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "cancel email change page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Cancel a pending email change with the token of the link sent to the current address.",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Cancel an email change",
+                "parameters": [
+                    {
+                        "description": "Token of the cancel link",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/EmailChangeTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "email change has been cancelled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/email_change/confirm": {
+            "get": {
+                "description": "Serve the page of the confirmation link sent to the new address: a form which asks to confirm, and posts the token to /email_change/confirm.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Confirm email change page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token of the confirmation link",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "confirm email change page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Change the email of an account with the token of the confirmation link sent to the new address. The new email is verified.",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Confirm an email change",
+                "parameters": [
+                    {
+                        "description": "Token of the confirmation link",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/EmailChangeTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/2fa": {
+            "post": {
+                "description": "Complete the login of a user with two-factor authentication, using the challenge token returned by login and a TOTP code or a recovery code.\nA challenge token allows a few attempts and is used once. Failed codes count as failed logins of the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Login second step",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "challenge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/LoginTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/magic_link": {
+            "post": {
+                "description": "Send a single-use sign-in link to the email of an account, for logging in without a password. The link only works in the browser which asked for it,\nwhich is identified by a cookie set in the response. The response does not tell whether the email belongs to an account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Request a magic link",
+                "parameters": [
+                    {
+                        "description": "Email of the account",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MagicLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "if the email belongs to an account, a sign-in link has been sent",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/magic_link/verify": {
+            "get": {
+                "description": "Log in with the token of a sign-in link, from the browser which asked for it. Returns the same response as a login with a password,\nor a challenge token if the user has two-factor authentication.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Log in with a magic link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token of the sign-in link",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/oidc": {
+            "get": {
+                "description": "Start a login with the OpenID Connect identity provider: redirects the browser to the provider, which redirects it back to the callback.",
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Log in with the identity provider",
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/oidc/callback": {
+            "get": {
+                "description": "Callback of the identity provider, in the browser which started the login. The identity is linked to the account with the same email, verified by both the provider and the account,\non first login, or to a new account if signups are allowed. Returns the same response as a login with a password,\nor a challenge token if the user has two-factor authentication.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Complete a login with the identity provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State of the login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Revoke the session of the access token used for the request.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "logged out",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/authorize": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Validate an authorization request (RFC 6749 4.1.1) from a third-party app, and return what the user is asked to consent to. PKCE with S256 is required.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Start an OAuth authorization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Must be code",
+                        "name": "response_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered redirect URI",
+                        "name": "redirect_uri",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space-delimited scopes",
+                        "name": "scope",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque value returned to the client",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge",
+                        "name": "code_challenge",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Must be S256",
+                        "name": "code_challenge_method",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/AuthorizeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Record the consent decision of the user for an authorization request. Returns the redirect URI to send the user agent to, with either an authorization code or an access_denied error.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Consent to an OAuth authorization",
+                "parameters": [
+                    {
+                        "description": "Authorization request and decision",
+                        "name": "authorization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AuthorizeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/AuthorizeDecisionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/clients": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the OAuth clients registered by the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "List OAuth clients",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/OAuthClientResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Register a third-party app which can then ask users for access to their budgets. The client secret of confidential clients is only shown in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Register an OAuth client",
+                "parameters": [
+                    {
+                        "description": "Client details",
+                        "name": "client",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/OAuthClientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/OAuthClientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/clients/{client_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete an OAuth client registered by the authenticated user. All tokens issued to the client are revoked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Delete an OAuth client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "client deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "Exchange an authorization code and its PKCE code verifier, or a refresh token, for an access token (RFC 6749 4.1.3 and 6). Refresh tokens are rotated on every use, and replaying a used one revokes the session. Confidential clients authenticate with HTTP basic auth or client_secret.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "OAuth token endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "authorization_code or refresh_token",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Redirect URI used in the authorization request",
+                        "name": "redirect_uri",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code verifier",
+                        "name": "code_verifier",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refresh token",
+                        "name": "refresh_token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret of confidential clients",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/OAuthTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/OAuthError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/OAuthError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/OAuthError"
+                        }
+                    }
+                }
+            }
+        },
+        "/password_reset": {
+            "get": {
+                "description": "Serve the page of the password reset link: a form which posts the token and a new password to /password_reset/confirm.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Password reset page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Password reset token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "password reset page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Send a single-use password reset link to the email of an account. The response does not tell whether the email belongs to an account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Request a password reset",
+                "parameters": [
+                    {
+                        "description": "Email of the account",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/PasswordResetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "if the email belongs to an account, a password reset link has been sent",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/password_reset/confirm": {
+            "post": {
+                "description": "Set a new password using the token of a password reset link. All existing sessions of the user are blocked, and their personal access tokens revoked.\nThe password must meet the password policy, otherwise the rules it fails are returned.",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Reset a password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "reset",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/PasswordResetConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "password has been reset",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/PasswordPolicyError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/renew_token": {
+            "post": {
+                "description": "Renew access token using a refresh token. The refresh token is rotated: the response contains a new one, and the old one cannot be used again. Presenting an already used refresh token revokes the session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Renew token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "refresh_token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/RenewTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/RenewTokenResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/revoke": {
+            "get": {
+                "description": "Serve the page of the revoke link sent in the new login email: a form which asks to confirm, and posts the token to /sessions/revoke.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Revoke login page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token of the revoke link",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "revoke login page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Log out the session of a login from a new device, with the token of the link sent in the alert email.",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Security"
+                ],
+                "summary": "Revoke the session of a login",
+                "parameters": [
+                    {
+                        "description": "Token of the revoke link",
+                        "name": "revoke",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/RevokeLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "session revoked",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update the authenticated user's account. Changing the password requires the current password.\nA new password must meet the password policy, otherwise the rules it fails are returned.\nThe email cannot be changed here, see POST /user/email.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "description": "Update account",
+                        "name": "account",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/PasswordPolicyError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new user account. An email is sent asking the user to verify their email.\nThe password must meet the password policy, otherwise the rules it fails are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "description": "Create a new user",
+                        "name": "account",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/PasswordPolicyError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete the authenticated user's account.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete user",
+                "responses": {
+                    "200": {
+                        "description": "user has been deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Disable two-factor authentication for the authenticated user. The user has to re-authenticate with their password and a TOTP code or a recovery code. Wrong passwords and codes count as failed logins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Password and code",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/DisableTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "two-factor authentication disabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa/totp": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Generate a TOTP secret for the authenticated user. Two-factor authentication is enabled once a code generated from the secret is confirmed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Start two-factor enrollment",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TOTPEnrollmentResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa/totp/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Enable two-factor authentication with a code from the authenticator app. Returns one-time recovery codes, which are only shown in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Confirm two-factor enrollment",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TOTPConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/email": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Ask to change the email of the authenticated user, which requires the current password. A confirmation link is sent to the new address,\nand a notification with a link to cancel the change to the current one. The email is only changed once the new address is confirmed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Change email",
+                "parameters": [
+                    {
+                        "description": "New email and current password",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/EmailChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "a confirmation link has been sent to the new email",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/logins": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the recent logins of the authenticated user, successful or not, most recent first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Login history",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/LoginEvent"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the active sessions of the authenticated user, i.e. the devices and apps where they are logged in.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/SessionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Revoke all sessions of the authenticated user, including the current one and the sessions of OAuth clients.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Log out everywhere",
+                "responses": {
+                    "200": {
+                        "description": "all sessions revoked",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/sessions/{session_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Log out the authenticated user from one of their sessions. The refresh and access tokens of the session stop working immediately.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "session revoked",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the personal access tokens of the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "List personal access tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/PersonalAccessTokenResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create a long-lived token for scripts and integrations. Scopes are read, write and budget:{budget_id}. The token is only shown in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Create a personal access token",
+                "parameters": [
+                    {
+                        "description": "Token details",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/PersonalAccessTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/PersonalAccessTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/tokens/{token_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Revoke a personal access token of the authenticated user. The token stops working immediately.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Revoke a personal access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token ID",
+                        "name": "token_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "token revoked",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "Account": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "balance_decimal": {
+                    "type": "string",
+                    "example": "1250.00"
+                },
+                "budget_id": {
+                    "type": "string"
+                },
+                "cleared_balance": {
+                    "type": "integer"
+                },
+                "cleared_balance_decimal": {
+                    "type": "string",
+                    "example": "1000.00"
+                },
+                "closed": {
+                    "type": "boolean"
+                },
+                "currency_code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_reconciled_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "note": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "on_budget": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "uncleared_balance": {
+                    "type": "integer"
+                },
+                "uncleared_balance_decimal": {
+                    "type": "string",
+                    "example": "250.00"
+                }
+            }
+        },
+        "AdminUser": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "fname.lname@contoso.com"
+                },
+                "email_verified": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "last_password_change": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "admin"
+                    ]
+                },
+                "totp_enabled": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "username": {
+                    "type": "string",
+                    "example": "rjoooidggt"
+                }
+            }
+        },
+        "AdminUserList": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/AdminUser"
+                    }
+                }
+            }
+        },
+        "AuditLogEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "block_sessions"
+                },
+                "admin_username": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "client_ip": {
+                    "type": "string",
+                    "example": "127.0.0.1"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "target_username": {
+                    "type": "string",
+                    "example": "rjoooidggt"
+                }
+            }
+        },
+        "AuthorizeDecisionResponse": {
+            "type": "object",
+            "properties": {
+                "redirect_uri": {
+                    "type": "string",
+                    "example": "https://sheets.example.com/callback?code=4f3c2a...\u0026state=af0ifjsldkj"
+                }
+            }
+        },
+        "AuthorizeRequest": {
+            "type": "object",
+            "required": [
+                "client_id",
+                "code_challenge",
+                "code_challenge_method",
+                "redirect_uri",
+                "response_type",
+                "scope"
+            ],
+            "properties": {
+                "approve": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "client_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "code_challenge": {
+                    "type": "string",
+                    "example": "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+                },
+                "code_challenge_method": {
+                    "type": "string",
+                    "example": "S256"
+                },
+                "redirect_uri": {
+                    "type": "string",
+                    "example": "https://sheets.example.com/callback"
+                },
+                "response_type": {
+                    "type": "string",
+                    "example": "code"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "read budget:ea930f68-e192-407d..."
+                },
+                "state": {
+                    "type": "string",
+                    "example": "af0ifjsldkj"
+                }
+            }
+        },
+        "AuthorizeResponse": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "client_name": {
+                    "type": "string",
+                    "example": "Budget Sheets"
+                },
+                "redirect_uri": {
+                    "type": "string",
+                    "example": "https://sheets.example.com/callback"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "budget:ea930f68-e192-407d..."
+                    ]
+                }
+            }
+        },
+        "CategoryBalance": {
+            "type": "object",
+            "properties": {
+                "activity": {
+                    "type": "integer",
+                    "example": -10000
+                },
+                "assigned": {
+                    "type": "integer",
+                    "example": 12000
+                },
+                "available": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "cash_overspent": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "category_group_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "credit_overspent": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Rent"
+                },
+                "payment_account_id": {
+                    "description": "Set for the payment category of a credit card account",
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                }
+            }
+        },
+        "CreateUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "fname.lname@contoso.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password123456"
+                },
+                "username": {
+                    "type": "string",
+                    "minLength": 5
+                }
+            }
+        },
+        "CreatedTransaction": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "amount_decimal": {
+                    "type": "string",
+                    "example": "-12.50"
+                },
+                "approved": {
+                    "type": "boolean"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "cleared": {
+                    "type": "boolean"
+                },
+                "date": {
+                    "$ref": "#/definitions/pgtype.Date"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "memo": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "payee_id": {
+                    "type": "string"
+                },
+                "reconciled": {
+                    "type": "boolean"
+                },
+                "transfer_amount": {
+                    "$ref": "#/definitions/pgtype.Int8"
+                }
+            }
+        },
+        "DetailedBudgetResponse": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.Account"
+                    }
+                },
+                "currency_code": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "My USD Budget"
+                },
+                "ready_to_assign": {
+                    "description": "Inflows of on-budget accounts without category less the money assigned to categories, in milliunits",
+                    "type": "integer",
+                    "example": 150000
+                }
+            }
+        },
+        "DisableTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password123456"
+                }
+            }
+        },
+        "EmailChangeRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "fname.lname@contoso.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password123456"
+                }
+            }
+        },
+        "EmailChangeTokenRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "4f3c2a..."
+                }
+            }
+        },
+        "ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2024-05-31"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 1.0812
+                },
+                "to": {
+                    "type": "string",
+                    "example": "USD"
+                }
+            }
+        },
+        "FXGains": {
+            "type": "object",
+            "properties": {
+                "currency_code": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": -210
+                },
+                "transfers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/FXTransfer"
+                    }
+                }
+            }
+        },
+        "FXTransfer": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "amount": {
+                    "type": "integer",
+                    "example": -100000
+                },
+                "currency_code": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-05-31"
+                },
+                "gain": {
+                    "type": "integer",
+                    "example": -210
+                },
+                "received": {
+                    "type": "integer",
+                    "example": 99790
+                },
+                "sent": {
+                    "description": "Both sides in the currency of the budget at the rates of the date, and the difference between them",
+                    "type": "integer",
+                    "example": -100000
+                },
+                "transaction_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "transfer_account_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "transfer_amount": {
+                    "type": "integer",
+                    "example": 107900
+                },
+                "transfer_currency_code": {
+                    "type": "string",
+                    "example": "USD"
+                }
+            }
+        },
+        "Holding": {
+            "type": "object",
+            "properties": {
+                "cost_basis": {
+                    "type": "integer",
+                    "example": 1150000
+                },
+                "gain": {
+                    "type": "integer",
+                    "example": 91100
+                },
+                "market_value": {
+                    "description": "Valued at the cost basis when there is no price",
+                    "type": "integer",
+                    "example": 1241100
+                },
+                "price": {
+                    "description": "Last price on or before the date, left out if the security has no price yet",
+                    "type": "integer",
+                    "example": 118200
+                },
+                "price_date": {
+                    "type": "string",
+                    "example": "2024-05-31"
+                },
+                "quantity": {
+                    "type": "number",
+                    "example": 10.5
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "VWCE"
+                }
+            }
+        },
+        "Loan": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "balance": {
+                    "description": "Principal left after the payments that were made",
+                    "type": "integer",
+                    "example": 249773990
+                },
+                "extra_payment": {
+                    "type": "integer",
+                    "example": 100000
+                },
+                "interest_paid": {
+                    "type": "integer",
+                    "example": 1354170
+                },
+                "interest_rate": {
+                    "type": "number",
+                    "example": 6.5
+                },
+                "monthly_payment": {
+                    "description": "Scheduled monthly payment, without the extra payment",
+                    "type": "integer",
+                    "example": 1580180
+                },
+                "payment_day": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "payments": {
+                    "description": "Payments that were made",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/LoanPayment"
+                    }
+                },
+                "payoff_date": {
+                    "type": "string",
+                    "example": "2049-03-01"
+                },
+                "principal": {
+                    "type": "integer",
+                    "example": 250000000
+                },
+                "schedule": {
+                    "description": "Payments left until the loan is paid off",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/LoanPayment"
+                    }
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-01-15"
+                },
+                "term_months": {
+                    "type": "integer",
+                    "example": 360
+                },
+                "total_interest": {
+                    "type": "integer",
+                    "example": 263865000
+                }
+            }
+        },
+        "LoanPayment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 1580180
+                },
+                "balance": {
+                    "type": "integer",
+                    "example": 249773990
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-02-01"
+                },
+                "interest": {
+                    "type": "integer",
+                    "example": 1354170
+                },
+                "number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "principal": {
+                    "type": "integer",
+                    "example": 226010
+                },
+                "transaction_id": {
+                    "description": "Set for the payments that were made, which are transfers to the loan account",
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                }
+            }
+        },
+        "LoginEvent": {
+            "type": "object",
+            "properties": {
+                "client_ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "session_id": {
+                    "description": "Set for successful logins",
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0 (X11; Linux x86_64)..."
+                }
+            }
+        },
+        "LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1Ni..."
+                },
+                "access_token_expires_at": {
+                    "type": "string",
+                    "example": "2023-10-30T22:14:50+08:00"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "fname.lname@contoso.com"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "last_password_change": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1Ni..."
+                },
+                "refresh_token_expires_at": {
+                    "type": "string",
+                    "example": "2023-10-31T22:14:50+08:00"
+                },
+                "session_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "username": {
+                    "type": "string",
+                    "example": "rjoooidggt"
+                }
+            }
+        },
+        "LoginTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1Ni..."
+                },
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "MagicLinkRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "fname.lname@contoso.com"
+                }
+            }
+        },
+        "NetWorth": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/NetWorthAccount"
+                    }
+                },
+                "assets": {
+                    "type": "integer",
+                    "example": 1291100
+                },
+                "currency_code": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-05-31"
+                },
+                "liabilities": {
+                    "type": "integer",
+                    "example": -250000
+                },
+                "net_worth": {
+                    "type": "integer",
+                    "example": 1041100
+                }
+            }
+        },
+        "NetWorthAccount": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "balance": {
+                    "description": "On the date, in the currency of the account. The cash of investment accounts.",
+                    "type": "integer",
+                    "example": 50000
+                },
+                "currency_code": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "market_value": {
+                    "description": "Market value of the holdings of investment accounts, in the currency of the account",
+                    "type": "integer",
+                    "example": 1241100
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Broker"
+                },
+                "total": {
+                    "description": "In the currency of the budget",
+                    "type": "integer",
+                    "example": 1190550
+                },
+                "type": {
+                    "type": "string",
+                    "example": "investment"
+                }
+            }
+        },
+        "OAuthClientRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "redirect_uris",
+                "scopes"
+            ],
+            "properties": {
+                "confidential": {
+                    "description": "Confidential clients, i.e. server-side apps, get a client secret. Public clients only use PKCE.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "minLength": 2,
+                    "example": "Budget Sheets"
+                },
+                "redirect_uris": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://sheets.example.com/callback"
+                    ]
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read"
+                    ]
+                }
+            }
+        },
+        "OAuthClientResponse": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "client_secret": {
+                    "type": "string",
+                    "example": "4f3c2a..."
+                },
+                "confidential": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Budget Sheets"
+                },
+                "redirect_uris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://sheets.example.com/callback"
+                    ]
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read"
+                    ]
+                }
+            }
+        },
+        "OAuthError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid_grant"
+                },
+                "error_description": {
+                    "type": "string",
+                    "example": "authorization code has expired"
+                }
+            }
+        },
+        "OAuthTokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1Ni..."
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1Ni..."
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "read budget:ea930f68-e192-407d..."
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "PasswordPolicyError": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/PasswordViolation"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "example": "password does not meet the password policy"
+                }
+            }
+        },
+        "PasswordResetConfirmRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "password123456"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "4f3c2a..."
+                }
+            }
+        },
+        "PasswordResetRequest": {
+            "type": "object",
+            "required": [
+                "email"
             ],
             "properties": {
                 "email": {
                     "type": "string",
                     "example": "fname.lname@contoso.com"
-                },
-                "password": {
+                }
+            }
+        },
+        "PasswordViolation": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
-                    "minLength": 10,
-                    "example": "password123456"
+                    "example": "must be at least 10 characters long"
                 },
-                "username": {
+                "rule": {
                     "type": "string",
-                    "minLength": 5
+                    "example": "min_length"
                 }
             }
         },
-        "DetailedBudgetResponse": {
+        "PersonalAccessTokenRequest": {
             "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
             "properties": {
-                "accounts": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-09-29T22:14:50+08:00"
+                },
+                "name": {
+                    "type": "string",
+                    "minLength": 2,
+                    "example": "Home Assistant"
+                },
+                "scopes": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
-                        "$ref": "#/definitions/db.Account"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "budget:ea930f68-e192-407d..."
+                    ]
+                }
+            }
+        },
+        "PersonalAccessTokenResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
                 },
-                "currency_code": {
+                "expires_at": {
                     "type": "string",
-                    "example": "USD"
+                    "example": "2024-09-29T22:14:50+08:00"
                 },
                 "id": {
                     "type": "string",
                     "example": "ea930f68-e192-407d..."
                 },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
                 "name": {
                     "type": "string",
-                    "example": "My USD Budget"
+                    "example": "Home Assistant"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "budget:ea930f68-e192-407d..."
+                    ]
+                },
+                "token": {
+                    "type": "string",
+                    "example": "gbpat_4f3c2a..."
+                }
+            }
+        },
+        "RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "k7mzq-4hp2x"
+                    ]
                 }
             }
         },
@@ -1595,18 +6027,96 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2023-10-31T22:14:50+08:00"
                 },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1Ni..."
+                },
+                "refresh_token_expires_at": {
+                    "type": "string",
+                    "example": "2023-11-28T22:14:50+08:00"
+                },
                 "session_id": {
                     "type": "string",
                     "example": "ea930f68-e192-407d..."
                 }
             }
         },
+        "RevokeLoginRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "4f3c2a..."
+                }
+            }
+        },
+        "SessionResponse": {
+            "type": "object",
+            "properties": {
+                "client_ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "current": {
+                    "description": "Whether this is the session of the request",
+                    "type": "boolean",
+                    "example": true
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2023-10-31T22:14:50+08:00"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "oauth_client_id": {
+                    "description": "Set for sessions of OAuth clients",
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0 (X11; Linux x86_64)..."
+                }
+            }
+        },
+        "TOTPConfirmRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "TOTPEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/gobudget:rjoooidggt?secret=JBSWY3DPEHPK3PXP\u0026issuer=gobudget"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
+                }
+            }
+        },
         "TransactionRequest": {
             "type": "object",
             "required": [
                 "account_id",
-                "amount",
-                "category_id",
                 "date",
                 "payee_id"
             ],
@@ -1615,9 +6125,16 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "amount": {
-                    "type": "integer"
+                    "description": "In milliunits, or as a decimal in the currency of the account with amount_decimal",
+                    "type": "integer",
+                    "example": -12500
+                },
+                "amount_decimal": {
+                    "type": "string",
+                    "example": "-12.50"
                 },
                 "category_id": {
+                    "description": "Required for on-budget accounts, not allowed for tracking accounts",
                     "type": "string"
                 },
                 "cleared": {
@@ -1634,6 +6151,15 @@ const docTemplate = `{
                 },
                 "reconciled": {
                     "type": "boolean"
+                },
+                "transfer_amount": {
+                    "description": "Required for transfers to an account in another currency: the amount the other account receives, or sends,\nin its currency",
+                    "type": "integer",
+                    "example": 92500
+                },
+                "transfer_amount_decimal": {
+                    "type": "string",
+                    "example": "92.50"
                 }
             }
         },
@@ -1646,6 +6172,10 @@ const docTemplate = `{
                 "amount": {
                     "type": "integer"
                 },
+                "amount_decimal": {
+                    "type": "string",
+                    "example": "-12.50"
+                },
                 "approved": {
                     "type": "boolean"
                 },
@@ -1655,12 +6185,71 @@ const docTemplate = `{
                 "cleared": {
                     "type": "boolean"
                 },
+                "currency_code": {
+                    "description": "Currency of the account, in which the amount is",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "date": {
+                    "$ref": "#/definitions/pgtype.Date"
+                },
+                "memo": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "payee_name": {
+                    "type": "string"
+                },
+                "reconciled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "TransactionView": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string"
+                },
+                "account_name": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "amount_decimal": {
+                    "type": "string",
+                    "example": "-12.50"
+                },
+                "approved": {
+                    "type": "boolean"
+                },
+                "budget_id": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "category_name": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "cleared": {
+                    "type": "boolean"
+                },
+                "currency_code": {
+                    "type": "string"
+                },
                 "date": {
                     "$ref": "#/definitions/pgtype.Date"
                 },
+                "id": {
+                    "type": "string"
+                },
                 "memo": {
                     "$ref": "#/definitions/pgtype.Text"
                 },
+                "payee_id": {
+                    "type": "string"
+                },
                 "payee_name": {
                     "type": "string"
                 },
@@ -1672,6 +6261,11 @@ const docTemplate = `{
         "UpdateUserRequest": {
             "type": "object",
             "properties": {
+                "current_password": {
+                    "description": "required to change the password",
+                    "type": "string",
+                    "example": "password654321"
+                },
                 "email": {
                     "type": "string",
                     "example": "fname.lname@contoso.com"
@@ -1682,6 +6276,31 @@ const docTemplate = `{
                 }
             }
         },
+        "UpdateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "events": {
+                    "description": "transaction.updated is added once transactions can be updated",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "transaction.created"
+                    ]
+                },
+                "url": {
+                    "description": "a pointer, unlike pgtype.Text, can be validated",
+                    "type": "string",
+                    "example": "https://example.com/hooks/gobudget"
+                }
+            }
+        },
         "UserResponse": {
             "type": "object",
             "properties": {
@@ -1697,13 +6316,120 @@ const docTemplate = `{
                     "type": "boolean",
                     "example": true
                 },
-                "last_password_change": {
+                "last_password_change": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "rjoooidggt"
+                }
+            }
+        },
+        "WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "delivered_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string",
+                    "example": "transaction.created"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "redelivery": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "webhook_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                }
+            }
+        },
+        "WebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "description": "transaction.updated is added once transactions can be updated",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "transaction.created",
+                        "account.reconciled"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/gobudget"
+                }
+            }
+        },
+        "WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "budget_id": {
+                    "type": "string",
+                    "example": "ea930f68-e192-407d..."
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-09-29T22:14:50+08:00"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "transaction.created",
+                        "account.reconciled"
+                    ]
+                },
+                "id": {
                     "type": "string",
-                    "example": "2023-09-29T22:14:50+08:00"
+                    "example": "ea930f68-e192-407d..."
                 },
-                "username": {
+                "secret": {
                     "type": "string",
-                    "example": "rjoooidggt"
+                    "example": "4f3c2a..."
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/gobudget"
                 }
             }
         },
@@ -1716,6 +6442,49 @@ const docTemplate = `{
                 }
             }
         },
+        "api.accountRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "type"
+            ],
+            "properties": {
+                "balance": {
+                    "description": "Starting balance in milliunits, or as a decimal in the currency of the account with balance_decimal",
+                    "type": "integer",
+                    "example": 1250000
+                },
+                "balance_decimal": {
+                    "type": "string",
+                    "example": "1250.00"
+                },
+                "currency_code": {
+                    "description": "Currency of the account, the one of the budget if left out",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Chase Savings"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "checking",
+                        "savings",
+                        "cash",
+                        "credit_card",
+                        "line_of_credit",
+                        "mortgage",
+                        "auto_loan",
+                        "asset",
+                        "liability",
+                        "investment"
+                    ],
+                    "example": "savings"
+                }
+            }
+        },
         "api.categoryGroupRqst": {
             "type": "object",
             "required": [
@@ -1735,7 +6504,7 @@ const docTemplate = `{
                 "categories": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/db.Category"
+                        "$ref": "#/definitions/CategoryBalance"
                     }
                 },
                 "category_group_id": {
@@ -1743,7 +6512,7 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string",
-                    "example": "Rent"
+                    "example": "Living Expenses"
                 }
             }
         },
@@ -1760,6 +6529,150 @@ const docTemplate = `{
                 }
             }
         },
+        "api.exchangeRateRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "from",
+                "rate",
+                "to"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2024-05-31"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "rate": {
+                    "description": "Worth of one unit of the from currency in the to currency",
+                    "type": "number",
+                    "example": 1.0812
+                },
+                "to": {
+                    "type": "string",
+                    "example": "USD"
+                }
+            }
+        },
+        "api.exchangeRatesRequest": {
+            "type": "object",
+            "required": [
+                "rates"
+            ],
+            "properties": {
+                "rates": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api.exchangeRateRequest"
+                    }
+                }
+            }
+        },
+        "api.investmentTransactionRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "symbol",
+                "type"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Cash paid for a buy, received for a sell or a dividend, in milliunits or as a decimal with amount_decimal",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1150000
+                },
+                "amount_decimal": {
+                    "type": "string",
+                    "example": "1150.00"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-05-02"
+                },
+                "memo": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "Number of shares bought or sold, left out for dividends",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 10.5
+                },
+                "symbol": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "VWCE"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "buy",
+                        "sell",
+                        "dividend"
+                    ],
+                    "example": "buy"
+                }
+            }
+        },
+        "api.loanRequest": {
+            "type": "object",
+            "required": [
+                "payment_day",
+                "start_date",
+                "term_months"
+            ],
+            "properties": {
+                "extra_payment": {
+                    "description": "Paid every month on top of the scheduled payment, in milliunits or as a decimal with extra_payment_decimal",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 100000
+                },
+                "extra_payment_decimal": {
+                    "type": "string",
+                    "example": "100.00"
+                },
+                "interest_rate": {
+                    "description": "Annual interest rate, in percent",
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 6.5
+                },
+                "payment_day": {
+                    "type": "integer",
+                    "maximum": 28,
+                    "minimum": 1,
+                    "example": 1
+                },
+                "principal": {
+                    "description": "In milliunits, or as a decimal in the currency of the account with principal_decimal",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 250000000
+                },
+                "principal_decimal": {
+                    "type": "string",
+                    "example": "250000.00"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-01-15"
+                },
+                "term_months": {
+                    "type": "integer",
+                    "maximum": 600,
+                    "minimum": 1,
+                    "example": 360
+                }
+            }
+        },
         "api.payeeRqst": {
             "type": "object",
             "required": [
@@ -1773,16 +6686,69 @@ const docTemplate = `{
                 }
             }
         },
+        "api.securityPriceRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "symbol"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2024-05-31"
+                },
+                "price": {
+                    "description": "In milliunits of the currency of the budget, or as a decimal with price_decimal",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 118200
+                },
+                "price_decimal": {
+                    "type": "string",
+                    "example": "118.20"
+                },
+                "symbol": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "VWCE"
+                }
+            }
+        },
+        "api.securityPricesRequest": {
+            "type": "object",
+            "required": [
+                "prices"
+            ],
+            "properties": {
+                "prices": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api.securityPriceRequest"
+                    }
+                }
+            }
+        },
         "api.updateAccountRequest": {
             "type": "object",
             "properties": {
                 "balance": {
+                    "description": "Balances in milliunits, or as decimals in the currency of the account in the fields ending with _decimal",
                     "type": "integer",
-                    "example": 100
+                    "example": 1000
+                },
+                "balance_decimal": {
+                    "type": "string",
+                    "example": "1.00"
                 },
                 "cleared_balance": {
                     "type": "integer",
-                    "example": 50
+                    "example": 500
+                },
+                "cleared_balance_decimal": {
+                    "type": "string",
+                    "example": "0.50"
                 },
                 "closed": {
                     "type": "boolean",
@@ -1800,11 +6766,45 @@ const docTemplate = `{
                 },
                 "type": {
                     "type": "string",
-                    "example": "Savings"
+                    "enum": [
+                        "checking",
+                        "savings",
+                        "cash",
+                        "credit_card",
+                        "line_of_credit",
+                        "mortgage",
+                        "auto_loan",
+                        "asset",
+                        "liability",
+                        "investment"
+                    ],
+                    "example": "savings"
                 },
                 "uncleared_balance": {
                     "type": "integer",
-                    "example": 50
+                    "example": 500
+                },
+                "uncleared_balance_decimal": {
+                    "type": "string",
+                    "example": "0.50"
+                }
+            }
+        },
+        "api.updateCategoryRqst": {
+            "type": "object",
+            "properties": {
+                "assigned": {
+                    "description": "In milliunits, or as a decimal in the currency of the budget with assigned_decimal",
+                    "type": "integer",
+                    "example": 12000
+                },
+                "assigned_decimal": {
+                    "type": "string",
+                    "example": "12.00"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Rent"
                 }
             }
         },
@@ -1823,6 +6823,9 @@ const docTemplate = `{
                 "closed": {
                     "type": "boolean"
                 },
+                "currency_code": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -1835,6 +6838,9 @@ const docTemplate = `{
                 "note": {
                     "$ref": "#/definitions/pgtype.Text"
                 },
+                "on_budget": {
+                    "type": "boolean"
+                },
                 "type": {
                     "type": "string"
                 },
@@ -1846,6 +6852,9 @@ const docTemplate = `{
         "db.Category": {
             "type": "object",
             "properties": {
+                "assigned": {
+                    "type": "integer"
+                },
                 "category_group_id": {
                     "type": "string"
                 },
@@ -1854,6 +6863,9 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "payment_account_id": {
+                    "type": "string"
                 }
             }
         },
@@ -1871,23 +6883,6 @@ const docTemplate = `{
                 }
             }
         },
-        "db.CreateAccountParams": {
-            "type": "object",
-            "properties": {
-                "balance": {
-                    "type": "integer"
-                },
-                "budget_id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "type": {
-                    "type": "string"
-                }
-            }
-        },
         "db.CreateBudgetParams": {
             "type": "object",
             "properties": {
@@ -1902,47 +6897,18 @@ const docTemplate = `{
                 }
             }
         },
-        "db.Payee": {
-            "type": "object",
-            "properties": {
-                "budget_id": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                }
-            }
-        },
-        "db.TransactionsView": {
+        "db.InvestmentTransaction": {
             "type": "object",
             "properties": {
                 "account_id": {
                     "type": "string"
                 },
-                "account_name": {
-                    "type": "string"
-                },
                 "amount": {
                     "type": "integer"
                 },
-                "approved": {
-                    "type": "boolean"
-                },
-                "budget_id": {
-                    "type": "string"
-                },
-                "category_id": {
-                    "type": "string"
-                },
-                "category_name": {
+                "created_at": {
                     "type": "string"
                 },
-                "cleared": {
-                    "type": "boolean"
-                },
                 "date": {
                     "$ref": "#/definitions/pgtype.Date"
                 },
@@ -1952,60 +6918,51 @@ const docTemplate = `{
                 "memo": {
                     "$ref": "#/definitions/pgtype.Text"
                 },
-                "payee_id": {
-                    "type": "string"
+                "quantity": {
+                    "type": "number"
                 },
-                "payee_name": {
+                "symbol": {
                     "type": "string"
                 },
-                "reconciled": {
-                    "type": "boolean"
+                "type": {
+                    "type": "string"
                 }
             }
         },
-        "db.UpdateAccountParams": {
+        "db.Payee": {
             "type": "object",
             "properties": {
-                "balance": {
-                    "$ref": "#/definitions/pgtype.Int4"
-                },
                 "budget_id": {
                     "type": "string"
                 },
-                "cleared_balance": {
-                    "$ref": "#/definitions/pgtype.Int4"
-                },
-                "closed": {
-                    "$ref": "#/definitions/pgtype.Bool"
-                },
                 "id": {
                     "type": "string"
                 },
-                "last_reconciled_at": {
-                    "$ref": "#/definitions/pgtype.Timestamptz"
-                },
                 "name": {
-                    "$ref": "#/definitions/pgtype.Text"
-                },
-                "note": {
-                    "$ref": "#/definitions/pgtype.Text"
-                },
-                "type": {
-                    "$ref": "#/definitions/pgtype.Text"
+                    "type": "string"
                 },
-                "uncleared_balance": {
-                    "$ref": "#/definitions/pgtype.Int4"
+                "transfer_account_id": {
+                    "type": "string"
                 }
             }
         },
-        "pgtype.Bool": {
+        "db.SecurityPrice": {
             "type": "object",
             "properties": {
-                "bool": {
-                    "type": "boolean"
+                "budget_id": {
+                    "type": "string"
                 },
-                "valid": {
-                    "type": "boolean"
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "$ref": "#/definitions/pgtype.Date"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "symbol": {
+                    "type": "string"
                 }
             }
         },
@@ -2036,10 +6993,10 @@ const docTemplate = `{
                 "NegativeInfinity"
             ]
         },
-        "pgtype.Int4": {
+        "pgtype.Int8": {
             "type": "object",
             "properties": {
-                "int32": {
+                "int64": {
                     "type": "integer"
                 },
                 "valid": {
@@ -2057,20 +7014,6 @@ const docTemplate = `{
                     "type": "boolean"
                 }
             }
-        },
-        "pgtype.Timestamptz": {
-            "type": "object",
-            "properties": {
-                "infinityModifier": {
-                    "$ref": "#/definitions/pgtype.InfinityModifier"
-                },
-                "time": {
-                    "type": "string"
-                },
-                "valid": {
-                    "type": "boolean"
-                }
-            }
         }
     },
     "securityDefinitions": {
@@ -49,6 +49,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Parameters of new password hashes
+	util.SetArgon2Params(util.Argon2Params{
+		Memory:      config.Argon2Memory,
+		Iterations:  config.Argon2Iterations,
+		Parallelism: config.Argon2Parallelism,
+	})
+
 	// Create a DB connection
 	conn, err := pgxpool.New(context.Background(), config.DBConnString)
 	if err != nil {
@@ -75,6 +82,13 @@ func main() {
 	// Run the db migration
 	dbMigration(config.DBMigrationFiles, config.DBConnString)
 
+	// Make the first administrator, who can then manage the others through the API
+	if config.AdminBootstrapUsername != "" {
+		if err := api.BootstrapAdmin(context.Background(), dbStore, config.AdminBootstrapUsername); err != nil {
+			slog.Error("cannot bootstrap the administrator", "errmsg", err)
+		}
+	}
+
 	// Create the server
 	apiServer, err := api.NewServer(config, dbStore, taskDistributor)
 	if err != nil {
@@ -1,10 +1,9 @@
 package api
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
-	"slices"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -12,6 +11,35 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// Types of accounts. Keep in sync with the oneof validation in accountRequest, and with the accounts_type_check constraint.
+const (
+	AccountTypeChecking     = "checking"
+	AccountTypeSavings      = "savings"
+	AccountTypeCash         = "cash"
+	AccountTypeCreditCard   = "credit_card"
+	AccountTypeLineOfCredit = "line_of_credit"
+	AccountTypeMortgage     = "mortgage"
+	AccountTypeAutoLoan     = "auto_loan"
+	AccountTypeAsset        = "asset"
+	AccountTypeLiability    = "liability"
+	AccountTypeInvestment   = "investment"
+)
+
+// Whether the transactions of each type of account are budgeted in categories. The other types are tracking accounts,
+// which only follow a balance. Keep in sync with the on_budget column of accounts.
+var accountTypeOnBudget = map[string]bool{
+	AccountTypeChecking:     true,
+	AccountTypeSavings:      true,
+	AccountTypeCash:         true,
+	AccountTypeCreditCard:   true,
+	AccountTypeLineOfCredit: true,
+	AccountTypeMortgage:     false,
+	AccountTypeAutoLoan:     false,
+	AccountTypeAsset:        false,
+	AccountTypeLiability:    false,
+	AccountTypeInvestment:   false,
+}
+
 // getAccounts godoc
 //
 //	@Summary	List all budgeting accounts
@@ -21,7 +49,7 @@ import (
 //	@Tags			Accounts
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	[]db.CreateAccountParams
+//	@Success		200	{object}	[]accountResponse
 //	@Failure		400	{object}	HTTPError
 //	@Failure		404	{object}	HTTPError
 //	@Failure		500	{object}	HTTPError
@@ -40,7 +68,12 @@ func (s *Server) getAccounts(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	rsp := make([]accountResponse, len(accounts))
+	for i, a := range accounts {
+		rsp[i] = newAccountResponse(a)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 // getAccount godoc
@@ -53,7 +86,7 @@ func (s *Server) getAccounts(ctx *gin.Context) {
 //	@Tags			Accounts
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	db.CreateAccountParams
+//	@Success		200	{object}	accountResponse
 //	@Failure		400	{object}	HTTPError
 //	@Failure		500	{object}	HTTPError
 //	@Router			/budgets/{budget_id}/accounts/{account_id} [get]
@@ -91,27 +124,32 @@ func (s *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
 }
 
 // createAccount godoc
 //
 //	@Summary	Create a budgeting account
 //	@Schemes
-//	@Description	Create a budgeting account.
-//	@Param			budget_id	path	string					true	"Budget ID"
-//	@Param			account		body	updateAccountRequest	true	"Account details"
+//	@Description	Create a budgeting account. Checking, savings, cash, credit card and line of credit accounts are on budget: their transactions are budgeted in categories.
+//	@Description	Mortgage, auto loan, asset, liability and investment accounts are tracking accounts, which only follow a balance.
+//	@Description	Every account gets a payee for the transfers to it, and credit card accounts a payment category.
+//	@Description	Accounts are in the currency of the budget unless another one is given, which cannot be changed later.
+//	@Description	Investment accounts are in the currency of the budget, which the prices of securities are in.
+//	@Description	Amounts are in milliunits, thousandths of the unit of the currency, or decimals in the currency in the fields ending with _decimal.
+//	@Param			budget_id	path	string			true	"Budget ID"
+//	@Param			account		body	accountRequest	true	"Account details"
 //	@Tags			Accounts
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	db.CreateAccountParams
+//	@Success		200	{object}	accountResponse
 //	@Failure		400	{object}	HTTPError
 //	@Failure		500	{object}	HTTPError
 //	@Router			/budgets/{budget_id}/accounts [post]
 func (s *Server) createAccount(ctx *gin.Context) {
 
-	var budgetId uuid.UUID
-	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+	budget, err := s.getOwnedBudget(ctx)
+	if err != nil {
 		return
 	}
 
@@ -120,52 +158,66 @@ func (s *Server) createAccount(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
 		return
 	}
-
-	// TODO: improve validation of account type
-	accountTypes := []string{"savings", "checking", "lineofcredit"}
-	if !slices.Contains(accountTypes, strings.ToLower(rqst.Type)) {
-		ctx.JSON(http.StatusBadRequest, errorResponse("invalid account type"))
+	if rqst.CurrencyCode == "" {
+		rqst.CurrencyCode = budget.CurrencyCode
+	}
+	// the prices of securities are in the currency of the budget
+	if rqst.Type == AccountTypeInvestment && rqst.CurrencyCode != budget.CurrencyCode {
+		ctx.JSON(http.StatusBadRequest, errorResponse("investment accounts are in the currency of the budget"))
+		return
+	}
+	balance, err := requestAmount("balance", rqst.Balance, rqst.BalanceDecimal, rqst.CurrencyCode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 		return
 	}
 
-	// Create the account
-	// TODO: this should be in a DB transaction, which includes createing a transaction for the starting balance
-	arg := db.CreateAccountParams{
-		BudgetID: budgetId,
-		Name:     rqst.Name,
-		Type:     rqst.Type,
-		Balance:  rqst.Balance,
+	// Create the account, with its transfer payee and the payment category of credit cards
+	// TODO: the transaction should include createing a transaction for the starting balance
+	arg := db.CreateAccountTxParams{
+		Account: db.CreateAccountParams{
+			BudgetID:     budget.ID,
+			Name:         rqst.Name,
+			Type:         rqst.Type,
+			Balance:      balance,
+			CurrencyCode: rqst.CurrencyCode,
+		},
+		PaymentCategory: rqst.Type == AccountTypeCreditCard,
 	}
-	account, err := s.db.CreateAccount(ctx, arg)
+	account, err := s.db.CreateAccountTx(ctx, arg)
 	if err != nil {
+		slog.Error(err.Error())
 		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
 }
 
 // updateAccount godoc
 //
 //	@Summary	Update a budgeting account
 //	@Schemes
-//	@Description	Update a budgeting account.
+//	@Description	Update a budgeting account. The type can only be changed to another on-budget type, or another tracking type, and credit cards keep their type.
+//	@Description	Only accounts in the currency of the budget can become investment accounts.
+//	@Description	Balances are in milliunits, thousandths of the unit of the currency, or decimals in the currency in the fields ending with _decimal.
 //	@Param			budget_id	path	string					true	"Budget ID"
 //	@Param			account_id	path	string					true	"Account ID"
 //	@Param			account		body	updateAccountRequest	true	"Account details"
 //	@Tags			Accounts
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	db.UpdateAccountParams
+//	@Success		200	{object}	accountResponse
 //	@Failure		400	{object}	HTTPError
 //	@Failure		500	{object}	HTTPError
 //	@Router			/budgets/{budget_id}/accounts/{account_id} [put]
 func (s *Server) updateAccount(ctx *gin.Context) {
 
-	var budgetId uuid.UUID
-	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+	budget, err := s.getOwnedBudget(ctx)
+	if err != nil {
 		return
 	}
+	budgetId := budget.ID
 
 	var acctRqst AccountId
 	if err := ctx.ShouldBindUri(&acctRqst); err != nil {
@@ -187,12 +239,61 @@ func (s *Server) updateAccount(ctx *gin.Context) {
 	}
 
 	// Validations
+	onBudget, ok := accountTypeOnBudget[rqst.Type.String]
+	if rqst.Type.Valid && !ok {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid account type"))
+		return
+	}
+	// the type is checked against the current one, and the balances are in the currency of the account
+	var acct db.Account
+	setsBalances := rqst.Balance.Valid || rqst.BalanceDecimal != "" || rqst.ClearedBalance.Valid ||
+		rqst.ClearedBalanceDecimal != "" || rqst.UnclearedBalance.Valid || rqst.UnclearedBalanceDecimal != ""
+	if rqst.Type.Valid || setsBalances {
+		acct, err = s.db.GetAccount(ctx, db.GetAccountParams{
+			BudgetID: budgetId,
+			ID:       acctId,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				ctx.JSON(http.StatusNotFound, errorResponse("account not found in budget"))
+				return
+			}
+			slog.Error(err.Error())
+			ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+			return
+		}
+	}
 	if rqst.Type.Valid {
-		accountTypes := []string{"savings", "checking", "lineofcredit"}
-		if !slices.Contains(accountTypes, strings.ToLower(rqst.Type.String)) {
-			ctx.JSON(http.StatusBadRequest, errorResponse("invalid account type"))
+		// the transactions of on-budget accounts have categories, those of tracking accounts don't
+		if onBudget != acct.OnBudget {
+			ctx.JSON(http.StatusBadRequest, errorResponse("cannot change an on-budget account into a tracking account or the other way around"))
+			return
+		}
+		// only credit cards have a payment category
+		if (rqst.Type.String == AccountTypeCreditCard) != (acct.Type == AccountTypeCreditCard) {
+			ctx.JSON(http.StatusBadRequest, errorResponse("cannot change a credit card account into another type or the other way around"))
 			return
 		}
+		// the prices of securities are in the currency of the budget
+		if rqst.Type.String == AccountTypeInvestment && acct.CurrencyCode != budget.CurrencyCode {
+			ctx.JSON(http.StatusBadRequest, errorResponse("investment accounts are in the currency of the budget"))
+			return
+		}
+	}
+	balance, err := requestOptionalAmount("balance", rqst.Balance, rqst.BalanceDecimal, acct.CurrencyCode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	clearedBalance, err := requestOptionalAmount("cleared_balance", rqst.ClearedBalance, rqst.ClearedBalanceDecimal, acct.CurrencyCode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	unclearedBalance, err := requestOptionalAmount("uncleared_balance", rqst.UnclearedBalance, rqst.UnclearedBalanceDecimal, acct.CurrencyCode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
 	}
 
 	// Send the update
@@ -203,9 +304,10 @@ func (s *Server) updateAccount(ctx *gin.Context) {
 		Type:             rqst.Type,
 		Closed:           rqst.Closed,
 		Note:             rqst.Note,
-		Balance:          rqst.Balance,
-		ClearedBalance:   rqst.ClearedBalance,
-		UnclearedBalance: rqst.UnclearedBalance,
+		Balance:          balance,
+		ClearedBalance:   clearedBalance,
+		UnclearedBalance: unclearedBalance,
+		LastReconciledAt: rqst.LastReconciledAt,
 	}
 	updatedAccount, err := s.db.UpdateAccount(ctx, arg)
 	if err != nil {
@@ -214,7 +316,11 @@ func (s *Server) updateAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, updatedAccount)
+	if rqst.LastReconciledAt.Valid {
+		s.publishWebhookEvent(ctx, budgetId, WebhookEventAccountReconciled, updatedAccount)
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(updatedAccount))
 }
 
 // deleteAccount godoc
@@ -279,3 +385,38 @@ func (s *Server) deleteAccount(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"msg": "budgeting account deleted"})
 }
+
+// Gets the account in the URI, which must be of one of the given types. Writes the error response if not.
+func (s *Server) getAccountOfType(ctx *gin.Context, budgetId uuid.UUID, types map[string]bool, typeError string) (db.Account, error) {
+
+	var acctRqst AccountId
+	if err := ctx.ShouldBindUri(&acctRqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return db.Account{}, err
+	}
+	acctId, err := uuid.Parse(acctRqst.AccountId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return db.Account{}, err
+	}
+
+	account, err := s.db.GetAccount(ctx, db.GetAccountParams{
+		BudgetID: budgetId,
+		ID:       acctId,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse("account not found in budget"))
+			return db.Account{}, err
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return db.Account{}, err
+	}
+	if !types[account.Type] {
+		ctx.JSON(http.StatusBadRequest, errorResponse(typeError))
+		return db.Account{}, errors.New("account type " + account.Type + " not allowed")
+	}
+
+	return account, nil
+}
@@ -0,0 +1,360 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateAccountAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OnBudget",
+			body: gin.H{"name": "Chase Savings", "type": AccountTypeSavings, "balance": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), db.CreateAccountTxParams{
+						Account: db.CreateAccountParams{BudgetID: budget.ID, Name: "Chase Savings", Type: AccountTypeSavings, Balance: 100, CurrencyCode: "EUR"},
+					}).
+					Times(1).
+					Return(db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Chase Savings", Type: AccountTypeSavings, Balance: 100, OnBudget: true}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var account db.Account
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &account))
+				require.True(t, account.OnBudget)
+			},
+		},
+		{
+			name: "CreditCard",
+			body: gin.H{"name": "Visa", "type": AccountTypeCreditCard},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), db.CreateAccountTxParams{
+						Account:         db.CreateAccountParams{BudgetID: budget.ID, Name: "Visa", Type: AccountTypeCreditCard, CurrencyCode: "EUR"},
+						PaymentCategory: true,
+					}).
+					Times(1).
+					Return(db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Visa", Type: AccountTypeCreditCard, OnBudget: true}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Tracking",
+			body: gin.H{"name": "House", "type": AccountTypeAsset},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "House", Type: AccountTypeAsset, OnBudget: false}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DecimalBalance",
+			body: gin.H{"name": "Yen", "type": AccountTypeCash, "currency_code": "JPY", "balance_decimal": "1500"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), db.CreateAccountTxParams{
+						Account: db.CreateAccountParams{BudgetID: budget.ID, Name: "Yen", Type: AccountTypeCash, Balance: 1500000, CurrencyCode: "JPY"},
+					}).
+					Times(1).
+					Return(db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Yen", Type: AccountTypeCash, Balance: 1500000, CurrencyCode: "JPY", OnBudget: true}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var account accountResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &account))
+				require.Equal(t, int64(1500000), account.Balance)
+				require.Equal(t, "1500", account.BalanceDecimal)
+			},
+		},
+		{
+			name: "BalanceNotWhole",
+			body: gin.H{"name": "Chase Savings", "type": AccountTypeSavings, "balance": 1005},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "EUR, which has 2 decimals")
+			},
+		},
+		{
+			name: "BalanceAndDecimal",
+			body: gin.H{"name": "Chase Savings", "type": AccountTypeSavings, "balance": 1000, "balance_decimal": "1.00"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvestmentInAnotherCurrency",
+			body: gin.H{"name": "Brokerage", "type": AccountTypeInvestment, "currency_code": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "FreeFormType",
+			body: gin.H{"name": "Chase Savings", "type": "Savings"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), db.GetBudgetParams{ID: budget.ID, OwnerUsername: username}).
+				Times(1).
+				Return(budget, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/beta/budgets/"+budget.ID.String()+"/accounts", bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateAccountTypeAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	account := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Chase", Type: AccountTypeChecking, OnBudget: true}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "SameClassification",
+			body: gin.H{"type": AccountTypeSavings},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: account.ID}).
+					Times(1).
+					Return(account, nil)
+				updated := account
+				updated.Type = AccountTypeSavings
+				store.EXPECT().
+					UpdateAccount(gomock.Any(), db.UpdateAccountParams{ID: account.ID, BudgetID: budget.ID, Type: pgtype.Text{String: AccountTypeSavings, Valid: true}}).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OnBudgetToTracking",
+			body: gin.H{"type": AccountTypeMortgage},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "IntoCreditCard",
+			body: gin.H{"type": AccountTypeCreditCard},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvestmentInAnotherCurrency",
+			body: gin.H{"type": AccountTypeInvestment},
+			buildStubs: func(store *mockdb.MockStore) {
+				asset := account
+				asset.Type = AccountTypeAsset
+				asset.OnBudget = false
+				asset.CurrencyCode = "USD"
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(asset, nil)
+				store.EXPECT().
+					UpdateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "currency of the budget")
+			},
+		},
+		{
+			name: "InvalidType",
+			body: gin.H{"type": "lineofcredit"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DecimalBalance",
+			body: gin.H{"balance_decimal": "12.50"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccount(gomock.Any(), db.UpdateAccountParams{ID: account.ID, BudgetID: budget.ID, Balance: pgtype.Int8{Int64: 12500, Valid: true}}).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BalanceAndDecimal",
+			body: gin.H{"balance": 0, "balance_decimal": "12.50"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoTypeChange",
+			body: gin.H{"name": "Chase Checking"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UpdateAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(budget, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			url := "/beta/budgets/" + budget.ID.String() + "/accounts/" + account.ID.String()
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,506 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Actions of the audit log
+const (
+	auditActionUnlock        = "unlock"
+	auditActionVerifyEmail   = "verify_email"
+	auditActionBlockSessions = "block_sessions"
+	auditActionResetTwoFA    = "reset_2fa"
+	auditActionDeleteUser    = "delete_user"
+	auditActionGrantAdmin    = "grant_admin"
+)
+
+// Admin of the actions which are not made through the API
+const auditSystemAdmin = "system"
+
+// Default page size of the admin listings
+const defaultAdminPageSize = 20
+
+// listUsers godoc
+//
+//	@Summary	List users
+//	@Schemes
+//	@Description	List the users, by username. The search matches part of the username or the email. Requires the admin role.
+//	@Tags			Admin
+//	@Param			search		query	string	false	"Part of the username or email"
+//	@Param			page		query	int		false	"Page, from 1"
+//	@Param			page_size	query	int		false	"Users per page, at most 100"
+//	@Produce		json
+//	@Success		200	{object}	adminUserListResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/admin/users [get]
+//	@Security		Bearer
+func (s *Server) listUsers(ctx *gin.Context) {
+
+	var rqst listUsersRequest
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	if rqst.Page == 0 {
+		rqst.Page = 1
+	}
+	if rqst.PageSize == 0 {
+		rqst.PageSize = defaultAdminPageSize
+	}
+
+	search := ""
+	if rqst.Search != "" {
+		search = "%" + escapeLikePattern(rqst.Search) + "%"
+	}
+
+	total, err := s.db.CountUsers(ctx, search)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	users, err := s.db.ListUsers(ctx, db.ListUsersParams{
+		Search:     search,
+		PageSize:   rqst.PageSize,
+		PageOffset: (rqst.Page - 1) * rqst.PageSize,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	resp := adminUserListResponse{
+		Users:    make([]adminUserResponse, len(users)),
+		Total:    total,
+		Page:     rqst.Page,
+		PageSize: rqst.PageSize,
+	}
+	for i, u := range users {
+		resp.Users[i] = newAdminUserResponse(u)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// getUser godoc
+//
+//	@Summary	Get a user
+//	@Schemes
+//	@Description	Get a user. Requires the admin role.
+//	@Tags			Admin
+//	@Param			username	path	string	true	"Username"
+//	@Produce		json
+//	@Success		200	{object}	adminUserResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/admin/users/{username} [get]
+//	@Security		Bearer
+func (s *Server) getUser(ctx *gin.Context) {
+
+	u, ok := s.adminTargetUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(u))
+}
+
+// unlockUser godoc
+//
+//	@Summary	Unlock a user
+//	@Schemes
+//	@Description	Lift the lockout of a user after too many failed logins, and clear their failed logins. Requires the admin role.
+//	@Tags			Admin
+//	@Param			username	path	string	true	"Username"
+//	@Produce		json
+//	@Success		200	{string}	string	"user unlocked"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/admin/users/{username}/unlock [post]
+//	@Security		Bearer
+func (s *Server) unlockUser(ctx *gin.Context) {
+
+	u, ok := s.adminTargetUser(ctx)
+	if !ok {
+		return
+	}
+	if !s.auditAdminAction(ctx, auditActionUnlock, u.Username, "") {
+		return
+	}
+
+	if err := s.loginLimiter.Reset(ctx, u.Username); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "user unlocked"})
+}
+
+// verifyUserEmail godoc
+//
+//	@Summary	Verify the email of a user
+//	@Schemes
+//	@Description	Mark the email of a user as verified, e.g. when the verification email does not reach them. Requires the admin role.
+//	@Tags			Admin
+//	@Param			username	path	string	true	"Username"
+//	@Produce		json
+//	@Success		200	{object}	adminUserResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/admin/users/{username}/verify_email [post]
+//	@Security		Bearer
+func (s *Server) verifyUserEmail(ctx *gin.Context) {
+
+	u, ok := s.adminTargetUser(ctx)
+	if !ok {
+		return
+	}
+	if !s.auditAdminAction(ctx, auditActionVerifyEmail, u.Username, u.Email) {
+		return
+	}
+
+	updatedUser, err := s.db.UpdateUser(ctx, db.UpdateUserParams{
+		Username:      u.Username,
+		EmailVerified: pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(updatedUser))
+}
+
+// blockUserSessions godoc
+//
+//	@Summary	Block the sessions of a user
+//	@Schemes
+//	@Description	Log out a user from all their sessions and revoke their personal access tokens. The refresh and access tokens of the sessions,
+//	@Description	and the personal access tokens, stop working immediately. Requires the admin role.
+//	@Tags			Admin
+//	@Param			username	path	string	true	"Username"
+//	@Produce		json
+//	@Success		200	{string}	string	"sessions blocked"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/admin/users/{username}/sessions [delete]
+//	@Security		Bearer
+func (s *Server) blockUserSessions(ctx *gin.Context) {
+
+	u, ok := s.adminTargetUser(ctx)
+	if !ok {
+		return
+	}
+	if !s.auditAdminAction(ctx, auditActionBlockSessions, u.Username, "personal access tokens revoked") {
+		return
+	}
+
+	if err := s.db.BlockUserAccessTx(ctx, u.Username); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "sessions blocked and personal access tokens revoked"})
+}
+
+// resetUserTwoFactor godoc
+//
+//	@Summary	Reset the two-factor authentication of a user
+//	@Schemes
+//	@Description	Disable the two-factor authentication of a user and delete their recovery codes, e.g. when they lost their device and their codes.
+//	@Description	Requires the admin role.
+//	@Tags			Admin
+//	@Param			username	path	string	true	"Username"
+//	@Produce		json
+//	@Success		200	{string}	string	"two-factor authentication reset"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/admin/users/{username}/2fa [delete]
+//	@Security		Bearer
+func (s *Server) resetUserTwoFactor(ctx *gin.Context) {
+
+	u, ok := s.adminTargetUser(ctx)
+	if !ok {
+		return
+	}
+	if !u.TotpEnabled && !u.TotpSecret.Valid {
+		ctx.JSON(http.StatusBadRequest, errorResponse("two-factor authentication is not enabled"))
+		return
+	}
+	if !s.auditAdminAction(ctx, auditActionResetTwoFA, u.Username, "") {
+		return
+	}
+
+	if err := s.db.DisableTOTPTx(ctx, u.Username); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "two-factor authentication reset"})
+}
+
+// adminDeleteUser godoc
+//
+//	@Summary	Delete a user
+//	@Schemes
+//	@Description	Delete a user and everything they own, like the deletion of an account by its user. Administrators cannot delete themselves here.
+//	@Description	Requires the admin role.
+//	@Tags			Admin
+//	@Param			username	path	string	true	"Username"
+//	@Produce		json
+//	@Success		200	{string}	string	"user has been deleted"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/admin/users/{username} [delete]
+//	@Security		Bearer
+func (s *Server) adminDeleteUser(ctx *gin.Context) {
+
+	// Get the authenticated admin
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	u, ok := s.adminTargetUser(ctx)
+	if !ok {
+		return
+	}
+	if u.Username == authz_payload.Username {
+		ctx.JSON(http.StatusBadRequest, errorResponse("administrators delete their own account with DELETE /user"))
+		return
+	}
+
+	budgets, err := s.db.GetBudgets(ctx, u.Username)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	budgetIds := make([]uuid.UUID, len(budgets))
+	for b := range budgets {
+		budgetIds[b] = budgets[b].ID
+	}
+
+	if !s.auditAdminAction(ctx, auditActionDeleteUser, u.Username, u.Email) {
+		return
+	}
+
+	afterDeleteFn := func(deletedUser db.UserParams) error {
+		payload := &worker.SendEmailPayload{
+			Username: deletedUser.Username,
+			Email:    deletedUser.Email,
+		}
+		return s.taskDistributor.DistributeSendEmail(ctx, payload, worker.TaskSendAccountDeletedEmail)
+	}
+	userArg := db.UserParams{
+		Username: u.Username,
+		Email:    u.Email,
+	}
+	if err := s.db.DeleteUserTx(ctx, userArg, budgetIds, afterDeleteFn); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "user has been deleted"})
+}
+
+// getAuditLog godoc
+//
+//	@Summary	Get the audit log
+//	@Schemes
+//	@Description	List the actions of administrators, the latest first, optionally only those on a user. Requires the admin role.
+//	@Tags			Admin
+//	@Param			username	query	string	false	"Only the actions on this user"
+//	@Param			page		query	int		false	"Page, from 1"
+//	@Param			page_size	query	int		false	"Entries per page, at most 100"
+//	@Produce		json
+//	@Success		200	{object}	[]auditLogResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/admin/audit_log [get]
+//	@Security		Bearer
+func (s *Server) getAuditLog(ctx *gin.Context) {
+
+	var rqst auditLogRequest
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	if rqst.Page == 0 {
+		rqst.Page = 1
+	}
+	if rqst.PageSize == 0 {
+		rqst.PageSize = defaultAdminPageSize
+	}
+
+	entries, err := s.db.GetAuditLogs(ctx, db.GetAuditLogsParams{
+		TargetUsername: rqst.Username,
+		PageSize:       rqst.PageSize,
+		PageOffset:     (rqst.Page - 1) * rqst.PageSize,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	resp := make([]auditLogResponse, len(entries))
+	for i, e := range entries {
+		resp[i] = auditLogResponse{
+			ID:             e.ID,
+			AdminUsername:  e.AdminUsername,
+			Action:         e.Action,
+			TargetUsername: e.TargetUsername,
+			Details:        e.Details,
+			ClientIp:       e.ClientIp,
+			CreatedAt:      e.CreatedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Gives the admin role to a user, e.g. the first administrator from the ADMIN_BOOTSTRAP_USERNAME setting.
+// The user must have signed up already. Nothing is done if they are already an administrator.
+func BootstrapAdmin(ctx context.Context, store db.Store, username string) error {
+
+	n, err := store.AddUserRole(ctx, db.AddUserRoleParams{
+		Username: username,
+		Role:     token.RoleAdmin,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		if _, err := store.GetUserByUsername(ctx, username); err != nil {
+			if err == pgx.ErrNoRows {
+				return fmt.Errorf("user %s does not exist, it must sign up before it can be made an administrator", username)
+			}
+			return err
+		}
+		return nil
+	}
+
+	_, err = store.CreateAuditLog(ctx, db.CreateAuditLogParams{
+		AdminUsername:  auditSystemAdmin,
+		Action:         auditActionGrantAdmin,
+		TargetUsername: username,
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Made user an administrator", "user", username)
+
+	return nil
+}
+
+// Returns the user of the username in the path, or writes the error response.
+func (s *Server) adminTargetUser(ctx *gin.Context) (db.User, bool) {
+
+	var username UsernameUri
+	if err := ctx.ShouldBindUri(&username); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return db.User{}, false
+	}
+
+	u, err := s.db.GetUserByUsername(ctx, username.Username)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse("user not found"))
+			return db.User{}, false
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return db.User{}, false
+	}
+
+	return u, true
+}
+
+// Writes an admin action to the audit log, or writes the error response. Entries are written before
+// the actions, so that no action goes unrecorded: an entry without its effect means the action failed.
+func (s *Server) auditAdminAction(ctx *gin.Context, action string, target string, details string) bool {
+
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return false
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	_, err := s.db.CreateAuditLog(ctx, db.CreateAuditLogParams{
+		AdminUsername:  authz_payload.Username,
+		Action:         action,
+		TargetUsername: target,
+		Details:        details,
+		ClientIp:       ctx.ClientIP(),
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return false
+	}
+	slog.Info("Admin action", "action", action, "user", target, "admin", authz_payload.Username)
+
+	return true
+}
+
+func newAdminUserResponse(u db.User) adminUserResponse {
+	return adminUserResponse{
+		Username:           u.Username,
+		Email:              u.Email,
+		EmailVerified:      u.EmailVerified,
+		TotpEnabled:        u.TotpEnabled,
+		Roles:              u.Roles,
+		CreatedAt:          u.CreatedAt,
+		LastPasswordChange: u.LastPasswordChange,
+	}
+}
+
+// Escapes the wildcards of a LIKE pattern, so that the search is matched literally
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
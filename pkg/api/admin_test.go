@@ -0,0 +1,469 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testAdminUsername = "adminuser"
+
+func newAdminRequest(t *testing.T, server *Server, method string, url string, roles []string) *http.Request {
+	request, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+	accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: testAdminUsername, Duration: time.Minute, Purpose: token.PurposeAccess, Roles: roles})
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	return request
+}
+
+func expectAuditLog(store *mockdb.MockStore, action string, target string) *gomock.Call {
+	return store.EXPECT().
+		CreateAuditLog(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+			if arg.AdminUsername != testAdminUsername || arg.Action != action || arg.TargetUsername != target {
+				return db.AuditLog{}, errors.New("unexpected audit log entry")
+			}
+			return db.AuditLog{}, nil
+		})
+}
+
+func TestListUsersAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+
+	testCases := []struct {
+		name          string
+		query         string
+		roles         []string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?search=50%25_off&page=3&page_size=10",
+			roles: []string{token.RoleAdmin},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CountUsers(gomock.Any(), `%50\%\_off%`).
+					Times(1).
+					Return(int64(21), nil)
+				store.EXPECT().
+					ListUsers(gomock.Any(), db.ListUsersParams{Search: `%50\%\_off%`, PageSize: 10, PageOffset: 20}).
+					Times(1).
+					Return([]db.User{user}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp adminUserListResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, int64(21), resp.Total)
+				require.Equal(t, int32(3), resp.Page)
+				require.Len(t, resp.Users, 1)
+				require.Equal(t, user.Username, resp.Users[0].Username)
+				require.NotContains(t, recorder.Body.String(), user.Password)
+			},
+		},
+		{
+			name:  "Defaults",
+			roles: []string{token.RoleAdmin},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CountUsers(gomock.Any(), "").
+					Times(1)
+				store.EXPECT().
+					ListUsers(gomock.Any(), db.ListUsersParams{Search: "", PageSize: defaultAdminPageSize, PageOffset: 0}).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "PageSizeTooLarge",
+			query: "?page_size=1000",
+			roles: []string{token.RoleAdmin},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			roles: nil,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			server.Router.ServeHTTP(recorder, newAdminRequest(t, server, http.MethodGet, "/beta/admin/users"+tc.query, tc.roles))
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestAdminUserActionsAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+	user.EmailVerified = false
+	twoFactorUser := user
+	twoFactorUser.TotpEnabled = true
+	twoFactorUser.TotpSecret = pgtype.Text{String: "secret", Valid: true}
+	budgetID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		buildStubs    func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "GetUser",
+			method: http.MethodGet,
+			path:   "/beta/admin/users/" + user.Username,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp adminUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, user.Email, resp.Email)
+			},
+		},
+		{
+			name:   "GetUserNotFound",
+			method: http.MethodGet,
+			path:   "/beta/admin/users/" + user.Username,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "VerifyEmail",
+			method: http.MethodPost,
+			path:   "/beta/admin/users/" + user.Username + "/verify_email",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				verified := user
+				verified.EmailVerified = true
+				gomock.InOrder(
+					expectAuditLog(store, auditActionVerifyEmail, user.Username),
+					store.EXPECT().
+						UpdateUser(gomock.Any(), db.UpdateUserParams{Username: user.Username, EmailVerified: pgtype.Bool{Bool: true, Valid: true}}).
+						Times(1).
+						Return(verified, nil),
+				)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BlockSessions",
+			method: http.MethodDelete,
+			path:   "/beta/admin/users/" + user.Username + "/sessions",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				gomock.InOrder(
+					expectAuditLog(store, auditActionBlockSessions, user.Username),
+					store.EXPECT().
+						BlockUserAccessTx(gomock.Any(), user.Username).
+						Times(1),
+				)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "AuditLogFailure",
+			method: http.MethodDelete,
+			path:   "/beta/admin/users/" + user.Username + "/sessions",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditLog{}, errors.New("connection refused"))
+				store.EXPECT().
+					BlockUserAccessTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "ResetTwoFactor",
+			method: http.MethodDelete,
+			path:   "/beta/admin/users/" + user.Username + "/2fa",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(twoFactorUser, nil)
+				gomock.InOrder(
+					expectAuditLog(store, auditActionResetTwoFA, user.Username),
+					store.EXPECT().
+						DisableTOTPTx(gomock.Any(), user.Username).
+						Times(1),
+				)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ResetTwoFactorNotEnabled",
+			method: http.MethodDelete,
+			path:   "/beta/admin/users/" + user.Username + "/2fa",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DisableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "DeleteUser",
+			method: http.MethodDelete,
+			path:   "/beta/admin/users/" + user.Username,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetBudgets(gomock.Any(), user.Username).
+					Times(1).
+					Return([]db.Budget{{ID: budgetID, OwnerUsername: user.Username}}, nil)
+				gomock.InOrder(
+					expectAuditLog(store, auditActionDeleteUser, user.Username),
+					store.EXPECT().
+						DeleteUserTx(gomock.Any(), db.UserParams{Username: user.Username, Email: user.Email}, []uuid.UUID{budgetID}, gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ any, arg db.UserParams, _ []uuid.UUID, fn func(db.UserParams) error) error {
+							return fn(arg)
+						}),
+				)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), worker.TaskSendAccountDeletedEmail).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "DeleteSelf",
+			method: http.MethodDelete,
+			path:   "/beta/admin/users/" + testAdminUsername,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), testAdminUsername).
+					Times(1).
+					Return(db.User{Username: testAdminUsername}, nil)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			dist := mockdb.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, dist)
+
+			server := NewTestServer(t, store, dist)
+			recorder := httptest.NewRecorder()
+
+			server.Router.ServeHTTP(recorder, newAdminRequest(t, server, tc.method, tc.path, []string{token.RoleAdmin}))
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetAuditLogAPI(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	entry := db.AuditLog{
+		ID:             1,
+		AdminUsername:  testAdminUsername,
+		Action:         auditActionBlockSessions,
+		TargetUsername: "randomusername",
+		ClientIp:       "192.0.2.1",
+		CreatedAt:      time.Now(),
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetAuditLogs(gomock.Any(), db.GetAuditLogsParams{TargetUsername: "randomusername", PageSize: defaultAdminPageSize, PageOffset: 0}).
+		Times(1).
+		Return([]db.AuditLog{entry}, nil)
+
+	server := NewTestServer(t, store, nil)
+	recorder := httptest.NewRecorder()
+
+	server.Router.ServeHTTP(recorder, newAdminRequest(t, server, http.MethodGet, "/beta/admin/audit_log?username=randomusername", []string{token.RoleAdmin}))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp []auditLogResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	require.Equal(t, entry.Action, resp[0].Action)
+	require.Equal(t, entry.TargetUsername, resp[0].TargetUsername)
+}
+
+func TestBootstrapAdmin(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		wantErr    bool
+	}{
+		{
+			name: "Granted",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AddUserRole(gomock.Any(), db.AddUserRoleParams{Username: user.Username, Role: token.RoleAdmin}).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), db.CreateAuditLogParams{AdminUsername: auditSystemAdmin, Action: auditActionGrantAdmin, TargetUsername: user.Username}).
+					Times(1)
+			},
+		},
+		{
+			name: "AlreadyAdmin",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AddUserRole(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+		{
+			name: "NoSuchUser",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AddUserRole(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			err := BootstrapAdmin(context.Background(), store, user.Username)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
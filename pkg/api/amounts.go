@@ -0,0 +1,65 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/money"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Returns an amount of a request, given in milliunits or as a decimal in a currency, but not both. It must be a whole
+// amount of the currency. The errors are meant for the user.
+func requestAmount(name string, milliunits int64, decimal string, currency string) (int64, error) {
+
+	if decimal == "" {
+		rounded, err := money.Round(milliunits, currency)
+		if err != nil || rounded != milliunits {
+			return 0, fmt.Errorf("%s is not a whole amount of %s, which has %d decimals", name, currency, money.Decimals(currency))
+		}
+		return milliunits, nil
+	}
+	if milliunits != 0 {
+		return 0, fmt.Errorf("set either %s or %s_decimal", name, name)
+	}
+	amount, err := money.Parse(decimal, currency)
+	if err != nil {
+		return 0, fmt.Errorf("%s_decimal: %w", name, err)
+	}
+
+	return amount, nil
+}
+
+// Returns an amount an update request may leave out, given like with requestAmount. It is left out if neither field is
+// set.
+func requestOptionalAmount(name string, milliunits pgtype.Int8, decimal string, currency string) (pgtype.Int8, error) {
+
+	if decimal == "" && !milliunits.Valid {
+		return milliunits, nil
+	}
+	if decimal != "" && milliunits.Valid {
+		return pgtype.Int8{}, fmt.Errorf("set either %s or %s_decimal", name, name)
+	}
+	amount, err := requestAmount(name, milliunits.Int64, decimal, currency)
+	if err != nil {
+		return pgtype.Int8{}, err
+	}
+
+	return pgtype.Int8{Int64: amount, Valid: true}, nil
+}
+
+func newAccountResponse(a db.Account) accountResponse {
+	return accountResponse{
+		Account:                 a,
+		BalanceDecimal:          money.Format(a.Balance, a.CurrencyCode),
+		ClearedBalanceDecimal:   money.Format(a.ClearedBalance, a.CurrencyCode),
+		UnclearedBalanceDecimal: money.Format(a.UnclearedBalance, a.CurrencyCode),
+	}
+}
+
+func newTransactionViewResponse(t db.TransactionsView) transactionViewResponse {
+	return transactionViewResponse{
+		TransactionsView: t,
+		AmountDecimal:    money.Format(t.Amount, t.CurrencyCode),
+	}
+}
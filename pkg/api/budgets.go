@@ -9,6 +9,7 @@ import (
 	"github.com/guerzon/gobudget-api/pkg/db"
 	"github.com/guerzon/gobudget-api/pkg/token"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // getBudgets godoc
@@ -46,7 +47,8 @@ func (s *Server) getBudgets(ctx *gin.Context) {
 //
 //	@Summary	Get budget
 //	@Schemes
-//	@Description	Get the details of a budget.
+//	@Description	Get the details of a budget, with the money ready to assign: the inflows of on-budget accounts without category,
+//	@Description	converted into the currency of the budget, less the money assigned to categories.
 //	@Tags			Budget
 //	@Accept			json
 //	@Produce		json
@@ -97,11 +99,48 @@ func (s *Server) getBudget(ctx *gin.Context) {
 		return
 	}
 
+	// Ready to assign, in the currency of the budget
+	categories, err := s.db.GetBudgetCategories(ctx, budgetId)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	payees, err := s.db.GetPayees(ctx, budgetId)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	transactions, err := s.db.GetTransactions(ctx, budgetId)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	rates, err := s.loadExchangeRates(ctx, budget, accounts, pgtype.Date{})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	transactions, err = convertTransactions(rates, budget, accounts, transactions)
+	if err != nil {
+		conversionErrorResponse(ctx, err)
+		return
+	}
+	readyToAssign, err := computeReadyToAssign(categories, accounts, payees, transactions)
+	if err != nil {
+		conversionErrorResponse(ctx, err)
+		return
+	}
+
 	resp := detailedBudgetResponse{
-		Id:           budget.ID,
-		Name:         budget.Name,
-		CurrencyCode: budget.CurrencyCode,
-		Accounts:     accounts,
+		Id:            budget.ID,
+		Name:          budget.Name,
+		CurrencyCode:  budget.CurrencyCode,
+		Accounts:      accounts,
+		ReadyToAssign: readyToAssign,
 	}
 
 	ctx.JSON(http.StatusOK, resp)
@@ -9,9 +9,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/guerzon/gobudget-api/pkg/db"
 	mock "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
 	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -120,7 +123,7 @@ func TestCreateBudgetAPI(t *testing.T) {
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
 			require.NoError(t, err)
 			// add the header
-			token, _, err := server.tokenBuilder.CreateToken(util.RandomUsername(), time.Duration(time.Minute*15))
+			token, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: util.RandomUsername(), Duration: time.Duration(time.Minute * 15), Purpose: token.PurposeAccess})
 			require.NoError(t, err)
 			authzHeader := "Bearer " + token
 			request.Header.Set("Authorization", authzHeader)
@@ -131,3 +134,56 @@ func TestCreateBudgetAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBudgetAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	checking := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Chase", Type: AccountTypeChecking, OnBudget: true, CurrencyCode: "EUR"}
+	employer := db.Payee{ID: uuid.New(), BudgetID: budget.ID, Name: "Employer"}
+	groceries := db.Category{ID: uuid.New(), Name: "Groceries", Assigned: 400000}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockStore(ctrl)
+	store.EXPECT().
+		GetBudget(gomock.Any(), db.GetBudgetParams{OwnerUsername: username, ID: budget.ID}).
+		Times(1).
+		Return(budget, nil)
+	store.EXPECT().
+		GetAccounts(gomock.Any(), budget.ID).
+		Times(1).
+		Return([]db.Account{checking}, nil)
+	store.EXPECT().
+		GetBudgetCategories(gomock.Any(), budget.ID).
+		Times(1).
+		Return([]db.Category{groceries}, nil)
+	store.EXPECT().
+		GetPayees(gomock.Any(), budget.ID).
+		Times(1).
+		Return([]db.Payee{employer}, nil)
+	store.EXPECT().
+		GetTransactions(gomock.Any(), budget.ID).
+		Times(1).
+		Return([]db.Transaction{
+			{ID: uuid.New(), AccountID: checking.ID, PayeeID: employer.ID, Amount: 2500000},
+			{ID: uuid.New(), AccountID: checking.ID, PayeeID: employer.ID, CategoryID: pgtype.UUID{Bytes: groceries.ID, Valid: true}, Amount: -120000},
+		}, nil)
+
+	server := NewTestServer(t, store, nil)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/beta/budgets/"+budget.ID.String(), nil)
+	require.NoError(t, err)
+	accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp detailedBudgetResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Equal(t, int64(2100000), resp.ReadyToAssign)
+}
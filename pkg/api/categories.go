@@ -1,7 +1,6 @@
 package api
 
 import (
-	"fmt"
 	"log/slog"
 	"net/http"
 
@@ -9,27 +8,32 @@ import (
 	"github.com/google/uuid"
 	"github.com/guerzon/gobudget-api/pkg/db"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // getCategories godoc
 //
 //	@Summary	Get category groups and categories
 //	@Schemes
-//	@Description	List all categories in a budget grouped by category group
+//	@Description	List all categories in a budget grouped by category group, with their balances.
+//	@Description	Credit card accounts have a payment category, which receives the money of the spending on the card that categories could cover.
+//	@Description	Overspending is split between cash overspending, which already left on-budget accounts, and credit overspending, which became credit card debt.
+//	@Description	The transactions of accounts in other currencies are converted into the currency of the budget at the rates of their dates.
 //	@Param			budget_id	path	string	true	"Budget ID"
 //	@Tags			Categories
 //	@Produce		json
-//	@Success		200	{object}	categoryResponse
+//	@Success		200	{object}	[]categoryResponse
 //	@Failure		400	{object}	HTTPError
 //	@Failure		404	{object}	HTTPError
 //	@Failure		500	{object}	HTTPError
 //	@Router			/budgets/{budget_id}/categories [get]
 func (s *Server) getCategories(ctx *gin.Context) {
 
-	var budgetId uuid.UUID
-	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+	budget, err := s.getOwnedBudget(ctx)
+	if err != nil {
 		return
 	}
+	budgetId := budget.ID
 
 	categoryGroups, err := s.db.GetCategoryGroupsByBudgetId(ctx, budgetId)
 	if err != nil {
@@ -37,30 +41,89 @@ func (s *Server) getCategories(ctx *gin.Context) {
 		return
 	}
 
-	resp := make([]categoryResponse, len(categoryGroups))
+	// Everything which moves money between categories
+	categories, err := s.db.GetBudgetCategories(ctx, budgetId)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	accounts, err := s.db.GetAccounts(ctx, budgetId)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	payees, err := s.db.GetPayees(ctx, budgetId)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	transactions, err := s.db.GetTransactions(ctx, budgetId)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	// Categories are in the currency of the budget
+	rates, err := s.loadExchangeRates(ctx, budget, accounts, pgtype.Date{})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	transactions, err = convertTransactions(rates, budget, accounts, transactions)
+	if err != nil {
+		conversionErrorResponse(ctx, err)
+		return
+	}
+	balances, err := computeCategoryBalances(categories, accounts, payees, transactions)
+	if err != nil {
+		conversionErrorResponse(ctx, err)
+		return
+	}
 
-	fmt.Printf("categoryGroups length is: %d\n", len(categoryGroups))
-	// Get the category group and the categories
+	// Group the categories
+	resp := make([]categoryResponse, len(categoryGroups))
+	groups := make(map[uuid.UUID]int, len(categoryGroups))
 	for c := range categoryGroups {
-		cgrp, err := s.db.GetCategoryGroup(ctx, categoryGroups[c].ID)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
-			return
-		}
-		resp[c].CategoryGroupId = cgrp.ID
-		resp[c].Name = cgrp.Name
-
-		cgs, err := s.db.GetCategories(ctx, cgrp.ID)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
-			return
+		resp[c].CategoryGroupId = categoryGroups[c].ID
+		resp[c].Name = categoryGroups[c].Name
+		resp[c].Categories = []categoryBalanceResponse{}
+		groups[categoryGroups[c].ID] = c
+	}
+	for _, category := range categories {
+		g, ok := groups[category.CategoryGroupID]
+		if !ok {
+			continue
 		}
-		resp[c].Categories = cgs
+		resp[g].Categories = append(resp[g].Categories, newCategoryBalanceResponse(category, balances[category.ID]))
 	}
 
 	ctx.JSON(http.StatusOK, resp)
 }
 
+func newCategoryBalanceResponse(category db.Category, balance categoryBalance) categoryBalanceResponse {
+
+	rsp := categoryBalanceResponse{
+		ID:              category.ID,
+		CategoryGroupID: category.CategoryGroupID,
+		Name:            category.Name,
+		Assigned:        category.Assigned,
+		Activity:        balance.Activity,
+		Available:       balance.Available,
+		CashOverspent:   balance.CashOverspent,
+		CreditOverspent: balance.CreditOverspent,
+	}
+	if category.PaymentAccountID.Valid {
+		accountId := uuid.UUID(category.PaymentAccountID.Bytes)
+		rsp.PaymentAccountID = &accountId
+	}
+
+	return rsp
+}
+
 // createCategory godoc
 //
 //	@Summary	Create a budgeting category
@@ -116,10 +179,11 @@ func (s *Server) createCategory(ctx *gin.Context) {
 //
 //	@Summary	Update a budgeting category
 //	@Schemes
-//	@Description	Update a budgeting category.
-//	@Param			budget_id	path	string			true	"Budget ID"
-//	@Param			category_id	path	string			true	"Category ID"
-//	@Param			account		body	categoryRqst	true	"Category details"
+//	@Description	Rename a budgeting category, or change the amount assigned to it.
+//	@Description	The amount assigned is in milliunits, thousandths of the unit of the currency of the budget, or a decimal in the currency with assigned_decimal.
+//	@Param			budget_id	path	string				true	"Budget ID"
+//	@Param			category_id	path	string				true	"Category ID"
+//	@Param			account		body	updateCategoryRqst	true	"Category details"
 //	@Tags			Categories
 //	@Accept			json
 //	@Produce		json
@@ -129,8 +193,8 @@ func (s *Server) createCategory(ctx *gin.Context) {
 //	@Router			/budgets/{budget_id}/categories/{category_id} [put]
 func (s *Server) updateCategory(ctx *gin.Context) {
 
-	var budgetId uuid.UUID
-	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+	budget, err := s.getOwnedBudget(ctx)
+	if err != nil {
 		return
 	}
 
@@ -147,16 +211,27 @@ func (s *Server) updateCategory(ctx *gin.Context) {
 	}
 
 	// Parse the JSON request body
-	var rqst categoryRqst
+	var rqst updateCategoryRqst
 	if err := ctx.ShouldBindJSON(&rqst); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
 		return
 	}
+	if rqst.Name.Valid && len(rqst.Name.String) < 2 {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	// categories are in the currency of the budget
+	assigned, err := requestOptionalAmount("assigned", rqst.Assigned, rqst.AssignedDecimal, budget.CurrencyCode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
 
 	// Update
 	newcGroup, err := s.db.UpdateCategory(ctx, db.UpdateCategoryParams{
-		ID:   categoryUuid,
-		Name: rqst.Name,
+		ID:       categoryUuid,
+		Name:     rqst.Name,
+		Assigned: assigned,
 	})
 	if err != nil {
 		slog.Error(err.Error())
@@ -201,6 +276,22 @@ func (s *Server) deleteCategory(ctx *gin.Context) {
 		return
 	}
 
+	// Payment categories go away with their credit card account
+	category, err := s.db.GetCategory(ctx, categoryUuid)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse("category not found"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if category.PaymentAccountID.Valid {
+		ctx.JSON(http.StatusBadRequest, errorResponse("cannot delete the payment category of a credit card account"))
+		return
+	}
+
 	// TODO: this should be in a DB transaction, which includes deleting transactions associated with the category
 
 	err = s.db.DeleteCategory(ctx, categoryUuid)
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeleteCategoryAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	groceries := db.Category{ID: uuid.New(), Name: "Groceries"}
+	payment := db.Category{ID: uuid.New(), Name: "Visa", PaymentAccountID: pgtype.UUID{Bytes: uuid.New(), Valid: true}}
+
+	testCases := []struct {
+		name          string
+		category      db.Category
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			category: groceries,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategory(gomock.Any(), groceries.ID).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "PaymentCategory",
+			category: payment,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategory(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(budget, nil)
+			store.EXPECT().
+				GetCategory(gomock.Any(), tc.category.ID).
+				Times(1).
+				Return(tc.category, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			url := "/beta/budgets/" + budget.ID.String() + "/categories/" + tc.category.ID.String()
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateCategoryAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "JPY"}
+	groceries := db.Category{ID: uuid.New(), Name: "Groceries"}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "DecimalAssigned",
+			body: gin.H{"assigned_decimal": "1500"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategory(gomock.Any(), db.UpdateCategoryParams{ID: groceries.ID, Assigned: pgtype.Int8{Int64: 1500000, Valid: true}}).
+					Times(1).
+					Return(groceries, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AssignedNotWhole",
+			body: gin.H{"assigned": 1500500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategory(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "JPY, which has 0 decimals")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(budget, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			url := "/beta/budgets/" + budget.ID.String() + "/categories/" + groceries.ID.String()
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
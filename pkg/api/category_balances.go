@@ -0,0 +1,179 @@
+package api
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/money"
+)
+
+// Money of a category once the transactions of the budget are applied
+type categoryBalance struct {
+	Activity  int64
+	Available int64
+	// Overspending covered by the cash of on-budget accounts, which has already left them
+	CashOverspent int64
+	// Overspending on credit cards, which became debt without money to pay it
+	CreditOverspent int64
+}
+
+// Computes the balances of the categories of a budget from its transactions.
+//
+// Spending on a credit card moves the money from the category of the transaction to the payment category of the card,
+// as far as the category has money available; the rest is credit overspending. Refunds on a credit card move it back.
+// Transfers between a credit card and another on-budget account are payments, which draw from the payment category.
+// Returns money.ErrOverflow if a balance is out of range.
+func computeCategoryBalances(categories []db.Category, accounts []db.Account, payees []db.Payee, transactions []db.Transaction) (map[uuid.UUID]categoryBalance, error) {
+
+	type categoryState struct {
+		assigned        int64
+		activity        int64
+		uncoveredCredit int64
+	}
+	states := make(map[uuid.UUID]*categoryState, len(categories))
+	paymentCategories := make(map[uuid.UUID]*categoryState)
+	for _, c := range categories {
+		states[c.ID] = &categoryState{assigned: c.Assigned}
+		if c.PaymentAccountID.Valid {
+			paymentCategories[uuid.UUID(c.PaymentAccountID.Bytes)] = states[c.ID]
+		}
+	}
+	accountsById := make(map[uuid.UUID]db.Account, len(accounts))
+	for _, a := range accounts {
+		accountsById[a.ID] = a
+	}
+	transferAccounts := make(map[uuid.UUID]uuid.UUID)
+	for _, p := range payees {
+		if p.TransferAccountID.Valid {
+			transferAccounts[p.ID] = uuid.UUID(p.TransferAccountID.Bytes)
+		}
+	}
+
+	// the money available when spending on a credit card depends on what came before
+	transactions = slices.Clone(transactions)
+	slices.SortStableFunc(transactions, func(a, b db.Transaction) int {
+		return a.Date.Time.Compare(b.Date.Time)
+	})
+
+	var err error
+	for _, t := range transactions {
+		account, ok := accountsById[t.AccountID]
+		if !ok || !account.OnBudget {
+			continue
+		}
+		amount := t.Amount
+		payment := paymentCategories[account.ID]
+
+		if t.CategoryID.Valid {
+			category, ok := states[uuid.UUID(t.CategoryID.Bytes)]
+			if !ok {
+				continue
+			}
+			available, err := money.Add(category.assigned, category.activity)
+			if err != nil {
+				return nil, err
+			}
+			if category.activity, err = money.Add(category.activity, amount); err != nil {
+				return nil, err
+			}
+			if account.Type != AccountTypeCreditCard || payment == nil {
+				continue
+			}
+			if amount < 0 {
+				spent, err := money.Neg(amount)
+				if err != nil {
+					return nil, err
+				}
+				covered := min(spent, max(available, 0))
+				if category.uncoveredCredit, err = money.Add(category.uncoveredCredit, spent-covered); err != nil {
+					return nil, err
+				}
+				if payment.activity, err = money.Add(payment.activity, covered); err != nil {
+					return nil, err
+				}
+			} else if payment.activity, err = money.Sub(payment.activity, amount); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Payments between a credit card and another on-budget account
+		transferAccountId, ok := transferAccounts[t.PayeeID]
+		if !ok || transferAccountId == account.ID {
+			continue
+		}
+		transferAccount, ok := accountsById[transferAccountId]
+		if !ok || !transferAccount.OnBudget {
+			continue
+		}
+		if payment != nil {
+			// recorded on the credit card, the inflow is the payment
+			payment.activity, err = money.Sub(payment.activity, amount)
+		} else if cardPayment := paymentCategories[transferAccountId]; cardPayment != nil {
+			// recorded on the account paying the credit card, the outflow is the payment
+			cardPayment.activity, err = money.Add(cardPayment.activity, amount)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	balances := make(map[uuid.UUID]categoryBalance, len(states))
+	for id, state := range states {
+		available, err := money.Add(state.assigned, state.activity)
+		if err != nil {
+			return nil, err
+		}
+		balance := categoryBalance{
+			Activity:  state.activity,
+			Available: available,
+		}
+		if balance.Available < 0 {
+			overspent, err := money.Neg(balance.Available)
+			if err != nil {
+				return nil, err
+			}
+			balance.CreditOverspent = min(overspent, state.uncoveredCredit)
+			balance.CashOverspent = overspent - balance.CreditOverspent
+		}
+		balances[id] = balance
+	}
+
+	return balances, nil
+}
+
+// Computes the money of a budget which is ready to assign: the inflows of on-budget accounts without category,
+// less the money assigned to the categories. Transfers between on-budget accounts only move money, so they are left out.
+// Returns money.ErrOverflow if the amount is out of range.
+func computeReadyToAssign(categories []db.Category, accounts []db.Account, payees []db.Payee, transactions []db.Transaction) (int64, error) {
+
+	onBudget := make(map[uuid.UUID]bool, len(accounts))
+	for _, a := range accounts {
+		onBudget[a.ID] = a.OnBudget
+	}
+	onBudgetTransfers := make(map[uuid.UUID]bool)
+	for _, p := range payees {
+		if p.TransferAccountID.Valid && onBudget[uuid.UUID(p.TransferAccountID.Bytes)] {
+			onBudgetTransfers[p.ID] = true
+		}
+	}
+
+	var ready int64
+	var err error
+	for _, t := range transactions {
+		if !onBudget[t.AccountID] || t.CategoryID.Valid || t.Amount <= 0 || onBudgetTransfers[t.PayeeID] {
+			continue
+		}
+		if ready, err = money.Add(ready, t.Amount); err != nil {
+			return 0, err
+		}
+	}
+	for _, c := range categories {
+		if ready, err = money.Sub(ready, c.Assigned); err != nil {
+			return 0, err
+		}
+	}
+
+	return ready, nil
+}
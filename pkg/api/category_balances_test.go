@@ -0,0 +1,228 @@
+package api
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/money"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeCategoryBalances(t *testing.T) {
+
+	checking := db.Account{ID: uuid.New(), Type: AccountTypeChecking, OnBudget: true}
+	card := db.Account{ID: uuid.New(), Type: AccountTypeCreditCard, OnBudget: true}
+	house := db.Account{ID: uuid.New(), Type: AccountTypeAsset, OnBudget: false}
+	accounts := []db.Account{checking, card, house}
+
+	groceries := db.Category{ID: uuid.New(), Name: "Groceries", Assigned: 100}
+	dining := db.Category{ID: uuid.New(), Name: "Dining", Assigned: 50}
+	payment := db.Category{ID: uuid.New(), Name: "Visa", PaymentAccountID: pgtype.UUID{Bytes: card.ID, Valid: true}}
+	categories := []db.Category{groceries, dining, payment}
+
+	shop := db.Payee{ID: uuid.New(), Name: "Edeka"}
+	toCard := db.Payee{ID: uuid.New(), Name: "Transfer : Visa", TransferAccountID: pgtype.UUID{Bytes: card.ID, Valid: true}}
+	toChecking := db.Payee{ID: uuid.New(), Name: "Transfer : Checking", TransferAccountID: pgtype.UUID{Bytes: checking.ID, Valid: true}}
+	payees := []db.Payee{shop, toCard, toChecking}
+
+	day := func(d int) pgtype.Date {
+		return pgtype.Date{Time: time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC), Valid: true}
+	}
+	spend := func(account db.Account, category db.Category, amount int64, d int) db.Transaction {
+		return db.Transaction{ID: uuid.New(), AccountID: account.ID, PayeeID: shop.ID, CategoryID: pgtype.UUID{Bytes: category.ID, Valid: true}, Amount: amount, Date: day(d)}
+	}
+	transfer := func(account db.Account, payee db.Payee, amount int64, d int) db.Transaction {
+		return db.Transaction{ID: uuid.New(), AccountID: account.ID, PayeeID: payee.ID, Amount: amount, Date: day(d)}
+	}
+
+	testCases := []struct {
+		name         string
+		transactions []db.Transaction
+		want         map[uuid.UUID]categoryBalance
+		wantErr      error
+	}{
+		{
+			name:         "CardSpendingCovered",
+			transactions: []db.Transaction{spend(card, groceries, -60, 1)},
+			want: map[uuid.UUID]categoryBalance{
+				groceries.ID: {Activity: -60, Available: 40},
+				payment.ID:   {Activity: 60, Available: 60},
+			},
+		},
+		{
+			name:         "CreditOverspending",
+			transactions: []db.Transaction{spend(card, dining, -80, 1)},
+			want: map[uuid.UUID]categoryBalance{
+				dining.ID:  {Activity: -80, Available: -30, CreditOverspent: 30},
+				payment.ID: {Activity: 50, Available: 50},
+			},
+		},
+		{
+			name:         "CashOverspending",
+			transactions: []db.Transaction{spend(checking, dining, -80, 1)},
+			want: map[uuid.UUID]categoryBalance{
+				dining.ID:  {Activity: -80, Available: -30, CashOverspent: 30},
+				payment.ID: {},
+			},
+		},
+		{
+			name: "CashAfterCreditOverspending",
+			transactions: []db.Transaction{
+				// the dates decide the order, not the order of the list
+				spend(checking, dining, -20, 2),
+				spend(card, dining, -40, 1),
+			},
+			want: map[uuid.UUID]categoryBalance{
+				dining.ID:  {Activity: -60, Available: -10, CashOverspent: 10},
+				payment.ID: {Activity: 40, Available: 40},
+			},
+		},
+		{
+			name: "Payments",
+			transactions: []db.Transaction{
+				spend(card, groceries, -60, 1),
+				transfer(checking, toCard, -50, 2),
+				transfer(card, toChecking, 5, 3),
+			},
+			want: map[uuid.UUID]categoryBalance{
+				groceries.ID: {Activity: -60, Available: 40},
+				payment.ID:   {Activity: 5, Available: 5},
+			},
+		},
+		{
+			name: "Refund",
+			transactions: []db.Transaction{
+				spend(card, groceries, -60, 1),
+				spend(card, groceries, 20, 2),
+			},
+			want: map[uuid.UUID]categoryBalance{
+				groceries.ID: {Activity: -40, Available: 60},
+				payment.ID:   {Activity: 40, Available: 40},
+			},
+		},
+		{
+			name:         "TrackingAccount",
+			transactions: []db.Transaction{spend(house, groceries, -60, 1)},
+			want: map[uuid.UUID]categoryBalance{
+				groceries.ID: {Available: 100},
+			},
+		},
+		{
+			name:         "Overflow",
+			transactions: []db.Transaction{spend(checking, groceries, math.MaxInt64, 1), spend(checking, groceries, 1, 2)},
+			wantErr:      money.ErrOverflow,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			balances, err := computeCategoryBalances(categories, accounts, payees, tc.transactions)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, balances, len(categories))
+			for id, want := range tc.want {
+				require.Equal(t, want, balances[id])
+			}
+		})
+	}
+}
+
+func TestComputeReadyToAssign(t *testing.T) {
+
+	checking := db.Account{ID: uuid.New(), Type: AccountTypeChecking, OnBudget: true}
+	card := db.Account{ID: uuid.New(), Type: AccountTypeCreditCard, OnBudget: true}
+	brokerage := db.Account{ID: uuid.New(), Type: AccountTypeAsset, OnBudget: false}
+	accounts := []db.Account{checking, card, brokerage}
+
+	groceries := db.Category{ID: uuid.New(), Name: "Groceries", Assigned: 300}
+	payment := db.Category{ID: uuid.New(), Name: "Visa", Assigned: 100, PaymentAccountID: pgtype.UUID{Bytes: card.ID, Valid: true}}
+	categories := []db.Category{groceries, payment}
+
+	employer := db.Payee{ID: uuid.New(), Name: "Employer"}
+	toCard := db.Payee{ID: uuid.New(), Name: "Transfer : Visa", TransferAccountID: pgtype.UUID{Bytes: card.ID, Valid: true}}
+	toChecking := db.Payee{ID: uuid.New(), Name: "Transfer : Checking", TransferAccountID: pgtype.UUID{Bytes: checking.ID, Valid: true}}
+	toBrokerage := db.Payee{ID: uuid.New(), Name: "Transfer : Brokerage", TransferAccountID: pgtype.UUID{Bytes: brokerage.ID, Valid: true}}
+	payees := []db.Payee{employer, toCard, toChecking, toBrokerage}
+
+	transaction := func(account db.Account, payee db.Payee, category *db.Category, amount int64) db.Transaction {
+		t := db.Transaction{ID: uuid.New(), AccountID: account.ID, PayeeID: payee.ID, Amount: amount}
+		if category != nil {
+			t.CategoryID = pgtype.UUID{Bytes: category.ID, Valid: true}
+		}
+		return t
+	}
+
+	testCases := []struct {
+		name         string
+		transactions []db.Transaction
+		want         int64
+		wantErr      error
+	}{
+		{
+			name:         "NothingAssignedYet",
+			transactions: []db.Transaction{transaction(checking, employer, nil, 1000)},
+			want:         600,
+		},
+		{
+			// the inflow and the spending of a category do not change the money to assign
+			name: "CategorizedTransactions",
+			transactions: []db.Transaction{
+				transaction(checking, employer, nil, 1000),
+				transaction(checking, employer, &groceries, 50),
+				transaction(checking, employer, &groceries, -200),
+			},
+			want: 600,
+		},
+		{
+			name: "TransfersBetweenOnBudgetAccounts",
+			transactions: []db.Transaction{
+				transaction(checking, employer, nil, 1000),
+				transaction(card, toChecking, nil, 400),
+				transaction(checking, toCard, nil, -400),
+			},
+			want: 600,
+		},
+		{
+			name: "TransferFromTrackingAccount",
+			transactions: []db.Transaction{
+				transaction(checking, toBrokerage, nil, 500),
+				transaction(brokerage, employer, nil, 2000),
+			},
+			want: 100,
+		},
+		{
+			name:         "Overassigned",
+			transactions: []db.Transaction{transaction(checking, employer, nil, 100)},
+			want:         -300,
+		},
+		{
+			name: "Overflow",
+			transactions: []db.Transaction{
+				transaction(checking, employer, nil, math.MaxInt64),
+				transaction(card, employer, nil, 1),
+			},
+			wantErr: money.ErrOverflow,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := computeReadyToAssign(categories, accounts, payees, tc.transactions)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
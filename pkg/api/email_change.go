@@ -0,0 +1,327 @@
+package api
+
+import (
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Pages of the links of an email change. Following a link only asks for a confirmation, so that mail scanners
+// opening links do not confirm or cancel the change; the forms post the token to the same path.
+var confirmEmailChangePage = template.Must(template.New("confirm_email_change").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Confirm your new gobudget email</title></head>
+<body>
+<form method="post" action="confirm">
+<input type="hidden" name="token" value="{{.}}">
+<p>Use this address as the email of your gobudget account?</p>
+<button type="submit">Confirm the new email</button>
+</form>
+</body>
+</html>
+`))
+
+var cancelEmailChangePage = template.Must(template.New("cancel_email_change").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Cancel the change of your gobudget email</title></head>
+<body>
+<form method="post" action="cancel">
+<input type="hidden" name="token" value="{{.}}">
+<p>Cancel the change of the email of your gobudget account?</p>
+<button type="submit">Cancel the change</button>
+</form>
+</body>
+</html>
+`))
+
+// requestEmailChange godoc
+//
+//	@Summary	Change email
+//	@Schemes
+//	@Description	Ask to change the email of the authenticated user, which requires the current password. A confirmation link is sent to the new address,
+//	@Description	and a notification with a link to cancel the change to the current one. The email is only changed once the new address is confirmed.
+//	@Tags			User
+//	@Accept			json
+//	@Param			email	body	emailChangeRequest	true	"New email and current password"
+//	@Produce		json
+//	@Success		202	{string}	string	"a confirmation link has been sent to the new email"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		429	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/email [post]
+//	@Security		Bearer
+func (s *Server) requestEmailChange(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	var rqst emailChangeRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	// Checking the password is throttled like the login
+	clientIp := ctx.ClientIP()
+	if s.loginThrottled(ctx, authz_payload.Username, clientIp) {
+		return
+	}
+
+	u, err := s.db.GetUserByUsername(ctx, authz_payload.Username)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse("user is not valid"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	// Re-authenticate the user, a stolen access token is not enough to take over the email
+	if err := util.CheckPassword(u.Password, rqst.Password); err != nil {
+		s.recordLoginFailure(ctx, u.Username, clientIp, true)
+		ctx.JSON(http.StatusUnauthorized, errorResponse("invalid password"))
+		return
+	}
+	if rqst.Email == u.Email {
+		ctx.JSON(http.StatusBadRequest, errorResponse("the new email is the current one"))
+		return
+	}
+	if _, err := s.db.GetUserByEmail(ctx, rqst.Email); err == nil {
+		ctx.JSON(http.StatusForbidden, errorResponse("email is already in use"))
+		return
+	} else if err != pgx.ErrNoRows {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	taskPayload := &worker.SendEmailPayload{
+		Username: u.Username,
+		Email:    rqst.Email,
+	}
+	if err := s.taskDistributor.DistributeSendEmail(ctx, taskPayload, worker.TaskSendEmailChangeEmail); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	slog.Info("Requested an email change", "user", u.Username)
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "a confirmation link has been sent to the new email"})
+}
+
+// getConfirmEmailChangePage godoc
+//
+//	@Summary	Confirm email change page
+//	@Schemes
+//	@Description	Serve the page of the confirmation link sent to the new address: a form which asks to confirm, and posts the token to /email_change/confirm.
+//	@Tags			User
+//	@Param			token	query	string	true	"Token of the confirmation link"
+//	@Produce		html
+//	@Success		200	{string}	string	"confirm email change page"
+//	@Failure		400	{object}	HTTPError
+//	@Router			/email_change/confirm [get]
+func (s *Server) getConfirmEmailChangePage(ctx *gin.Context) {
+	s.serveEmailChangePage(ctx, confirmEmailChangePage)
+}
+
+// getCancelEmailChangePage godoc
+//
+//	@Summary	Cancel email change page
+//	@Schemes
+//	@Description	Serve the page of the cancel link sent to the current address: a form which asks to confirm, and posts the token to /email_change/cancel.
+//	@Tags			User
+//	@Param			token	query	string	true	"Token of the cancel link"
+//	@Produce		html
+//	@Success		200	{string}	string	"cancel email change page"
+//	@Failure		400	{object}	HTTPError
+//	@Router			/email_change/cancel [get]
+func (s *Server) getCancelEmailChangePage(ctx *gin.Context) {
+	s.serveEmailChangePage(ctx, cancelEmailChangePage)
+}
+
+func (s *Server) serveEmailChangePage(ctx *gin.Context, page *template.Template) {
+
+	var rqst emailChangeTokenRequest
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	// the token is in the URL, which must not leak to other sites
+	ctx.Header("Referrer-Policy", "no-referrer")
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	if err := page.Execute(ctx.Writer, rqst.Token); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+// Binds the token of an email change, posted as JSON or by the form of its page
+func bindEmailChangeToken(ctx *gin.Context, rqst *emailChangeTokenRequest) error {
+
+	if ctx.ContentType() == binding.MIMEPOSTForm {
+		return ctx.ShouldBind(rqst)
+	}
+	return ctx.ShouldBindJSON(rqst)
+}
+
+// confirmEmailChange godoc
+//
+//	@Summary	Confirm an email change
+//	@Schemes
+//	@Description	Change the email of an account with the token of the confirmation link sent to the new address. The new email is verified.
+//	@Tags			User
+//	@Accept			json,x-www-form-urlencoded
+//	@Param			token	body	emailChangeTokenRequest	true	"Token of the confirmation link"
+//	@Produce		json
+//	@Success		200	{object}	userResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/email_change/confirm [post]
+func (s *Server) confirmEmailChange(ctx *gin.Context) {
+
+	var rqst emailChangeTokenRequest
+	if err := bindEmailChangeToken(ctx, &rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	change, err := s.db.GetEmailChangeByHash(ctx, token.HashOpaqueToken(rqst.Token))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired email change"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if change.Used || time.Now().After(change.ExpiresAt) {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired email change"))
+		return
+	}
+
+	u, err := s.db.GetUserByUsername(ctx, change.Username)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	// the email was changed some other way since the change was asked for
+	if u.Email != change.OldEmail {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired email change"))
+		return
+	}
+
+	n, err := s.db.UseEmailChange(ctx, change.ID)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if n == 0 {
+		// confirmed or cancelled by a concurrent request
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired email change"))
+		return
+	}
+
+	// the link was opened from the new address, so it is verified
+	updatedUser, err := s.db.UpdateUser(ctx, db.UpdateUserParams{
+		Username:      u.Username,
+		Email:         pgtype.Text{String: change.NewEmail, Valid: true},
+		EmailVerified: pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			ctx.JSON(http.StatusForbidden, errorResponse("email is already in use"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	slog.Info("Changed email", "user", updatedUser.Username)
+
+	resp := userResponse{
+		Username:           updatedUser.Username,
+		Email:              updatedUser.Email,
+		EmailVerified:      updatedUser.EmailVerified,
+		CreatedAt:          updatedUser.CreatedAt,
+		LastPasswordChange: updatedUser.LastPasswordChange,
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// cancelEmailChange godoc
+//
+//	@Summary	Cancel an email change
+//	@Schemes
+//	@Description	Cancel a pending email change with the token of the link sent to the current address.
+//	@Tags			User
+//	@Accept			json,x-www-form-urlencoded
+//	@Param			token	body	emailChangeTokenRequest	true	"Token of the cancel link"
+//	@Produce		json
+//	@Success		200	{string}	string	"email change has been cancelled"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/email_change/cancel [post]
+func (s *Server) cancelEmailChange(ctx *gin.Context) {
+
+	var rqst emailChangeTokenRequest
+	if err := bindEmailChangeToken(ctx, &rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	change, err := s.db.GetEmailChangeByCancelHash(ctx, token.HashOpaqueToken(rqst.Token))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse("invalid email change"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	n, err := s.db.UseEmailChange(ctx, change.ID)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse("the email change has already been confirmed or cancelled"))
+		return
+	}
+
+	slog.Info("Cancelled an email change", "user", change.Username)
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "email change has been cancelled"})
+}
@@ -0,0 +1,454 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/limiter"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequestEmailChangeAPI(t *testing.T) {
+
+	user, plainPassword := buildTestUser(t)
+	newEmail := "charles.leclerc@ferrari.com"
+
+	testCases := []struct {
+		name          string
+		email         string
+		password      string
+		lockedOut     bool
+		buildStubs    func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			email:    newEmail,
+			password: plainPassword,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), newEmail).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), &worker.SendEmailPayload{Username: user.Username, Email: newEmail}, worker.TaskSendEmailChangeEmail).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:     "WrongPassword",
+			email:    newEmail,
+			password: "incorrectPassword",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateLoginEventParams) (db.LoginEvent, error) {
+						require.False(t, arg.Success)
+						return db.LoginEvent{}, nil
+					})
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "LockedOut",
+			email:     newEmail,
+			password:  plainPassword,
+			lockedOut: true,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name:     "SameEmail",
+			email:    user.Email,
+			password: plainPassword,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "EmailInUse",
+			email:    newEmail,
+			password: plainPassword,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), newEmail).
+					Times(1).
+					Return(db.User{Username: "otheruser", Email: newEmail}, nil)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidEmail",
+			email:    "notanemail",
+			password: plainPassword,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			dist := mockdb.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, dist)
+
+			server := NewTestServer(t, store, dist)
+			if tc.lockedOut {
+				server.loginLimiter = limiter.NewMemoryLoginLimiter(limiter.LoginPolicy{LockoutThreshold: 1, LockoutDuration: time.Hour})
+				_, err := server.loginLimiter.RecordFailure(context.Background(), user.Username, "192.0.2.1")
+				require.NoError(t, err)
+			}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"email": tc.email, "password": tc.password})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/beta/user/email", bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: user.Username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestConfirmEmailChangeAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+	newEmail := "charles.leclerc@ferrari.com"
+
+	confirmToken, confirmTokenHash, err := token.NewOpaqueToken("")
+	require.NoError(t, err)
+
+	change := db.EmailChange{
+		ID:        1,
+		Username:  user.Username,
+		OldEmail:  user.Email,
+		NewEmail:  newEmail,
+		TokenHash: confirmTokenHash,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetEmailChangeByHash(gomock.Any(), confirmTokenHash).
+					Times(1).
+					Return(change, nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseEmailChange(gomock.Any(), change.ID).
+					Times(1).
+					Return(int64(1), nil)
+				updated := user
+				updated.Email = newEmail
+				store.EXPECT().
+					UpdateUser(gomock.Any(), db.UpdateUserParams{
+						Username:      user.Username,
+						Email:         pgtype.Text{String: newEmail, Valid: true},
+						EmailVerified: pgtype.Bool{Bool: true, Valid: true},
+					}).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp userResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, newEmail, resp.Email)
+				require.True(t, resp.EmailVerified)
+			},
+		},
+		{
+			name: "Expired",
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := change
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				store.EXPECT().
+					GetEmailChangeByHash(gomock.Any(), confirmTokenHash).
+					Times(1).
+					Return(expired, nil)
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Cancelled",
+			buildStubs: func(store *mockdb.MockStore) {
+				cancelled := change
+				cancelled.Used = true
+				store.EXPECT().
+					GetEmailChangeByHash(gomock.Any(), confirmTokenHash).
+					Times(1).
+					Return(cancelled, nil)
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EmailChangedSince",
+			buildStubs: func(store *mockdb.MockStore) {
+				changed := user
+				changed.Email = "other@example.com"
+				store.EXPECT().
+					GetEmailChangeByHash(gomock.Any(), confirmTokenHash).
+					Times(1).
+					Return(change, nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(changed, nil)
+				store.EXPECT().
+					UseEmailChange(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EmailInUse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetEmailChangeByHash(gomock.Any(), confirmTokenHash).
+					Times(1).
+					Return(change, nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseEmailChange(gomock.Any(), change.ID).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, &pgconn.PgError{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownToken",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetEmailChangeByHash(gomock.Any(), confirmTokenHash).
+					Times(1).
+					Return(db.EmailChange{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"token": confirmToken})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/beta/email_change/confirm", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCancelEmailChangeAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+
+	cancelToken, cancelTokenHash, err := token.NewOpaqueToken("")
+	require.NoError(t, err)
+
+	change := db.EmailChange{
+		ID:              1,
+		Username:        user.Username,
+		OldEmail:        user.Email,
+		NewEmail:        "charles.leclerc@ferrari.com",
+		CancelTokenHash: cancelTokenHash,
+		ExpiresAt:       time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		rowsAffected  int64
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "OK",
+			rowsAffected: 1,
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:         "AlreadyConfirmed",
+			rowsAffected: 0,
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetEmailChangeByCancelHash(gomock.Any(), cancelTokenHash).
+				Times(1).
+				Return(change, nil)
+			store.EXPECT().
+				UseEmailChange(gomock.Any(), change.ID).
+				Times(1).
+				Return(tc.rowsAffected, nil)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			// posted by the form of the cancel page
+			form := url.Values{"token": {cancelToken}}
+			request, err := http.NewRequest(http.MethodPost, "/beta/email_change/cancel", strings.NewReader(form.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestEmailChangePagesAPI(t *testing.T) {
+
+	// following the links only shows the pages, without touching the email change
+	server := NewTestServer(t, nil, nil)
+
+	for _, action := range []string{"confirm", "cancel"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/beta/email_change/"+action+"?token="+url.QueryEscape(`abc"><script>`), nil)
+		require.NoError(t, err)
+		server.Router.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+		require.Equal(t, "no-referrer", recorder.Header().Get("Referrer-Policy"))
+		require.Contains(t, recorder.Body.String(), `<form method="post" action="`+action+`">`)
+		require.Contains(t, recorder.Body.String(), `value="abc&#34;&gt;&lt;script&gt;"`)
+
+		recorder = httptest.NewRecorder()
+		request, err = http.NewRequest(http.MethodGet, "/beta/email_change/"+action, nil)
+		require.NoError(t, err)
+		server.Router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	}
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/fx"
+	"github.com/guerzon/gobudget-api/pkg/money"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Most rates a file can import at once, about the full history of the rates of the ECB
+const maxRateImportRows = 200000
+
+// Largest file of rates which can be imported, about twice the full history of the rates of the ECB
+const maxRateImportBytes = 16 << 20
+
+// getExchangeRates godoc
+//
+//	@Summary	List exchange rates
+//	@Schemes
+//	@Description	List the exchange rates of a budget, optionally between two currencies.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Param			from		query	string	false	"Currency converted from"
+//	@Param			to			query	string	false	"Currency converted to"
+//	@Tags			Exchange rates
+//	@Produce		json
+//	@Success		200	{object}	[]exchangeRateResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/exchange-rates [get]
+func (s *Server) getExchangeRates(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	var rqst exchangeRatesQuery
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	rates, err := s.db.GetExchangeRates(ctx, db.GetExchangeRatesParams{
+		BudgetID:     budgetId,
+		FromCurrency: pgtype.Text{String: rqst.From, Valid: rqst.From != ""},
+		ToCurrency:   pgtype.Text{String: rqst.To, Valid: rqst.To != ""},
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	rsp := make([]exchangeRateResponse, len(rates))
+	for i, r := range rates {
+		rsp[i] = exchangeRateResponse{
+			Date: r.Date,
+			From: r.FromCurrency,
+			To:   r.ToCurrency,
+			Rate: r.Rate,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// updateExchangeRates godoc
+//
+//	@Summary	Set exchange rates
+//	@Schemes
+//	@Description	Set exchange rates on dates, replacing the rates already set for the same currencies and date.
+//	@Description	Amounts are converted at the last rate on or before their date, the other way around with the inverse rate,
+//	@Description	or through a third currency when there is no rate between two currencies.
+//	@Param			budget_id	path	string					true	"Budget ID"
+//	@Param			rates		body	exchangeRatesRequest	true	"Exchange rates"
+//	@Tags			Exchange rates
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	string	"exchange rates saved"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/exchange-rates [put]
+func (s *Server) updateExchangeRates(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	var rqst exchangeRatesRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	rates := make([]db.UpsertExchangeRateParams, len(rqst.Rates))
+	for i, r := range rqst.Rates {
+		rates[i] = db.UpsertExchangeRateParams{
+			BudgetID:     budgetId,
+			Date:         r.Date,
+			FromCurrency: r.From,
+			ToCurrency:   r.To,
+			Rate:         r.Rate,
+		}
+	}
+
+	if err := s.db.UpsertExchangeRatesTx(ctx, rates); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "exchange rates saved", "count": len(rates)})
+}
+
+// importExchangeRates godoc
+//
+//	@Summary	Import exchange rates from an ECB file
+//	@Schemes
+//	@Description	Import the euro reference rates of the European Central Bank, from a file such as eurofxref-daily.xml or eurofxref-hist.xml.
+//	@Description	The file is imported whole or not at all, and can be up to 16 MB with up to 200000 rates.
+//	@Param			budget_id	path		string	true	"Budget ID"
+//	@Param			file		formData	file	true	"ECB XML file"
+//	@Tags			Exchange rates
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		200	{object}	string	"exchange rates imported"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		413	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/exchange-rates/import [post]
+func (s *Server) importExchangeRates(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxRateImportBytes)
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(fmt.Sprintf("the file is larger than %d MB", maxRateImportBytes>>20)))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	defer file.Close()
+
+	parsed, err := fx.ParseECB(file, maxRateImportRows)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	rates := make([]db.UpsertExchangeRateParams, len(parsed))
+	for i, r := range parsed {
+		rates[i] = db.UpsertExchangeRateParams{
+			BudgetID:     budgetId,
+			Date:         pgtype.Date{Time: r.Date, Valid: true},
+			FromCurrency: r.From,
+			ToCurrency:   r.To,
+			Rate:         r.Rate,
+		}
+	}
+
+	if err := s.db.UpsertExchangeRatesTx(ctx, rates); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "exchange rates imported", "count": len(rates)})
+}
+
+// Loads the exchange rates of a budget on or before a date, or all of them if the date is not valid. Skips the query
+// when all the accounts are in the currency of the budget.
+func (s *Server) loadExchangeRates(ctx context.Context, budget db.Budget, accounts []db.Account, date pgtype.Date) (*fx.Table, error) {
+
+	foreign := false
+	for _, a := range accounts {
+		if a.CurrencyCode != budget.CurrencyCode {
+			foreign = true
+			break
+		}
+	}
+	if !foreign {
+		return fx.NewTable(nil), nil
+	}
+
+	rates, err := s.db.GetExchangeRatesUntil(ctx, db.GetExchangeRatesUntilParams{
+		BudgetID: budget.ID,
+		Date:     date,
+	})
+	if err != nil {
+		return nil, err
+	}
+	fxRates := make([]fx.Rate, len(rates))
+	for i, r := range rates {
+		fxRates[i] = fx.Rate{Date: r.Date.Time, From: r.FromCurrency, To: r.ToCurrency, Rate: r.Rate}
+	}
+
+	return fx.NewTable(fxRates), nil
+}
+
+// Writes the response of an error converting between currencies or adding up amounts: a missing rate is for the user
+// to add, and an amount out of range comes from amounts the user entered.
+func conversionErrorResponse(ctx *gin.Context, err error) {
+	var missing *fx.MissingRateError
+	if errors.As(err, &missing) || errors.Is(err, money.ErrOverflow) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	slog.Error(err.Error())
+	ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+}
+
+// Converts the amounts of transactions into the currency of the budget, at the rates of their dates
+func convertTransactions(rates *fx.Table, budget db.Budget, accounts []db.Account, transactions []db.Transaction) ([]db.Transaction, error) {
+
+	currencies := make(map[uuid.UUID]string, len(accounts))
+	for _, a := range accounts {
+		currencies[a.ID] = a.CurrencyCode
+	}
+
+	converted := make([]db.Transaction, len(transactions))
+	for i, t := range transactions {
+		converted[i] = t
+		currency, ok := currencies[t.AccountID]
+		if !ok || currency == budget.CurrencyCode {
+			continue
+		}
+		amount, err := rates.Convert(t.Amount, currency, budget.CurrencyCode, t.Date.Time)
+		if err != nil {
+			return nil, err
+		}
+		converted[i].Amount = amount
+	}
+
+	return converted, nil
+}
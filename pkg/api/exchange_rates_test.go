@@ -0,0 +1,193 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestImportExchangeRatesAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+
+	testCases := []struct {
+		name          string
+		xml           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			xml:  "<Envelope><Cube><Cube time='2024-05-31'><Cube currency='USD' rate='1.0812'/><Cube currency='JPY' rate='170.08'/></Cube></Cube></Envelope>",
+			buildStubs: func(store *mockdb.MockStore) {
+				date := pgtype.Date{Time: time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC), Valid: true}
+				store.EXPECT().
+					UpsertExchangeRatesTx(gomock.Any(), []db.UpsertExchangeRateParams{
+						{BudgetID: budget.ID, Date: date, FromCurrency: "EUR", ToCurrency: "USD", Rate: 1.0812},
+						{BudgetID: budget.ID, Date: date, FromCurrency: "EUR", ToCurrency: "JPY", Rate: 170.08},
+					}).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRate",
+			xml:  "<Envelope><Cube><Cube time='2024-05-31'><Cube currency='USD' rate='abc'/></Cube></Cube></Envelope>",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRatesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "invalid rate")
+			},
+		},
+		{
+			name: "NotXML",
+			xml:  "date,rate\n2024-05-31,1.08\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRatesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TooLarge",
+			xml:  "<Envelope>" + strings.Repeat(" ", maxRateImportBytes) + "</Envelope>",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRatesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), db.GetBudgetParams{ID: budget.ID, OwnerUsername: username}).
+				Times(1).
+				Return(budget, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			part, err := writer.CreateFormFile("file", "eurofxref-daily.xml")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(tc.xml))
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			request, err := http.NewRequest(http.MethodPost, "/beta/budgets/"+budget.ID.String()+"/exchange-rates/import", &body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetFXGainsAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	checking := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Chase", Type: AccountTypeChecking, CurrencyCode: "EUR", OnBudget: true}
+	dollars := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Dollars", Type: AccountTypeSavings, CurrencyCode: "USD", OnBudget: true}
+	date := pgtype.Date{Time: time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC), Valid: true}
+	transfer := db.GetCurrencyTransfersRow{ID: uuid.New(), Date: date, AccountID: checking.ID, TransferAccountID: dollars.ID, Amount: -10000, TransferAmount: 12600}
+
+	testCases := []struct {
+		name          string
+		rates         []db.ExchangeRate
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			rates: []db.ExchangeRate{{BudgetID: budget.ID, Date: date, FromCurrency: "EUR", ToCurrency: "USD", Rate: 1.25}},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp fxGainsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.Transfers, 1)
+				require.Equal(t, int64(-10000), rsp.Transfers[0].Sent)
+				require.Equal(t, int64(10080), rsp.Transfers[0].Received)
+				require.Equal(t, int64(80), rsp.Transfers[0].Gain)
+				require.Equal(t, int64(80), rsp.Total)
+			},
+		},
+		{
+			name:  "MissingRate",
+			rates: []db.ExchangeRate{},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "no exchange rate from USD to EUR")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), db.GetBudgetParams{ID: budget.ID, OwnerUsername: username}).
+				Times(1).
+				Return(budget, nil)
+			store.EXPECT().GetAccounts(gomock.Any(), budget.ID).Times(1).Return([]db.Account{checking, dollars}, nil)
+			store.EXPECT().GetCurrencyTransfers(gomock.Any(), budget.ID).Times(1).Return([]db.GetCurrencyTransfersRow{transfer}, nil)
+			store.EXPECT().
+				GetExchangeRatesUntil(gomock.Any(), db.GetExchangeRatesUntilParams{BudgetID: budget.ID}).
+				Times(1).
+				Return(tc.rates, nil)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/beta/budgets/"+budget.ID.String()+"/reports/fx-gains", nil)
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
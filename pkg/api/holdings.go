@@ -0,0 +1,132 @@
+package api
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/money"
+)
+
+// Types of investment transactions. Keep in sync with the investment_transactions_type_check constraint.
+const (
+	InvestmentBuy      = "buy"
+	InvestmentSell     = "sell"
+	InvestmentDividend = "dividend"
+)
+
+// Quantities below this are what is left of rounding after selling a whole position
+const quantityEpsilon = 1e-9
+
+// A position in a security
+type holding struct {
+	Symbol    string
+	Quantity  float64
+	CostBasis int64
+}
+
+// Computes the holdings of investment transactions on or before a date, sorted by symbol. The cost basis is the
+// average cost: a sell removes the cost basis of the shares sold in proportion to the shares held.
+func computeHoldings(transactions []db.InvestmentTransaction, date time.Time) []holding {
+
+	transactions = slices.Clone(transactions)
+	slices.SortStableFunc(transactions, func(a, b db.InvestmentTransaction) int {
+		return a.Date.Time.Compare(b.Date.Time)
+	})
+
+	positions := make(map[string]*holding)
+	for _, t := range transactions {
+		if t.Date.Time.After(date) {
+			break
+		}
+		p, ok := positions[t.Symbol]
+		if !ok {
+			p = &holding{Symbol: t.Symbol}
+			positions[t.Symbol] = p
+		}
+		switch t.Type {
+		case InvestmentBuy:
+			p.Quantity += t.Quantity
+			p.CostBasis += t.Amount
+		case InvestmentSell:
+			if p.Quantity <= quantityEpsilon {
+				continue
+			}
+			sold := min(t.Quantity, p.Quantity)
+			p.CostBasis -= int64(math.Round(float64(p.CostBasis) * sold / p.Quantity))
+			p.Quantity -= sold
+			if p.Quantity <= quantityEpsilon {
+				p.Quantity = 0
+				p.CostBasis = 0
+			}
+		}
+	}
+
+	holdings := make([]holding, 0, len(positions))
+	for _, p := range positions {
+		if p.Quantity > quantityEpsilon {
+			holdings = append(holdings, *p)
+		}
+	}
+	slices.SortFunc(holdings, func(a, b holding) int {
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+
+	return holdings
+}
+
+// Replays investment transactions in date order and returns the first symbol of which more shares are sold than held
+// on or after a date, empty if there is none. A sell can only be added if the sells after it still have their shares.
+func oversoldSymbol(transactions []db.InvestmentTransaction, date time.Time) string {
+
+	transactions = slices.Clone(transactions)
+	slices.SortStableFunc(transactions, func(a, b db.InvestmentTransaction) int {
+		return a.Date.Time.Compare(b.Date.Time)
+	})
+
+	quantities := make(map[string]float64)
+	for _, t := range transactions {
+		switch t.Type {
+		case InvestmentBuy:
+			quantities[t.Symbol] += t.Quantity
+		case InvestmentSell:
+			quantities[t.Symbol] -= t.Quantity
+			if quantities[t.Symbol] < -quantityEpsilon && !t.Date.Time.Before(date) {
+				return t.Symbol
+			}
+		}
+	}
+
+	return ""
+}
+
+// Computes the cash that investment transactions on or before a date moved in their account: buys pay cash for shares,
+// sells and dividends bring cash in.
+func investmentCash(transactions []db.InvestmentTransaction, date time.Time) (int64, error) {
+
+	var cash int64
+	for _, t := range transactions {
+		if t.Date.Time.After(date) {
+			continue
+		}
+		var err error
+		switch t.Type {
+		case InvestmentBuy:
+			cash, err = money.Sub(cash, t.Amount)
+		case InvestmentSell, InvestmentDividend:
+			cash, err = money.Add(cash, t.Amount)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return cash, nil
+}
+
+// Returns the value of a quantity of shares at a price
+func marketValue(quantity float64, price int64) int64 {
+	return int64(math.Round(quantity * float64(price)))
+}
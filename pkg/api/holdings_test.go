@@ -0,0 +1,126 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeHoldings(t *testing.T) {
+
+	accountId := uuid.New()
+	day := func(d int) time.Time {
+		return time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC)
+	}
+	trade := func(kind string, symbol string, quantity float64, amount int64, d int) db.InvestmentTransaction {
+		return db.InvestmentTransaction{ID: uuid.New(), AccountID: accountId, Type: kind, Symbol: symbol, Quantity: quantity, Amount: amount, Date: pgtype.Date{Time: day(d), Valid: true}}
+	}
+
+	testCases := []struct {
+		name         string
+		transactions []db.InvestmentTransaction
+		date         time.Time
+		want         []holding
+	}{
+		{
+			name: "Buys",
+			transactions: []db.InvestmentTransaction{
+				trade(InvestmentBuy, "VWCE", 10, 100000, 1),
+				trade(InvestmentBuy, "VWCE", 5, 60000, 2),
+				trade(InvestmentBuy, "AAPL", 2.5, 45000, 3),
+			},
+			date: day(31),
+			want: []holding{
+				{Symbol: "AAPL", Quantity: 2.5, CostBasis: 45000},
+				{Symbol: "VWCE", Quantity: 15, CostBasis: 160000},
+			},
+		},
+		{
+			name: "SellAtAverageCost",
+			transactions: []db.InvestmentTransaction{
+				trade(InvestmentBuy, "VWCE", 10, 100000, 1),
+				trade(InvestmentBuy, "VWCE", 10, 140000, 2),
+				trade(InvestmentSell, "VWCE", 5, 80000, 3),
+			},
+			date: day(31),
+			want: []holding{
+				{Symbol: "VWCE", Quantity: 15, CostBasis: 180000},
+			},
+		},
+		{
+			name: "SellEverything",
+			transactions: []db.InvestmentTransaction{
+				trade(InvestmentBuy, "VWCE", 0.1, 1000, 1),
+				trade(InvestmentBuy, "VWCE", 0.2, 2000, 2),
+				trade(InvestmentSell, "VWCE", 0.3, 3500, 3),
+			},
+			date: day(31),
+			want: []holding{},
+		},
+		{
+			name: "DividendsKeepTheHolding",
+			transactions: []db.InvestmentTransaction{
+				trade(InvestmentBuy, "VWCE", 10, 100000, 1),
+				trade(InvestmentDividend, "VWCE", 0, 500, 2),
+			},
+			date: day(31),
+			want: []holding{
+				{Symbol: "VWCE", Quantity: 10, CostBasis: 100000},
+			},
+		},
+		{
+			name: "AsOfDate",
+			transactions: []db.InvestmentTransaction{
+				trade(InvestmentBuy, "VWCE", 10, 100000, 10),
+				trade(InvestmentBuy, "VWCE", 10, 100000, 1),
+			},
+			date: day(5),
+			want: []holding{
+				{Symbol: "VWCE", Quantity: 10, CostBasis: 100000},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			got := computeHoldings(tc.transactions, tc.date)
+			require.Len(t, got, len(tc.want))
+			for i := range tc.want {
+				require.Equal(t, tc.want[i].Symbol, got[i].Symbol)
+				require.InDelta(t, tc.want[i].Quantity, got[i].Quantity, quantityEpsilon)
+				require.Equal(t, tc.want[i].CostBasis, got[i].CostBasis)
+			}
+		})
+	}
+}
+
+func TestInvestmentCash(t *testing.T) {
+
+	day := func(d int) pgtype.Date {
+		return pgtype.Date{Time: time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC), Valid: true}
+	}
+	transactions := []db.InvestmentTransaction{
+		{Type: InvestmentBuy, Symbol: "VWCE", Quantity: 10, Amount: 100000, Date: day(1)},
+		{Type: InvestmentSell, Symbol: "VWCE", Quantity: 5, Amount: 60000, Date: day(10)},
+		{Type: InvestmentDividend, Symbol: "VWCE", Amount: 1500, Date: day(20)},
+	}
+
+	cash, err := investmentCash(transactions, day(15).Time)
+	require.NoError(t, err)
+	require.Equal(t, int64(-100000+60000), cash)
+
+	cash, err = investmentCash(transactions, day(31).Time)
+	require.NoError(t, err)
+	require.Equal(t, int64(-100000+60000+1500), cash)
+}
+
+func TestMarketValue(t *testing.T) {
+	require.Equal(t, int64(150000), marketValue(15, 10000))
+	require.Equal(t, int64(2358), marketValue(0.123456, 19100))
+	require.Zero(t, marketValue(0, 10000))
+}
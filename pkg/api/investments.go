@@ -0,0 +1,500 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Most prices a CSV file can import at once
+const maxPriceImportRows = 10000
+
+// Largest CSV file of prices which can be imported, about twice what the most prices take
+const maxPriceImportBytes = 1 << 20
+
+// Types of accounts which hold securities
+var investmentAccountTypes = map[string]bool{
+	AccountTypeInvestment: true,
+}
+
+// getInvestmentTransactions godoc
+//
+//	@Summary	List the investment transactions of an account
+//	@Schemes
+//	@Description	List the buys, sells and dividends of an investment account.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Param			account_id	path	string	true	"Account ID"
+//	@Tags			Investments
+//	@Produce		json
+//	@Success		200	{object}	[]db.InvestmentTransaction
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/accounts/{account_id}/investment-transactions [get]
+func (s *Server) getInvestmentTransactions(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	account, err := s.getAccountOfType(ctx, budgetId, investmentAccountTypes, "only investment accounts have investment transactions")
+	if err != nil {
+		return
+	}
+
+	transactions, err := s.db.GetInvestmentTransactions(ctx, account.ID)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transactions)
+}
+
+// createInvestmentTransaction godoc
+//
+//	@Summary	Create an investment transaction
+//	@Schemes
+//	@Description	Record a buy, sell or dividend of a security in an investment account. Buys and sells need a quantity,
+//	@Description	and an account can only sell the shares it holds on the date of the sale and does not sell later. The amount is paid from, or into, the cash of the account.
+//	@Description	It is in milliunits, thousandths of the unit of the currency, or a decimal in the currency with amount_decimal.
+//	@Param			budget_id	path	string							true	"Budget ID"
+//	@Param			account_id	path	string							true	"Account ID"
+//	@Param			transaction	body	investmentTransactionRequest	true	"Investment transaction"
+//	@Tags			Investments
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	db.InvestmentTransaction
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/accounts/{account_id}/investment-transactions [post]
+func (s *Server) createInvestmentTransaction(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	account, err := s.getAccountOfType(ctx, budgetId, investmentAccountTypes, "only investment accounts have investment transactions")
+	if err != nil {
+		return
+	}
+
+	var rqst investmentTransactionRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	symbol := normalizeSymbol(rqst.Symbol)
+	if symbol == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	amount, err := requestAmount("amount", rqst.Amount, rqst.AmountDecimal, account.CurrencyCode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	if amount < 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse("amount cannot be negative"))
+		return
+	}
+	if rqst.Type == InvestmentDividend {
+		rqst.Quantity = 0
+	} else if rqst.Quantity <= 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse("buys and sells need a quantity"))
+		return
+	}
+
+	if rqst.Type == InvestmentSell {
+		transactions, err := s.db.GetInvestmentTransactions(ctx, account.ID)
+		if err != nil {
+			slog.Error(err.Error())
+			ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+			return
+		}
+		// the sells after this one must still have their shares
+		transactions = append(transactions, db.InvestmentTransaction{
+			AccountID: account.ID,
+			Date:      rqst.Date,
+			Type:      rqst.Type,
+			Symbol:    symbol,
+			Quantity:  rqst.Quantity,
+		})
+		if oversoldSymbol(transactions, rqst.Date.Time) != "" {
+			ctx.JSON(http.StatusBadRequest, errorResponse("cannot sell more shares than the account holds"))
+			return
+		}
+	}
+
+	transaction, err := s.db.CreateInvestmentTransaction(ctx, db.CreateInvestmentTransactionParams{
+		AccountID: account.ID,
+		Date:      rqst.Date,
+		Type:      rqst.Type,
+		Symbol:    symbol,
+		Quantity:  rqst.Quantity,
+		Amount:    amount,
+		Memo:      rqst.Memo,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transaction)
+}
+
+// getHoldings godoc
+//
+//	@Summary	List the holdings of an investment account
+//	@Schemes
+//	@Description	List the securities an investment account holds on a date, valued at their last price on or before the date.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Param			account_id	path	string	true	"Account ID"
+//	@Param			date		query	string	false	"Date of the holdings (YYYY-MM-DD), today if left out"
+//	@Tags			Investments
+//	@Produce		json
+//	@Success		200	{object}	[]holdingResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/accounts/{account_id}/holdings [get]
+func (s *Server) getHoldings(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	date, err := bindAsOfDate(ctx)
+	if err != nil {
+		return
+	}
+
+	account, err := s.getAccountOfType(ctx, budgetId, investmentAccountTypes, "only investment accounts have holdings")
+	if err != nil {
+		return
+	}
+
+	transactions, err := s.db.GetInvestmentTransactions(ctx, account.ID)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	prices, err := s.db.GetLatestSecurityPrices(ctx, db.GetLatestSecurityPricesParams{
+		BudgetID: budgetId,
+		Date:     date,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, valueHoldings(computeHoldings(transactions, date.Time), prices))
+}
+
+// getSecurityPrices godoc
+//
+//	@Summary	List the prices of securities
+//	@Schemes
+//	@Description	List the price history of the securities of a budget, optionally of a single symbol.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Param			symbol		query	string	false	"Symbol of the security"
+//	@Tags			Investments
+//	@Produce		json
+//	@Success		200	{object}	[]db.SecurityPrice
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/prices [get]
+func (s *Server) getSecurityPrices(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	var rqst securityPricesQuery
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	arg := db.GetSecurityPricesParams{BudgetID: budgetId}
+	if symbol := normalizeSymbol(rqst.Symbol); symbol != "" {
+		arg.Symbol = pgtype.Text{String: symbol, Valid: true}
+	}
+
+	prices, err := s.db.GetSecurityPrices(ctx, arg)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, prices)
+}
+
+// updateSecurityPrices godoc
+//
+//	@Summary	Set prices of securities
+//	@Schemes
+//	@Description	Set the prices of securities on dates, replacing the prices already set for the same symbol and date.
+//	@Description	Prices are in milliunits of the currency of the budget, which investment accounts are in, or decimals in the currency in price_decimal.
+//	@Param			budget_id	path	string					true	"Budget ID"
+//	@Param			prices		body	securityPricesRequest	true	"Prices"
+//	@Tags			Investments
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	string	"prices saved"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/prices [put]
+func (s *Server) updateSecurityPrices(ctx *gin.Context) {
+
+	budget, err := s.getOwnedBudget(ctx)
+	if err != nil {
+		return
+	}
+
+	var rqst securityPricesRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	prices := make([]db.UpsertSecurityPriceParams, len(rqst.Prices))
+	for i, p := range rqst.Prices {
+		price, err := requestAmount("price", p.Price, p.PriceDecimal, budget.CurrencyCode)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		prices[i] = db.UpsertSecurityPriceParams{
+			BudgetID: budget.ID,
+			Symbol:   normalizeSymbol(p.Symbol),
+			Date:     p.Date,
+			Price:    price,
+		}
+		if prices[i].Symbol == "" || price < 0 {
+			ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+			return
+		}
+	}
+
+	if err := s.db.UpsertSecurityPricesTx(ctx, prices); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "prices saved", "count": len(prices)})
+}
+
+// importSecurityPrices godoc
+//
+//	@Summary	Import prices of securities from a CSV file
+//	@Schemes
+//	@Description	Import prices from a CSV file with a header row naming the symbol, date (YYYY-MM-DD) and price columns.
+//	@Description	Prices are in milliunits, thousandths of the unit of the currency of the budget, or decimals in the currency in a price_decimal column instead.
+//	@Description	The file is imported whole or not at all.
+//	@Param			budget_id	path		string	true	"Budget ID"
+//	@Param			file		formData	file	true	"CSV file"
+//	@Tags			Investments
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		200	{object}	string	"prices imported"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		413	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/prices/import [post]
+func (s *Server) importSecurityPrices(ctx *gin.Context) {
+
+	budget, err := s.getOwnedBudget(ctx)
+	if err != nil {
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxPriceImportBytes)
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(fmt.Sprintf("the file is larger than %d MB", maxPriceImportBytes>>20)))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	defer file.Close()
+
+	prices, err := parseSecurityPricesCSV(file, budget.ID, budget.CurrencyCode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	if err := s.db.UpsertSecurityPricesTx(ctx, prices); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "prices imported", "count": len(prices)})
+}
+
+// Parses a CSV file of prices in a currency. The errors are meant for the user.
+func parseSecurityPricesCSV(r io.Reader, budgetId uuid.UUID, currency string) ([]db.UpsertSecurityPriceParams, error) {
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("the file has no header row")
+	}
+	columns := map[string]int{"symbol": -1, "date": -1, "price": -1, "price_decimal": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	for _, name := range []string{"symbol", "date"} {
+		if columns[name] < 0 {
+			return nil, fmt.Errorf("the header row has no %s column", name)
+		}
+	}
+	// prices are in milliunits, or decimals in the price_decimal column
+	decimals := columns["price_decimal"] >= 0
+	if decimals == (columns["price"] >= 0) {
+		return nil, errors.New("the header row needs either a price or a price_decimal column")
+	}
+
+	var prices []db.UpsertSecurityPriceParams
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid CSV", line)
+		}
+		if len(prices) == maxPriceImportRows {
+			return nil, fmt.Errorf("the file has more than %d prices", maxPriceImportRows)
+		}
+
+		symbol := normalizeSymbol(record[columns["symbol"]])
+		if symbol == "" || len(symbol) > 20 {
+			return nil, fmt.Errorf("line %d: invalid symbol", line)
+		}
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(record[columns["date"]]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date", line)
+		}
+		var milliunits int64
+		var decimal string
+		if decimals {
+			decimal = strings.TrimSpace(record[columns["price_decimal"]])
+		} else {
+			milliunits, err = strconv.ParseInt(strings.TrimSpace(record[columns["price"]]), 10, 64)
+		}
+		if err != nil || (decimals && decimal == "") {
+			return nil, fmt.Errorf("line %d: invalid price", line)
+		}
+		price, err := requestAmount("price", milliunits, decimal, currency)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if price < 0 {
+			return nil, fmt.Errorf("line %d: invalid price", line)
+		}
+
+		prices = append(prices, db.UpsertSecurityPriceParams{
+			BudgetID: budgetId,
+			Symbol:   symbol,
+			Date:     pgtype.Date{Time: date, Valid: true},
+			Price:    price,
+		})
+	}
+	if len(prices) == 0 {
+		return nil, errors.New("the file has no prices")
+	}
+
+	return prices, nil
+}
+
+// Values holdings at the last price of each security. Holdings without a price are valued at their cost basis.
+func valueHoldings(holdings []holding, prices []db.SecurityPrice) []holdingResponse {
+
+	latest := make(map[string]db.SecurityPrice, len(prices))
+	for _, p := range prices {
+		latest[p.Symbol] = p
+	}
+
+	rsp := make([]holdingResponse, len(holdings))
+	for i, h := range holdings {
+		rsp[i] = holdingResponse{
+			Symbol:      h.Symbol,
+			Quantity:    h.Quantity,
+			CostBasis:   h.CostBasis,
+			MarketValue: h.CostBasis,
+		}
+		if p, ok := latest[h.Symbol]; ok {
+			rsp[i].Price = &p.Price
+			rsp[i].PriceDate = &p.Date
+			rsp[i].MarketValue = marketValue(h.Quantity, p.Price)
+		}
+		rsp[i].Gain = rsp[i].MarketValue - h.CostBasis
+	}
+
+	return rsp
+}
+
+// Binds the date of the holdings and the net worth in the query, today if left out. Writes the error response if invalid.
+func bindAsOfDate(ctx *gin.Context) (pgtype.Date, error) {
+
+	var rqst asOfDateRequest
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return pgtype.Date{}, err
+	}
+	if rqst.Date == "" {
+		now := time.Now().UTC()
+		return pgtype.Date{Time: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), Valid: true}, nil
+	}
+	date, err := time.Parse(time.DateOnly, rqst.Date)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return pgtype.Date{}, err
+	}
+
+	return pgtype.Date{Time: date, Valid: true}, nil
+}
+
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
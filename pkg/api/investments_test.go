@@ -0,0 +1,418 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateInvestmentTransactionAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	account := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Broker", Type: AccountTypeInvestment}
+	bought := []db.InvestmentTransaction{
+		{ID: uuid.New(), AccountID: account.ID, Type: InvestmentBuy, Symbol: "VWCE", Quantity: 10, Amount: 100000, Date: pgtype.Date{Time: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), Valid: true}},
+	}
+
+	testCases := []struct {
+		name          string
+		account       db.Account
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "Buy",
+			account: account,
+			body:    gin.H{"date": "2024-05-02", "type": InvestmentBuy, "symbol": " vwce ", "quantity": 2.5, "amount": 28000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvestmentTransactions(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreateInvestmentTransaction(gomock.Any(), db.CreateInvestmentTransactionParams{
+						AccountID: account.ID,
+						Date:      pgtype.Date{Time: time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+						Type:      InvestmentBuy,
+						Symbol:    "VWCE",
+						Quantity:  2.5,
+						Amount:    28000,
+					}).
+					Times(1).
+					Return(db.InvestmentTransaction{ID: uuid.New(), AccountID: account.ID, Type: InvestmentBuy, Symbol: "VWCE", Quantity: 2.5, Amount: 28000}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "DecimalAmount",
+			account: account,
+			body:    gin.H{"date": "2024-05-02", "type": InvestmentDividend, "symbol": "VWCE", "amount_decimal": "12.50"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateInvestmentTransaction(gomock.Any(), db.CreateInvestmentTransactionParams{
+						AccountID: account.ID,
+						Date:      pgtype.Date{Time: time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+						Type:      InvestmentDividend,
+						Symbol:    "VWCE",
+						Amount:    12500,
+					}).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "Sell",
+			account: account,
+			body:    gin.H{"date": "2024-05-02", "type": InvestmentSell, "symbol": "VWCE", "quantity": 10, "amount": 110000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvestmentTransactions(gomock.Any(), account.ID).Times(1).Return(bought, nil)
+				store.EXPECT().CreateInvestmentTransaction(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "SellMoreThanHeld",
+			account: account,
+			body:    gin.H{"date": "2024-05-02", "type": InvestmentSell, "symbol": "VWCE", "quantity": 11, "amount": 121000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvestmentTransactions(gomock.Any(), account.ID).Times(1).Return(bought, nil)
+				store.EXPECT().CreateInvestmentTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "BackdatedSellOfSharesSoldLater",
+			account: account,
+			body:    gin.H{"date": "2024-05-05", "type": InvestmentSell, "symbol": "VWCE", "quantity": 10, "amount": 110000},
+			buildStubs: func(store *mockdb.MockStore) {
+				sold := append(slices.Clone(bought), db.InvestmentTransaction{ID: uuid.New(), AccountID: account.ID, Type: InvestmentSell, Symbol: "VWCE", Quantity: 10, Amount: 120000, Date: pgtype.Date{Time: time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC), Valid: true}})
+				store.EXPECT().GetInvestmentTransactions(gomock.Any(), account.ID).Times(1).Return(sold, nil)
+				store.EXPECT().CreateInvestmentTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "BackdatedSellBeforeLaterBuy",
+			account: account,
+			body:    gin.H{"date": "2024-05-05", "type": InvestmentSell, "symbol": "VWCE", "quantity": 10, "amount": 110000},
+			buildStubs: func(store *mockdb.MockStore) {
+				rebought := append(slices.Clone(bought),
+					db.InvestmentTransaction{ID: uuid.New(), AccountID: account.ID, Type: InvestmentBuy, Symbol: "VWCE", Quantity: 10, Amount: 110000, Date: pgtype.Date{Time: time.Date(2024, time.May, 8, 0, 0, 0, 0, time.UTC), Valid: true}},
+					db.InvestmentTransaction{ID: uuid.New(), AccountID: account.ID, Type: InvestmentSell, Symbol: "VWCE", Quantity: 10, Amount: 120000, Date: pgtype.Date{Time: time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC), Valid: true}},
+				)
+				store.EXPECT().GetInvestmentTransactions(gomock.Any(), account.ID).Times(1).Return(rebought, nil)
+				store.EXPECT().CreateInvestmentTransaction(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "SellBeforeBuying",
+			account: account,
+			body:    gin.H{"date": "2024-04-30", "type": InvestmentSell, "symbol": "VWCE", "quantity": 1, "amount": 11000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvestmentTransactions(gomock.Any(), account.ID).Times(1).Return(bought, nil)
+				store.EXPECT().CreateInvestmentTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "Dividend",
+			account: account,
+			body:    gin.H{"date": "2024-05-02", "type": InvestmentDividend, "symbol": "VWCE", "quantity": 3, "amount": 500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateInvestmentTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateInvestmentTransactionParams) (db.InvestmentTransaction, error) {
+						require.Zero(t, arg.Quantity)
+						return db.InvestmentTransaction{ID: uuid.New()}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "BuyWithoutQuantity",
+			account: account,
+			body:    gin.H{"date": "2024-05-02", "type": InvestmentBuy, "symbol": "VWCE", "amount": 28000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInvestmentTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "NotAnInvestmentAccount",
+			account: db.Account{ID: account.ID, BudgetID: budget.ID, Name: "Chase", Type: AccountTypeChecking, OnBudget: true},
+			body:    gin.H{"date": "2024-05-02", "type": InvestmentBuy, "symbol": "VWCE", "quantity": 1, "amount": 11000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInvestmentTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), db.GetBudgetParams{ID: budget.ID, OwnerUsername: username}).
+				Times(1).
+				Return(budget, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: account.ID}).
+				Times(1).
+				Return(tc.account, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/beta/budgets/"+budget.ID.String()+"/accounts/"+account.ID.String()+"/investment-transactions", bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestImportSecurityPricesAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+
+	testCases := []struct {
+		name          string
+		csv           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			csv:  "Date,Symbol,Price\n2024-05-30,vwce,11790\n2024-05-31, VWCE ,11820\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertSecurityPricesTx(gomock.Any(), []db.UpsertSecurityPriceParams{
+						{BudgetID: budget.ID, Symbol: "VWCE", Date: pgtype.Date{Time: time.Date(2024, time.May, 30, 0, 0, 0, 0, time.UTC), Valid: true}, Price: 11790},
+						{BudgetID: budget.ID, Symbol: "VWCE", Date: pgtype.Date{Time: time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC), Valid: true}, Price: 11820},
+					}).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MissingColumn",
+			csv:  "symbol,price\nVWCE,11820\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertSecurityPricesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "no date column")
+			},
+		},
+		{
+			name: "InvalidPrice",
+			csv:  "symbol,date,price\nVWCE,2024-05-30,11790\nVWCE,2024-05-31,118.20\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertSecurityPricesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "line 3: invalid price")
+			},
+		},
+		{
+			name: "DecimalColumn",
+			csv:  "symbol,date,price_decimal\nVWCE,2024-05-31,118.20\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertSecurityPricesTx(gomock.Any(), []db.UpsertSecurityPriceParams{
+						{BudgetID: budget.ID, Symbol: "VWCE", Date: pgtype.Date{Time: time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC), Valid: true}, Price: 118200},
+					}).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "PriceNotWhole",
+			csv:  "symbol,date,price\nVWCE,2024-05-31,118205\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertSecurityPricesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "line 2: price is not a whole amount of EUR")
+			},
+		},
+		{
+			name: "NoPrices",
+			csv:  "symbol,date,price\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertSecurityPricesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TooLarge",
+			csv:  "symbol,date,price\n" + strings.Repeat(" ", maxPriceImportBytes),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertSecurityPricesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), db.GetBudgetParams{ID: budget.ID, OwnerUsername: username}).
+				Times(1).
+				Return(budget, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			part, err := writer.CreateFormFile("file", "prices.csv")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(tc.csv))
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			request, err := http.NewRequest(http.MethodPost, "/beta/budgets/"+budget.ID.String()+"/prices/import", &body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetNetWorthAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	checking := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Chase", Type: AccountTypeChecking, Balance: 50000, CurrencyCode: "EUR", OnBudget: true}
+	broker := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Broker", Type: AccountTypeInvestment, Balance: 125000, CurrencyCode: "EUR"}
+	mortgage := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "House", Type: AccountTypeMortgage, Balance: -200000, CurrencyCode: "EUR"}
+	date := pgtype.Date{Time: time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC), Valid: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetBudget(gomock.Any(), db.GetBudgetParams{ID: budget.ID, OwnerUsername: username}).
+		Times(1).
+		Return(budget, nil)
+	store.EXPECT().GetAccounts(gomock.Any(), budget.ID).Times(1).Return([]db.Account{checking, broker, mortgage}, nil)
+	store.EXPECT().
+		GetBudgetInvestmentTransactions(gomock.Any(), db.GetBudgetInvestmentTransactionsParams{BudgetID: budget.ID, Date: date}).
+		Times(1).
+		Return([]db.InvestmentTransaction{
+			{AccountID: broker.ID, Type: InvestmentBuy, Symbol: "VWCE", Quantity: 10, Amount: 100000, Date: pgtype.Date{Time: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), Valid: true}},
+			{AccountID: broker.ID, Type: InvestmentBuy, Symbol: "AAPL", Quantity: 1, Amount: 17000, Date: pgtype.Date{Time: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), Valid: true}},
+			{AccountID: broker.ID, Type: InvestmentDividend, Symbol: "AAPL", Amount: 2000, Date: pgtype.Date{Time: time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC), Valid: true}},
+		}, nil)
+	// spent from the checking account after the date
+	store.EXPECT().
+		GetAccountChangesAfter(gomock.Any(), db.GetAccountChangesAfterParams{BudgetID: budget.ID, Date: date}).
+		Times(1).
+		Return([]db.GetAccountChangesAfterRow{{AccountID: checking.ID, Amount: -3000}}, nil)
+	store.EXPECT().
+		GetLatestSecurityPrices(gomock.Any(), db.GetLatestSecurityPricesParams{BudgetID: budget.ID, Date: date}).
+		Times(1).
+		Return([]db.SecurityPrice{{BudgetID: budget.ID, Symbol: "VWCE", Date: date, Price: 11820}}, nil)
+
+	server := NewTestServer(t, store, nil)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/beta/budgets/"+budget.ID.String()+"/reports/net-worth?date=2024-05-31", nil)
+	require.NoError(t, err)
+	accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp netWorthResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Len(t, rsp.Accounts, 3)
+	require.Equal(t, int64(50000+3000), rsp.Accounts[0].Balance)
+	// the cash left after the buys and the dividend
+	require.Equal(t, int64(125000-100000-17000+2000), rsp.Accounts[1].Balance)
+	// VWCE at its price, AAPL at its cost basis
+	require.Equal(t, int64(118200+17000), rsp.Accounts[1].MarketValue)
+	require.Equal(t, int64(10000+118200+17000), rsp.Accounts[1].Total)
+	require.Equal(t, int64(53000+10000+118200+17000), rsp.Assets)
+	require.Equal(t, int64(-200000), rsp.Liabilities)
+	require.Equal(t, rsp.Assets+rsp.Liabilities, rsp.NetWorth)
+}
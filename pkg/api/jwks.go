@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guerzon/gobudget-api/pkg/token"
+)
+
+// getJWKS publishes the public keys of the asymmetric token builder at /.well-known/jwks.json, so that other services can verify tokens.
+// It is not part of the versioned API.
+func (s *Server) getJWKS(ctx *gin.Context) {
+
+	builder, ok := s.tokenBuilder.(*token.AsymmetricJWTBuilder)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, errorResponse("tokens are not signed with asymmetric keys"))
+		return
+	}
+
+	// Clients can cache the keys, but not past a key rotation
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, builder.JWKS())
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJWKS(t *testing.T) {
+
+	// Write a signing key
+	dir := t.TempDir()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "key1.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		config        util.Config
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			config: util.Config{
+				TokenKeysDir:        dir,
+				TokenActiveKeyID:    "key1",
+				TokenKeyGracePeriod: time.Hour,
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var jwks token.JWKS
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &jwks))
+				require.Len(t, jwks.Keys, 1)
+				require.Equal(t, "key1", jwks.Keys[0].KeyID)
+				require.Equal(t, "OKP", jwks.Keys[0].KeyType)
+				require.NotEmpty(t, jwks.Keys[0].X)
+			},
+		},
+		{
+			name: "SymmetricKey",
+			config: util.Config{
+				SecretKey: util.RandomString(32, ""),
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server, err := NewServer(tc.config, mockdb.NewMockStore(ctrl), nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
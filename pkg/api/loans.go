@@ -0,0 +1,280 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/loan"
+	"github.com/guerzon/gobudget-api/pkg/money"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Types of accounts which can have loan details
+var loanAccountTypes = map[string]bool{
+	AccountTypeMortgage:  true,
+	AccountTypeAutoLoan:  true,
+	AccountTypeLiability: true,
+}
+
+// getLoan godoc
+//
+//	@Summary	Get the loan details of an account
+//	@Schemes
+//	@Description	Get the loan details of a mortgage, auto loan or liability account, with its amortization schedule.
+//	@Description	The transfers to the account are its payments, grouped by the payment period they fall in. The interest of a period accrues once, and payments pay it before the principal.
+//	@Description	The schedule projects the payments left from the period after the last payment, with the extra payment, until the payoff date.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Param			account_id	path	string	true	"Account ID"
+//	@Tags			Accounts
+//	@Produce		json
+//	@Success		200	{object}	loanResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/accounts/{account_id}/loan [get]
+func (s *Server) getLoan(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	account, err := s.getAccountOfType(ctx, budgetId, loanAccountTypes, "only mortgage, auto loan and liability accounts have loan details")
+	if err != nil {
+		return
+	}
+
+	l, err := s.db.GetLoan(ctx, account.ID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse("account has no loan details"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	payments, err := s.db.GetLoanPayments(ctx, account.ID)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	rsp, err := newLoanResponse(l, payments)
+	if err != nil {
+		conversionErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// updateLoan godoc
+//
+//	@Summary	Set the loan details of an account
+//	@Schemes
+//	@Description	Set the loan details of a mortgage, auto loan or liability account. Payments are due every month from the month after the start date.
+//	@Description	Amounts are in milliunits, thousandths of the unit of the currency, or decimals in the currency in the fields ending with _decimal.
+//	@Param			budget_id	path	string		true	"Budget ID"
+//	@Param			account_id	path	string		true	"Account ID"
+//	@Param			loan		body	loanRequest	true	"Loan details"
+//	@Tags			Accounts
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	loanResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/accounts/{account_id}/loan [put]
+func (s *Server) updateLoan(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	account, err := s.getAccountOfType(ctx, budgetId, loanAccountTypes, "only mortgage, auto loan and liability accounts have loan details")
+	if err != nil {
+		return
+	}
+
+	var rqst loanRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	principal, err := requestAmount("principal", rqst.Principal, rqst.PrincipalDecimal, account.CurrencyCode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	if principal <= 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse("principal must be positive"))
+		return
+	}
+	extraPayment, err := requestAmount("extra_payment", rqst.ExtraPayment, rqst.ExtraPaymentDecimal, account.CurrencyCode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	if extraPayment < 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse("extra_payment cannot be negative"))
+		return
+	}
+
+	l, err := s.db.UpsertLoan(ctx, db.UpsertLoanParams{
+		AccountID:    account.ID,
+		Principal:    principal,
+		InterestRate: rqst.InterestRate,
+		TermMonths:   rqst.TermMonths,
+		StartDate:    rqst.StartDate,
+		PaymentDay:   rqst.PaymentDay,
+		ExtraPayment: extraPayment,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	payments, err := s.db.GetLoanPayments(ctx, account.ID)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	rsp, err := newLoanResponse(l, payments)
+	if err != nil {
+		conversionErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// deleteLoan godoc
+//
+//	@Summary	Delete the loan details of an account
+//	@Schemes
+//	@Description	Delete the loan details of an account. The account and its transactions are kept.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Param			account_id	path	string	true	"Account ID"
+//	@Tags			Accounts
+//	@Produce		json
+//	@Success		200	{object}	string	"loan details deleted"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/accounts/{account_id}/loan [delete]
+func (s *Server) deleteLoan(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	account, err := s.getAccountOfType(ctx, budgetId, loanAccountTypes, "only mortgage, auto loan and liability accounts have loan details")
+	if err != nil {
+		return
+	}
+
+	if err := s.db.DeleteLoan(ctx, account.ID); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "loan details deleted"})
+}
+
+// Splits the payments made on a loan and projects the rest of its amortization schedule. Returns money.ErrOverflow if
+// the interest adds up to an amount out of range.
+func newLoanResponse(l db.Loan, transactions []db.Transaction) (loanResponse, error) {
+
+	terms := loan.Terms{
+		Principal:    l.Principal,
+		AnnualRate:   l.InterestRate,
+		TermMonths:   int(l.TermMonths),
+		StartDate:    l.StartDate.Time,
+		PaymentDay:   int(l.PaymentDay),
+		ExtraPayment: l.ExtraPayment,
+	}
+	// outflows of the paying account and inflows of the loan account are both payments, in the currency of the loan
+	// account: what the loan account received from an account in another currency is the transfer amount
+	paid := make([]loan.Paid, len(transactions))
+	for i, t := range transactions {
+		amount := t.Amount
+		if t.AccountID != l.AccountID && t.TransferAmount.Valid {
+			amount = t.TransferAmount.Int64
+		}
+		paid[i] = loan.Paid{Date: t.Date.Time, Amount: abs(amount)}
+	}
+	payments, balance := loan.Apply(terms, paid)
+	// the schedule goes on from the period after the last payment
+	next := 1
+	if len(payments) > 0 {
+		next = payments[len(payments)-1].Number + 1
+	}
+	schedule := loan.Schedule(terms, balance, next)
+
+	rsp := loanResponse{
+		AccountID:      l.AccountID,
+		Principal:      l.Principal,
+		InterestRate:   l.InterestRate,
+		TermMonths:     l.TermMonths,
+		StartDate:      l.StartDate,
+		PaymentDay:     l.PaymentDay,
+		ExtraPayment:   l.ExtraPayment,
+		MonthlyPayment: loan.MonthlyPayment(terms.Principal, terms.AnnualRate, terms.TermMonths),
+		Balance:        balance,
+		Payments:       make([]loanPaymentResponse, len(payments)),
+		Schedule:       make([]loanPaymentResponse, len(schedule)),
+	}
+	var err error
+	for i, p := range payments {
+		rsp.Payments[i] = newLoanPaymentResponse(p)
+		rsp.Payments[i].TransactionID = &transactions[i].ID
+		if rsp.InterestPaid, err = money.Add(rsp.InterestPaid, p.Interest); err != nil {
+			return loanResponse{}, err
+		}
+	}
+	rsp.TotalInterest = rsp.InterestPaid
+	for i, p := range schedule {
+		rsp.Schedule[i] = newLoanPaymentResponse(p)
+		if rsp.TotalInterest, err = money.Add(rsp.TotalInterest, p.Interest); err != nil {
+			return loanResponse{}, err
+		}
+	}
+	if len(schedule) > 0 {
+		rsp.PayoffDate = rsp.Schedule[len(schedule)-1].Date
+	} else if len(payments) > 0 {
+		rsp.PayoffDate = rsp.Payments[len(payments)-1].Date
+	}
+
+	return rsp, nil
+}
+
+func newLoanPaymentResponse(p loan.Payment) loanPaymentResponse {
+	return loanPaymentResponse{
+		Number:    p.Number,
+		Date:      pgtype.Date{Time: p.Date, Valid: true},
+		Amount:    p.Amount,
+		Principal: p.Principal,
+		Interest:  p.Interest,
+		Balance:   p.Balance,
+	}
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetLoanAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	account := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Car", Type: AccountTypeAutoLoan}
+	l := db.Loan{
+		AccountID:    account.ID,
+		Principal:    1200000,
+		InterestRate: 12,
+		TermMonths:   12,
+		StartDate:    pgtype.Date{Time: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		PaymentDay:   1,
+	}
+	// from the checking account, and recorded on the loan account
+	payments := []db.Transaction{
+		{ID: uuid.New(), AccountID: uuid.New(), Date: pgtype.Date{Time: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), Valid: true}, Amount: -106619},
+		{ID: uuid.New(), AccountID: account.ID, Date: pgtype.Date{Time: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true}, Amount: 106619},
+	}
+
+	testCases := []struct {
+		name          string
+		account       db.Account
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			account: account,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoan(gomock.Any(), account.ID).Times(1).Return(l, nil)
+				store.EXPECT().GetLoanPayments(gomock.Any(), account.ID).Times(1).Return(payments, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loanResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, int64(106619), rsp.MonthlyPayment)
+
+				require.Len(t, rsp.Payments, 2)
+				require.Equal(t, payments[0].ID, *rsp.Payments[0].TransactionID)
+				require.Equal(t, int64(12000), rsp.Payments[0].Interest)
+				require.Equal(t, int64(94619), rsp.Payments[0].Principal)
+				require.Equal(t, int64(106619), rsp.Payments[1].Amount)
+				require.Equal(t, rsp.Payments[1].Balance, rsp.Balance)
+				require.Equal(t, rsp.Payments[0].Interest+rsp.Payments[1].Interest, rsp.InterestPaid)
+
+				require.Len(t, rsp.Schedule, 10)
+				require.Equal(t, 3, rsp.Schedule[0].Number)
+				require.Nil(t, rsp.Schedule[0].TransactionID)
+				require.Zero(t, rsp.Schedule[9].Balance)
+				require.Equal(t, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), rsp.PayoffDate.Time)
+			},
+		},
+		{
+			name:    "PaymentInAnotherCurrency",
+			account: account,
+			buildStubs: func(store *mockdb.MockStore) {
+				// from a dollar account, the loan account receiving the transfer amount
+				payment := payments[0]
+				payment.Amount = -115000
+				payment.TransferAmount = pgtype.Int8{Int64: 106619, Valid: true}
+				store.EXPECT().GetLoan(gomock.Any(), account.ID).Times(1).Return(l, nil)
+				store.EXPECT().GetLoanPayments(gomock.Any(), account.ID).Times(1).Return([]db.Transaction{payment}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loanResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.Payments, 1)
+				require.Equal(t, int64(106619), rsp.Payments[0].Amount)
+				require.Equal(t, int64(12000), rsp.Payments[0].Interest)
+				require.Equal(t, int64(94619), rsp.Payments[0].Principal)
+			},
+		},
+		{
+			name:    "NoLoanDetails",
+			account: account,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoan(gomock.Any(), account.ID).Times(1).Return(db.Loan{}, pgx.ErrNoRows)
+				store.EXPECT().GetLoanPayments(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:    "NotALoanAccount",
+			account: db.Account{ID: account.ID, BudgetID: budget.ID, Name: "Chase", Type: AccountTypeChecking, OnBudget: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), db.GetBudgetParams{ID: budget.ID, OwnerUsername: username}).
+				Times(1).
+				Return(budget, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: account.ID}).
+				Times(1).
+				Return(tc.account, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/beta/budgets/"+budget.ID.String()+"/accounts/"+account.ID.String()+"/loan", nil)
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateLoanAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	account := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "House", Type: AccountTypeMortgage, CurrencyCode: "EUR"}
+	startDate := pgtype.Date{Time: time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), Valid: true}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"principal": 20000000, "interest_rate": 6, "term_months": 360, "start_date": "2024-01-15", "payment_day": 1, "extra_payment": 10000},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertLoanParams{
+					AccountID:    account.ID,
+					Principal:    20000000,
+					InterestRate: 6,
+					TermMonths:   360,
+					StartDate:    startDate,
+					PaymentDay:   1,
+					ExtraPayment: 10000,
+				}
+				store.EXPECT().
+					UpsertLoan(gomock.Any(), arg).
+					Times(1).
+					Return(db.Loan{
+						AccountID:    arg.AccountID,
+						Principal:    arg.Principal,
+						InterestRate: arg.InterestRate,
+						TermMonths:   arg.TermMonths,
+						StartDate:    arg.StartDate,
+						PaymentDay:   arg.PaymentDay,
+						ExtraPayment: arg.ExtraPayment,
+					}, nil)
+				store.EXPECT().GetLoanPayments(gomock.Any(), account.ID).Times(1).Return([]db.Transaction{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loanResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, int64(119910), rsp.MonthlyPayment)
+				require.Equal(t, int64(20000000), rsp.Balance)
+				require.Empty(t, rsp.Payments)
+				// the extra payment pays off the loan early
+				require.Less(t, len(rsp.Schedule), 360)
+				require.Equal(t, int64(129910), rsp.Schedule[0].Amount)
+				require.Equal(t, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), rsp.Schedule[0].Date.Time)
+				require.True(t, rsp.PayoffDate.Time.Before(time.Date(2054, time.January, 1, 0, 0, 0, 0, time.UTC)))
+			},
+		},
+		{
+			name: "DecimalAmounts",
+			body: gin.H{"principal_decimal": "20000.00", "interest_rate": 6, "term_months": 360, "start_date": "2024-01-15", "payment_day": 1, "extra_payment_decimal": "10.00"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertLoanParams{
+					AccountID:    account.ID,
+					Principal:    20000000,
+					InterestRate: 6,
+					TermMonths:   360,
+					StartDate:    startDate,
+					PaymentDay:   1,
+					ExtraPayment: 10000,
+				}
+				store.EXPECT().
+					UpsertLoan(gomock.Any(), arg).
+					Times(1).
+					Return(db.Loan{AccountID: arg.AccountID, Principal: arg.Principal, InterestRate: arg.InterestRate, TermMonths: arg.TermMonths, StartDate: arg.StartDate, PaymentDay: arg.PaymentDay, ExtraPayment: arg.ExtraPayment}, nil)
+				store.EXPECT().GetLoanPayments(gomock.Any(), account.ID).Times(1).Return([]db.Transaction{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NegativeDecimalPrincipal",
+			body: gin.H{"principal_decimal": "-20000.00", "interest_rate": 6, "term_months": 360, "start_date": "2024-01-15", "payment_day": 1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertLoan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidPaymentDay",
+			body: gin.H{"principal": 20000000, "interest_rate": 6, "term_months": 360, "start_date": "2024-01-15", "payment_day": 31},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertLoan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NegativeInterestRate",
+			body: gin.H{"principal": 20000000, "interest_rate": -1, "term_months": 360, "start_date": "2024-01-15", "payment_day": 1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertLoan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), db.GetBudgetParams{ID: budget.ID, OwnerUsername: username}).
+				Times(1).
+				Return(budget, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: account.ID}).
+				Times(1).
+				Return(account, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPut, "/beta/budgets/"+budget.ID.String()+"/accounts/"+account.ID.String()+"/loan", bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -2,14 +2,20 @@ package api
 
 import (
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
 	"github.com/guerzon/gobudget-api/pkg/util"
 	"github.com/guerzon/gobudget-api/pkg/worker"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // POST request to /login containing the username and password
@@ -21,11 +27,19 @@ func (s *Server) login(ctx *gin.Context) {
 		return
 	}
 
+	// Slow down password guessing
+	clientIp := ctx.ClientIP()
+	if s.loginThrottled(ctx, rqst.Username, clientIp) {
+		return
+	}
+
 	// Validate the email
 	u, err := s.db.GetUserByUsername(ctx, rqst.Username)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse("invalid username or password"))
+			// same work and response as a wrong password, so that usernames cannot be enumerated
+			_ = util.CheckPassword(dummyPasswordHash(), rqst.Password)
+			s.loginFailed(ctx, rqst.Username, clientIp, false)
 			return
 		}
 		slog.Error(err.Error())
@@ -36,9 +50,20 @@ func (s *Server) login(ctx *gin.Context) {
 	// Validate the password
 	err = util.CheckPassword(u.Password, rqst.Password)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse("invalid username or password"))
+		s.loginFailed(ctx, rqst.Username, clientIp, true)
 		return
 	}
+	// with two-factor authentication, the failed logins are only cleared by the second step
+	if !u.TotpEnabled {
+		if err := s.loginLimiter.Reset(ctx, u.Username); err != nil {
+			slog.Error("cannot reset failed logins", "user", u.Username, "errmsg", err)
+		}
+	}
+
+	// Upgrade bcrypt hashes and hashes with outdated parameters, now that the password is known
+	if util.NeedsRehash(u.Password) {
+		s.rehashPassword(ctx, u, rqst.Password)
+	}
 
 	// Check if the email has been validated
 	if !u.EmailVerified {
@@ -71,10 +96,138 @@ func (s *Server) login(ctx *gin.Context) {
 		return
 	}
 
-	// from this point, user is validated
+	// from this point, the password is validated
+
+	// Users with two-factor authentication get a challenge token for the second step
+	if u.TotpEnabled {
+		s.startTwoFactorChallenge(ctx, u)
+		return
+	}
+
+	s.startSession(ctx, u)
+}
+
+// Issues the challenge token of a user with two-factor authentication who passed the first step, and writes the response.
+func (s *Server) startTwoFactorChallenge(ctx *gin.Context, u db.User) {
+
+	challengeToken, challengeTokenClaims, err := s.tokenBuilder.CreateToken(token.CreateTokenParams{
+		Username: u.Username,
+		Duration: twoFactorChallengeDuration,
+		Purpose:  token.PurposeChallenge,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	// the challenge allows a few attempts at a code, and is used once
+	err = s.db.CreateTwoFactorChallenge(ctx, db.CreateTwoFactorChallengeParams{
+		ID:        challengeTokenClaims.ID,
+		Username:  u.Username,
+		ExpiresAt: challengeTokenClaims.ExpiresAt.Time,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	resp := twoFactorChallengeResponse{
+		TwoFactorRequired:       true,
+		ChallengeToken:          challengeToken,
+		ChallengeTokenExpiresAt: challengeTokenClaims.ExpiresAt.Time,
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Hash compared against the password of logins with unknown usernames, so that they take as long as the others
+var dummyPasswordHash = sync.OnceValue(func() string {
+	h, _ := util.HashPassword(util.RandomString(32, ""))
+	return h
+})
+
+// Replaces the password hash of a user with a hash of the current algorithm and parameters.
+// Failures are only logged, the old hash still works.
+func (s *Server) rehashPassword(ctx *gin.Context, u db.User, password string) {
+
+	hashedPassword, err := util.HashPassword(password)
+	if err != nil {
+		slog.Error("cannot rehash password", "user", u.Username, "errmsg", err)
+		return
+	}
+	err = s.db.UpdateUserPasswordHash(ctx, db.UpdateUserPasswordHashParams{
+		NewPassword: hashedPassword,
+		Username:    u.Username,
+		OldPassword: u.Password,
+	})
+	if err != nil {
+		slog.Error("cannot rehash password", "user", u.Username, "errmsg", err)
+	}
+}
+
+// Records a failed login and writes the response, which is the same whether or not the user exists.
+func (s *Server) loginFailed(ctx *gin.Context, username string, clientIp string, userExists bool) {
+
+	s.recordLoginFailure(ctx, username, clientIp, userExists)
+
+	ctx.JSON(http.StatusNotFound, errorResponse("invalid username or password"))
+}
+
+// Answers with a 429 when a user or an IP address has too many failed logins. Every check of a password or a second
+// factor goes through it, so that none of them can be used to guess faster than the login.
+func (s *Server) loginThrottled(ctx *gin.Context, username string, clientIp string) bool {
+
+	wait, err := s.loginLimiter.Check(ctx, username, clientIp)
+	if err != nil {
+		// don't lock everyone out if Redis is down
+		slog.Error("cannot check failed logins", "errmsg", err)
+	}
+	if wait > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse("too many failed login attempts, try again later"))
+		return true
+	}
+
+	return false
+}
+
+// Records a failed login, of a password or a second factor. Users who get locked out are notified by email.
+func (s *Server) recordLoginFailure(ctx *gin.Context, username string, clientIp string, userExists bool) {
+
+	if userExists {
+		s.recordFailedLogin(ctx, username)
+	}
+
+	locked, err := s.loginLimiter.RecordFailure(ctx, username, clientIp)
+	if err != nil {
+		slog.Error("cannot record failed login", "errmsg", err)
+	}
+	if locked {
+		slog.Warn("Locked out user after too many failed logins", "user", username, "ip", clientIp)
+		if userExists {
+			taskPayload := &worker.SendEmailPayload{
+				Username: username,
+			}
+			if err := s.taskDistributor.DistributeSendEmail(ctx, taskPayload, worker.TaskSendAccountLockedEmail); err != nil {
+				slog.Error("cannot distribute account locked email", "user", username, "errmsg", err)
+			}
+		}
+	}
+}
+
+// Issues the access and refresh tokens of a user who has been fully authenticated, and writes the login response.
+func (s *Server) startSession(ctx *gin.Context, u db.User) {
+
+	// both tokens are linked to the session, so that blocking it revokes them
+	sessionID := uuid.New()
 
 	// create the access token
-	accessToken, accessTokenClaims, err := s.tokenBuilder.CreateToken(u.Username, s.config.AccessTokenDuration)
+	accessToken, accessTokenClaims, err := s.tokenBuilder.CreateToken(token.CreateTokenParams{
+		Username:  u.Username,
+		Duration:  s.config.AccessTokenDuration,
+		Purpose:   token.PurposeAccess,
+		SessionID: sessionID,
+		Roles:     u.Roles,
+	})
 	if err != nil {
 		slog.Error(err.Error())
 		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
@@ -82,7 +235,12 @@ func (s *Server) login(ctx *gin.Context) {
 	}
 
 	// create the refresh token
-	refreshToken, refreshTokenClaims, err := s.tokenBuilder.CreateToken(u.Username, s.config.RefreshTokenDuration)
+	refreshToken, refreshTokenClaims, err := s.tokenBuilder.CreateToken(token.CreateTokenParams{
+		Username:  u.Username,
+		Duration:  s.config.RefreshTokenDuration,
+		Purpose:   token.PurposeRefresh,
+		SessionID: sessionID,
+	})
 	if err != nil {
 		slog.Error(err.Error())
 		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
@@ -91,7 +249,7 @@ func (s *Server) login(ctx *gin.Context) {
 
 	// save the refreshToken in the session
 	arg := db.CreateSessionParams{
-		ID:           refreshTokenClaims.ID,
+		ID:           sessionID,
 		Username:     u.Username,
 		RefreshToken: refreshToken,
 		UserAgent:    ctx.Request.UserAgent(),
@@ -104,6 +262,7 @@ func (s *Server) login(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
 		return
 	}
+	s.recordLogin(ctx, u, session)
 
 	// build and send the response
 	resp := loginResponse{
@@ -121,3 +280,56 @@ func (s *Server) login(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, resp)
 }
+
+// Adds a successful login to the history of the user, and alerts them by email when it comes from a device or network
+// they never logged in from. Failures are only logged, they don't fail the login.
+func (s *Server) recordLogin(ctx *gin.Context, u db.User, session db.Session) {
+
+	// checked before the login is added, since it would make the device known
+	device, err := s.db.GetLoginDevice(ctx, db.GetLoginDeviceParams{
+		Username:  u.Username,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+	})
+	if err != nil {
+		slog.Error("cannot check the login device", "user", u.Username, "errmsg", err)
+	}
+	_, err = s.db.CreateLoginEvent(ctx, db.CreateLoginEventParams{
+		Username:  u.Username,
+		Success:   true,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		SessionID: pgtype.UUID{Bytes: session.ID, Valid: true},
+	})
+	if err != nil {
+		slog.Error("cannot record login", "user", u.Username, "errmsg", err)
+		return
+	}
+
+	// the first login of an account is not news
+	if !device.HasLoggedIn || device.IsKnown {
+		return
+	}
+	slog.Info("Login from a new device", "user", u.Username, "ip", session.ClientIp)
+	taskPayload := &worker.SendEmailPayload{
+		Username:  u.Username,
+		SessionID: session.ID,
+	}
+	if err := s.taskDistributor.DistributeSendEmail(ctx, taskPayload, worker.TaskSendNewDeviceLoginEmail); err != nil {
+		slog.Error("cannot distribute new device login email", "user", u.Username, "errmsg", err)
+	}
+}
+
+// Adds a failed login of an existing user to their history. Failures are only logged.
+func (s *Server) recordFailedLogin(ctx *gin.Context, username string) {
+
+	_, err := s.db.CreateLoginEvent(ctx, db.CreateLoginEventParams{
+		Username:  username,
+		Success:   false,
+		UserAgent: ctx.Request.UserAgent(),
+		ClientIp:  ctx.ClientIP(),
+	})
+	if err != nil {
+		slog.Error("cannot record failed login", "user", username, "errmsg", err)
+	}
+}
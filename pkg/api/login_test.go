@@ -2,17 +2,25 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/limiter"
 	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/guerzon/gobudget-api/pkg/worker"
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestLoginAPI(t *testing.T) {
@@ -20,6 +28,13 @@ func TestLoginAPI(t *testing.T) {
 	user, plainPassword := buildTestUser(t)
 	user2 := user
 	user2.EmailVerified = false
+	user3 := user
+	user3.TotpEnabled = true
+	// hashed by an earlier version
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(plainPassword), bcrypt.MinCost)
+	require.NoError(t, err)
+	user4 := user
+	user4.Password = string(bcryptHash)
 
 	testCases := []struct {
 		name          string
@@ -41,12 +56,74 @@ func TestLoginAPI(t *testing.T) {
 				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(1)
+				expectLoginRecorded(store)
 
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "RehashBcryptPassword",
+			body: gin.H{
+				"username": user.Username,
+				"password": plainPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user4, nil)
+				store.EXPECT().
+					UpdateUserPasswordHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserPasswordHashParams) error {
+						require.Equal(t, user4.Username, arg.Username)
+						require.Equal(t, user4.Password, arg.OldPassword)
+						require.NoError(t, util.CheckPassword(arg.NewPassword, plainPassword))
+						require.False(t, util.NeedsRehash(arg.NewPassword))
+						return nil
+					})
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+				expectLoginRecorded(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "TwoFactorRequired",
+			body: gin.H{
+				"username": user.Username,
+				"password": plainPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user3, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTwoFactorChallengeParams) error {
+						require.Equal(t, user.Username, arg.Username)
+						return nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp twoFactorChallengeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.True(t, resp.TwoFactorRequired)
+				require.NotEmpty(t, resp.ChallengeToken)
+			},
+		},
 		{
 			name: "EmailNotFound",
 			body: gin.H{
@@ -74,11 +151,93 @@ func TestLoginAPI(t *testing.T) {
 					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateLoginEventParams) (db.LoginEvent, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.False(t, arg.Success)
+						require.False(t, arg.SessionID.Valid)
+						return db.LoginEvent{}, nil
+					})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name: "NewDevice",
+			body: gin.H{
+				"username": user.Username,
+				"password": plainPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				var sessionID uuid.UUID
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateSessionParams) (db.Session, error) {
+						sessionID = arg.ID
+						return db.Session{ID: arg.ID, Username: arg.Username, UserAgent: arg.UserAgent, ClientIp: arg.ClientIp}, nil
+					})
+				store.EXPECT().
+					GetLoginDevice(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetLoginDeviceRow{HasLoggedIn: true, IsKnown: false}, nil)
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateLoginEventParams) (db.LoginEvent, error) {
+						require.True(t, arg.Success)
+						require.Equal(t, sessionID, uuid.UUID(arg.SessionID.Bytes))
+						return db.LoginEvent{}, nil
+					})
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), worker.TaskSendNewDeviceLoginEmail).
+					Times(1).
+					DoAndReturn(func(_ any, payload *worker.SendEmailPayload, _ string) error {
+						require.Equal(t, user.Username, payload.Username)
+						require.Equal(t, sessionID, payload.SessionID)
+						return nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "FirstLogin",
+			body: gin.H{
+				"username": user.Username,
+				"password": plainPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					GetLoginDevice(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetLoginDeviceRow{HasLoggedIn: false, IsKnown: false}, nil)
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
 		{
 			name: "InvalidUsername",
 			body: gin.H{
@@ -145,3 +304,185 @@ func TestLoginAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginLockout(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+
+	testCases := []struct {
+		name       string
+		username   string
+		buildStubs func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor)
+	}{
+		{
+			name:     "ExistingUser",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(2).
+					Return(user, nil)
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(2)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), &worker.SendEmailPayload{Username: user.Username}, worker.TaskSendAccountLockedEmail).
+					Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name:     "UnknownUser",
+			username: "unknownuser",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), "unknownuser").
+					Times(2).
+					Return(db.User{}, pgx.ErrNoRows)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			dist := mockdb.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, dist)
+
+			server := NewTestServer(t, store, dist)
+			server.loginLimiter = limiter.NewMemoryLoginLimiter(limiter.LoginPolicy{LockoutThreshold: 2, LockoutDuration: time.Hour})
+
+			login := func() *httptest.ResponseRecorder {
+				data, err := json.Marshal(gin.H{"username": tc.username, "password": "wrongpassword"})
+				require.NoError(t, err)
+				request, err := http.NewRequest(http.MethodPost, "/beta/login", bytes.NewReader(data))
+				require.NoError(t, err)
+				recorder := httptest.NewRecorder()
+				server.Router.ServeHTTP(recorder, request)
+				return recorder
+			}
+
+			// The responses are the same whether or not the user exists
+			for i := 0; i < 2; i++ {
+				recorder := login()
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.JSONEq(t, `{"error":"invalid username or password"}`, recorder.Body.String())
+			}
+
+			recorder := login()
+			require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			require.Equal(t, "3600", recorder.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestUnlockUserAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+
+	testCases := []struct {
+		name          string
+		roles         []string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder, server *Server)
+	}{
+		{
+			name:  "OK",
+			roles: []string{token.RoleAdmin},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, auditActionUnlock, arg.Action)
+						require.Equal(t, user.Username, arg.TargetUsername)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				wait, err := server.loginLimiter.Check(context.Background(), user.Username, "192.0.2.1")
+				require.NoError(t, err)
+				require.Zero(t, wait)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			roles: nil,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				wait, err := server.loginLimiter.Check(context.Background(), user.Username, "192.0.2.1")
+				require.NoError(t, err)
+				require.NotZero(t, wait)
+			},
+		},
+		{
+			name:  "NotFound",
+			roles: []string{token.RoleAdmin},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			server.loginLimiter = limiter.NewMemoryLoginLimiter(limiter.LoginPolicy{LockoutThreshold: 1})
+			_, err := server.loginLimiter.RecordFailure(context.Background(), user.Username, "192.0.2.1")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/beta/admin/users/"+user.Username+"/unlock", nil)
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: "admin" + util.RandomUsername(), Duration: time.Minute, Purpose: token.PurposeAccess, Roles: tc.roles})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, server)
+		})
+	}
+}
+
+// Expects the login to be added to the history, from a device which was already used
+func expectLoginRecorded(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetLoginDevice(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.GetLoginDeviceRow{HasLoggedIn: true, IsKnown: true}, nil)
+	store.EXPECT().
+		CreateLoginEvent(gomock.Any(), gomock.Any()).
+		Times(1)
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/jackc/pgx/v5"
+)
+
+// Same response whether the email belongs to an account or not, so that it cannot be used to find accounts
+const magicLinkMessage = "if the email belongs to an account, a sign-in link has been sent"
+
+// Cookie binding magic links to the browser which asked for them, so that the link in the email is not enough to log in
+const (
+	magicLinkNonceCookie = "magic_link_nonce"
+	magicLinkCookiePath  = "/beta/login/magic_link"
+)
+
+// Size of the nonce in bytes
+const magicLinkNonceSize = 32
+
+// requestMagicLink godoc
+//
+//	@Summary	Request a magic link
+//	@Schemes
+//	@Description	Send a single-use sign-in link to the email of an account, for logging in without a password. The link only works in the browser which asked for it,
+//	@Description	which is identified by a cookie set in the response. The response does not tell whether the email belongs to an account.
+//	@Tags			Security
+//	@Accept			json
+//	@Param			email	body	magicLinkRequest	true	"Email of the account"
+//	@Produce		json
+//	@Success		202	{string}	string	"if the email belongs to an account, a sign-in link has been sent"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/login/magic_link [post]
+func (s *Server) requestMagicLink(ctx *gin.Context) {
+
+	var rqst magicLinkRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	// The browser gets a cookie whether or not a link is sent, so that the responses are the same
+	nonce, err := util.RandomSecret(magicLinkNonceSize)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	s.setMagicLinkCookie(ctx, nonce, int(worker.MagicLinkExpiration.Seconds()))
+
+	u, err := s.db.GetUserByEmail(ctx, rqst.Email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusAccepted, gin.H{"msg": magicLinkMessage})
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	// accounts whose email was never verified log in with their password, which resends the verification email
+	if !u.EmailVerified {
+		ctx.JSON(http.StatusAccepted, gin.H{"msg": magicLinkMessage})
+		return
+	}
+
+	taskPayload := &worker.SendEmailPayload{
+		Username:  u.Username,
+		Email:     u.Email,
+		NonceHash: token.HashOpaqueToken(nonce),
+	}
+	if err := s.taskDistributor.DistributeSendEmail(ctx, taskPayload, worker.TaskSendMagicLinkEmail); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": magicLinkMessage})
+}
+
+// verifyMagicLink godoc
+//
+//	@Summary	Log in with a magic link
+//	@Schemes
+//	@Description	Log in with the token of a sign-in link, from the browser which asked for it. Returns the same response as a login with a password,
+//	@Description	or a challenge token if the user has two-factor authentication.
+//	@Tags			Security
+//	@Param			token	query	string	true	"Token of the sign-in link"
+//	@Produce		json
+//	@Success		200	{object}	loginResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/login/magic_link/verify [get]
+func (s *Server) verifyMagicLink(ctx *gin.Context) {
+
+	var rqst verifyMagicLinkRequest
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	nonce, err := ctx.Cookie(magicLinkNonceCookie)
+	if err != nil || nonce == "" {
+		ctx.JSON(http.StatusUnauthorized, errorResponse("the sign-in link must be opened in the browser where it was requested"))
+		return
+	}
+
+	link, err := s.db.GetMagicLinkByHash(ctx, token.HashOpaqueToken(rqst.Token))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired sign-in link"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if link.Used || time.Now().After(link.ExpiresAt) {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired sign-in link"))
+		return
+	}
+	// the link stays usable from the right browser
+	if subtle.ConstantTimeCompare([]byte(token.HashOpaqueToken(nonce)), []byte(link.NonceHash)) != 1 {
+		ctx.JSON(http.StatusUnauthorized, errorResponse("the sign-in link must be opened in the browser where it was requested"))
+		return
+	}
+
+	n, err := s.db.UseMagicLink(ctx, link.ID)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if n == 0 {
+		// used by a concurrent request
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired sign-in link"))
+		return
+	}
+	s.setMagicLinkCookie(ctx, "", -1)
+
+	u, err := s.db.GetUserByUsername(ctx, link.Username)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired sign-in link"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	// from this point, the user is authenticated like with a password
+	slog.Info("Logged in with a magic link", "user", u.Username)
+
+	if u.TotpEnabled {
+		s.startTwoFactorChallenge(ctx, u)
+		return
+	}
+
+	s.startSession(ctx, u)
+}
+
+// Sets the nonce cookie of magic links, or deletes it with a negative maxAge.
+// It is only sent to the magic link endpoints, and is kept by top-level navigations from the email.
+func (s *Server) setMagicLinkCookie(ctx *gin.Context, nonce string, maxAge int) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(magicLinkNonceCookie, nonce, maxAge, magicLinkCookiePath, "", s.config.Environment != "local", true)
+}
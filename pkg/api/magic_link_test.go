@@ -0,0 +1,317 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequestMagicLinkAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+	unverified := user
+	unverified.EmailVerified = false
+
+	// hash of the nonce sent to the worker
+	var nonceHash string
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), user.Email).
+					Times(1).
+					Return(user, nil)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), worker.TaskSendMagicLinkEmail).
+					Times(1).
+					DoAndReturn(func(_ any, payload *worker.SendEmailPayload, _ string) error {
+						require.Equal(t, user.Username, payload.Username)
+						nonceHash = payload.NonceHash
+						return nil
+					})
+			},
+		},
+		{
+			name: "UnknownEmail",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), user.Email).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+		{
+			name: "EmailNotVerified",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), user.Email).
+					Times(1).
+					Return(unverified, nil)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			dist := mockdb.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, dist)
+
+			nonceHash = ""
+			server := NewTestServer(t, store, dist)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"email": user.Email})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/beta/login/magic_link", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+
+			// The response must not tell whether the account exists
+			require.Equal(t, http.StatusAccepted, recorder.Code)
+			require.JSONEq(t, `{"msg":"`+magicLinkMessage+`"}`, recorder.Body.String())
+
+			cookies := recorder.Result().Cookies()
+			require.Len(t, cookies, 1)
+			require.Equal(t, magicLinkNonceCookie, cookies[0].Name)
+			require.NotEmpty(t, cookies[0].Value)
+			require.True(t, cookies[0].HttpOnly)
+			require.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+			if nonceHash != "" {
+				require.Equal(t, token.HashOpaqueToken(cookies[0].Value), nonceHash)
+			}
+		})
+	}
+}
+
+func TestVerifyMagicLinkAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+	user2 := user
+	user2.TotpEnabled = true
+
+	linkToken, linkTokenHash, err := token.NewOpaqueToken("")
+	require.NoError(t, err)
+	nonce, nonceHash, err := token.NewOpaqueToken("")
+	require.NoError(t, err)
+
+	link := db.MagicLink{
+		ID:        1,
+		Username:  user.Username,
+		TokenHash: linkTokenHash,
+		NonceHash: nonceHash,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	testCases := []struct {
+		name          string
+		nonce         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			nonce: nonce,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMagicLinkByHash(gomock.Any(), linkTokenHash).
+					Times(1).
+					Return(link, nil)
+				store.EXPECT().
+					UseMagicLink(gomock.Any(), link.ID).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+				expectLoginRecorded(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp loginResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.NotEmpty(t, resp.AccessToken)
+				require.NotEmpty(t, resp.RefreshToken)
+
+				// the nonce cookie is deleted
+				cookies := recorder.Result().Cookies()
+				require.Len(t, cookies, 1)
+				require.Equal(t, magicLinkNonceCookie, cookies[0].Name)
+				require.Negative(t, cookies[0].MaxAge)
+			},
+		},
+		{
+			name:  "TwoFactorRequired",
+			nonce: nonce,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMagicLinkByHash(gomock.Any(), linkTokenHash).
+					Times(1).
+					Return(link, nil)
+				store.EXPECT().
+					UseMagicLink(gomock.Any(), link.ID).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user2, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTwoFactorChallengeParams) error {
+						require.Equal(t, user.Username, arg.Username)
+						return nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp twoFactorChallengeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.True(t, resp.TwoFactorRequired)
+				require.NotEmpty(t, resp.ChallengeToken)
+			},
+		},
+		{
+			name:  "NoNonce",
+			nonce: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMagicLinkByHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "OtherBrowser",
+			nonce: "othernonce",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMagicLinkByHash(gomock.Any(), linkTokenHash).
+					Times(1).
+					Return(link, nil)
+				store.EXPECT().
+					UseMagicLink(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "Expired",
+			nonce: nonce,
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := link
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				store.EXPECT().
+					GetMagicLinkByHash(gomock.Any(), linkTokenHash).
+					Times(1).
+					Return(expired, nil)
+				store.EXPECT().
+					UseMagicLink(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "AlreadyUsed",
+			nonce: nonce,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMagicLinkByHash(gomock.Any(), linkTokenHash).
+					Times(1).
+					Return(link, nil)
+				store.EXPECT().
+					UseMagicLink(gomock.Any(), link.ID).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "UnknownToken",
+			nonce: nonce,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMagicLinkByHash(gomock.Any(), linkTokenHash).
+					Times(1).
+					Return(db.MagicLink{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/beta/login/magic_link/verify?token="+linkToken, nil)
+			require.NoError(t, err)
+			if tc.nonce != "" {
+				request.AddCookie(&http.Cookie{Name: magicLinkNonceCookie, Value: tc.nonce})
+			}
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
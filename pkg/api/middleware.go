@@ -1,14 +1,28 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/limiter"
 	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/jackc/pgx/v5"
 )
 
-func AuthMiddleware(tokenMaker token.Builder) gin.HandlerFunc {
+// Authenticates the request using either a signed token or a personal access token, and checks that the token scopes allow the request.
+// The store is used for personal access tokens, and to check that the session of a signed token is still active.
+// It accepts tokens of any purpose, so it must be followed by RequirePurpose.
+func AuthMiddleware(tokenMaker token.Builder, store db.Store) gin.HandlerFunc {
 
 	return func(ctx *gin.Context) {
 
@@ -37,15 +51,37 @@ func AuthMiddleware(tokenMaker token.Builder) gin.HandlerFunc {
 
 		// Process the token
 		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err.Error()))
-			return
+		var payload *token.TokenPayload
+		if token.IsPersonalAccessToken(accessToken) {
+			var status int
+			var err error
+			payload, status, err = verifyPersonalAccessToken(ctx, store, accessToken)
+			if err != nil {
+				ctx.AbortWithStatusJSON(status, errorResponse(err.Error()))
+				return
+			}
+		} else {
+			var err error
+			payload, err = tokenMaker.VerifyToken(accessToken)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err.Error()))
+				return
+			}
+
+			// Reject tokens of sessions which were revoked
+			if payload.SessionID != uuid.Nil {
+				if status, err := verifySession(ctx, store, payload.SessionID); err != nil {
+					ctx.AbortWithStatusJSON(status, errorResponse(err.Error()))
+					return
+				}
+			}
 		}
 
-		// Hack: prevent the refresh token, which does not have 'aud', from being used for login.
-		if len(payload.Audience) == 0 {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse("invalid session token"))
+		// Check the token scopes against the request
+		budgetId, _ := uuid.Parse(ctx.Param("budget_id"))
+		readOnly := ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead
+		if !payload.Allows(readOnly, budgetId) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse("token scope does not allow this request"))
 			return
 		}
 
@@ -54,3 +90,184 @@ func AuthMiddleware(tokenMaker token.Builder) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// Only lets through tokens issued for a purpose, e.g. access tokens for API requests. This is what keeps
+// refresh and challenge tokens from being used as bearer tokens.
+func RequirePurpose(purpose token.Purpose) gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+
+		k, exists := ctx.Get("authz_payload")
+		if !exists {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+			return
+		}
+		if k.(*token.TokenPayload).Purpose != purpose {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse("token cannot be used for this request"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// Only lets through users with a role. Tokens not issued by the login flow never have roles.
+func RequireRole(role string) gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+
+		k, exists := ctx.Get("authz_payload")
+		if !exists {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+			return
+		}
+		if !k.(*token.TokenPayload).HasRole(role) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse("this request requires the "+role+" role"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// Only lets through tokens with full access, i.e. issued by the login flow. Used for account management
+// endpoints that scoped tokens must never reach, even with the write scope.
+func RequireFullAccess() gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+
+		k, exists := ctx.Get("authz_payload")
+		if !exists {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+			return
+		}
+		if k.(*token.TokenPayload).IsScoped() {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse("this request requires a login session"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// Limits the requests of each client to a policy, identifying clients with key. Requests over the limit get a 429.
+// The RateLimit-* headers tell clients where they stand; with several limits on a route, they show the closest one.
+// If the limiter fails, e.g. Redis is down, requests are let through.
+func RateLimitMiddleware(rateLimiter limiter.RateLimiter, group string, policy limiter.Policy, key func(ctx *gin.Context) string) gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+
+		res, err := rateLimiter.Allow(ctx, group+":"+key(ctx), policy)
+		if err != nil {
+			slog.Error("cannot check rate limit", "group", group, "errmsg", err)
+			ctx.Next()
+			return
+		}
+
+		reset := strconv.Itoa(int(math.Ceil(res.Reset.Seconds())))
+		previous, err := strconv.Atoi(ctx.Writer.Header().Get("RateLimit-Remaining"))
+		if err != nil || res.Remaining <= previous {
+			ctx.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds())))
+			ctx.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+			ctx.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			ctx.Header("RateLimit-Reset", reset)
+		}
+
+		if !res.Allowed {
+			ctx.Header("Retry-After", reset)
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, errorResponse("rate limit exceeded, try again later"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// Rate limit key of the client IP
+func rateLimitByIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// Rate limit key of the authenticated user, for limits shared by all their clients.
+// Must come after AuthMiddleware.
+func rateLimitByUser(ctx *gin.Context) string {
+
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		return rateLimitByIP(ctx)
+	}
+	return "user:" + k.(*token.TokenPayload).Username
+}
+
+// Rate limit key of the session or personal access token, so that a runaway script does not block the other clients of the user.
+// Must come after AuthMiddleware.
+func rateLimitByToken(ctx *gin.Context) string {
+
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		return rateLimitByIP(ctx)
+	}
+	payload := k.(*token.TokenPayload)
+	if payload.SessionID != uuid.Nil {
+		return "session:" + payload.SessionID.String()
+	}
+	return "token:" + payload.ID.String()
+}
+
+// Looks up a personal access token by its hash and builds the payload of the authenticated user.
+// On failure, returns the HTTP status code to respond with.
+func verifyPersonalAccessToken(ctx *gin.Context, store db.Store, accessToken string) (*token.TokenPayload, int, error) {
+
+	pat, err := store.GetPersonalAccessTokenByHash(ctx, token.HashPersonalAccessToken(accessToken))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, http.StatusUnauthorized, errors.New("invalid personal access token")
+		}
+		slog.Error(err.Error())
+		return nil, http.StatusInternalServerError, errors.New(internal_error_message)
+	}
+	if pat.ExpiresAt.Valid && time.Now().After(pat.ExpiresAt.Time) {
+		return nil, http.StatusUnauthorized, errors.New("personal access token has expired")
+	}
+
+	if err := store.UpdatePersonalAccessTokenLastUsed(ctx, pat.ID); err != nil {
+		slog.Error("cannot update personal access token", "token", pat.ID, "errmsg", err)
+	}
+
+	return newPersonalAccessTokenPayload(pat), http.StatusOK, nil
+}
+
+// Checks that the session a token belongs to has not been blocked, e.g. by logging out.
+// On failure, returns the HTTP status code to respond with.
+func verifySession(ctx *gin.Context, store db.Store, sessionId uuid.UUID) (int, error) {
+
+	session, err := store.GetSession(ctx, sessionId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return http.StatusUnauthorized, errors.New("session not found")
+		}
+		slog.Error(err.Error())
+		return http.StatusInternalServerError, errors.New(internal_error_message)
+	}
+	if session.IsBlocked {
+		return http.StatusUnauthorized, errors.New("session has been revoked")
+	}
+
+	return http.StatusOK, nil
+}
+
+func newPersonalAccessTokenPayload(pat db.PersonalAccessToken) *token.TokenPayload {
+
+	payload := &token.TokenPayload{
+		ID:       pat.ID,
+		Username: pat.Username,
+		Purpose:  token.PurposeAccess,
+		Scopes:   pat.Scopes,
+	}
+	payload.IssuedAt = jwt.NewNumericDate(pat.CreatedAt)
+	if pat.ExpiresAt.Valid {
+		payload.ExpiresAt = jwt.NewNumericDate(pat.ExpiresAt.Time)
+	}
+
+	return payload
+}
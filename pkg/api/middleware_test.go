@@ -3,13 +3,21 @@ package api
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/limiter"
+	mock "github.com/guerzon/gobudget-api/pkg/mock"
 	"github.com/guerzon/gobudget-api/pkg/token"
 	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func TestAuthMiddleware(t *testing.T) {
@@ -22,7 +30,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "OK",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Builder) {
-				token, _, err := tokenMaker.CreateToken(util.RandomUsername(), time.Duration(time.Minute*15))
+				token, _, err := tokenMaker.CreateToken(token.CreateTokenParams{Username: util.RandomUsername(), Duration: time.Duration(time.Minute * 15), Purpose: token.PurposeAccess})
 				require.NoError(t, err)
 				authzHeader := "Bearer " + token
 				request.Header.Set("Authorization", authzHeader)
@@ -42,7 +50,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "InvalidHeader",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Builder) {
-				token, _, err := tokenMaker.CreateToken(util.RandomUsername(), time.Duration(time.Minute*15))
+				token, _, err := tokenMaker.CreateToken(token.CreateTokenParams{Username: util.RandomUsername(), Duration: time.Duration(time.Minute * 15), Purpose: token.PurposeAccess})
 				require.NoError(t, err)
 				authzHeader := token // i.e. "Bearer " is missing
 				request.Header.Set("Authorization", authzHeader)
@@ -64,7 +72,19 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "InvalidToken",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Builder) {
-				token, _, err := tokenMaker.CreateToken(util.RandomUsername(), -1) // expired token
+				token, _, err := tokenMaker.CreateToken(token.CreateTokenParams{Username: util.RandomUsername(), Duration: -1, Purpose: token.PurposeAccess}) // expired token
+				require.NoError(t, err)
+				authzHeader := "Bearer " + token
+				request.Header.Set("Authorization", authzHeader)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Builder) {
+				token, _, err := tokenMaker.CreateToken(token.CreateTokenParams{Username: util.RandomUsername(), Duration: time.Duration(time.Minute * 15), Purpose: token.PurposeRefresh})
 				require.NoError(t, err)
 				authzHeader := "Bearer " + token
 				request.Header.Set("Authorization", authzHeader)
@@ -82,7 +102,7 @@ func TestAuthMiddleware(t *testing.T) {
 
 			server := NewTestServer(t, nil, nil)
 			authPath := "/auth" // dummy path
-			server.Router.GET(authPath, AuthMiddleware(server.tokenBuilder), func(ctx *gin.Context) {
+			server.Router.GET(authPath, AuthMiddleware(server.tokenBuilder, server.db), RequirePurpose(token.PurposeAccess), func(ctx *gin.Context) {
 				ctx.JSON(http.StatusOK, gin.H{})
 			})
 
@@ -96,3 +116,217 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddlewarePersonalAccessToken(t *testing.T) {
+
+	budgetId := uuid.New()
+
+	testCases := []struct {
+		name          string
+		method        string
+		budgetId      uuid.UUID
+		pat           func(hash string) (db.PersonalAccessToken, error)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			method:   http.MethodPost,
+			budgetId: budgetId,
+			pat: func(hash string) (db.PersonalAccessToken, error) {
+				return db.PersonalAccessToken{ID: uuid.New(), TokenHash: hash, Scopes: []string{token.ScopeWrite}}, nil
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "ReadOnly",
+			method:   http.MethodPost,
+			budgetId: budgetId,
+			pat: func(hash string) (db.PersonalAccessToken, error) {
+				return db.PersonalAccessToken{ID: uuid.New(), TokenHash: hash, Scopes: []string{token.ScopeRead}}, nil
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherBudget",
+			method:   http.MethodGet,
+			budgetId: uuid.New(),
+			pat: func(hash string) (db.PersonalAccessToken, error) {
+				scopes := []string{token.ScopeRead, token.BudgetScope(budgetId)}
+				return db.PersonalAccessToken{ID: uuid.New(), TokenHash: hash, Scopes: scopes}, nil
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "Expired",
+			method:   http.MethodGet,
+			budgetId: budgetId,
+			pat: func(hash string) (db.PersonalAccessToken, error) {
+				expiresAt := pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+				return db.PersonalAccessToken{ID: uuid.New(), TokenHash: hash, Scopes: []string{token.ScopeRead}, ExpiresAt: expiresAt}, nil
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "Revoked",
+			method:   http.MethodGet,
+			budgetId: budgetId,
+			pat: func(hash string) (db.PersonalAccessToken, error) {
+				return db.PersonalAccessToken{}, pgx.ErrNoRows
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			pat, hash, err := token.NewPersonalAccessToken()
+			require.NoError(t, err)
+
+			store := mock.NewMockStore(ctrl)
+			store.EXPECT().
+				GetPersonalAccessTokenByHash(gomock.Any(), gomock.Eq(hash)).
+				Times(1).
+				Return(tc.pat(hash))
+			store.EXPECT().
+				UpdatePersonalAccessTokenLastUsed(gomock.Any(), gomock.Any()).
+				AnyTimes()
+
+			server := NewTestServer(t, store, nil)
+			authPath := "/auth/:budget_id" // dummy path
+			server.Router.Handle(tc.method, authPath, AuthMiddleware(server.tokenBuilder, server.db), RequirePurpose(token.PurposeAccess), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tc.method, "/auth/"+tc.budgetId.String(), nil)
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+pat)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+
+	testCases := []struct {
+		name          string
+		roles         []string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			roles: []string{token.RoleAdmin},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "NoRole",
+			roles: nil,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+
+			server := NewTestServer(t, nil, nil)
+			authPath := "/auth" // dummy path
+			server.Router.GET(authPath, AuthMiddleware(server.tokenBuilder, server.db), RequirePurpose(token.PurposeAccess), RequireRole(token.RoleAdmin), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: util.RandomUsername(), Duration: time.Minute, Purpose: token.PurposeAccess, Roles: tc.roles})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+
+	server := NewTestServer(t, nil, nil)
+	path := "/limited" // dummy path
+	strict := limiter.Policy{Limit: 2, Window: time.Minute}
+	loose := limiter.Policy{Limit: 10, Window: time.Minute}
+	server.Router.GET(path,
+		RateLimitMiddleware(server.rateLimiter, "loose", loose, rateLimitByIP),
+		RateLimitMiddleware(server.rateLimiter, "strict", strict, rateLimitByIP),
+		func(ctx *gin.Context) {
+			ctx.JSON(http.StatusOK, gin.H{})
+		})
+
+	send := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	for i := 0; i < strict.Limit; i++ {
+		recorder := send()
+		require.Equal(t, http.StatusOK, recorder.Code)
+		// the headers show the closest limit
+		require.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+		require.Equal(t, strconv.Itoa(strict.Limit-i-1), recorder.Header().Get("RateLimit-Remaining"))
+		require.Equal(t, "2;w=60", recorder.Header().Get("RateLimit-Policy"))
+		require.NotEmpty(t, recorder.Header().Get("RateLimit-Reset"))
+	}
+
+	recorder := send()
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+	require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+}
+
+func TestRateLimitSettings(t *testing.T) {
+
+	config := util.Config{
+		SecretKey:       util.RandomString(32, ""),
+		RateLimitPublic: "100",
+	}
+	_, err := NewServer(config, nil, nil)
+	require.Error(t, err)
+
+	config.RateLimitPublic = "1/1m"
+	server, err := NewServer(config, nil, nil)
+	require.NoError(t, err)
+
+	// the second request is rejected before reaching the handler
+	for _, expected := range []int{http.StatusBadRequest, http.StatusTooManyRequests} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, "/beta/login", nil)
+		require.NoError(t, err)
+		server.Router.ServeHTTP(recorder, request)
+		require.Equal(t, expected, recorder.Code)
+	}
+}
@@ -0,0 +1,706 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// How long an authorization code can be exchanged for tokens
+const oauthCodeDuration = 10 * time.Minute
+
+// Grant types supported by the token endpoint
+const (
+	grantTypeAuthorizationCode = "authorization_code"
+	grantTypeRefreshToken      = "refresh_token"
+)
+
+// Error codes of the token endpoint (RFC 6749 5.2)
+const (
+	oauthErrorInvalidRequest       = "invalid_request"
+	oauthErrorInvalidClient        = "invalid_client"
+	oauthErrorInvalidGrant         = "invalid_grant"
+	oauthErrorUnsupportedGrantType = "unsupported_grant_type"
+	oauthErrorServerError          = "server_error"
+	oauthErrorAccessDenied         = "access_denied"
+)
+
+func oauthError(code string, description string) oauthErrorResponse {
+	return oauthErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	}
+}
+
+func newOAuthClientResponse(client db.OauthClient) oauthClientResponse {
+	return oauthClientResponse{
+		ID:           client.ID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectUris,
+		Scopes:       client.Scopes,
+		Confidential: client.SecretHash.Valid,
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+// getOAuthClients godoc
+//
+//	@Summary	List OAuth clients
+//	@Schemes
+//	@Description	List the OAuth clients registered by the authenticated user.
+//	@Tags			OAuth
+//	@Produce		json
+//	@Success		200	{object}	[]oauthClientResponse
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/oauth/clients [get]
+//	@Security		Bearer
+func (s *Server) getOAuthClients(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	clients, err := s.db.GetOAuthClients(ctx, authz_payload.Username)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	resp := make([]oauthClientResponse, len(clients))
+	for i := range clients {
+		resp[i] = newOAuthClientResponse(clients[i])
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// createOAuthClient godoc
+//
+//	@Summary	Register an OAuth client
+//	@Schemes
+//	@Description	Register a third-party app which can then ask users for access to their budgets. The client secret of confidential clients is only shown in this response.
+//	@Tags			OAuth
+//	@Accept			json
+//	@Param			client	body	oauthClientRequest	true	"Client details"
+//	@Produce		json
+//	@Success		201	{object}	oauthClientResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/oauth/clients [post]
+//	@Security		Bearer
+func (s *Server) createOAuthClient(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	var rqst oauthClientRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	arg := db.CreateOAuthClientParams{
+		OwnerUsername: authz_payload.Username,
+		Name:          rqst.Name,
+		RedirectUris:  rqst.RedirectURIs,
+		Scopes:        rqst.Scopes,
+	}
+	var secret string
+	if rqst.Confidential {
+		var secretHash string
+		var err error
+		secret, secretHash, err = token.NewOAuthSecret()
+		if err != nil {
+			slog.Error(err.Error())
+			ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+			return
+		}
+		arg.SecretHash = pgtype.Text{String: secretHash, Valid: true}
+	}
+
+	client, err := s.db.CreateOAuthClient(ctx, arg)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	slog.Info("Registered OAuth client", "user", client.OwnerUsername, "client", client.ID)
+
+	resp := newOAuthClientResponse(client)
+	resp.Secret = secret
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// deleteOAuthClient godoc
+//
+//	@Summary	Delete an OAuth client
+//	@Schemes
+//	@Description	Delete an OAuth client registered by the authenticated user. All tokens issued to the client are revoked.
+//	@Tags			OAuth
+//	@Param			client_id	path	string	true	"Client ID"
+//	@Produce		json
+//	@Success		200	{string}	string	"client deleted"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/oauth/clients/{client_id} [delete]
+//	@Security		Bearer
+func (s *Server) deleteOAuthClient(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	var clientId OAuthClientId
+	if err := ctx.ShouldBindUri(&clientId); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	clientUuid, err := uuid.Parse(clientId.ClientId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	client, err := s.db.GetOAuthClient(ctx, clientUuid)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse("client not found"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if client.OwnerUsername != authz_payload.Username {
+		ctx.JSON(http.StatusNotFound, errorResponse("client not found"))
+		return
+	}
+
+	if err := s.db.DeleteOAuthClientTx(ctx, client.ID); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "client deleted"})
+}
+
+// Validates an authorization request for the authenticated user. Returns the client and the requested scopes.
+// On failure, writes the response. Errors are never sent to the redirect URI, since it might not belong to the client.
+func (s *Server) validateAuthorizeRequest(ctx *gin.Context, rqst authorizeRequest, username string) (db.OauthClient, []string, error) {
+
+	clientUuid, err := uuid.Parse(rqst.ClientID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return db.OauthClient{}, nil, err
+	}
+	client, err := s.db.GetOAuthClient(ctx, clientUuid)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse("unknown client"))
+			return db.OauthClient{}, nil, err
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return db.OauthClient{}, nil, err
+	}
+
+	// The redirect URI must match one of the registered URIs exactly
+	if !slices.Contains(client.RedirectUris, rqst.RedirectURI) {
+		e := "redirect_uri is not registered for this client"
+		ctx.JSON(http.StatusBadRequest, errorResponse(e))
+		return db.OauthClient{}, nil, errors.New(e)
+	}
+
+	// Scopes are space-delimited (RFC 6749 3.3)
+	scopes := strings.Fields(rqst.Scope)
+	if err := token.ValidateScopes(scopes); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return db.OauthClient{}, nil, err
+	}
+	for _, scope := range scopes {
+		if (scope == token.ScopeRead || scope == token.ScopeWrite) && !slices.Contains(client.Scopes, scope) {
+			e := "scope " + scope + " is not allowed for this client"
+			ctx.JSON(http.StatusBadRequest, errorResponse(e))
+			return db.OauthClient{}, nil, errors.New(e)
+		}
+	}
+	if err := s.ValidateBudgetScopes(ctx, username, scopes); err != nil {
+		return db.OauthClient{}, nil, err
+	}
+
+	return client, scopes, nil
+}
+
+// getAuthorize godoc
+//
+//	@Summary	Start an OAuth authorization
+//	@Schemes
+//	@Description	Validate an authorization request (RFC 6749 4.1.1) from a third-party app, and return what the user is asked to consent to. PKCE with S256 is required.
+//	@Tags			OAuth
+//	@Param			response_type			query	string	true	"Must be code"
+//	@Param			client_id				query	string	true	"Client ID"
+//	@Param			redirect_uri			query	string	true	"Registered redirect URI"
+//	@Param			scope					query	string	true	"Space-delimited scopes"
+//	@Param			state					query	string	false	"Opaque value returned to the client"
+//	@Param			code_challenge			query	string	true	"PKCE code challenge"
+//	@Param			code_challenge_method	query	string	true	"Must be S256"
+//	@Produce		json
+//	@Success		200	{object}	authorizeResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/oauth/authorize [get]
+//	@Security		Bearer
+func (s *Server) getAuthorize(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	var rqst authorizeRequest
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	client, scopes, err := s.validateAuthorizeRequest(ctx, rqst, authz_payload.Username)
+	if err != nil {
+		return
+	}
+
+	resp := authorizeResponse{
+		ClientID:    client.ID,
+		ClientName:  client.Name,
+		RedirectURI: rqst.RedirectURI,
+		Scopes:      scopes,
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// postAuthorize godoc
+//
+//	@Summary	Consent to an OAuth authorization
+//	@Schemes
+//	@Description	Record the consent decision of the user for an authorization request. Returns the redirect URI to send the user agent to, with either an authorization code or an access_denied error.
+//	@Tags			OAuth
+//	@Accept			json
+//	@Param			authorization	body	authorizeRequest	true	"Authorization request and decision"
+//	@Produce		json
+//	@Success		200	{object}	authorizeDecisionResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/oauth/authorize [post]
+//	@Security		Bearer
+func (s *Server) postAuthorize(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	var rqst authorizeRequest
+	if err := ctx.ShouldBind(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	client, scopes, err := s.validateAuthorizeRequest(ctx, rqst, authz_payload.Username)
+	if err != nil {
+		return
+	}
+
+	redirect, err := url.Parse(rqst.RedirectURI)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	query := redirect.Query()
+	if rqst.State != "" {
+		query.Set("state", rqst.State)
+	}
+
+	if !rqst.Approve {
+		query.Set("error", oauthErrorAccessDenied)
+		redirect.RawQuery = query.Encode()
+		ctx.JSON(http.StatusOK, authorizeDecisionResponse{RedirectURI: redirect.String()})
+		return
+	}
+
+	code, codeHash, err := token.NewOAuthSecret()
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	_, err = s.db.CreateOAuthAuthorizationCode(ctx, db.CreateOAuthAuthorizationCodeParams{
+		CodeHash:      codeHash,
+		ClientID:      client.ID,
+		Username:      authz_payload.Username,
+		RedirectUri:   rqst.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: rqst.CodeChallenge,
+		ExpiresAt:     time.Now().Add(oauthCodeDuration),
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	query.Set("code", code)
+	redirect.RawQuery = query.Encode()
+
+	ctx.JSON(http.StatusOK, authorizeDecisionResponse{RedirectURI: redirect.String()})
+}
+
+// oauthToken godoc
+//
+//	@Summary	OAuth token endpoint
+//	@Schemes
+//	@Description	Exchange an authorization code and its PKCE code verifier, or a refresh token, for an access token (RFC 6749 4.1.3 and 6). Refresh tokens are rotated on every use, and replaying a used one revokes the session. Confidential clients authenticate with HTTP basic auth or client_secret.
+//	@Tags			OAuth
+//	@Accept			x-www-form-urlencoded
+//	@Param			grant_type		formData	string	true	"authorization_code or refresh_token"
+//	@Param			code			formData	string	false	"Authorization code"
+//	@Param			redirect_uri	formData	string	false	"Redirect URI used in the authorization request"
+//	@Param			code_verifier	formData	string	false	"PKCE code verifier"
+//	@Param			refresh_token	formData	string	false	"Refresh token"
+//	@Param			client_id		formData	string	false	"Client ID"
+//	@Param			client_secret	formData	string	false	"Client secret of confidential clients"
+//	@Produce		json
+//	@Success		200	{object}	oauthTokenResponse
+//	@Failure		400	{object}	oauthErrorResponse
+//	@Failure		401	{object}	oauthErrorResponse
+//	@Failure		500	{object}	oauthErrorResponse
+//	@Router			/oauth/token [post]
+func (s *Server) oauthToken(ctx *gin.Context) {
+
+	// RFC 6749 5.1
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	var rqst oauthTokenRequest
+	if err := ctx.ShouldBind(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidRequest, "grant_type is required"))
+		return
+	}
+
+	client, err := s.authenticateOAuthClient(ctx, rqst)
+	if err != nil {
+		return
+	}
+
+	switch rqst.GrantType {
+	case grantTypeAuthorizationCode:
+		s.authorizationCodeGrant(ctx, client, rqst)
+	case grantTypeRefreshToken:
+		s.refreshTokenGrant(ctx, client, rqst)
+	default:
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorUnsupportedGrantType, ""))
+	}
+}
+
+// Identifies the client of a token request, and authenticates it if it is confidential.
+// On failure, writes the response.
+func (s *Server) authenticateOAuthClient(ctx *gin.Context, rqst oauthTokenRequest) (db.OauthClient, error) {
+
+	clientId, clientSecret, basicAuth := ctx.Request.BasicAuth()
+	if !basicAuth {
+		clientId, clientSecret = rqst.ClientID, rqst.ClientSecret
+	}
+
+	clientUuid, err := uuid.Parse(clientId)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, oauthError(oauthErrorInvalidClient, "unknown client"))
+		return db.OauthClient{}, err
+	}
+	client, err := s.db.GetOAuthClient(ctx, clientUuid)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, oauthError(oauthErrorInvalidClient, "unknown client"))
+			return db.OauthClient{}, err
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return db.OauthClient{}, err
+	}
+
+	if client.SecretHash.Valid {
+		secretHash := token.HashOAuthSecret(clientSecret)
+		if subtle.ConstantTimeCompare([]byte(secretHash), []byte(client.SecretHash.String)) != 1 {
+			e := "invalid client credentials"
+			ctx.JSON(http.StatusUnauthorized, oauthError(oauthErrorInvalidClient, e))
+			return db.OauthClient{}, errors.New(e)
+		}
+	}
+
+	return client, nil
+}
+
+func (s *Server) authorizationCodeGrant(ctx *gin.Context, client db.OauthClient, rqst oauthTokenRequest) {
+
+	if rqst.Code == "" || rqst.CodeVerifier == "" || rqst.RedirectURI == "" {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidRequest, "code, code_verifier and redirect_uri are required"))
+		return
+	}
+
+	code, err := s.db.GetOAuthAuthorizationCode(ctx, token.HashOAuthSecret(rqst.Code))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "invalid authorization code"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return
+	}
+
+	// Validations
+	if code.ClientID != client.ID {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "invalid authorization code"))
+		return
+	}
+	if code.Used {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "authorization code has already been used"))
+		return
+	}
+	if time.Now().After(code.ExpiresAt) {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "authorization code has expired"))
+		return
+	}
+	if code.RedirectUri != rqst.RedirectURI {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "redirect_uri does not match the authorization request"))
+		return
+	}
+	if !token.VerifyCodeChallenge(rqst.CodeVerifier, code.CodeChallenge) {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "invalid code verifier"))
+		return
+	}
+
+	// Mark the code as used, which fails if a concurrent request exchanged it first
+	n, err := s.db.UseOAuthAuthorizationCode(ctx, code.CodeHash)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "authorization code has already been used"))
+		return
+	}
+
+	// both tokens are linked to a session of the client
+	sessionID := uuid.New()
+
+	// create the access token
+	accessToken, _, err := s.tokenBuilder.CreateToken(token.CreateTokenParams{
+		Username:  code.Username,
+		Duration:  s.config.AccessTokenDuration,
+		Purpose:   token.PurposeAccess,
+		SessionID: sessionID,
+		Scopes:    code.Scopes,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return
+	}
+
+	// create the refresh token, which is saved in the session
+	refreshToken, refreshTokenClaims, err := s.tokenBuilder.CreateToken(token.CreateTokenParams{
+		Username:  code.Username,
+		Duration:  s.config.RefreshTokenDuration,
+		Purpose:   token.PurposeRefresh,
+		SessionID: sessionID,
+		Scopes:    code.Scopes,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return
+	}
+	_, err = s.db.CreateSession(ctx, db.CreateSessionParams{
+		ID:           sessionID,
+		Username:     code.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+		ExpiresAt:    refreshTokenClaims.ExpiresAt.Time,
+		ClientID:     pgtype.UUID{Bytes: client.ID, Valid: true},
+		Scopes:       code.Scopes,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return
+	}
+
+	slog.Info("Issued OAuth tokens", "user", code.Username, "client", client.ID)
+
+	resp := oauthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.config.AccessTokenDuration.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(code.Scopes, " "),
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) refreshTokenGrant(ctx *gin.Context, client db.OauthClient, rqst oauthTokenRequest) {
+
+	if rqst.RefreshToken == "" {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidRequest, "refresh_token is required"))
+		return
+	}
+
+	refreshTokenClaims, err := s.tokenBuilder.VerifyToken(rqst.RefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, err.Error()))
+		return
+	}
+	if refreshTokenClaims.Purpose != token.PurposeRefresh {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "invalid refresh token"))
+		return
+	}
+
+	session, err := s.db.GetSession(ctx, refreshTokenClaims.SessionID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "invalid refresh token"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return
+	}
+
+	// The session must have been issued to this client
+	if !session.ClientID.Valid || uuid.UUID(session.ClientID.Bytes) != client.ID || session.Username != refreshTokenClaims.Username {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "invalid refresh token"))
+		return
+	}
+	if session.IsBlocked {
+		ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "refresh token is revoked"))
+		return
+	}
+	// Like renewToken, every refresh rotates the refresh token: a token which is not the current one of its session
+	// has already been used, and the session cannot be trusted anymore
+	if session.RefreshToken != rqst.RefreshToken {
+		s.revokeReusedOAuthSession(ctx, session)
+		return
+	}
+
+	// The new refresh token does not outlive the session
+	refreshToken, _, err := s.tokenBuilder.CreateToken(token.CreateTokenParams{
+		Username:  session.Username,
+		Duration:  time.Until(session.ExpiresAt),
+		Purpose:   token.PurposeRefresh,
+		SessionID: session.ID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return
+	}
+
+	// The access token gets the scopes of the original authorization
+	accessToken, _, err := s.tokenBuilder.CreateToken(token.CreateTokenParams{
+		Username:  session.Username,
+		Duration:  s.config.AccessTokenDuration,
+		Purpose:   token.PurposeAccess,
+		SessionID: session.ID,
+		Scopes:    session.Scopes,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return
+	}
+
+	// Replace the refresh token of the session, which fails if a concurrent request already did
+	rotated, err := s.db.RotateSessionRefreshToken(ctx, db.RotateSessionRefreshTokenParams{
+		ID:              session.ID,
+		RefreshToken:    rqst.RefreshToken,
+		NewRefreshToken: refreshToken,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return
+	}
+	if rotated == 0 {
+		s.revokeReusedOAuthSession(ctx, session)
+		return
+	}
+
+	resp := oauthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.config.AccessTokenDuration.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(session.Scopes, " "),
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Blocks the session of a client whose refresh token was reused, answering with the error of the token endpoint
+func (s *Server) revokeReusedOAuthSession(ctx *gin.Context, session db.Session) {
+
+	if err := s.blockReusedSession(ctx, session); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, oauthError(oauthErrorServerError, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusBadRequest, oauthError(oauthErrorInvalidGrant, "refresh token has already been used"))
+}
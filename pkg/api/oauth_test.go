@@ -0,0 +1,375 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// Runs the whole authorization code flow with PKCE against a real HTTP server: client registration,
+// consent, code exchange, scoped API access and refresh. The store mock keeps the rows created along the way.
+func TestOAuthFlow(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+	budget := db.Budget{
+		ID:            uuid.New(),
+		OwnerUsername: user.Username,
+		Name:          "My Budget",
+		CurrencyCode:  "EUR",
+	}
+	redirectURI := "https://sheets.example.com/callback"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+
+	// State kept by the mocked database
+	var client db.OauthClient
+	var code db.OauthAuthorizationCode
+	var session db.Session
+
+	store.EXPECT().
+		CreateOAuthClient(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.CreateOAuthClientParams) (db.OauthClient, error) {
+			client = db.OauthClient{
+				ID:            uuid.New(),
+				OwnerUsername: arg.OwnerUsername,
+				Name:          arg.Name,
+				SecretHash:    arg.SecretHash,
+				RedirectUris:  arg.RedirectUris,
+				Scopes:        arg.Scopes,
+			}
+			return client, nil
+		})
+	store.EXPECT().
+		GetOAuthClient(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ any, id uuid.UUID) (db.OauthClient, error) {
+			if id != client.ID {
+				return db.OauthClient{}, pgx.ErrNoRows
+			}
+			return client, nil
+		})
+	store.EXPECT().
+		GetBudget(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ any, arg db.GetBudgetParams) (db.Budget, error) {
+			if arg.ID != budget.ID || arg.OwnerUsername != budget.OwnerUsername {
+				return db.Budget{}, pgx.ErrNoRows
+			}
+			return budget, nil
+		})
+	store.EXPECT().
+		GetAccounts(gomock.Any(), budget.ID).
+		AnyTimes().
+		Return([]db.Account{}, nil)
+	store.EXPECT().
+		GetBudgetCategories(gomock.Any(), budget.ID).
+		AnyTimes().
+		Return([]db.Category{}, nil)
+	store.EXPECT().
+		GetPayees(gomock.Any(), budget.ID).
+		AnyTimes().
+		Return([]db.Payee{}, nil)
+	store.EXPECT().
+		GetTransactions(gomock.Any(), budget.ID).
+		AnyTimes().
+		Return([]db.Transaction{}, nil)
+	store.EXPECT().
+		CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.CreateOAuthAuthorizationCodeParams) (db.OauthAuthorizationCode, error) {
+			code = db.OauthAuthorizationCode{
+				CodeHash:      arg.CodeHash,
+				ClientID:      arg.ClientID,
+				Username:      arg.Username,
+				RedirectUri:   arg.RedirectUri,
+				Scopes:        arg.Scopes,
+				CodeChallenge: arg.CodeChallenge,
+				ExpiresAt:     arg.ExpiresAt,
+			}
+			return code, nil
+		})
+	store.EXPECT().
+		GetOAuthAuthorizationCode(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ any, codeHash string) (db.OauthAuthorizationCode, error) {
+			if codeHash != code.CodeHash {
+				return db.OauthAuthorizationCode{}, pgx.ErrNoRows
+			}
+			return code, nil
+		})
+	store.EXPECT().
+		UseOAuthAuthorizationCode(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, codeHash string) (int64, error) {
+			code.Used = true
+			return 1, nil
+		})
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.CreateSessionParams) (db.Session, error) {
+			session = db.Session{
+				ID:           arg.ID,
+				Username:     arg.Username,
+				RefreshToken: arg.RefreshToken,
+				ExpiresAt:    arg.ExpiresAt,
+				ClientID:     arg.ClientID,
+				Scopes:       arg.Scopes,
+			}
+			return session, nil
+		})
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ any, id uuid.UUID) (db.Session, error) {
+			if id != session.ID {
+				return db.Session{}, pgx.ErrNoRows
+			}
+			return session, nil
+		})
+	store.EXPECT().
+		RotateSessionRefreshToken(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ any, arg db.RotateSessionRefreshTokenParams) (int64, error) {
+			if arg.ID != session.ID || arg.RefreshToken != session.RefreshToken {
+				return 0, nil
+			}
+			session.RefreshToken = arg.NewRefreshToken
+			return 1, nil
+		})
+	store.EXPECT().
+		BlockSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.BlockSessionParams) (db.Session, error) {
+			session.IsBlocked = true
+			return session, nil
+		})
+
+	dist := mockdb.NewMockTaskDistributor(ctrl)
+	dist.EXPECT().
+		DistributeSendEmail(gomock.Any(), &worker.SendEmailPayload{Username: user.Username}, worker.TaskSendRefreshTokenReuseEmail).
+		Times(1).
+		Return(nil)
+
+	server := NewTestServer(t, store, dist)
+	httpServer := httptest.NewServer(server.Router)
+	defer httpServer.Close()
+
+	// The user is logged in to gobudget
+	userToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: user.Username, Duration: time.Minute, Purpose: token.PurposeAccess})
+	require.NoError(t, err)
+
+	do := func(method, path, bearer string, body gin.H) *http.Response {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		request, err := http.NewRequest(method, httpServer.URL+path, bytes.NewReader(data))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+bearer)
+		resp, err := httpServer.Client().Do(request)
+		require.NoError(t, err)
+		return resp
+	}
+	postForm := func(form url.Values) (*http.Response, map[string]any) {
+		resp, err := httpServer.Client().PostForm(httpServer.URL+"/beta/oauth/token", form)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp, body
+	}
+
+	// 1. The developer registers a public client
+	resp := do(http.MethodPost, "/beta/oauth/clients", userToken, gin.H{
+		"name":          "Budget Sheets",
+		"redirect_uris": []string{redirectURI},
+		"scopes":        []string{token.ScopeRead},
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var registered oauthClientResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&registered))
+	resp.Body.Close()
+	require.Empty(t, registered.Secret)
+
+	// 2. The app sends the user to the consent page, which asks for read access to one budget
+	verifier := util.RandomString(64, "")
+	challenge := sha256.Sum256([]byte(verifier))
+	authorize := gin.H{
+		"response_type":         "code",
+		"client_id":             registered.ID.String(),
+		"redirect_uri":          redirectURI,
+		"scope":                 token.ScopeRead + " " + token.BudgetScope(budget.ID),
+		"state":                 "xyz",
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(challenge[:]),
+		"code_challenge_method": "S256",
+	}
+	query := url.Values{}
+	for k, v := range authorize {
+		query.Set(k, v.(string))
+	}
+	resp = do(http.MethodGet, "/beta/oauth/authorize?"+query.Encode(), userToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var consent authorizeResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&consent))
+	resp.Body.Close()
+	require.Equal(t, "Budget Sheets", consent.ClientName)
+	require.Equal(t, []string{token.ScopeRead, token.BudgetScope(budget.ID)}, consent.Scopes)
+
+	// The client was not registered with the write scope
+	query.Set("scope", token.ScopeWrite)
+	resp = do(http.MethodGet, "/beta/oauth/authorize?"+query.Encode(), userToken, nil)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// 3. The user approves, and is sent back to the app with a code
+	authorize["approve"] = true
+	resp = do(http.MethodPost, "/beta/oauth/authorize", userToken, authorize)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var decision authorizeDecisionResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decision))
+	resp.Body.Close()
+	redirect, err := url.Parse(decision.RedirectURI)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(decision.RedirectURI, redirectURI+"?"))
+	require.Equal(t, "xyz", redirect.Query().Get("state"))
+	authzCode := redirect.Query().Get("code")
+	require.NotEmpty(t, authzCode)
+
+	// 4. The app exchanges the code, which needs the PKCE verifier
+	exchange := url.Values{
+		"grant_type":    {grantTypeAuthorizationCode},
+		"code":          {authzCode},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {registered.ID.String()},
+		"code_verifier": {util.RandomString(64, "")},
+	}
+	resp, body := postForm(exchange)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, oauthErrorInvalidGrant, body["error"])
+
+	exchange.Set("code_verifier", verifier)
+	resp, body = postForm(exchange)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	require.Equal(t, "Bearer", body["token_type"])
+	require.Equal(t, token.ScopeRead+" "+token.BudgetScope(budget.ID), body["scope"])
+	accessToken := body["access_token"].(string)
+	refreshToken := body["refresh_token"].(string)
+
+	// Codes can only be used once
+	resp, body = postForm(exchange)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, oauthErrorInvalidGrant, body["error"])
+
+	// 5. The access token can only read the budget the user consented to
+	resp = do(http.MethodGet, "/beta/budgets/"+budget.ID.String(), accessToken, nil)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = do(http.MethodGet, "/beta/budgets/"+uuid.NewString(), accessToken, nil)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = do(http.MethodDelete, "/beta/budgets/"+budget.ID.String(), accessToken, nil)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/beta/oauth/clients", accessToken, gin.H{})
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// 6. The refresh token gives a new access token with the same scopes, but only at the token endpoint
+	refresh := func(refreshToken string) (*http.Response, map[string]any) {
+		return postForm(url.Values{
+			"grant_type":    {grantTypeRefreshToken},
+			"refresh_token": {refreshToken},
+			"client_id":     {registered.ID.String()},
+		})
+	}
+	resp, body = refresh(refreshToken)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, token.ScopeRead+" "+token.BudgetScope(budget.ID), body["scope"])
+	rotatedToken := body["refresh_token"].(string)
+	require.NotEqual(t, refreshToken, rotatedToken)
+
+	resp = do(http.MethodPost, "/beta/renew_token", "", gin.H{"refresh_token": rotatedToken})
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// 7. Replaying a used refresh token revokes the session, so the rotated token stops working too
+	resp, body = refresh(refreshToken)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, oauthErrorInvalidGrant, body["error"])
+	require.True(t, session.IsBlocked)
+
+	resp, body = refresh(rotatedToken)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, oauthErrorInvalidGrant, body["error"])
+}
+
+func TestOAuthTokenInvalidClient(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secret, secretHash, err := token.NewOAuthSecret()
+	require.NoError(t, err)
+	client := db.OauthClient{
+		ID:         uuid.New(),
+		SecretHash: pgtype.Text{String: secretHash, Valid: true},
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetOAuthClient(gomock.Any(), client.ID).
+		Times(2).
+		Return(client, nil)
+
+	server := NewTestServer(t, store, nil)
+
+	// Wrong secret, then the right one with an unsupported grant
+	for _, tc := range []struct {
+		secret string
+		status int
+		error  string
+	}{
+		{secret: "wrong", status: http.StatusUnauthorized, error: oauthErrorInvalidClient},
+		{secret: secret, status: http.StatusBadRequest, error: oauthErrorUnsupportedGrantType},
+	} {
+		form := url.Values{"grant_type": {"password"}}
+		request, err := http.NewRequest(http.MethodPost, "/beta/oauth/token", strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.SetBasicAuth(client.ID.String(), tc.secret)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, request)
+		require.Equal(t, tc.status, recorder.Code)
+
+		var body oauthErrorResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		require.Equal(t, tc.error, body.Error)
+	}
+}
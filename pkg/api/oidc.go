@@ -0,0 +1,286 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/oidc"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Cookie keeping the state, nonce and PKCE code verifier of a login with the identity provider,
+// so that the callback only completes a login started by the same browser
+const (
+	oidcLoginCookie     = "oidc_login"
+	oidcLoginCookiePath = "/beta/login/oidc"
+	oidcLoginDuration   = 10 * time.Minute
+)
+
+// Accounts created on first login get usernames derived from the identity, with a random suffix if they are taken
+const (
+	oidcUsernameMinLength = 5
+	oidcUsernameMaxLength = 32
+	oidcUsernameAttempts  = 3
+)
+
+// loginOIDC godoc
+//
+//	@Summary	Log in with the identity provider
+//	@Schemes
+//	@Description	Start a login with the OpenID Connect identity provider: redirects the browser to the provider, which redirects it back to the callback.
+//	@Tags			Security
+//	@Success		302
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/login/oidc [get]
+func (s *Server) loginOIDC(ctx *gin.Context) {
+
+	if s.oidcProvider == nil {
+		ctx.JSON(http.StatusNotFound, errorResponse("login with an identity provider is not configured"))
+		return
+	}
+
+	state, err := util.RandomSecret(32)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	nonce, err := util.RandomSecret(32)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	authURL, err := s.oidcProvider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	s.setOIDCLoginCookie(ctx, strings.Join([]string{state, nonce, verifier}, "."), int(oidcLoginDuration.Seconds()))
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// oidcCallback godoc
+//
+//	@Summary	Complete a login with the identity provider
+//	@Schemes
+//	@Description	Callback of the identity provider, in the browser which started the login. The identity is linked to the account with the same email, verified by both the provider and the account,
+//	@Description	on first login, or to a new account if signups are allowed. Returns the same response as a login with a password,
+//	@Description	or a challenge token if the user has two-factor authentication.
+//	@Tags			Security
+//	@Param			code	query	string	true	"Authorization code"
+//	@Param			state	query	string	true	"State of the login"
+//	@Produce		json
+//	@Success		200	{object}	loginResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/login/oidc/callback [get]
+func (s *Server) oidcCallback(ctx *gin.Context) {
+
+	if s.oidcProvider == nil {
+		ctx.JSON(http.StatusNotFound, errorResponse("login with an identity provider is not configured"))
+		return
+	}
+
+	var rqst oidcCallbackRequest
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	if rqst.Error != "" {
+		slog.Info("Login refused by the identity provider", "error", rqst.Error, "description", rqst.ErrorDescription)
+		ctx.JSON(http.StatusUnauthorized, errorResponse("the identity provider did not authenticate the user"))
+		return
+	}
+	if rqst.Code == "" || rqst.State == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	cookie, err := ctx.Cookie(oidcLoginCookie)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("the login must be completed in the browser where it was started"))
+		return
+	}
+	parts := strings.Split(cookie, ".")
+	if len(parts) != 3 || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(rqst.State)) != 1 {
+		ctx.JSON(http.StatusBadRequest, errorResponse("the login must be completed in the browser where it was started"))
+		return
+	}
+	nonce, verifier := parts[1], parts[2]
+	// each login can only be completed once
+	s.setOIDCLoginCookie(ctx, "", -1)
+
+	claims, err := s.oidcProvider.Exchange(ctx, rqst.Code, verifier, nonce)
+	if err != nil {
+		slog.Info("Cannot verify the identity", "error", err.Error())
+		ctx.JSON(http.StatusUnauthorized, errorResponse("cannot verify the identity"))
+		return
+	}
+
+	u, status, err := s.oidcUser(ctx, claims)
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			slog.Error(err.Error())
+			ctx.JSON(status, errorResponse(internal_error_message))
+			return
+		}
+		ctx.JSON(status, errorResponse(err.Error()))
+		return
+	}
+
+	// from this point, the user is authenticated like with a password
+	slog.Info("Logged in with the identity provider", "user", u.Username)
+
+	if u.TotpEnabled {
+		s.startTwoFactorChallenge(ctx, u)
+		return
+	}
+
+	s.startSession(ctx, u)
+}
+
+// Returns the user of an identity, linking it to the account with the same verified email, or to a new account if
+// signups are allowed. Errors come with the status of the response.
+func (s *Server) oidcUser(ctx *gin.Context, claims *oidc.Claims) (db.User, int, error) {
+
+	identity, err := s.db.GetUserIdentity(ctx, db.GetUserIdentityParams{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+	})
+	if err == nil {
+		u, err := s.db.GetUserByUsername(ctx, identity.Username)
+		if err != nil {
+			return db.User{}, http.StatusInternalServerError, err
+		}
+		return u, http.StatusOK, nil
+	}
+	if err != pgx.ErrNoRows {
+		return db.User{}, http.StatusInternalServerError, err
+	}
+
+	// an unverified email could belong to anyone, so it is neither linked nor used for a new account
+	if claims.Email == "" || !claims.EmailVerified {
+		if s.config.OIDCAllowSignup {
+			return db.User{}, http.StatusForbidden, errors.New("the identity provider did not return a verified email")
+		}
+		return db.User{}, http.StatusForbidden, errors.New("no account is linked to this identity")
+	}
+
+	u, err := s.db.GetUserByEmail(ctx, claims.Email)
+	if err == nil {
+		// whoever signed up with an email they do not own must not get the identity of its owner
+		if !u.EmailVerified {
+			return db.User{}, http.StatusForbidden, errors.New("the account with this email has not verified it, log in with its password first")
+		}
+		_, err = s.db.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+			Username: u.Username,
+			Issuer:   claims.Issuer,
+			Subject:  claims.Subject,
+			Email:    claims.Email,
+		})
+		if err != nil {
+			return db.User{}, http.StatusInternalServerError, err
+		}
+		slog.Info("Linked an identity", "user", u.Username, "issuer", claims.Issuer)
+		return u, http.StatusOK, nil
+	}
+	if err != pgx.ErrNoRows {
+		return db.User{}, http.StatusInternalServerError, err
+	}
+
+	if !s.config.OIDCAllowSignup {
+		return db.User{}, http.StatusForbidden, errors.New("no account is linked to this identity")
+	}
+
+	// the password is random and not given to anyone, until the user resets it
+	secret, err := util.RandomSecret(32)
+	if err != nil {
+		return db.User{}, http.StatusInternalServerError, err
+	}
+	hashedPassword, err := util.HashPassword(secret)
+	if err != nil {
+		return db.User{}, http.StatusInternalServerError, err
+	}
+
+	username := oidcUsername(claims)
+	for attempt := 1; ; attempt++ {
+		u, err = s.db.CreateUserWithIdentityTx(ctx, db.CreateUserWithIdentityTxParams{
+			User: db.CreateUserParams{
+				Username:           username,
+				Password:           hashedPassword,
+				Email:              claims.Email,
+				EmailVerified:      true,
+				LastPasswordChange: time.Now(),
+			},
+			Issuer:  claims.Issuer,
+			Subject: claims.Subject,
+		})
+		if err == nil {
+			break
+		}
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != "23505" || attempt == oidcUsernameAttempts {
+			return db.User{}, http.StatusInternalServerError, err
+		}
+		// the username is taken
+		username = oidcUsername(claims) + "-" + util.RandomString(4, "1234567890")
+	}
+	slog.Info("Created new user", "user", u.Username, "issuer", claims.Issuer)
+
+	return u, http.StatusOK, nil
+}
+
+// Username of a new account, from the preferred username or the email of the identity.
+// Characters other than letters, digits, dots, hyphens and underscores are dropped.
+func oidcUsername(claims *oidc.Claims) string {
+
+	candidate := claims.PreferredUsername
+	if candidate == "" {
+		candidate, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	var sb strings.Builder
+	for _, r := range strings.ToLower(candidate) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_' {
+			sb.WriteRune(r)
+		}
+		if sb.Len() == oidcUsernameMaxLength {
+			break
+		}
+	}
+	username := sb.String()
+	if len(username) < oidcUsernameMinLength {
+		username += util.RandomString(oidcUsernameMinLength-len(username)+3, "1234567890")
+	}
+
+	return username
+}
+
+// Sets the cookie of a login with the identity provider, or deletes it with a negative maxAge.
+// It is kept by the top-level navigation back from the provider.
+func (s *Server) setOIDCLoginCookie(ctx *gin.Context, value string, maxAge int) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcLoginCookie, value, maxAge, oidcLoginCookiePath, "", s.config.Environment != "local", true)
+}
@@ -0,0 +1,410 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/oidc/oidctest"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newOIDCTestServer(t *testing.T, store db.Store, provider *oidctest.Provider, allowSignup bool) *Server {
+	config := util.Config{
+		SecretKey:            util.RandomString(32, ""),
+		AccessTokenDuration:  time.Minute * 15,
+		RefreshTokenDuration: time.Hour,
+		OIDCIssuerURL:        provider.Issuer(),
+		OIDCClientID:         provider.ClientID,
+		OIDCClientSecret:     provider.ClientSecret,
+		OIDCRedirectURL:      "http://localhost:8080/beta/login/oidc/callback",
+		OIDCAllowSignup:      allowSignup,
+	}
+
+	testServer, err := NewServer(config, store, nil)
+	require.NoError(t, err)
+
+	return testServer
+}
+
+func TestOIDCLoginAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+	user2 := user
+	user2.TotpEnabled = true
+	user3 := user
+	user3.EmailVerified = false
+
+	verified := oidctest.Identity{
+		Subject:           "248289761001",
+		Email:             user.Email,
+		EmailVerified:     true,
+		PreferredUsername: "Charles.Leclerc",
+	}
+	unverified := verified
+	unverified.EmailVerified = false
+
+	testCases := []struct {
+		name          string
+		identity      oidctest.Identity
+		allowSignup   bool
+		state         string
+		noCookie      bool
+		modifyClaims  func(claims jwt.MapClaims)
+		buildStubs    func(store *mockdb.MockStore, issuer string)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "LinkedIdentity",
+			identity: verified,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), db.GetUserIdentityParams{Issuer: issuer, Subject: verified.Subject}).
+					Times(1).
+					Return(db.UserIdentity{Username: user.Username, Issuer: issuer, Subject: verified.Subject}, nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+				expectLoginRecorded(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp loginResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.NotEmpty(t, resp.AccessToken)
+				require.NotEmpty(t, resp.RefreshToken)
+
+				// the login cookie is deleted
+				cookies := recorder.Result().Cookies()
+				require.Len(t, cookies, 1)
+				require.Equal(t, oidcLoginCookie, cookies[0].Name)
+				require.Negative(t, cookies[0].MaxAge)
+			},
+		},
+		{
+			name:     "TwoFactorRequired",
+			identity: verified,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{Username: user.Username}, nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user2, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTwoFactorChallengeParams) error {
+						require.Equal(t, user.Username, arg.Username)
+						return nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp twoFactorChallengeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.True(t, resp.TwoFactorRequired)
+				require.NotEmpty(t, resp.ChallengeToken)
+			},
+		},
+		{
+			name:     "LinkByVerifiedEmail",
+			identity: verified,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, pgx.ErrNoRows)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), user.Email).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateUserIdentity(gomock.Any(), db.CreateUserIdentityParams{
+						Username: user.Username,
+						Issuer:   issuer,
+						Subject:  verified.Subject,
+						Email:    user.Email,
+					}).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+				expectLoginRecorded(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "LinkToUnverifiedAccount",
+			identity: verified,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, pgx.ErrNoRows)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), user.Email).
+					Times(1).
+					Return(user3, nil)
+				store.EXPECT().
+					CreateUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "UnverifiedEmail",
+			identity: unverified,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, pgx.ErrNoRows)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:        "Signup",
+			identity:    verified,
+			allowSignup: true,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, pgx.ErrNoRows)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), user.Email).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().
+					CreateUserWithIdentityTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateUserWithIdentityTxParams) (db.User, error) {
+						require.Equal(t, "charles.leclerc", arg.User.Username)
+						require.Equal(t, user.Email, arg.User.Email)
+						require.True(t, arg.User.EmailVerified)
+						require.NotEmpty(t, arg.User.Password)
+						require.Equal(t, issuer, arg.Issuer)
+						require.Equal(t, verified.Subject, arg.Subject)
+						return user, nil
+					})
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+				expectLoginRecorded(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "SignupUsernameTaken",
+			identity:    verified,
+			allowSignup: true,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, pgx.ErrNoRows)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), user.Email).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				gomock.InOrder(
+					store.EXPECT().
+						CreateUserWithIdentityTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.User{}, &pgconn.PgError{Code: "23505"}),
+					store.EXPECT().
+						CreateUserWithIdentityTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ any, arg db.CreateUserWithIdentityTxParams) (db.User, error) {
+							require.Regexp(t, `^charles\.leclerc-[0-9]{4}$`, arg.User.Username)
+							return user, nil
+						}),
+				)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+				expectLoginRecorded(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "SignupNotAllowed",
+			identity: verified,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, pgx.ErrNoRows)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), user.Email).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().
+					CreateUserWithIdentityTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "WrongState",
+			identity: verified,
+			state:    "otherstate",
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherBrowser",
+			identity: verified,
+			noCookie: true,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InvalidIDToken",
+			identity:     verified,
+			modifyClaims: func(claims jwt.MapClaims) { claims["aud"] = "otherclient" },
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			provider := oidctest.NewProvider(t, "gobudget", "secret")
+			provider.ModifyClaims = tc.modifyClaims
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, provider.Issuer())
+
+			server := newOIDCTestServer(t, store, provider, tc.allowSignup)
+
+			// the browser starts the login, and is redirected to the provider
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/beta/login/oidc", nil)
+			require.NoError(t, err)
+			server.Router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusFound, recorder.Code)
+
+			cookies := recorder.Result().Cookies()
+			require.Len(t, cookies, 1)
+			require.Equal(t, oidcLoginCookie, cookies[0].Name)
+			require.True(t, cookies[0].HttpOnly)
+
+			// the user logs in at the provider, which redirects back with a code
+			code, state, err := provider.Authorize(recorder.Header().Get("Location"), tc.identity)
+			require.NoError(t, err)
+			if tc.state != "" {
+				state = tc.state
+			}
+
+			recorder = httptest.NewRecorder()
+			query := url.Values{"code": {code}, "state": {state}}
+			request, err = http.NewRequest(http.MethodGet, "/beta/login/oidc/callback?"+query.Encode(), nil)
+			require.NoError(t, err)
+			if !tc.noCookie {
+				request.AddCookie(&http.Cookie{Name: cookies[0].Name, Value: cookies[0].Value})
+			}
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestOIDCCallbackProviderError(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := oidctest.NewProvider(t, "gobudget", "secret")
+	store := mockdb.NewMockStore(ctrl)
+	server := newOIDCTestServer(t, store, provider, false)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/beta/login/oidc/callback?error=access_denied&state=state", nil)
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestOIDCNotConfigured(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewTestServer(t, mockdb.NewMockStore(ctrl), nil)
+
+	for _, path := range []string{"/beta/login/oidc", "/beta/login/oidc/callback?code=code&state=state"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		server.Router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+	}
+}
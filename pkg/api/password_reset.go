@@ -0,0 +1,178 @@
+package api
+
+import (
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/jackc/pgx/v5"
+)
+
+// Same response whether the email belongs to an account or not, so that it cannot be used to find accounts
+const passwordResetMessage = "if the email belongs to an account, a password reset link has been sent"
+
+// Page of the link in the password reset email, for users without a front-end. The form posts to /password_reset/confirm.
+var passwordResetPage = template.Must(template.New("password_reset").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Reset your gobudget password</title></head>
+<body>
+<form method="post" action="password_reset/confirm">
+<input type="hidden" name="token" value="{{.}}">
+<label>New password <input type="password" name="password" autocomplete="new-password" required></label>
+<button type="submit">Reset password</button>
+</form>
+</body>
+</html>
+`))
+
+// requestPasswordReset godoc
+//
+//	@Summary	Request a password reset
+//	@Schemes
+//	@Description	Send a single-use password reset link to the email of an account. The response does not tell whether the email belongs to an account.
+//	@Tags			Security
+//	@Accept			json
+//	@Param			email	body	passwordResetRequest	true	"Email of the account"
+//	@Produce		json
+//	@Success		202	{string}	string	"if the email belongs to an account, a password reset link has been sent"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/password_reset [post]
+func (s *Server) requestPasswordReset(ctx *gin.Context) {
+
+	var rqst passwordResetRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	u, err := s.db.GetUserByEmail(ctx, rqst.Email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusAccepted, gin.H{"msg": passwordResetMessage})
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	taskPayload := &worker.SendEmailPayload{
+		Username: u.Username,
+		Email:    u.Email,
+	}
+	if err := s.taskDistributor.DistributeSendEmail(ctx, taskPayload, worker.TaskSendPasswordResetEmail); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": passwordResetMessage})
+}
+
+// getPasswordResetPage godoc
+//
+//	@Summary	Password reset page
+//	@Schemes
+//	@Description	Serve the page of the password reset link: a form which posts the token and a new password to /password_reset/confirm.
+//	@Tags			Security
+//	@Param			token	query	string	true	"Password reset token"
+//	@Produce		html
+//	@Success		200	{string}	string	"password reset page"
+//	@Failure		400	{object}	HTTPError
+//	@Router			/password_reset [get]
+func (s *Server) getPasswordResetPage(ctx *gin.Context) {
+
+	var rqst passwordResetPageRequest
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	// the token is in the URL, which must not leak to other sites
+	ctx.Header("Referrer-Policy", "no-referrer")
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	if err := passwordResetPage.Execute(ctx.Writer, rqst.Token); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+// confirmPasswordReset godoc
+//
+//	@Summary	Reset a password
+//	@Schemes
+//	@Description	Set a new password using the token of a password reset link. All existing sessions of the user are blocked, and their personal access tokens revoked.
+//	@Description	The password must meet the password policy, otherwise the rules it fails are returned.
+//	@Tags			Security
+//	@Accept			json,x-www-form-urlencoded
+//	@Param			reset	body	passwordResetConfirmRequest	true	"Reset token and new password"
+//	@Produce		json
+//	@Success		200	{string}	string	"password has been reset"
+//	@Failure		400	{object}	PasswordPolicyError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/password_reset/confirm [post]
+func (s *Server) confirmPasswordReset(ctx *gin.Context) {
+
+	// JSON, or the form of the password reset page
+	var rqst passwordResetConfirmRequest
+	bind := ctx.ShouldBindJSON
+	if ctx.ContentType() == binding.MIMEPOSTForm {
+		bind = ctx.ShouldBind
+	}
+	if err := bind(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	reset, err := s.db.GetPasswordResetByHash(ctx, token.HashOpaqueToken(rqst.Token))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired password reset link"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if reset.Used || time.Now().After(reset.ExpiresAt) {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired password reset link"))
+		return
+	}
+	if !s.checkPasswordPolicy(ctx, rqst.Password, reset.Username) {
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(rqst.Password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	err = s.db.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		ResetID:  reset.ID,
+		Username: reset.Username,
+		Password: hashedPassword,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrPasswordResetUsed) {
+			ctx.JSON(http.StatusBadRequest, errorResponse("invalid or expired password reset link"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	slog.Info("Reset password", "user", reset.Username)
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "password has been reset"})
+}
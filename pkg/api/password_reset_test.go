@@ -0,0 +1,270 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequestPasswordResetAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), user.Email).
+					Times(1).
+					Return(user, nil)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), worker.TaskSendPasswordResetEmail).
+					Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name: "UnknownEmail",
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), user.Email).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			dist := mockdb.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, dist)
+
+			server := NewTestServer(t, store, dist)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"email": user.Email})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/beta/password_reset", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+
+			// The response must not tell whether the account exists
+			require.Equal(t, http.StatusAccepted, recorder.Code)
+			require.JSONEq(t, `{"msg":"`+passwordResetMessage+`"}`, recorder.Body.String())
+		})
+	}
+}
+
+func TestConfirmPasswordResetAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+	resetToken, resetTokenHash, err := token.NewOpaqueToken("")
+	require.NoError(t, err)
+	newPassword := util.RandomPassword()
+
+	reset := db.PasswordReset{
+		ID:        1,
+		Username:  user.Username,
+		TokenHash: resetTokenHash,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	testCases := []struct {
+		name          string
+		password      string
+		form          bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPasswordResetByHash(gomock.Any(), resetTokenHash).
+					Times(1).
+					Return(reset, nil)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ResetPasswordTxParams) error {
+						require.Equal(t, reset.ID, arg.ResetID)
+						require.Equal(t, user.Username, arg.Username)
+						require.NoError(t, util.CheckPassword(arg.Password, newPassword))
+						return nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Form",
+			form: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPasswordResetByHash(gomock.Any(), resetTokenHash).
+					Times(1).
+					Return(reset, nil)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Expired",
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := reset
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				store.EXPECT().
+					GetPasswordResetByHash(gomock.Any(), resetTokenHash).
+					Times(1).
+					Return(expired, nil)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyUsed",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPasswordResetByHash(gomock.Any(), resetTokenHash).
+					Times(1).
+					Return(reset, nil)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ErrPasswordResetUsed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "WeakPassword",
+			password: "aaaaaaaaaaaa",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPasswordResetByHash(gomock.Any(), resetTokenHash).
+					Times(1).
+					Return(reset, nil)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var resp PasswordPolicyError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Len(t, resp.Details, 1)
+				require.Equal(t, util.PasswordRuleStrength, resp.Details[0].Rule)
+			},
+		},
+		{
+			name: "UnknownToken",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPasswordResetByHash(gomock.Any(), resetTokenHash).
+					Times(1).
+					Return(db.PasswordReset{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			password := tc.password
+			if password == "" {
+				password = newPassword
+			}
+			var request *http.Request
+			if tc.form {
+				form := url.Values{"token": {resetToken}, "password": {password}}
+				request, err = http.NewRequest(http.MethodPost, "/beta/password_reset/confirm", strings.NewReader(form.Encode()))
+				require.NoError(t, err)
+				request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				data, err := json.Marshal(gin.H{"token": resetToken, "password": password})
+				require.NoError(t, err)
+				request, err = http.NewRequest(http.MethodPost, "/beta/password_reset/confirm", bytes.NewReader(data))
+				require.NoError(t, err)
+			}
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestPasswordResetPageAPI(t *testing.T) {
+
+	server := NewTestServer(t, nil, nil)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/beta/password_reset?token="+url.QueryEscape(`abc"><script>`), nil)
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+	require.Equal(t, "no-referrer", recorder.Header().Get("Referrer-Policy"))
+	require.Contains(t, recorder.Body.String(), `action="password_reset/confirm"`)
+	require.Contains(t, recorder.Body.String(), `value="abc&#34;&gt;&lt;script&gt;"`)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/beta/password_reset", nil)
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func newPersonalAccessTokenResponse(pat db.PersonalAccessToken) personalAccessTokenResponse {
+	resp := personalAccessTokenResponse{
+		ID:        pat.ID,
+		Name:      pat.Name,
+		Scopes:    pat.Scopes,
+		CreatedAt: pat.CreatedAt,
+	}
+	if pat.ExpiresAt.Valid {
+		resp.ExpiresAt = &pat.ExpiresAt.Time
+	}
+	if pat.LastUsedAt.Valid {
+		resp.LastUsedAt = &pat.LastUsedAt.Time
+	}
+	return resp
+}
+
+// getPersonalAccessTokens godoc
+//
+//	@Summary	List personal access tokens
+//	@Schemes
+//	@Description	List the personal access tokens of the authenticated user.
+//	@Tags			User
+//	@Produce		json
+//	@Success		200	{object}	[]personalAccessTokenResponse
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/tokens [get]
+//	@Security		Bearer
+func (s *Server) getPersonalAccessTokens(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	pats, err := s.db.GetPersonalAccessTokens(ctx, authz_payload.Username)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	resp := make([]personalAccessTokenResponse, len(pats))
+	for i := range pats {
+		resp[i] = newPersonalAccessTokenResponse(pats[i])
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// createPersonalAccessToken godoc
+//
+//	@Summary	Create a personal access token
+//	@Schemes
+//	@Description	Create a long-lived token for scripts and integrations. Scopes are read, write and budget:{budget_id}. The token is only shown in this response.
+//	@Tags			User
+//	@Accept			json
+//	@Param			token	body	personalAccessTokenRequest	true	"Token details"
+//	@Produce		json
+//	@Success		201	{object}	personalAccessTokenResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/tokens [post]
+//	@Security		Bearer
+func (s *Server) createPersonalAccessToken(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	var rqst personalAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	// Validations
+	if err := token.ValidateScopes(rqst.Scopes); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	if rqst.ExpiresAt != nil && rqst.ExpiresAt.Before(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, errorResponse("expiration must be in the future"))
+		return
+	}
+	if err := s.ValidateBudgetScopes(ctx, authz_payload.Username, rqst.Scopes); err != nil {
+		return
+	}
+
+	plainToken, tokenHash, err := token.NewPersonalAccessToken()
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	arg := db.CreatePersonalAccessTokenParams{
+		Username:  authz_payload.Username,
+		Name:      rqst.Name,
+		TokenHash: tokenHash,
+		Scopes:    rqst.Scopes,
+	}
+	if rqst.ExpiresAt != nil {
+		arg.ExpiresAt = pgtype.Timestamptz{Time: *rqst.ExpiresAt, Valid: true}
+	}
+	pat, err := s.db.CreatePersonalAccessToken(ctx, arg)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	slog.Info("Created personal access token", "user", pat.Username, "token", pat.ID)
+
+	resp := newPersonalAccessTokenResponse(pat)
+	resp.Token = plainToken
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// deletePersonalAccessToken godoc
+//
+//	@Summary	Revoke a personal access token
+//	@Schemes
+//	@Description	Revoke a personal access token of the authenticated user. The token stops working immediately.
+//	@Tags			User
+//	@Param			token_id	path	string	true	"Token ID"
+//	@Produce		json
+//	@Success		200	{string}	string	"token revoked"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/tokens/{token_id} [delete]
+//	@Security		Bearer
+func (s *Server) deletePersonalAccessToken(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	var tokenId PersonalAccessTokenId
+	if err := ctx.ShouldBindUri(&tokenId); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	tokenUuid, err := uuid.Parse(tokenId.TokenId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	deleted, err := s.db.DeletePersonalAccessToken(ctx, db.DeletePersonalAccessTokenParams{
+		ID:       tokenUuid,
+		Username: authz_payload.Username,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if deleted == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse("token not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "token revoked"})
+}
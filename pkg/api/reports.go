@@ -0,0 +1,235 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/money"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// getNetWorth godoc
+//
+//	@Summary	Get the net worth of a budget
+//	@Schemes
+//	@Description	Get the net worth of a budget on a date: the balances of its accounts, plus the market value of the holdings of investment accounts.
+//	@Description	The balances on a past date leave out the transactions after it. The balance of an investment account is its cash: buys are paid from it, sells and dividends paid into it.
+//	@Description	Holdings are valued at the last price of each security on or before the date. Accounts with a positive total are assets, the others liabilities.
+//	@Description	The totals are in the currency of the budget, at the exchange rates of the date. Prices are in the currency of the budget, which investment accounts are in.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Param			date		query	string	false	"Date of the net worth (YYYY-MM-DD), today if left out"
+//	@Tags			Reports
+//	@Produce		json
+//	@Success		200	{object}	netWorthResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/reports/net-worth [get]
+func (s *Server) getNetWorth(ctx *gin.Context) {
+
+	budget, err := s.getOwnedBudget(ctx)
+	if err != nil {
+		return
+	}
+	budgetId := budget.ID
+
+	date, err := bindAsOfDate(ctx)
+	if err != nil {
+		return
+	}
+
+	accounts, err := s.db.GetAccounts(ctx, budgetId)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	transactions, err := s.db.GetBudgetInvestmentTransactions(ctx, db.GetBudgetInvestmentTransactionsParams{
+		BudgetID: budgetId,
+		Date:     date,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	prices, err := s.db.GetLatestSecurityPrices(ctx, db.GetLatestSecurityPricesParams{
+		BudgetID: budgetId,
+		Date:     date,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	// the balances are today's, so the transactions after the date are taken back out
+	changes, err := s.db.GetAccountChangesAfter(ctx, db.GetAccountChangesAfterParams{
+		BudgetID: budgetId,
+		Date:     date,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	rates, err := s.loadExchangeRates(ctx, budget, accounts, date)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	accountTransactions := make(map[uuid.UUID][]db.InvestmentTransaction)
+	for _, t := range transactions {
+		accountTransactions[t.AccountID] = append(accountTransactions[t.AccountID], t)
+	}
+	laterChanges := make(map[uuid.UUID]int64, len(changes))
+	for _, c := range changes {
+		laterChanges[c.AccountID] = c.Amount
+	}
+
+	rsp := netWorthResponse{
+		Date:         date,
+		CurrencyCode: budget.CurrencyCode,
+		Accounts:     make([]netWorthAccountResponse, len(accounts)),
+	}
+	for i, a := range accounts {
+		account := netWorthAccountResponse{
+			AccountID:    a.ID,
+			Name:         a.Name,
+			Type:         a.Type,
+			CurrencyCode: a.CurrencyCode,
+		}
+		cash, err := investmentCash(accountTransactions[a.ID], date.Time)
+		if err == nil {
+			account.Balance, err = money.Sub(a.Balance, laterChanges[a.ID])
+		}
+		if err == nil {
+			account.Balance, err = money.Add(account.Balance, cash)
+		}
+		if err != nil {
+			conversionErrorResponse(ctx, err)
+			return
+		}
+		for _, h := range valueHoldings(computeHoldings(accountTransactions[a.ID], date.Time), prices) {
+			if account.MarketValue, err = money.Add(account.MarketValue, h.MarketValue); err != nil {
+				break
+			}
+		}
+		var value int64
+		if err == nil {
+			value, err = money.Add(account.Balance, account.MarketValue)
+		}
+		if err == nil {
+			account.Total, err = rates.Convert(value, a.CurrencyCode, budget.CurrencyCode, date.Time)
+		}
+		if err == nil {
+			if account.Total > 0 {
+				rsp.Assets, err = money.Add(rsp.Assets, account.Total)
+			} else {
+				rsp.Liabilities, err = money.Add(rsp.Liabilities, account.Total)
+			}
+		}
+		if err != nil {
+			conversionErrorResponse(ctx, err)
+			return
+		}
+		rsp.Accounts[i] = account
+	}
+	rsp.NetWorth, err = money.Add(rsp.Assets, rsp.Liabilities)
+	if err != nil {
+		conversionErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// getFXGains godoc
+//
+//	@Summary	Get the exchange gains and losses of a budget
+//	@Schemes
+//	@Description	List the transfers between accounts in different currencies. Both sides are converted into the currency of the budget
+//	@Description	at the rates of the date of the transfer, and the difference between what was received and what was sent is the gain or loss.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Tags			Reports
+//	@Produce		json
+//	@Success		200	{object}	fxGainsResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/reports/fx-gains [get]
+func (s *Server) getFXGains(ctx *gin.Context) {
+
+	budget, err := s.getOwnedBudget(ctx)
+	if err != nil {
+		return
+	}
+
+	accounts, err := s.db.GetAccounts(ctx, budget.ID)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	transfers, err := s.db.GetCurrencyTransfers(ctx, budget.ID)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	rates, err := s.loadExchangeRates(ctx, budget, accounts, pgtype.Date{})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	currencies := make(map[uuid.UUID]string, len(accounts))
+	for _, a := range accounts {
+		currencies[a.ID] = a.CurrencyCode
+	}
+
+	rsp := fxGainsResponse{
+		CurrencyCode: budget.CurrencyCode,
+		Transfers:    make([]fxTransferResponse, len(transfers)),
+	}
+	for i, t := range transfers {
+		transfer := fxTransferResponse{
+			TransactionID:     t.ID,
+			Date:              t.Date,
+			AccountID:         t.AccountID,
+			CurrencyCode:      currencies[t.AccountID],
+			Amount:            t.Amount,
+			TransferAccountID: t.TransferAccountID,
+			TransferCurrency:  currencies[t.TransferAccountID],
+			TransferAmount:    t.TransferAmount,
+		}
+		transfer.Sent, err = rates.Convert(transfer.Amount, transfer.CurrencyCode, budget.CurrencyCode, t.Date.Time)
+		if err != nil {
+			conversionErrorResponse(ctx, err)
+			return
+		}
+		transfer.Received, err = rates.Convert(transfer.TransferAmount, transfer.TransferCurrency, budget.CurrencyCode, t.Date.Time)
+		if err != nil {
+			conversionErrorResponse(ctx, err)
+			return
+		}
+		// one side is negative, the money leaving an account
+		transfer.Gain, err = money.Add(transfer.Sent, transfer.Received)
+		if err == nil {
+			rsp.Total, err = money.Add(rsp.Total, transfer.Gain)
+		}
+		if err != nil {
+			conversionErrorResponse(ctx, err)
+			return
+		}
+		rsp.Transfers[i] = transfer
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -16,41 +16,75 @@ import (
 // On success, writes the budgetId to the pointer specified.
 func (s *Server) ValidateBudgetOwnership(ctx *gin.Context, budgetId *uuid.UUID) error {
 
+	budget, err := s.getOwnedBudget(ctx)
+	if err != nil {
+		return err
+	}
+	*budgetId = budget.ID
+
+	return nil
+}
+
+// Gets the budget in the URL, if the user in the context owns it. Writes the error response if not.
+func (s *Server) getOwnedBudget(ctx *gin.Context) (db.Budget, error) {
+
 	// Get the authenticated user
 	k, exists := ctx.Get("authz_payload")
 	if !exists {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
-		return errors.New("authz_payload not set")
+		return db.Budget{}, errors.New("authz_payload not set")
 	}
 	authz_payload := k.(*token.TokenPayload)
 
 	var rqst BudgetId
 	if err := ctx.ShouldBindUri(&rqst); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
-		return err
+		return db.Budget{}, err
 	}
 	b, err := uuid.Parse(rqst.BudgetId)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
-		return err
+		return db.Budget{}, err
 	}
 
 	// Ensure the user matches the budget
-	_, err = s.db.GetBudget(ctx, db.GetBudgetParams{
+	budget, err := s.db.GetBudget(ctx, db.GetBudgetParams{
 		ID:            b,
 		OwnerUsername: authz_payload.Username,
 	})
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse("budget not found or user has no permission"))
-			return err
+			return db.Budget{}, err
 		}
 		slog.Error(err.Error())
 		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
-		return err
+		return db.Budget{}, err
 	}
 
-	*budgetId = b
+	return budget, nil
+}
+
+// Validate that the user owns every budget that the scopes restrict a token to.
+// On failure, writes the response.
+func (s *Server) ValidateBudgetScopes(ctx *gin.Context, username string, scopes []string) error {
+
+	restricted := token.TokenPayload{Scopes: scopes}
+	for _, b := range restricted.BudgetScopes() {
+		_, err := s.db.GetBudget(ctx, db.GetBudgetParams{
+			ID:            b,
+			OwnerUsername: username,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				ctx.JSON(http.StatusBadRequest, errorResponse("budget "+b.String()+" not found or user has no permission"))
+				return err
+			}
+			slog.Error(err.Error())
+			ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+			return err
+		}
+	}
 
 	return nil
 }
@@ -1,14 +1,21 @@
 package api
 
 import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/guerzon/gobudget-api/docs"
 	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/limiter"
+	"github.com/guerzon/gobudget-api/pkg/oidc"
 	"github.com/guerzon/gobudget-api/pkg/token"
 	"github.com/guerzon/gobudget-api/pkg/util"
 	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/redis/go-redis/v9"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -19,32 +26,191 @@ type Server struct {
 	db              db.Store
 	tokenBuilder    token.Builder
 	taskDistributor worker.TaskDistributor
+	loginLimiter    limiter.LoginLimiter
+	rateLimiter     limiter.RateLimiter
+	passwordPolicy  util.PasswordPolicy
+	oidcProvider    *oidc.Provider
+}
+
+// Token types of the TOKEN_TYPE setting
+const (
+	tokenTypeJWT          = "jwt"
+	tokenTypePasetoLocal  = "paseto_local"
+	tokenTypePasetoPublic = "paseto_public"
+)
+
+// Builds the token builder of the configuration:
+//   - jwt (default): asymmetric keys if a key directory is set, HS256 with the secret key otherwise
+//   - paseto_local: v4.local with a key derived from the secret key
+//   - paseto_public: v4.public with the active Ed25519 key of the key directory
+func newTokenBuilder(config util.Config) (token.Builder, error) {
+
+	switch config.TokenType {
+	case "", tokenTypeJWT:
+		if config.TokenKeysDir == "" {
+			return token.NewJWTBuilder(config.SecretKey)
+		}
+		keys, err := token.LoadJWTKeys(config.TokenKeysDir)
+		if err != nil {
+			return nil, err
+		}
+		return token.NewAsymmetricJWTBuilder(keys, config.TokenActiveKeyID, config.TokenKeyGracePeriod)
+
+	case tokenTypePasetoLocal:
+		if len(config.SecretKey) < 32 {
+			return nil, fmt.Errorf("invalid secret key size: must be at least 32 characters")
+		}
+		key := sha256.Sum256([]byte(config.SecretKey))
+		return token.NewPasetoLocalBuilder(key[:])
+
+	case tokenTypePasetoPublic:
+		keys, err := token.LoadJWTKeys(config.TokenKeysDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			if k.ID == config.TokenActiveKeyID {
+				privateKey, ok := k.PrivateKey.(ed25519.PrivateKey)
+				if !ok {
+					return nil, fmt.Errorf("key %s is not an Ed25519 private key", k.ID)
+				}
+				return token.NewPasetoPublicBuilder(privateKey)
+			}
+		}
+		return nil, fmt.Errorf("active key %s not found", config.TokenActiveKeyID)
+
+	default:
+		return nil, fmt.Errorf("unsupported token type %s", config.TokenType)
+	}
 }
 
 func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) (*Server, error) {
 
 	// Create a new token builder
-	jwtTokenBuilder, err := token.NewJWTBuilder(config.SecretKey)
+	tokenBuilder, err := newTokenBuilder(config)
 	if err != nil {
-		slog.Error("cannot create a new JWT token builder")
+		slog.Error("cannot create a new token builder")
+		return nil, err
+	}
+
+	loginPolicy := limiter.LoginPolicy{
+		LockoutThreshold: config.LoginLockoutThreshold,
+		LockoutDuration:  config.LoginLockoutDuration,
+	}
+	// Failed logins and rate limits are shared through Redis, or kept in memory without it
+	var loginLimiter limiter.LoginLimiter
+	var rateLimiter limiter.RateLimiter
+	if config.RedisAddress != "" {
+		redisClient := redis.NewClient(&redis.Options{Addr: config.RedisAddress})
+		loginLimiter = limiter.NewRedisLoginLimiter(redisClient, loginPolicy)
+		rateLimiter = limiter.NewRedisRateLimiter(redisClient)
+	} else {
+		loginLimiter = limiter.NewMemoryLoginLimiter(loginPolicy)
+		rateLimiter = limiter.NewMemoryRateLimiter()
+	}
+
+	// Password policy, with the breached passwords loaded once at startup
+	passwordPolicy := util.PasswordPolicy{
+		MinLength:   config.PasswordMinLength,
+		MaxLength:   config.PasswordMaxLength,
+		MinStrength: config.PasswordMinStrength,
+	}
+	if config.PasswordBreachedFile != "" {
+		passwordPolicy.Breached, err = util.LoadBreachedPasswords(config.PasswordBreachedFile)
+		if err != nil {
+			slog.Error("cannot load the breached passwords")
+			return nil, err
+		}
+		slog.Info("Loaded breached passwords", "count", passwordPolicy.Breached.Len())
+	}
+
+	// Login with an external identity provider, if one is configured
+	var oidcProvider *oidc.Provider
+	if config.OIDCIssuerURL != "" {
+		oidcProvider, err = oidc.NewProvider(oidc.Config{
+			IssuerURL:    config.OIDCIssuerURL,
+			ClientID:     config.OIDCClientID,
+			ClientSecret: config.OIDCClientSecret,
+			RedirectURL:  config.OIDCRedirectURL,
+			Scopes:       strings.Fields(config.OIDCScopes),
+		})
+		if err != nil {
+			slog.Error("cannot create the OpenID Connect provider")
+			return nil, err
+		}
 	}
 
 	// create a new server to return
 	server := &Server{
 		db:              store,
 		config:          config,
-		tokenBuilder:    jwtTokenBuilder,
+		tokenBuilder:    tokenBuilder,
 		taskDistributor: taskDistributor,
+		loginLimiter:    loginLimiter,
+		rateLimiter:     rateLimiter,
+		passwordPolicy:  passwordPolicy,
+		oidcProvider:    oidcProvider,
+	}
+
+	// Rate limits: public endpoints per client IP, authenticated ones per session or token,
+	// and expensive ones per user across all their clients
+	publicLimit, err := server.rateLimit("public", config.RateLimitPublic, rateLimitByIP)
+	if err != nil {
+		return nil, err
+	}
+	authenticatedLimit, err := server.rateLimit("authenticated", config.RateLimitAuthenticated, rateLimitByToken)
+	if err != nil {
+		return nil, err
+	}
+	expensiveLimit, err := server.rateLimit("expensive", config.RateLimitExpensive, rateLimitByUser)
+	if err != nil {
+		return nil, err
 	}
 
 	Router := gin.Default()
 
 	// User facing endpoints, auth required
-	beta_users := Router.Group("beta").Use(AuthMiddleware(server.tokenBuilder))
+	beta_users := Router.Group("beta").Use(AuthMiddleware(server.tokenBuilder, server.db), RequirePurpose(token.PurposeAccess), authenticatedLimit)
 	{
 		// User profile actions
-		beta_users.PUT("/user", server.updateUser)
-		beta_users.DELETE("/user", server.deleteUser)
+		beta_users.PUT("/user", RequireFullAccess(), server.updateUser)
+		beta_users.DELETE("/user", RequireFullAccess(), server.deleteUser)
+		beta_users.POST("/user/email", RequireFullAccess(), server.requestEmailChange)
+
+		// sessions
+		beta_users.POST("/logout", server.logout)
+		beta_users.GET("/user/sessions", RequireFullAccess(), server.getSessions)
+		beta_users.DELETE("/user/sessions", RequireFullAccess(), server.revokeSessions)
+		beta_users.DELETE("/user/sessions/:session_id", RequireFullAccess(), server.revokeSession)
+		beta_users.GET("/user/logins", RequireFullAccess(), server.getLoginHistory)
+
+		// two-factor authentication
+		beta_users.POST("/user/2fa/totp", RequireFullAccess(), server.enrollTOTP)
+		beta_users.POST("/user/2fa/totp/confirm", RequireFullAccess(), server.confirmTOTP)
+		beta_users.POST("/user/2fa/disable", RequireFullAccess(), server.disableTwoFactor)
+
+		// personal access tokens
+		beta_users.GET("/user/tokens", RequireFullAccess(), server.getPersonalAccessTokens)
+		beta_users.POST("/user/tokens", RequireFullAccess(), server.createPersonalAccessToken)
+		beta_users.DELETE("/user/tokens/:token_id", RequireFullAccess(), server.deletePersonalAccessToken)
+
+		// OAuth clients and consent
+		beta_users.GET("/oauth/clients", RequireFullAccess(), server.getOAuthClients)
+		beta_users.POST("/oauth/clients", RequireFullAccess(), server.createOAuthClient)
+		beta_users.DELETE("/oauth/clients/:client_id", RequireFullAccess(), server.deleteOAuthClient)
+		beta_users.GET("/oauth/authorize", RequireFullAccess(), server.getAuthorize)
+		beta_users.POST("/oauth/authorize", RequireFullAccess(), server.postAuthorize)
+
+		// administration
+		admin := []gin.HandlerFunc{RequireFullAccess(), RequireRole(token.RoleAdmin)}
+		beta_users.GET("/admin/users", append(admin, server.listUsers)...)
+		beta_users.GET("/admin/users/:username", append(admin, server.getUser)...)
+		beta_users.DELETE("/admin/users/:username", append(admin, server.adminDeleteUser)...)
+		beta_users.POST("/admin/users/:username/unlock", append(admin, server.unlockUser)...)
+		beta_users.POST("/admin/users/:username/verify_email", append(admin, server.verifyUserEmail)...)
+		beta_users.DELETE("/admin/users/:username/sessions", append(admin, server.blockUserSessions)...)
+		beta_users.DELETE("/admin/users/:username/2fa", append(admin, server.resetUserTwoFactor)...)
+		beta_users.GET("/admin/audit_log", append(admin, server.getAuditLog)...)
 
 		// budgets
 		beta_users.GET("/budgets", server.getBudgets)
@@ -58,6 +224,26 @@ func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDi
 		beta_users.POST("/budgets/:budget_id/accounts", server.createAccount)
 		beta_users.PUT("/budgets/:budget_id/accounts/:account_id", server.updateAccount)
 		beta_users.DELETE("/budgets/:budget_id/accounts/:account_id", server.deleteAccount)
+		beta_users.GET("/budgets/:budget_id/accounts/:account_id/loan", server.getLoan)
+		beta_users.PUT("/budgets/:budget_id/accounts/:account_id/loan", server.updateLoan)
+		beta_users.DELETE("/budgets/:budget_id/accounts/:account_id/loan", server.deleteLoan)
+
+		// investments
+		beta_users.GET("/budgets/:budget_id/accounts/:account_id/investment-transactions", server.getInvestmentTransactions)
+		beta_users.POST("/budgets/:budget_id/accounts/:account_id/investment-transactions", server.createInvestmentTransaction)
+		beta_users.GET("/budgets/:budget_id/accounts/:account_id/holdings", server.getHoldings)
+		beta_users.GET("/budgets/:budget_id/prices", server.getSecurityPrices)
+		beta_users.PUT("/budgets/:budget_id/prices", server.updateSecurityPrices)
+		beta_users.POST("/budgets/:budget_id/prices/import", server.importSecurityPrices)
+
+		// reports
+		beta_users.GET("/budgets/:budget_id/reports/net-worth", expensiveLimit, server.getNetWorth)
+		beta_users.GET("/budgets/:budget_id/reports/fx-gains", expensiveLimit, server.getFXGains)
+
+		// exchange rates
+		beta_users.GET("/budgets/:budget_id/exchange-rates", server.getExchangeRates)
+		beta_users.PUT("/budgets/:budget_id/exchange-rates", server.updateExchangeRates)
+		beta_users.POST("/budgets/:budget_id/exchange-rates/import", server.importExchangeRates)
 
 		// category groups
 		beta_users.GET("/budgets/:budget_id/category-groups", server.getCategoryGroups)
@@ -66,7 +252,7 @@ func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDi
 		beta_users.DELETE("/budgets/:budget_id/category-groups/:category_group_id", server.deleteCategoryGroup)
 
 		// categories
-		beta_users.GET("/budgets/:budget_id/categories", server.getCategories)
+		beta_users.GET("/budgets/:budget_id/categories", expensiveLimit, server.getCategories)
 		beta_users.POST("/budgets/:budget_id/categories/:category_group_id", server.createCategory)
 		beta_users.PUT("/budgets/:budget_id/categories/:category_id", server.updateCategory)
 		beta_users.DELETE("/budgets/:budget_id/categories/:category_id", server.deleteCategory)
@@ -79,29 +265,78 @@ func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDi
 		beta_users.DELETE("/budgets/:budget_id/payees/:payee_id", server.deletePayee)
 
 		// transactions
-		beta_users.GET("/budgets/:budget_id/transactions", server.getTransactions)
+		beta_users.GET("/budgets/:budget_id/transactions", expensiveLimit, server.getTransactions)
 		beta_users.GET("/budgets/:budget_id/transactions/:transaction_id", server.getTransaction)
 		beta_users.POST("/budgets/:budget_id/transactions", server.createTransaction)
 		// beta_users.PUT("/budgets/:budget_id/transactions/:transaction_id", server.updateTransaction)
+
+		// webhooks
+		beta_users.GET("/budgets/:budget_id/webhooks", server.getWebhooks)
+		beta_users.POST("/budgets/:budget_id/webhooks", server.createWebhook)
+		beta_users.PUT("/budgets/:budget_id/webhooks/:webhook_id", server.updateWebhook)
+		beta_users.DELETE("/budgets/:budget_id/webhooks/:webhook_id", server.deleteWebhook)
+		beta_users.GET("/budgets/:budget_id/webhooks/:webhook_id/deliveries", server.getWebhookDeliveries)
+		beta_users.POST("/budgets/:budget_id/webhooks/:webhook_id/deliveries/:delivery_id/redeliver", server.redeliverWebhook)
 	}
 
 	// No auth required
-	beta_public := Router.Group("beta")
+	beta_public := Router.Group("beta").Use(publicLimit)
 	{
 		// Signup flow
 		beta_public.POST("/user", server.createUser)
 		beta_public.GET("/verify_email", server.verifyEmail)
 
+		// Email change flow, from the links sent to the new and the current address
+		beta_public.GET("/email_change/confirm", server.getConfirmEmailChangePage)
+		beta_public.POST("/email_change/confirm", server.confirmEmailChange)
+		beta_public.GET("/email_change/cancel", server.getCancelEmailChangePage)
+		beta_public.POST("/email_change/cancel", server.cancelEmailChange)
+
+		// Revoke link of the alert for logins from new devices
+		beta_public.GET("/sessions/revoke", server.getRevokeLoginPage)
+		beta_public.POST("/sessions/revoke", server.revokeLoginSession)
+
 		beta_public.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
 		beta_public.POST("/login", server.login)
+		beta_public.POST("/login/2fa", server.loginTwoFactor)
+		beta_public.POST("/login/magic_link", server.requestMagicLink)
+		beta_public.GET("/login/magic_link/verify", server.verifyMagicLink)
+		beta_public.GET("/login/oidc", server.loginOIDC)
+		beta_public.GET("/login/oidc/callback", server.oidcCallback)
+
+		// Password reset flow
+		beta_public.POST("/password_reset", server.requestPasswordReset)
+		beta_public.GET("/password_reset", server.getPasswordResetPage)
+		beta_public.POST("/password_reset/confirm", server.confirmPasswordReset)
 		beta_public.POST("/renew_token", server.renewToken)
+
+		// OAuth token endpoint, clients authenticate themselves
+		beta_public.POST("/oauth/token", server.oauthToken)
 	}
+
+	// Public keys for verifying tokens
+	Router.GET("/.well-known/jwks.json", server.getJWKS)
+
 	server.Router = Router
 
 	return server, nil
 }
 
+// Builds the middleware of a rate limit setting, e.g. 100/1m. Limits left empty are disabled.
+func (server *Server) rateLimit(group string, setting string, key func(ctx *gin.Context) string) (gin.HandlerFunc, error) {
+
+	if setting == "" {
+		return func(ctx *gin.Context) { ctx.Next() }, nil
+	}
+	policy, err := limiter.ParsePolicy(setting)
+	if err != nil {
+		return nil, err
+	}
+
+	return RateLimitMiddleware(server.rateLimiter, group, policy, key), nil
+}
+
 func errorResponse(msg string) gin.H {
 	return gin.H{
 		"error": msg,
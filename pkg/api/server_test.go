@@ -1,12 +1,18 @@
 package api
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
 	"github.com/guerzon/gobudget-api/pkg/util"
 	"github.com/guerzon/gobudget-api/pkg/worker"
 	"github.com/stretchr/testify/require"
@@ -14,8 +20,9 @@ import (
 
 func NewTestServer(t *testing.T, store db.Store, taskDistributor worker.TaskDistributor) *Server {
 	config := util.Config{
-		SecretKey:           util.RandomString(32, ""),
-		AccessTokenDuration: time.Minute * 15,
+		SecretKey:            util.RandomString(32, ""),
+		AccessTokenDuration:  time.Minute * 15,
+		RefreshTokenDuration: time.Hour,
 	}
 
 	testServer, err := NewServer(config, store, taskDistributor)
@@ -24,6 +31,70 @@ func NewTestServer(t *testing.T, store db.Store, taskDistributor worker.TaskDist
 	return testServer
 }
 
+func TestNewTokenBuilder(t *testing.T) {
+
+	// Write an Ed25519 key
+	dir := t.TempDir()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "key1.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		config  util.Config
+		builder token.Builder
+		wantErr bool
+	}{
+		{
+			name:    "Default",
+			config:  util.Config{SecretKey: util.RandomString(32, "")},
+			builder: &token.JWTBuilder{},
+		},
+		{
+			name:    "AsymmetricJWT",
+			config:  util.Config{TokenType: "jwt", TokenKeysDir: dir, TokenActiveKeyID: "key1"},
+			builder: &token.AsymmetricJWTBuilder{},
+		},
+		{
+			name:    "PasetoLocal",
+			config:  util.Config{TokenType: "paseto_local", SecretKey: util.RandomString(32, "")},
+			builder: &token.PasetoLocalBuilder{},
+		},
+		{
+			name:    "PasetoPublic",
+			config:  util.Config{TokenType: "paseto_public", TokenKeysDir: dir, TokenActiveKeyID: "key1"},
+			builder: &token.PasetoPublicBuilder{},
+		},
+		{
+			name:    "ShortSecretKey",
+			config:  util.Config{TokenType: "paseto_local", SecretKey: util.RandomString(16, "")},
+			wantErr: true,
+		},
+		{
+			name:    "UnknownType",
+			config:  util.Config{TokenType: "macaroon"},
+			wantErr: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			builder, err := newTokenBuilder(tc.config)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, tc.builder, builder)
+		})
+	}
+}
+
 // entrypoint for all unit tests in a Golang package
 func TestMain(m *testing.M) {
 
@@ -47,3 +118,22 @@ func buildTestUser(t *testing.T) (db.User, string) {
 
 	return charles, plainPassword
 }
+
+func TestNewServerBreachedPasswords(t *testing.T) {
+
+	config := util.Config{
+		SecretKey:            util.RandomString(32, ""),
+		PasswordBreachedFile: filepath.Join(t.TempDir(), "missing.txt"),
+	}
+	_, err := NewServer(config, nil, nil)
+	require.Error(t, err)
+
+	// SHA-1 of "correct horse battery staple"
+	config.PasswordBreachedFile = filepath.Join(t.TempDir(), "breached.txt")
+	err = os.WriteFile(config.PasswordBreachedFile, []byte("ABF7AAD6438836DBE526AA231ABDE2D0EEF74D42:3\n"), 0600)
+	require.NoError(t, err)
+	server, err := NewServer(config, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, server.passwordPolicy.Breached.Len())
+	require.True(t, server.passwordPolicy.Breached.Contains("correct horse battery staple"))
+}
@@ -0,0 +1,350 @@
+package api
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Page of the revoke link in the new login email. Following the link only asks for a confirmation, so that mail
+// scanners opening links do not revoke sessions; the form posts the token to /sessions/revoke.
+var revokeLoginPage = template.Must(template.New("revoke_login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Log out a gobudget session</title></head>
+<body>
+<form method="post" action="revoke">
+<input type="hidden" name="token" value="{{.}}">
+<p>Log out the session of the new login?</p>
+<button type="submit">Log out the session</button>
+</form>
+</body>
+</html>
+`))
+
+// getSessions godoc
+//
+//	@Summary	List sessions
+//	@Schemes
+//	@Description	List the active sessions of the authenticated user, i.e. the devices and apps where they are logged in.
+//	@Tags			User
+//	@Produce		json
+//	@Success		200	{object}	[]sessionResponse
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/sessions [get]
+//	@Security		Bearer
+func (s *Server) getSessions(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	sessions, err := s.db.GetUserSessions(ctx, authz_payload.Username)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	resp := make([]sessionResponse, len(sessions))
+	for i, session := range sessions {
+		resp[i] = sessionResponse{
+			ID:        session.ID,
+			UserAgent: session.UserAgent,
+			ClientIp:  session.ClientIp,
+			Current:   session.ID == authz_payload.SessionID,
+			ExpiresAt: session.ExpiresAt,
+			CreatedAt: session.CreatedAt,
+		}
+		if session.ClientID.Valid {
+			clientId := uuid.UUID(session.ClientID.Bytes)
+			resp[i].OAuthClientID = &clientId
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// revokeSession godoc
+//
+//	@Summary	Revoke a session
+//	@Schemes
+//	@Description	Log out the authenticated user from one of their sessions. The refresh and access tokens of the session stop working immediately.
+//	@Tags			User
+//	@Param			session_id	path	string	true	"Session ID"
+//	@Produce		json
+//	@Success		200	{string}	string	"session revoked"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/sessions/{session_id} [delete]
+//	@Security		Bearer
+func (s *Server) revokeSession(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	var sessionId SessionId
+	if err := ctx.ShouldBindUri(&sessionId); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	sessionUuid, err := uuid.Parse(sessionId.SessionId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	blocked, err := s.db.BlockSession(ctx, db.BlockSessionParams{
+		ID:       sessionUuid,
+		Username: authz_payload.Username,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if blocked == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse("session not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "session revoked"})
+}
+
+// revokeSessions godoc
+//
+//	@Summary	Log out everywhere
+//	@Schemes
+//	@Description	Revoke all sessions of the authenticated user, including the current one and the sessions of OAuth clients.
+//	@Tags			User
+//	@Produce		json
+//	@Success		200	{string}	string	"all sessions revoked"
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/sessions [delete]
+//	@Security		Bearer
+func (s *Server) revokeSessions(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	if err := s.db.BlockUserSessions(ctx, authz_payload.Username); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	slog.Info("Revoked all sessions", "user", authz_payload.Username)
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "all sessions revoked"})
+}
+
+// logout godoc
+//
+//	@Summary	Logout
+//	@Schemes
+//	@Description	Revoke the session of the access token used for the request.
+//	@Tags			Security
+//	@Produce		json
+//	@Success		200	{string}	string	"logged out"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/logout [post]
+//	@Security		Bearer
+func (s *Server) logout(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	// e.g. personal access tokens, which are revoked with their own endpoint
+	if authz_payload.SessionID == uuid.Nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("token does not belong to a session"))
+		return
+	}
+
+	_, err := s.db.BlockSession(ctx, db.BlockSessionParams{
+		ID:       authz_payload.SessionID,
+		Username: authz_payload.Username,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "logged out"})
+}
+
+// Number of logins returned by the login history
+const loginHistoryLimit = 50
+
+// getLoginHistory godoc
+//
+//	@Summary	Login history
+//	@Schemes
+//	@Description	List the recent logins of the authenticated user, successful or not, most recent first.
+//	@Tags			User
+//	@Produce		json
+//	@Success		200	{object}	[]loginEventResponse
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/logins [get]
+//	@Security		Bearer
+func (s *Server) getLoginHistory(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	events, err := s.db.GetLoginEvents(ctx, db.GetLoginEventsParams{
+		Username: authz_payload.Username,
+		Limit:    loginHistoryLimit,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	resp := make([]loginEventResponse, len(events))
+	for i, event := range events {
+		resp[i] = loginEventResponse{
+			Success:   event.Success,
+			UserAgent: event.UserAgent,
+			ClientIp:  event.ClientIp,
+			CreatedAt: event.CreatedAt,
+		}
+		if event.SessionID.Valid {
+			sessionId := uuid.UUID(event.SessionID.Bytes)
+			resp[i].SessionID = &sessionId
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// getRevokeLoginPage godoc
+//
+//	@Summary	Revoke login page
+//	@Schemes
+//	@Description	Serve the page of the revoke link sent in the new login email: a form which asks to confirm, and posts the token to /sessions/revoke.
+//	@Tags			Security
+//	@Param			token	query	string	true	"Token of the revoke link"
+//	@Produce		html
+//	@Success		200	{string}	string	"revoke login page"
+//	@Failure		400	{object}	HTTPError
+//	@Router			/sessions/revoke [get]
+func (s *Server) getRevokeLoginPage(ctx *gin.Context) {
+
+	var rqst revokeLoginRequest
+	if err := ctx.ShouldBindQuery(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	// the token is in the URL, which must not leak to other sites
+	ctx.Header("Referrer-Policy", "no-referrer")
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	if err := revokeLoginPage.Execute(ctx.Writer, rqst.Token); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+// revokeLoginSession godoc
+//
+//	@Summary	Revoke the session of a login
+//	@Schemes
+//	@Description	Log out the session of a login from a new device, with the token of the link sent in the alert email.
+//	@Tags			Security
+//	@Accept			json,x-www-form-urlencoded
+//	@Param			revoke	body	revokeLoginRequest	true	"Token of the revoke link"
+//	@Produce		json
+//	@Success		200	{string}	string	"session revoked"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/sessions/revoke [post]
+func (s *Server) revokeLoginSession(ctx *gin.Context) {
+
+	// JSON, or the form of the revoke login page
+	var rqst revokeLoginRequest
+	bind := ctx.ShouldBindJSON
+	if ctx.ContentType() == binding.MIMEPOSTForm {
+		bind = ctx.ShouldBind
+	}
+	if err := bind(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	event, err := s.db.GetLoginEventByRevokeHash(ctx, pgtype.Text{String: token.HashOpaqueToken(rqst.Token), Valid: true})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse("invalid revoke link"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if !event.SessionID.Valid {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid revoke link"))
+		return
+	}
+
+	blocked, err := s.db.BlockSession(ctx, db.BlockSessionParams{
+		ID:       uuid.UUID(event.SessionID.Bytes),
+		Username: event.Username,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if blocked == 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse("the session has already been revoked"))
+		return
+	}
+
+	slog.Info("Revoked the session of a login from a new device", "user", event.Username)
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "session revoked"})
+}
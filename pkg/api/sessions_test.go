@@ -0,0 +1,431 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestSession(username string) db.Session {
+	return db.Session{
+		ID:        uuid.New(),
+		Username:  username,
+		UserAgent: "test",
+		ClientIp:  "127.0.0.1",
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestLogoutAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	session := newTestSession(username)
+
+	testCases := []struct {
+		name          string
+		sessionId     uuid.UUID
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			sessionId: session.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), session.ID).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					BlockSession(gomock.Any(), db.BlockSessionParams{ID: session.ID, Username: username}).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "RevokedSession",
+			sessionId: session.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				blocked := session
+				blocked.IsBlocked = true
+				store.EXPECT().
+					GetSession(gomock.Any(), session.ID).
+					Times(1).
+					Return(blocked, nil)
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "SessionNotFound",
+			sessionId: session.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), session.ID).
+					Times(1).
+					Return(db.Session{}, pgx.ErrNoRows)
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "NoSession",
+			sessionId: uuid.Nil,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/beta/logout", nil)
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{
+				Username:  username,
+				Duration:  time.Minute,
+				Purpose:   token.PurposeAccess,
+				SessionID: tc.sessionId,
+			})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetSessionsAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	current := newTestSession(username)
+	other := newTestSession(username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetSession(gomock.Any(), current.ID).
+		Times(1).
+		Return(current, nil)
+	store.EXPECT().
+		GetUserSessions(gomock.Any(), username).
+		Times(1).
+		Return([]db.Session{other, current}, nil)
+
+	server := NewTestServer(t, store, nil)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/beta/user/sessions", nil)
+	require.NoError(t, err)
+	accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{
+		Username:  username,
+		Duration:  time.Minute,
+		Purpose:   token.PurposeAccess,
+		SessionID: current.ID,
+	})
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp []sessionResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Len(t, resp, 2)
+	require.Equal(t, other.ID, resp[0].ID)
+	require.False(t, resp[0].Current)
+	require.Equal(t, current.ID, resp[1].ID)
+	require.True(t, resp[1].Current)
+}
+
+func TestRevokeSessionAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	sessionId := uuid.New()
+
+	testCases := []struct {
+		name          string
+		sessionId     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			sessionId: sessionId.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), db.BlockSessionParams{ID: sessionId, Username: username}).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			sessionId: sessionId.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			sessionId: "invalid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, "/beta/user/sessions/"+tc.sessionId, nil)
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetLoginHistoryAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	session := newTestSession(username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetLoginEvents(gomock.Any(), db.GetLoginEventsParams{Username: username, Limit: loginHistoryLimit}).
+		Times(1).
+		Return([]db.LoginEvent{
+			{ID: 2, Username: username, Success: true, UserAgent: "test", ClientIp: "127.0.0.1", SessionID: pgtype.UUID{Bytes: session.ID, Valid: true}, CreatedAt: time.Now()},
+			{ID: 1, Username: username, Success: false, UserAgent: "curl", ClientIp: "192.0.2.1", CreatedAt: time.Now().Add(-time.Minute)},
+		}, nil)
+
+	server := NewTestServer(t, store, nil)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/beta/user/logins", nil)
+	require.NoError(t, err)
+	accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp []loginEventResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Len(t, resp, 2)
+	require.True(t, resp[0].Success)
+	require.Equal(t, session.ID, *resp[0].SessionID)
+	require.False(t, resp[1].Success)
+	require.Nil(t, resp[1].SessionID)
+	require.Equal(t, "192.0.2.1", resp[1].ClientIp)
+}
+
+func TestRevokeLoginSessionAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	sessionId := uuid.New()
+	revokeToken, revokeTokenHash, err := token.NewOpaqueToken("")
+	require.NoError(t, err)
+	event := db.LoginEvent{
+		ID:              1,
+		Username:        username,
+		Success:         true,
+		SessionID:       pgtype.UUID{Bytes: sessionId, Valid: true},
+		RevokeTokenHash: pgtype.Text{String: revokeTokenHash, Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		token         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			token: revokeToken,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginEventByRevokeHash(gomock.Any(), pgtype.Text{String: revokeTokenHash, Valid: true}).
+					Times(1).
+					Return(event, nil)
+				store.EXPECT().
+					BlockSession(gomock.Any(), db.BlockSessionParams{ID: sessionId, Username: username}).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "AlreadyRevoked",
+			token: revokeToken,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginEventByRevokeHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(event, nil)
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "UnknownToken",
+			token: "unknown",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginEventByRevokeHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginEvent{}, pgx.ErrNoRows)
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingToken",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginEventByRevokeHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			form := url.Values{"token": {tc.token}}
+			request, err := http.NewRequest(http.MethodPost, "/beta/sessions/revoke", strings.NewReader(form.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRevokeLoginPageAPI(t *testing.T) {
+
+	// following the link only shows the page, without touching the sessions
+	server := NewTestServer(t, nil, nil)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/beta/sessions/revoke?token="+url.QueryEscape(`abc"><script>`), nil)
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+	require.Equal(t, "no-referrer", recorder.Header().Get("Referrer-Policy"))
+	require.Contains(t, recorder.Body.String(), `<form method="post" action="revoke">`)
+	require.Contains(t, recorder.Body.String(), `value="abc&#34;&gt;&lt;script&gt;"`)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/beta/sessions/revoke", nil)
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -2,8 +2,12 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/worker"
 	"github.com/jackc/pgx/v5"
 	"golang.org/x/exp/slog"
 )
@@ -12,7 +16,7 @@ import (
 //
 //	@Summary	Renew token
 //	@Schemes
-//	@Description	Renew access token using a refresh token.
+//	@Description	Renew access token using a refresh token. The refresh token is rotated: the response contains a new one, and the old one cannot be used again. Presenting an already used refresh token revokes the session.
 //	@Tags			Security
 //	@Accept			json
 //	@Param			refresh_token	body	renewTokenRequest	true	"Refresh token"
@@ -36,12 +40,16 @@ func (s *Server) renewToken(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err.Error()))
 		return
 	}
+	if refreshTokenClaims.Purpose != token.PurposeRefresh {
+		ctx.JSON(http.StatusUnauthorized, errorResponse("not a refresh token"))
+		return
+	}
 
 	// Validate the session in the database
-	session, err := s.db.GetSession(ctx, refreshTokenClaims.ID)
+	session, err := s.db.GetSession(ctx, refreshTokenClaims.SessionID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			ctx.JSON(http.StatusUnauthorized, errorResponse("cannot find session with ID "+refreshTokenClaims.ID.String()))
+			ctx.JSON(http.StatusUnauthorized, errorResponse("cannot find session with ID "+refreshTokenClaims.SessionID.String()))
 			return
 		}
 		slog.Error(err.Error())
@@ -55,31 +63,123 @@ func (s *Server) renewToken(ctx *gin.Context) {
 		return
 	}
 
-	// These might be unnecessary
-	// if session.Username != refreshTokenClaims.Username {
-	// 	slog.Error(session.Username)
-	// 	ctx.JSON(http.StatusUnauthorized, errorResponse("incorrect user in token"))
-	// 	return
-	// }
-	// if session.RefreshToken != rqst.RefreshToken {
-	// 	ctx.JSON(http.StatusUnauthorized, errorResponse("mismatched session token"))
-	// 	return
-	// }
+	// Sessions of OAuth clients are limited to the scopes the user consented to, and are renewed with the token endpoint
+	if session.ClientID.Valid {
+		ctx.JSON(http.StatusUnauthorized, errorResponse("session belongs to an OAuth client"))
+		return
+	}
+
+	if session.Username != refreshTokenClaims.Username {
+		ctx.JSON(http.StatusUnauthorized, errorResponse("incorrect user in token"))
+		return
+	}
+
+	// Every renewal rotates the refresh token, so a token which is not the current one of its session
+	// has already been used. Someone is replaying it, and the session cannot be trusted anymore.
+	if session.RefreshToken != rqst.RefreshToken {
+		s.revokeReusedSession(ctx, session)
+		return
+	}
+
+	// The roles of the user might have changed since the login
+	u, err := s.db.GetUserByUsername(ctx, session.Username)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse("user is not valid"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	// The new refresh token does not outlive the session
+	refreshToken, refreshTokenClaims, err := s.tokenBuilder.CreateToken(token.CreateTokenParams{
+		Username:  session.Username,
+		Duration:  time.Until(session.ExpiresAt),
+		Purpose:   token.PurposeRefresh,
+		SessionID: session.ID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
 
 	// Create access token
-	accessToken, accessTokenClaims, err := s.tokenBuilder.CreateToken(refreshTokenClaims.Username, s.config.AccessTokenDuration)
+	accessToken, accessTokenClaims, err := s.tokenBuilder.CreateToken(token.CreateTokenParams{
+		Username:  session.Username,
+		Duration:  s.config.AccessTokenDuration,
+		Purpose:   token.PurposeAccess,
+		SessionID: session.ID,
+		Roles:     u.Roles,
+	})
 	if err != nil {
 		slog.Error(err.Error())
 		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
 		return
 	}
 
-	slog.Info("successfully renewed token for session " + refreshTokenClaims.ID.String())
+	// Replace the refresh token of the session, which fails if a concurrent request already did
+	rotated, err := s.db.RotateSessionRefreshToken(ctx, db.RotateSessionRefreshTokenParams{
+		ID:              session.ID,
+		RefreshToken:    rqst.RefreshToken,
+		NewRefreshToken: refreshToken,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if rotated == 0 {
+		s.revokeReusedSession(ctx, session)
+		return
+	}
+
+	slog.Info("successfully renewed token for session " + session.ID.String())
 
 	resp := renewTokenResponse{
-		SessionID:            session.ID,
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessTokenClaims.ExpiresAt.Time,
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessTokenClaims.ExpiresAt.Time,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshTokenClaims.ExpiresAt.Time,
 	}
 	ctx.JSON(http.StatusOK, resp)
 }
+
+// Blocks a session whose refresh token was reused, which logs out both the user and whoever replayed the token,
+// and lets the user know with a security email.
+func (s *Server) revokeReusedSession(ctx *gin.Context, session db.Session) {
+
+	if err := s.blockReusedSession(ctx, session); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusUnauthorized, errorResponse("refresh token has already been used"))
+}
+
+// Blocks a session whose refresh token was reused and sends the security email, without writing a response
+func (s *Server) blockReusedSession(ctx *gin.Context, session db.Session) error {
+
+	slog.Warn("refresh token reuse detected, revoking session", "user", session.Username, "session", session.ID)
+
+	_, err := s.db.BlockSession(ctx, db.BlockSessionParams{
+		ID:       session.ID,
+		Username: session.Username,
+	})
+	if err != nil {
+		return err
+	}
+
+	taskPayload := &worker.SendEmailPayload{
+		Username: session.Username,
+	}
+	if err := s.taskDistributor.DistributeSendEmail(ctx, taskPayload, worker.TaskSendRefreshTokenReuseEmail); err != nil {
+		slog.Error("cannot distribute security alert email", "user", session.Username, "errmsg", err)
+	}
+
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
 	"github.com/guerzon/gobudget-api/pkg/token"
 	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/guerzon/gobudget-api/pkg/worker"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -22,41 +23,157 @@ func TestToken(t *testing.T) {
 
 	testCases := []struct {
 		name          string
-		buildStubs    func(store *mockdb.MockStore, refreshTokenClaimsID uuid.UUID)
-		setupAuth     func(tokenMaker token.Builder) (string, *token.TokenPayload)
-		checkResponse func(recorder *httptest.ResponseRecorder)
+		duration      time.Duration
+		purpose       token.Purpose
+		buildStubs    func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor, session db.Session)
+		checkResponse func(recorder *httptest.ResponseRecorder, refreshToken string, tokenMaker token.Builder)
 	}{
 		{
-			name: "OK",
-			buildStubs: func(store *mockdb.MockStore, refreshTokenClaimsID uuid.UUID) {
+			name:     "OK",
+			duration: time.Minute * 15,
+			purpose:  token.PurposeRefresh,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor, session db.Session) {
 				store.EXPECT().
-					GetSession(gomock.Any(), refreshTokenClaimsID).
+					GetSession(gomock.Any(), session.ID).
 					Times(1).
-					Return(db.Session{}, nil)
+					Return(session, nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), session.Username).
+					Times(1).
+					Return(db.User{Username: session.Username, Roles: []string{token.RoleAdmin}}, nil)
+				store.EXPECT().
+					RotateSessionRefreshToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RotateSessionRefreshTokenParams) (int64, error) {
+						require.Equal(t, session.ID, arg.ID)
+						require.Equal(t, session.RefreshToken, arg.RefreshToken)
+						require.NotEqual(t, session.RefreshToken, arg.NewRefreshToken)
+						return 1, nil
+					})
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
-			setupAuth: func(tokenMaker token.Builder) (string, *token.TokenPayload) {
-				refreshToken, refreshTokenClaims, err := tokenMaker.CreateToken(util.RandomUsername(), time.Duration(time.Minute*15))
+			checkResponse: func(recorder *httptest.ResponseRecorder, refreshToken string, tokenMaker token.Builder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp renewTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.NotEmpty(t, resp.AccessToken)
+				require.NotEmpty(t, resp.RefreshToken)
+				require.NotEqual(t, refreshToken, resp.RefreshToken)
+
+				// The new access token has the current roles of the user
+				accessTokenClaims, err := tokenMaker.VerifyToken(resp.AccessToken)
 				require.NoError(t, err)
-				return refreshToken, refreshTokenClaims
+				require.Equal(t, []string{token.RoleAdmin}, accessTokenClaims.Roles)
 			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusOK, recorder.Code)
+		},
+		{
+			name:     "ExpiredToken",
+			duration: -1,
+			purpose:  token.PurposeRefresh,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, refreshToken string, tokenMaker token.Builder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
 		{
-			name: "ExpiredToken",
-			buildStubs: func(store *mockdb.MockStore, refreshTokenClaimsID uuid.UUID) {
+			name:     "AccessToken",
+			duration: time.Minute * 15,
+			purpose:  token.PurposeAccess,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
 				store.EXPECT().
-					GetSession(gomock.Any(), refreshTokenClaimsID).
-					Times(0).
-					Return(db.Session{}, nil)
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
-			setupAuth: func(tokenMaker token.Builder) (string, *token.TokenPayload) {
-				refreshToken, refreshTokenClaims, err := tokenMaker.CreateToken(util.RandomUsername(), -1)
-				require.NoError(t, err)
-				return refreshToken, refreshTokenClaims
+			checkResponse: func(recorder *httptest.ResponseRecorder, refreshToken string, tokenMaker token.Builder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
+		},
+		{
+			name:     "BlockedSession",
+			duration: time.Minute * 15,
+			purpose:  token.PurposeRefresh,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor, session db.Session) {
+				session.IsBlocked = true
+				store.EXPECT().
+					GetSession(gomock.Any(), session.ID).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					RotateSessionRefreshToken(gomock.Any(), gomock.Any()).
+					Times(0)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, refreshToken string, tokenMaker token.Builder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "ReusedToken",
+			duration: time.Minute * 15,
+			purpose:  token.PurposeRefresh,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor, session db.Session) {
+				// The session already moved on to another refresh token
+				rotated := session
+				rotated.RefreshToken = "rotated"
+				store.EXPECT().
+					GetSession(gomock.Any(), session.ID).
+					Times(1).
+					Return(rotated, nil)
+				store.EXPECT().
+					RotateSessionRefreshToken(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					BlockSession(gomock.Any(), db.BlockSessionParams{ID: session.ID, Username: session.Username}).
+					Times(1).
+					Return(int64(1), nil)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), &worker.SendEmailPayload{Username: session.Username}, worker.TaskSendRefreshTokenReuseEmail).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, refreshToken string, tokenMaker token.Builder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "ConcurrentRotation",
+			duration: time.Minute * 15,
+			purpose:  token.PurposeRefresh,
+			buildStubs: func(store *mockdb.MockStore, dist *mockdb.MockTaskDistributor, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), session.ID).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), session.Username).
+					Times(1).
+					Return(db.User{Username: session.Username, Roles: []string{token.RoleAdmin}}, nil)
+				store.EXPECT().
+					RotateSessionRefreshToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					BlockSession(gomock.Any(), db.BlockSessionParams{ID: session.ID, Username: session.Username}).
+					Times(1).
+					Return(int64(1), nil)
+				dist.EXPECT().
+					DistributeSendEmail(gomock.Any(), gomock.Any(), worker.TaskSendRefreshTokenReuseEmail).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, refreshToken string, tokenMaker token.Builder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
@@ -71,25 +188,39 @@ func TestToken(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			store := mockdb.NewMockStore(ctrl)
+			dist := mockdb.NewMockTaskDistributor(ctrl)
 
-			server := NewTestServer(t, store, nil)
+			server := NewTestServer(t, store, dist)
 
 			// get a refresh token
-			refreshToken, refreshTokenClaims := tc.setupAuth(server.tokenBuilder)
+			refreshToken, refreshTokenClaims, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{
+				Username:  util.RandomUsername(),
+				Duration:  tc.duration,
+				Purpose:   tc.purpose,
+				SessionID: uuid.New(),
+			})
+			require.NoError(t, err)
+			session := db.Session{
+				ID:           refreshTokenClaims.SessionID,
+				Username:     refreshTokenClaims.Username,
+				RefreshToken: refreshToken,
+				ExpiresAt:    refreshTokenClaims.ExpiresAt.Time,
+			}
+
 			body := gin.H{
 				"refresh_token": refreshToken,
 			}
 			data, err := json.Marshal(body)
 			require.NoError(t, err)
 
-			tc.buildStubs(store, refreshTokenClaims.ID)
+			tc.buildStubs(store, dist, session)
 
 			recorder := httptest.NewRecorder()
 			request, err := http.NewRequest(http.MethodPost, "/beta/renew_token", bytes.NewReader(data))
 			require.NoError(t, err)
 
 			server.Router.ServeHTTP(recorder, request)
-			tc.checkResponse(recorder)
+			tc.checkResponse(recorder, refreshToken, server.tokenBuilder)
 		})
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/money"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -22,7 +23,7 @@ import (
 //	@Tags			Transactions
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	[]db.TransactionsView
+//	@Success		200	{object}	[]transactionViewResponse
 //	@Failure		400	{object}	HTTPError
 //	@Failure		404	{object}	HTTPError
 //	@Failure		500	{object}	HTTPError
@@ -42,7 +43,12 @@ func (s *Server) getTransactions(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, transactions)
+	rsp := make([]transactionViewResponse, len(transactions))
+	for i, t := range transactions {
+		rsp[i] = newTransactionViewResponse(t)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 // getTransaction godoc
@@ -93,12 +99,15 @@ func (s *Server) getTransaction(ctx *gin.Context) {
 		Date:       transaction.Date,
 		Account:    transaction.AccountName,
 		Payee:      transaction.PayeeName,
-		Category:   transaction.CategoryName,
+		Category:   transaction.CategoryName.String,
 		Memo:       transaction.Memo,
 		Amount:     transaction.Amount,
 		Approved:   transaction.Approved,
 		Cleared:    transaction.Cleared,
 		Reconciled: transaction.Reconciled,
+
+		CurrencyCode:  transaction.CurrencyCode,
+		AmountDecimal: money.Format(transaction.Amount, transaction.CurrencyCode),
 	}
 	ctx.JSON(http.StatusOK, resp)
 }
@@ -107,13 +116,17 @@ func (s *Server) getTransaction(ctx *gin.Context) {
 //
 //	@Summary	Create a transaction
 //	@Schemes
-//	@Description	Create a transaction.
+//	@Description	Create a transaction. Outflows of on-budget accounts need a category, those of tracking accounts have none.
+//	@Description	Inflows of on-budget accounts without category are ready to assign.
+//	@Description	Transfers use the transfer payee of the other account, and have no category between on-budget accounts. Transfers to a credit card are payments.
+//	@Description	Transfers to an account in another currency need the amount in its currency, the difference with the exchange rate being a gain or loss.
+//	@Description	Amounts are in milliunits, thousandths of the unit of the currency, or decimals in the currency in the fields ending with _decimal.
 //	@Param			budget_id	path	string				true	"Budget ID"
 //	@Param			transaction	body	transactionRequest	true	"Transaction details"
 //	@Tags			Categories
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	db.TransactionsView
+//	@Success		200	{object}	createTransactionResponse
 //	@Failure		400	{object}	HTTPError
 //	@Failure		403	{object}	HTTPError
 //	@Failure		500	{object}	HTTPError
@@ -121,11 +134,12 @@ func (s *Server) getTransaction(ctx *gin.Context) {
 func (s *Server) createTransaction(ctx *gin.Context) {
 
 	// Parse the request
-	var budgetId uuid.UUID
-	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+	budget, err := s.getOwnedBudget(ctx)
+	if err != nil {
 		ctx.JSON(http.StatusForbidden, errorResponse("budget does not exist or does not belong to the user"))
 		return
 	}
+	budgetId := budget.ID
 	var rqst transactionRequest
 	if err := ctx.ShouldBindJSON(&rqst); err != nil {
 		slog.Error(err.Error())
@@ -160,6 +174,11 @@ func (s *Server) createTransaction(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse("account and payee should not be the same"))
 		return
 	}
+	amount, err := requestAmount("amount", rqst.Amount, rqst.AmountDecimal, acct.CurrencyCode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
 
 	// Parse the UUIDs
 	payeeId, err := uuid.Parse(rqst.Payee)
@@ -167,9 +186,104 @@ func (s *Server) createTransaction(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse("cannot parse payee ID"))
 		return
 	}
-	categoryId, err := uuid.Parse(rqst.Category)
+	payee, err := s.db.GetPayeeById(ctx, payeeId)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse("cannot parse category ID"))
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse("invalid payee or category ID"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if payee.BudgetID != budgetId {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid payee or category ID"))
+		return
+	}
+
+	// Only the transactions of on-budget accounts are budgeted in categories,
+	// except transfers between on-budget accounts which only move money
+	categorized := acct.OnBudget
+	var transferAmount pgtype.Int8
+	if payee.TransferAccountID.Valid {
+		transferAccountId := uuid.UUID(payee.TransferAccountID.Bytes)
+		if transferAccountId == acct.ID {
+			ctx.JSON(http.StatusBadRequest, errorResponse("cannot transfer to the same account"))
+			return
+		}
+		transferAccount, err := s.db.GetAccount(ctx, db.GetAccountParams{
+			BudgetID: budgetId,
+			ID:       transferAccountId,
+		})
+		if err != nil {
+			slog.Error(err.Error())
+			ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+			return
+		}
+		if acct.OnBudget && transferAccount.OnBudget {
+			categorized = false
+		}
+		// transfers between currencies say what the other account gets, which sets the exchange gain or loss
+		if transferAccount.CurrencyCode != acct.CurrencyCode {
+			received, err := requestAmount("transfer_amount", rqst.TransferAmount.Int64, rqst.TransferAmountDecimal, transferAccount.CurrencyCode)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+				return
+			}
+			if received <= 0 {
+				ctx.JSON(http.StatusBadRequest, errorResponse("transfers between accounts in different currencies need a positive transfer amount"))
+				return
+			}
+			// the other account receives what this one sends, or the other way around
+			transferAmount = pgtype.Int8{Int64: received, Valid: true}
+			if amount > 0 {
+				transferAmount.Int64 = -transferAmount.Int64
+			}
+		}
+	}
+	if (rqst.TransferAmount.Valid || rqst.TransferAmountDecimal != "") && !transferAmount.Valid {
+		ctx.JSON(http.StatusBadRequest, errorResponse("only transfers between accounts in different currencies have a transfer amount"))
+		return
+	}
+
+	var categoryId pgtype.UUID
+	if categorized && rqst.Category == "" {
+		// inflows without category go to ready to assign
+		if amount <= 0 {
+			ctx.JSON(http.StatusBadRequest, errorResponse("outflows of on-budget accounts need a category"))
+			return
+		}
+	} else if categorized {
+		id, err := uuid.Parse(rqst.Category)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse("cannot parse category ID"))
+			return
+		}
+		// money gets into payment categories from the spending on their credit card, and payments
+		category, err := s.db.GetBudgetCategory(ctx, db.GetBudgetCategoryParams{
+			ID:       id,
+			BudgetID: budgetId,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				ctx.JSON(http.StatusBadRequest, errorResponse("invalid payee or category ID"))
+				return
+			}
+			slog.Error(err.Error())
+			ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+			return
+		}
+		if category.PaymentAccountID.Valid {
+			ctx.JSON(http.StatusBadRequest, errorResponse("transactions cannot be categorized in a credit card payment category"))
+			return
+		}
+		categoryId = pgtype.UUID{Bytes: id, Valid: true}
+	} else if rqst.Category != "" {
+		if acct.OnBudget {
+			ctx.JSON(http.StatusBadRequest, errorResponse("transfers between on-budget accounts have no category"))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, errorResponse("transactions of tracking accounts have no category"))
 		return
 	}
 
@@ -182,18 +296,16 @@ func (s *Server) createTransaction(ctx *gin.Context) {
 			Valid: true,
 			Time:  rqst.Date.Time,
 		},
-		PayeeID: payeeId,
-		CategoryID: pgtype.UUID{
-			Bytes: categoryId,
-			Valid: true,
-		},
+		PayeeID:    payeeId,
+		CategoryID: categoryId,
 		Memo: pgtype.Text{
 			Valid:  true,
 			String: rqst.Memo,
 		},
-		Amount:     rqst.Amount,
-		Cleared:    rqst.Cleared,
-		Reconciled: rqst.Reconciled,
+		Amount:         amount,
+		Cleared:        rqst.Cleared,
+		Reconciled:     rqst.Reconciled,
+		TransferAmount: transferAmount,
 	}
 	resp, err := s.db.CreateTransaction(ctx, arg)
 	if err != nil {
@@ -213,7 +325,13 @@ func (s *Server) createTransaction(ctx *gin.Context) {
 
 	// Check if the payee is an account and debit as well
 
-	ctx.JSON(http.StatusOK, resp)
+	s.publishWebhookEvent(ctx, budgetId, WebhookEventTransactionCreated, resp)
+	s.publishCategoryOverspent(ctx, budget, resp)
+
+	ctx.JSON(http.StatusOK, createTransactionResponse{
+		Transaction:   resp,
+		AmountDecimal: money.Format(resp.Amount, acct.CurrencyCode),
+	})
 }
 
 // updateTransaction godoc
@@ -0,0 +1,552 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateTransactionCategoryAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	checking := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Chase", Type: AccountTypeChecking, OnBudget: true}
+	card := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Visa", Type: AccountTypeCreditCard, OnBudget: true}
+	house := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "House", Type: AccountTypeAsset, OnBudget: false}
+	shop := db.Payee{ID: uuid.New(), BudgetID: budget.ID, Name: "Edeka"}
+	toCard := db.Payee{ID: uuid.New(), BudgetID: budget.ID, Name: "Transfer : Visa", TransferAccountID: pgtype.UUID{Bytes: card.ID, Valid: true}}
+	toHouse := db.Payee{ID: uuid.New(), BudgetID: budget.ID, Name: "Transfer : House", TransferAccountID: pgtype.UUID{Bytes: house.ID, Valid: true}}
+	otherBudgetPayee := db.Payee{ID: uuid.New(), BudgetID: uuid.New(), Name: "Edeka"}
+	groceries := db.Category{ID: uuid.New(), Name: "Groceries"}
+	payment := db.Category{ID: uuid.New(), Name: "Visa", PaymentAccountID: pgtype.UUID{Bytes: card.ID, Valid: true}}
+	otherBudgetCategory := db.Category{ID: uuid.New(), CategoryGroupID: uuid.New(), Name: "Groceries"}
+
+	expectCreated := func(store *mockdb.MockStore, categoryId pgtype.UUID) {
+		store.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ any, arg db.CreateTransactionParams) (db.Transaction, error) {
+				require.Equal(t, categoryId, arg.CategoryID)
+				return db.Transaction{ID: uuid.New(), AccountID: arg.AccountID, CategoryID: arg.CategoryID}, nil
+			})
+		store.EXPECT().
+			GetSubscribedWebhooks(gomock.Any(), gomock.Any()).
+			Times(1)
+	}
+	expectNotCreated := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Times(0)
+	}
+
+	testCases := []struct {
+		name          string
+		account       db.Account
+		payee         db.Payee
+		category      *db.Category
+		amount        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OnBudgetWithCategory",
+			account:  checking,
+			payee:    shop,
+			category: &groceries,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetBudgetCategory(gomock.Any(), db.GetBudgetCategoryParams{ID: groceries.ID, BudgetID: budget.ID}).
+					Times(1).
+					Return(groceries, nil)
+				expectCreated(store, pgtype.UUID{Bytes: groceries.ID, Valid: true})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "CategoryOfAnotherBudget",
+			account:  checking,
+			payee:    shop,
+			category: &otherBudgetCategory,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetBudgetCategory(gomock.Any(), db.GetBudgetCategoryParams{ID: otherBudgetCategory.ID, BudgetID: budget.ID}).
+					Times(1).
+					Return(db.Category{}, pgx.ErrNoRows)
+				expectNotCreated(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "OnBudgetWithoutCategory",
+			account:    checking,
+			payee:      shop,
+			buildStubs: expectNotCreated,
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "OnBudgetInflowWithoutCategory",
+			account: checking,
+			payee:   shop,
+			amount:  250000,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectCreated(store, pgtype.UUID{})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "PaymentCategory",
+			account:  checking,
+			payee:    shop,
+			category: &payment,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetBudgetCategory(gomock.Any(), db.GetBudgetCategoryParams{ID: payment.ID, BudgetID: budget.ID}).
+					Times(1).
+					Return(payment, nil)
+				expectNotCreated(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "CreditCardPayment",
+			account: checking,
+			payee:   toCard,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: card.ID}).
+					Times(1).
+					Return(card, nil)
+				expectCreated(store, pgtype.UUID{})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OnBudgetTransferWithCategory",
+			account:  checking,
+			payee:    toCard,
+			category: &groceries,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: card.ID}).
+					Times(1).
+					Return(card, nil)
+				expectNotCreated(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "TransferToTrackingAccount",
+			account:  checking,
+			payee:    toHouse,
+			category: &groceries,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: house.ID}).
+					Times(1).
+					Return(house, nil)
+				store.EXPECT().
+					GetBudgetCategory(gomock.Any(), db.GetBudgetCategoryParams{ID: groceries.ID, BudgetID: budget.ID}).
+					Times(1).
+					Return(groceries, nil)
+				expectCreated(store, pgtype.UUID{Bytes: groceries.ID, Valid: true})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "TransferToSameAccount",
+			account:    card,
+			payee:      toCard,
+			buildStubs: expectNotCreated,
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "TrackingWithoutCategory",
+			account: house,
+			payee:   shop,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectCreated(store, pgtype.UUID{})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "TrackingWithCategory",
+			account:    house,
+			payee:      shop,
+			category:   &groceries,
+			buildStubs: expectNotCreated,
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "PayeeOfAnotherBudget",
+			account:    checking,
+			payee:      otherBudgetPayee,
+			category:   &groceries,
+			buildStubs: expectNotCreated,
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(budget, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: tc.account.ID}).
+				Times(1).
+				Return(tc.account, nil)
+			store.EXPECT().
+				GetPayeeById(gomock.Any(), tc.payee.ID).
+				Times(1).
+				Return(tc.payee, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			body := gin.H{
+				"account_id": tc.account.ID.String(),
+				"date":       "2024-05-01",
+				"payee_id":   tc.payee.ID.String(),
+				"amount":     -1500,
+			}
+			if tc.amount != 0 {
+				body["amount"] = tc.amount
+			}
+			if tc.category != nil {
+				body["category_id"] = tc.category.ID.String()
+			}
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/beta/budgets/"+budget.ID.String()+"/transactions", bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCreateCurrencyTransferAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	checking := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Chase", Type: AccountTypeChecking, OnBudget: true, CurrencyCode: "EUR"}
+	dollars := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Dollars", Type: AccountTypeSavings, OnBudget: true, CurrencyCode: "USD"}
+	card := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Visa", Type: AccountTypeCreditCard, OnBudget: true, CurrencyCode: "EUR"}
+	toDollars := db.Payee{ID: uuid.New(), BudgetID: budget.ID, Name: "Transfer : Dollars", TransferAccountID: pgtype.UUID{Bytes: dollars.ID, Valid: true}}
+	toCard := db.Payee{ID: uuid.New(), BudgetID: budget.ID, Name: "Transfer : Visa", TransferAccountID: pgtype.UUID{Bytes: card.ID, Valid: true}}
+
+	testCases := []struct {
+		name          string
+		payee         db.Payee
+		transfer      db.Account
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Outflow",
+			payee:    toDollars,
+			transfer: dollars,
+			body:     gin.H{"amount": -1500, "transfer_amount": 1620},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTransactionParams) (db.Transaction, error) {
+						require.Equal(t, pgtype.Int8{Int64: 1620, Valid: true}, arg.TransferAmount)
+						return db.Transaction{ID: uuid.New(), AccountID: arg.AccountID, Amount: arg.Amount, TransferAmount: arg.TransferAmount}, nil
+					})
+				store.EXPECT().GetSubscribedWebhooks(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Inflow",
+			payee:    toDollars,
+			transfer: dollars,
+			body:     gin.H{"amount": 1500, "transfer_amount": 1620},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTransactionParams) (db.Transaction, error) {
+						require.Equal(t, pgtype.Int8{Int64: -1620, Valid: true}, arg.TransferAmount)
+						return db.Transaction{ID: uuid.New()}, nil
+					})
+				store.EXPECT().GetSubscribedWebhooks(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Decimal",
+			payee:    toDollars,
+			transfer: dollars,
+			body:     gin.H{"amount_decimal": "-1.50", "transfer_amount_decimal": "1.62"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTransactionParams) (db.Transaction, error) {
+						require.Equal(t, int64(-1500), arg.Amount)
+						require.Equal(t, pgtype.Int8{Int64: 1620, Valid: true}, arg.TransferAmount)
+						return db.Transaction{ID: uuid.New(), AccountID: arg.AccountID, Amount: arg.Amount, TransferAmount: arg.TransferAmount}, nil
+					})
+				store.EXPECT().GetSubscribedWebhooks(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp createTransactionResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, "-1.50", rsp.AmountDecimal)
+			},
+		},
+		{
+			name:     "TransferAmountTooPrecise",
+			payee:    toDollars,
+			transfer: dollars,
+			body:     gin.H{"amount": -1500, "transfer_amount_decimal": "1.625"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "USD has 2 decimals")
+			},
+		},
+		{
+			name:     "WithoutTransferAmount",
+			payee:    toDollars,
+			transfer: dollars,
+			body:     gin.H{"amount": -1500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "SameCurrency",
+			payee:    toCard,
+			transfer: card,
+			body:     gin.H{"amount": -1500, "transfer_amount": 1500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(budget, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: checking.ID}).
+				Times(1).
+				Return(checking, nil)
+			store.EXPECT().
+				GetPayeeById(gomock.Any(), tc.payee.ID).
+				Times(1).
+				Return(tc.payee, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: tc.transfer.ID}).
+				Times(1).
+				Return(tc.transfer, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			body := gin.H{
+				"account_id": checking.ID.String(),
+				"date":       "2024-05-01",
+				"payee_id":   tc.payee.ID.String(),
+			}
+			for k, v := range tc.body {
+				body[k] = v
+			}
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/beta/budgets/"+budget.ID.String()+"/transactions", bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCreateTransactionOverspentAPI(t *testing.T) {
+
+	username := util.RandomUsername()
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: username, Name: "My Budget", CurrencyCode: "EUR"}
+	checking := db.Account{ID: uuid.New(), BudgetID: budget.ID, Name: "Chase", Type: AccountTypeChecking, CurrencyCode: "EUR", OnBudget: true}
+	shop := db.Payee{ID: uuid.New(), BudgetID: budget.ID, Name: "Edeka"}
+	groceries := db.Category{ID: uuid.New(), Name: "Groceries", Assigned: 10000}
+	webhook := db.Webhook{ID: uuid.New(), BudgetID: budget.ID, Events: []string{WebhookEventCategoryOverspent}, Active: true}
+	date := pgtype.Date{Time: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	spent := db.Transaction{ID: uuid.New(), AccountID: checking.ID, Date: date, PayeeID: shop.ID, CategoryID: pgtype.UUID{Bytes: groceries.ID, Valid: true}, Amount: -6000}
+
+	testCases := []struct {
+		name       string
+		amount     int64
+		previously []db.Transaction
+		published  bool
+	}{
+		{
+			name:       "Overspent",
+			amount:     -5000,
+			previously: []db.Transaction{spent},
+			published:  true,
+		},
+		{
+			name:      "NotOverspent",
+			amount:    -5000,
+			published: false,
+		},
+		{
+			name:       "AlreadyOverspent",
+			amount:     -5000,
+			previously: []db.Transaction{spent, spent},
+			published:  false,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			created := db.Transaction{ID: uuid.New(), AccountID: checking.ID, Date: date, PayeeID: shop.ID, CategoryID: pgtype.UUID{Bytes: groceries.ID, Valid: true}, Amount: tc.amount}
+
+			store := mockdb.NewMockStore(ctrl)
+			dist := mockdb.NewMockTaskDistributor(ctrl)
+			store.EXPECT().GetBudget(gomock.Any(), gomock.Any()).Times(1).Return(budget, nil)
+			store.EXPECT().GetAccount(gomock.Any(), db.GetAccountParams{BudgetID: budget.ID, ID: checking.ID}).Times(1).Return(checking, nil)
+			store.EXPECT().GetPayeeById(gomock.Any(), shop.ID).Times(1).Return(shop, nil)
+			store.EXPECT().GetBudgetCategory(gomock.Any(), db.GetBudgetCategoryParams{ID: groceries.ID, BudgetID: budget.ID}).Times(1).Return(groceries, nil)
+			store.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Times(1).Return(created, nil)
+			store.EXPECT().
+				GetSubscribedWebhooks(gomock.Any(), db.GetSubscribedWebhooksParams{BudgetID: budget.ID, Event: WebhookEventTransactionCreated}).
+				Times(1)
+			store.EXPECT().
+				GetSubscribedWebhooks(gomock.Any(), db.GetSubscribedWebhooksParams{BudgetID: budget.ID, Event: WebhookEventCategoryOverspent}).
+				MinTimes(1).
+				Return([]db.Webhook{webhook}, nil)
+			store.EXPECT().GetBudgetCategories(gomock.Any(), budget.ID).Times(1).Return([]db.Category{groceries}, nil)
+			store.EXPECT().GetAccounts(gomock.Any(), budget.ID).Times(1).Return([]db.Account{checking}, nil)
+			store.EXPECT().GetPayees(gomock.Any(), budget.ID).Times(1).Return([]db.Payee{shop}, nil)
+			store.EXPECT().GetTransactions(gomock.Any(), budget.ID).Times(1).Return(append(tc.previously, created), nil)
+
+			deliveries := 0
+			if tc.published {
+				deliveries = 1
+			}
+			store.EXPECT().
+				CreateWebhookDelivery(gomock.Any(), gomock.Any()).
+				Times(deliveries).
+				DoAndReturn(func(_ any, arg db.CreateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+					require.Equal(t, WebhookEventCategoryOverspent, arg.Event)
+					var event struct {
+						Data categoryBalanceResponse `json:"data"`
+					}
+					require.NoError(t, json.Unmarshal(arg.Payload, &event))
+					require.Equal(t, groceries.ID, event.Data.ID)
+					require.Equal(t, int64(-1000), event.Data.Available)
+					return db.WebhookDelivery{ID: uuid.New(), WebhookID: arg.WebhookID}, nil
+				})
+			dist.EXPECT().DistributeDeliverWebhook(gomock.Any(), gomock.Any()).Times(deliveries)
+
+			server := NewTestServer(t, store, dist)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"account_id":  checking.ID.String(),
+				"date":        "2024-05-01",
+				"payee_id":    shop.ID.String(),
+				"category_id": groceries.ID.String(),
+				"amount":      tc.amount,
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/beta/budgets/"+budget.ID.String()+"/transactions", bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+		})
+	}
+}
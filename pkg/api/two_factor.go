@@ -0,0 +1,393 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// How long the user has to complete the second login step
+const twoFactorChallengeDuration = 5 * time.Minute
+
+// Issuer shown in authenticator apps
+const totpIssuer = "gobudget"
+
+// Number of recovery codes generated when enabling two-factor authentication
+const recoveryCodeCount = 10
+
+// Number of codes that can be tried with a challenge token, after which the user has to log in again
+const maxTwoFactorAttempts = 5
+
+// Checks a second factor of the user, which is either a TOTP code or an unused recovery code.
+// Recovery codes are marked as used, and TOTP codes are only accepted once.
+func (s *Server) checkSecondFactor(ctx *gin.Context, u db.User, code string) (bool, error) {
+
+	code = strings.ToLower(strings.TrimSpace(code))
+
+	if len(code) == 6 {
+		return s.acceptTOTP(ctx, u, code)
+	}
+
+	// fails if the code is unknown, or was already used, also by a concurrent request
+	n, err := s.db.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		Username: u.Username,
+		CodeHash: token.HashOpaqueToken(code),
+	})
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+	slog.Info("Used recovery code", "user", u.Username)
+
+	return true, nil
+}
+
+// Checks a TOTP code of the user, which is rejected if it is not newer than the last one accepted.
+func (s *Server) acceptTOTP(ctx *gin.Context, u db.User, code string) (bool, error) {
+
+	step, ok := util.MatchTOTP(u.TotpSecret.String, code, time.Now())
+	if !ok || step <= u.TotpLastStep {
+		return false, nil
+	}
+	// fails if a concurrent request used the code, or a later one, first
+	n, err := s.db.UpdateUserTOTPLastStep(ctx, db.UpdateUserTOTPLastStepParams{
+		Username: u.Username,
+		Step:     step,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// loginTwoFactor godoc
+//
+//	@Summary	Login second step
+//	@Schemes
+//	@Description	Complete the login of a user with two-factor authentication, using the challenge token returned by login and a TOTP code or a recovery code.
+//	@Description	A challenge token allows a few attempts and is used once. Failed codes count as failed logins of the user.
+//	@Tags			Security
+//	@Accept			json
+//	@Param			challenge	body	loginTwoFactorRequest	true	"Challenge token and code"
+//	@Produce		json
+//	@Success		200	{object}	loginResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		429	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/login/2fa [post]
+func (s *Server) loginTwoFactor(ctx *gin.Context) {
+
+	var rqst loginTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	// Only challenge tokens are accepted
+	payload, err := s.tokenBuilder.VerifyToken(rqst.ChallengeToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err.Error()))
+		return
+	}
+	if payload.Purpose != token.PurposeChallenge {
+		ctx.JSON(http.StatusUnauthorized, errorResponse("invalid challenge token"))
+		return
+	}
+
+	// Slow down code guessing, like password guessing
+	clientIp := ctx.ClientIP()
+	if s.loginThrottled(ctx, payload.Username, clientIp) {
+		return
+	}
+
+	u, err := s.db.GetUserByUsername(ctx, payload.Username)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse("invalid challenge token"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if !u.TotpEnabled {
+		ctx.JSON(http.StatusUnauthorized, errorResponse("invalid challenge token"))
+		return
+	}
+
+	// Count the attempt, the challenge is refused once used, expired or out of attempts
+	_, err = s.db.AttemptTwoFactorChallenge(ctx, db.AttemptTwoFactorChallengeParams{
+		ID:          payload.ID,
+		MaxAttempts: maxTwoFactorAttempts,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse("invalid challenge token, log in again"))
+			return
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ok, err := s.checkSecondFactor(ctx, u, rqst.Code)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if !ok {
+		s.recordLoginFailure(ctx, u.Username, clientIp, true)
+		ctx.JSON(http.StatusUnauthorized, errorResponse("invalid code"))
+		return
+	}
+
+	// fails if a concurrent request completed the challenge first
+	n, err := s.db.UseTwoFactorChallenge(ctx, payload.ID)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusUnauthorized, errorResponse("invalid challenge token, log in again"))
+		return
+	}
+	if err := s.loginLimiter.Reset(ctx, u.Username); err != nil {
+		slog.Error("cannot reset failed logins", "user", u.Username, "errmsg", err)
+	}
+
+	s.startSession(ctx, u)
+}
+
+// enrollTOTP godoc
+//
+//	@Summary	Start two-factor enrollment
+//	@Schemes
+//	@Description	Generate a TOTP secret for the authenticated user. Two-factor authentication is enabled once a code generated from the secret is confirmed.
+//	@Tags			User
+//	@Produce		json
+//	@Success		200	{object}	totpEnrollmentResponse
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		409	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/2fa/totp [post]
+//	@Security		Bearer
+func (s *Server) enrollTOTP(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	u, err := s.db.GetUserByUsername(ctx, authz_payload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, errorResponse("user is not valid"))
+		return
+	}
+	if u.TotpEnabled {
+		ctx.JSON(http.StatusConflict, errorResponse("two-factor authentication is already enabled"))
+		return
+	}
+
+	secret, err := util.NewTOTPSecret()
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	err = s.db.UpdateUserTOTPSecret(ctx, db.UpdateUserTOTPSecretParams{
+		Username:   u.Username,
+		TotpSecret: pgtype.Text{String: secret, Valid: true},
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	resp := totpEnrollmentResponse{
+		Secret: secret,
+		URI:    util.TOTPURI(totpIssuer, u.Username, secret),
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// confirmTOTP godoc
+//
+//	@Summary	Confirm two-factor enrollment
+//	@Schemes
+//	@Description	Enable two-factor authentication with a code from the authenticator app. Returns one-time recovery codes, which are only shown in this response.
+//	@Tags			User
+//	@Accept			json
+//	@Param			code	body	totpConfirmRequest	true	"TOTP code"
+//	@Produce		json
+//	@Success		200	{object}	recoveryCodesResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		409	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/2fa/totp/confirm [post]
+//	@Security		Bearer
+func (s *Server) confirmTOTP(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	var rqst totpConfirmRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	u, err := s.db.GetUserByUsername(ctx, authz_payload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, errorResponse("user is not valid"))
+		return
+	}
+	if u.TotpEnabled {
+		ctx.JSON(http.StatusConflict, errorResponse("two-factor authentication is already enabled"))
+		return
+	}
+	if !u.TotpSecret.Valid {
+		ctx.JSON(http.StatusBadRequest, errorResponse("two-factor enrollment has not been started"))
+		return
+	}
+	ok, err := s.acceptTOTP(ctx, u, rqst.Code)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid code"))
+		return
+	}
+
+	// Generate the recovery codes, only their hashes are stored. Like opaque tokens, they are random enough
+	// for a plain hash, which allows looking them up.
+	recoveryCodes, err := util.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	hashes := make([]string, len(recoveryCodes))
+	for i := range recoveryCodes {
+		hashes[i] = token.HashOpaqueToken(recoveryCodes[i])
+	}
+
+	if err := s.db.EnableTOTPTx(ctx, u.Username, hashes); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	slog.Info("Enabled two-factor authentication", "user", u.Username)
+
+	ctx.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// disableTwoFactor godoc
+//
+//	@Summary	Disable two-factor authentication
+//	@Schemes
+//	@Description	Disable two-factor authentication for the authenticated user. The user has to re-authenticate with their password and a TOTP code or a recovery code. Wrong passwords and codes count as failed logins.
+//	@Tags			User
+//	@Accept			json
+//	@Param			credentials	body	disableTwoFactorRequest	true	"Password and code"
+//	@Produce		json
+//	@Success		200	{string}	string	"two-factor authentication disabled"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		401	{object}	HTTPError
+//	@Failure		403	{object}	HTTPError
+//	@Failure		429	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/user/2fa/disable [post]
+//	@Security		Bearer
+func (s *Server) disableTwoFactor(ctx *gin.Context) {
+
+	// Get the authenticated user
+	k, exists := ctx.Get("authz_payload")
+	if !exists {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	authz_payload := k.(*token.TokenPayload)
+
+	var rqst disableTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	// A stolen access token must not allow guessing the password faster than the login
+	clientIp := ctx.ClientIP()
+	if s.loginThrottled(ctx, authz_payload.Username, clientIp) {
+		return
+	}
+
+	u, err := s.db.GetUserByUsername(ctx, authz_payload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, errorResponse("user is not valid"))
+		return
+	}
+	if !u.TotpEnabled {
+		ctx.JSON(http.StatusBadRequest, errorResponse("two-factor authentication is not enabled"))
+		return
+	}
+
+	// Re-authenticate the user
+	if err := util.CheckPassword(u.Password, rqst.Password); err != nil {
+		s.recordLoginFailure(ctx, u.Username, clientIp, true)
+		ctx.JSON(http.StatusUnauthorized, errorResponse("invalid password or code"))
+		return
+	}
+	ok, err := s.checkSecondFactor(ctx, u, rqst.Code)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if !ok {
+		s.recordLoginFailure(ctx, u.Username, clientIp, true)
+		ctx.JSON(http.StatusUnauthorized, errorResponse("invalid password or code"))
+		return
+	}
+	if err := s.loginLimiter.Reset(ctx, u.Username); err != nil {
+		slog.Error("cannot reset failed logins", "user", u.Username, "errmsg", err)
+	}
+
+	if err := s.db.DisableTOTPTx(ctx, u.Username); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	slog.Info("Disabled two-factor authentication", "user", u.Username)
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "two-factor authentication disabled"})
+}
@@ -0,0 +1,486 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/limiter"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLoginTwoFactorAPI(t *testing.T) {
+
+	user, _ := buildTestUser(t)
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+	user.TotpSecret = pgtype.Text{String: secret, Valid: true}
+	user.TotpEnabled = true
+
+	// the user after logging in with the current code
+	user2 := user
+	step, ok := util.MatchTOTP(secret, mustTOTPCode(t, secret), time.Now())
+	require.True(t, ok)
+	user2.TotpLastStep = step
+
+	recoveryCode := "k7mzq-4hp2x"
+
+	testCases := []struct {
+		name          string
+		lockedOut     bool
+		body          func(challengeToken string, accessToken string) gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func(challengeToken string, accessToken string) gin.H {
+				code, err := util.TOTPCode(secret, time.Now())
+				require.NoError(t, err)
+				return gin.H{"challenge_token": challengeToken, "code": code}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				expectChallengeAttempt(store)
+				store.EXPECT().
+					UpdateUserTOTPLastStep(gomock.Any(), db.UpdateUserTOTPLastStepParams{Username: user.Username, Step: step}).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					UseTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+				expectLoginRecorded(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp loginResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.NotEmpty(t, resp.AccessToken)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: func(challengeToken string, accessToken string) gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": recoveryCode}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				expectChallengeAttempt(store)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), db.UseRecoveryCodeParams{Username: user.Username, CodeHash: token.HashOpaqueToken(recoveryCode)}).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					UseTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+				expectLoginRecorded(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			body: func(challengeToken string, accessToken string) gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": recoveryCode}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				expectChallengeAttempt(store)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginEvent{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: func(challengeToken string, accessToken string) gin.H {
+				// a code which is not valid in the accepted time window
+				code := "000000"
+				for util.ValidateTOTP(secret, code, time.Now()) {
+					code = util.RandomString(6, "0123456789")
+				}
+				return gin.H{"challenge_token": challengeToken, "code": code}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				expectChallengeAttempt(store)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateLoginEventParams) (db.LoginEvent, error) {
+						require.False(t, arg.Success)
+						return db.LoginEvent{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ReplayedCode",
+			body: func(challengeToken string, accessToken string) gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": mustTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user2, nil)
+				expectChallengeAttempt(store)
+				store.EXPECT().
+					UpdateUserTOTPLastStep(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeExhausted",
+			body: func(challengeToken string, accessToken string) gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": mustTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					AttemptTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TwoFactorChallenge{}, pgx.ErrNoRows)
+				store.EXPECT().
+					UpdateUserTOTPLastStep(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeAlreadyUsed",
+			body: func(challengeToken string, accessToken string) gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": mustTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				expectChallengeAttempt(store)
+				store.EXPECT().
+					UpdateUserTOTPLastStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					UseTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "TooManyFailures",
+			lockedOut: true,
+			body: func(challengeToken string, accessToken string) gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": mustTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					AttemptTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "AccessTokenAsChallenge",
+			body: func(challengeToken string, accessToken string) gin.H {
+				code, err := util.TOTPCode(secret, time.Now())
+				require.NoError(t, err)
+				return gin.H{"challenge_token": accessToken, "code": code}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			if tc.lockedOut {
+				server.loginLimiter = limiter.NewMemoryLoginLimiter(limiter.LoginPolicy{LockoutThreshold: 1, LockoutDuration: time.Hour})
+				_, err := server.loginLimiter.RecordFailure(context.Background(), user.Username, "192.0.2.1")
+				require.NoError(t, err)
+			}
+			recorder := httptest.NewRecorder()
+
+			challengeToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: user.Username, Duration: twoFactorChallengeDuration, Purpose: token.PurposeChallenge})
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: user.Username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+
+			data, err := json.Marshal(tc.body(challengeToken, accessToken))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/beta/login/2fa", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+// Expects an attempt at a code with a valid challenge
+func expectChallengeAttempt(store *mockdb.MockStore) {
+	store.EXPECT().
+		AttemptTwoFactorChallenge(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.AttemptTwoFactorChallengeParams) (db.TwoFactorChallenge, error) {
+			if arg.MaxAttempts != maxTwoFactorAttempts {
+				return db.TwoFactorChallenge{}, pgx.ErrNoRows
+			}
+			return db.TwoFactorChallenge{ID: arg.ID, Attempts: 1}, nil
+		})
+}
+
+func mustTOTPCode(t *testing.T, secret string) string {
+	code, err := util.TOTPCode(secret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func TestChallengeTokenIsNotAnAccessToken(t *testing.T) {
+
+	server := NewTestServer(t, nil, nil)
+
+	challengeToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: util.RandomUsername(), Duration: twoFactorChallengeDuration, Purpose: token.PurposeChallenge})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodGet, "/beta/budgets", nil)
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+challengeToken)
+
+	recorder := httptest.NewRecorder()
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestDisableTwoFactorAPI(t *testing.T) {
+
+	user, plainPassword := buildTestUser(t)
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+	user.TotpSecret = pgtype.Text{String: secret, Valid: true}
+	user.TotpEnabled = true
+
+	testCases := []struct {
+		name          string
+		password      string
+		code          string
+		lockedOut     bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			password: plainPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTOTPLastStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					DisableTOTPTx(gomock.Any(), user.Username).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "WrongPassword",
+			password: "incorrectPassword",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateLoginEventParams) (db.LoginEvent, error) {
+						require.False(t, arg.Success)
+						return db.LoginEvent{}, nil
+					})
+				store.EXPECT().
+					DisableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "WrongCode",
+			password: plainPassword,
+			code:     "000000",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginEvent{}, nil)
+				store.EXPECT().
+					DisableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "LockedOut",
+			password:  plainPassword,
+			lockedOut: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					DisableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			if tc.lockedOut {
+				server.loginLimiter = limiter.NewMemoryLoginLimiter(limiter.LoginPolicy{LockoutThreshold: 1, LockoutDuration: time.Hour})
+				_, err := server.loginLimiter.RecordFailure(context.Background(), user.Username, "192.0.2.1")
+				require.NoError(t, err)
+			}
+			recorder := httptest.NewRecorder()
+
+			code := tc.code
+			if code == "" {
+				code = mustTOTPCode(t, secret)
+			}
+			data, err := json.Marshal(gin.H{"password": tc.password, "code": code})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/beta/user/2fa/disable", bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: user.Username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/util"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -13,12 +15,14 @@ const internal_error_message = "Internal server error. Please contact the admini
 type createUserRequest struct {
 	Username string `json:"username" binding:"required,min=5"`
 	Email    string `json:"email" binding:"required,email" example:"fname.lname@contoso.com"`
-	Password string `json:"password" binding:"required,min=10" example:"password123456"`
+	Password string `json:"password" binding:"required" example:"password123456"`
 } //@name CreateUserRequest
 
 type updateUserRequest struct {
 	Email    pgtype.Text `json:"email" example:"fname.lname@contoso.com" swaggertype:"string"`
 	Password pgtype.Text `json:"password" example:"password123456" swaggertype:"string"`
+	// required to change the password
+	CurrentPassword string `json:"current_password" example:"password654321"`
 } //@name UpdateUserRequest
 
 // Custom user struct used for user responses
@@ -35,6 +39,12 @@ type HTTPError struct {
 	Message string `json:"msg" example:"invalid request"`
 }
 
+// Response to passwords rejected by the password policy, with the rules they failed
+type PasswordPolicyError struct {
+	Error   string                   `json:"error" example:"password does not meet the password policy"`
+	Details []util.PasswordViolation `json:"details"`
+} //@name PasswordPolicyError
+
 type BudgetId struct {
 	BudgetId string `uri:"budget_id" binding:"required,uuid"`
 }
@@ -44,20 +54,36 @@ type AccountId struct {
 }
 
 type accountRequest struct {
-	Name    string `json:"name" binding:"required" example:"Chase Savings"`
-	Type    string `json:"type" binding:"required" example:"Savings"`
-	Balance int32  `json:"balance"`
+	Name string `json:"name" binding:"required" example:"Chase Savings"`
+	Type string `json:"type" binding:"required,oneof=checking savings cash credit_card line_of_credit mortgage auto_loan asset liability investment" example:"savings"`
+	// Starting balance in milliunits, or as a decimal in the currency of the account with balance_decimal
+	Balance        int64  `json:"balance" example:"1250000"`
+	BalanceDecimal string `json:"balance_decimal" example:"1250.00"`
+	// Currency of the account, the one of the budget if left out
+	CurrencyCode string `json:"currency_code" binding:"omitempty,iso4217" example:"USD"`
 }
 
+// An account, with its balances also as decimals in its currency
+type accountResponse struct {
+	db.Account
+	BalanceDecimal          string `json:"balance_decimal" example:"1250.00"`
+	ClearedBalanceDecimal   string `json:"cleared_balance_decimal" example:"1000.00"`
+	UnclearedBalanceDecimal string `json:"uncleared_balance_decimal" example:"250.00"`
+} //@name Account
+
 type updateAccountRequest struct {
-	Name             pgtype.Text        `json:"name" example:"Chase Savings" swaggertype:"string"`
-	Type             pgtype.Text        `json:"type" example:"Savings" swaggertype:"string"`
-	Closed           pgtype.Bool        `json:"closed" example:"false" swaggertype:"boolean"`
-	Note             pgtype.Text        `json:"note" swaggertype:"string"`
-	Balance          pgtype.Int4        `json:"balance" example:"100" swaggertype:"integer"`
-	ClearedBalance   pgtype.Int4        `json:"cleared_balance" example:"50" swaggertype:"integer"`
-	UnclearedBalance pgtype.Int4        `json:"uncleared_balance" example:"50" swaggertype:"integer"`
-	LastReconciledAt pgtype.Timestamptz `json:"last_reconciled_at" swaggertype:"string"`
+	Name   pgtype.Text `json:"name" example:"Chase Savings" swaggertype:"string"`
+	Type   pgtype.Text `json:"type" example:"savings" swaggertype:"string" enums:"checking,savings,cash,credit_card,line_of_credit,mortgage,auto_loan,asset,liability,investment"`
+	Closed pgtype.Bool `json:"closed" example:"false" swaggertype:"boolean"`
+	Note   pgtype.Text `json:"note" swaggertype:"string"`
+	// Balances in milliunits, or as decimals in the currency of the account in the fields ending with _decimal
+	Balance                 pgtype.Int8        `json:"balance" example:"1000" swaggertype:"integer"`
+	BalanceDecimal          string             `json:"balance_decimal" example:"1.00"`
+	ClearedBalance          pgtype.Int8        `json:"cleared_balance" example:"500" swaggertype:"integer"`
+	ClearedBalanceDecimal   string             `json:"cleared_balance_decimal" example:"0.50"`
+	UnclearedBalance        pgtype.Int8        `json:"uncleared_balance" example:"500" swaggertype:"integer"`
+	UnclearedBalanceDecimal string             `json:"uncleared_balance_decimal" example:"0.50"`
+	LastReconciledAt        pgtype.Timestamptz `json:"last_reconciled_at" swaggertype:"string"`
 }
 
 type budgetRequest struct {
@@ -70,11 +96,13 @@ type detailedBudgetResponse struct {
 	Name         string       `json:"name" example:"My USD Budget"`
 	CurrencyCode string       `json:"currency_code" example:"USD"`
 	Accounts     []db.Account `json:"accounts"`
+	// Inflows of on-budget accounts without category less the money assigned to categories, in milliunits
+	ReadyToAssign int64 `json:"ready_to_assign" example:"150000"`
 } //@name DetailedBudgetResponse
 
 type loginRequest struct {
 	Username string `json:"username" binding:"required,min=5"`
-	Password string `json:"password" binding:"required,min=10"`
+	Password string `json:"password" binding:"required"`
 }
 
 type loginResponse struct {
@@ -90,14 +118,29 @@ type loginResponse struct {
 	LastPasswordChange    time.Time `json:"last_password_change,omitempty" example:"2023-09-29T22:14:50+08:00"`
 } //@name LoginResponse
 
+// Returned by login instead of loginResponse when the user has two-factor authentication enabled
+type twoFactorChallengeResponse struct {
+	TwoFactorRequired       bool      `json:"two_factor_required" example:"true"`
+	ChallengeToken          string    `json:"challenge_token" example:"eyJhbGciOiJIUzI1Ni..."`
+	ChallengeTokenExpiresAt time.Time `json:"challenge_token_expires_at" example:"2023-10-30T22:14:50+08:00"`
+} //@name TwoFactorChallengeResponse
+
+// The code is either a TOTP code or a recovery code
+type loginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required" example:"eyJhbGciOiJIUzI1Ni..."`
+	Code           string `json:"code" binding:"required" example:"123456"`
+} //@name LoginTwoFactorRequest
+
 type renewTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required" example:"eyJhbGciOiJIUzI1Ni..."`
 } //@name RenewTokenRequest
 
 type renewTokenResponse struct {
-	SessionID            uuid.UUID `json:"session_id" example:"ea930f68-e192-407d..."`
-	AccessToken          string    `json:"access_token" example:"eyJhbGciOiJIUzI1Ni..."`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at" example:"2023-10-31T22:14:50+08:00"`
+	SessionID             uuid.UUID `json:"session_id" example:"ea930f68-e192-407d..."`
+	AccessToken           string    `json:"access_token" example:"eyJhbGciOiJIUzI1Ni..."`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at" example:"2023-10-31T22:14:50+08:00"`
+	RefreshToken          string    `json:"refresh_token" example:"eyJhbGciOiJIUzI1Ni..."`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at" example:"2023-11-28T22:14:50+08:00"`
 } //@name RenewTokenResponse
 
 type verifyEmailRequest struct {
@@ -121,12 +164,32 @@ type categoryRqst struct {
 	Name string `json:"name" binding:"required,min=2" example:"Rent"`
 }
 
+type updateCategoryRqst struct {
+	Name pgtype.Text `json:"name" example:"Rent" swaggertype:"string"`
+	// In milliunits, or as a decimal in the currency of the budget with assigned_decimal
+	Assigned        pgtype.Int8 `json:"assigned" example:"12000" swaggertype:"integer"`
+	AssignedDecimal string      `json:"assigned_decimal" example:"12.00"`
+}
+
 type categoryResponse struct {
 	CategoryGroupId uuid.UUID `json:"category_group_id"`
-	Name            string    `json:"name" example:"Rent"`
-	Categories      []db.Category
+	Name            string    `json:"name" example:"Living Expenses"`
+	Categories      []categoryBalanceResponse
 }
 
+type categoryBalanceResponse struct {
+	ID              uuid.UUID `json:"id" example:"ea930f68-e192-407d..."`
+	CategoryGroupID uuid.UUID `json:"category_group_id" example:"ea930f68-e192-407d..."`
+	Name            string    `json:"name" example:"Rent"`
+	// Set for the payment category of a credit card account
+	PaymentAccountID *uuid.UUID `json:"payment_account_id,omitempty" example:"ea930f68-e192-407d..."`
+	Assigned         int64      `json:"assigned" example:"12000"`
+	Activity         int64      `json:"activity" example:"-10000"`
+	Available        int64      `json:"available" example:"2000"`
+	CashOverspent    int64      `json:"cash_overspent" example:"0"`
+	CreditOverspent  int64      `json:"credit_overspent" example:"0"`
+} //@name CategoryBalance
+
 type PayeeId struct {
 	PayeeId string `uri:"payee_id" binding:"required,uuid"`
 }
@@ -141,14 +204,21 @@ type TransactionId struct {
 }
 
 type transactionRequest struct {
-	Account    string      `json:"account_id" binding:"required,uuid" swaggertype:"string"`
-	Date       pgtype.Date `json:"date" binding:"required" swaggertype:"string"`
-	Payee      string      `json:"payee_id" binding:"required,uuid" swaggertype:"string"`
-	Category   string      `json:"category_id" binding:"required,uuid" swaggertype:"string"`
-	Memo       string      `json:"memo" swaggertype:"string"`
-	Amount     int32       `json:"amount" binding:"required,number"`
-	Cleared    bool        `json:"cleared" binding:"boolean"`
-	Reconciled bool        `json:"reconciled" binding:"boolean"`
+	Account string      `json:"account_id" binding:"required,uuid" swaggertype:"string"`
+	Date    pgtype.Date `json:"date" binding:"required" swaggertype:"string"`
+	Payee   string      `json:"payee_id" binding:"required,uuid" swaggertype:"string"`
+	// Required for on-budget accounts, not allowed for tracking accounts
+	Category string `json:"category_id" binding:"omitempty,uuid" swaggertype:"string"`
+	Memo     string `json:"memo" swaggertype:"string"`
+	// In milliunits, or as a decimal in the currency of the account with amount_decimal
+	Amount        int64  `json:"amount" binding:"required_without=AmountDecimal" example:"-12500"`
+	AmountDecimal string `json:"amount_decimal" example:"-12.50"`
+	Cleared       bool   `json:"cleared" binding:"boolean"`
+	Reconciled    bool   `json:"reconciled" binding:"boolean"`
+	// Required for transfers to an account in another currency: the amount the other account receives, or sends,
+	// in its currency
+	TransferAmount        pgtype.Int8 `json:"transfer_amount" swaggertype:"integer" example:"92500"`
+	TransferAmountDecimal string      `json:"transfer_amount_decimal" example:"92.50"`
 } //@name TransactionRequest
 
 type transactionResponse struct {
@@ -157,8 +227,452 @@ type transactionResponse struct {
 	Payee      string      `json:"payee_name"`
 	Category   string      `json:"category_name"`
 	Memo       pgtype.Text `json:"memo"`
-	Amount     int32       `json:"amount"`
+	Amount     int64       `json:"amount"`
 	Approved   bool        `json:"approved"`
 	Cleared    bool        `json:"cleared"`
 	Reconciled bool        `json:"reconciled"`
+	// Currency of the account, in which the amount is
+	CurrencyCode  string `json:"currency_code" example:"EUR"`
+	AmountDecimal string `json:"amount_decimal" example:"-12.50"`
 } //@name TransactionResponse
+
+// A transaction, with its amount also as a decimal in the currency of its account
+type transactionViewResponse struct {
+	db.TransactionsView
+	AmountDecimal string `json:"amount_decimal" example:"-12.50"`
+} //@name TransactionView
+
+// A created transaction, with its amount also as a decimal in the currency of its account
+type createTransactionResponse struct {
+	db.Transaction
+	AmountDecimal string `json:"amount_decimal" example:"-12.50"`
+} //@name CreatedTransaction
+
+type WebhookId struct {
+	WebhookId string `uri:"webhook_id" binding:"required,uuid"`
+}
+
+type WebhookDeliveryId struct {
+	DeliveryId string `uri:"delivery_id" binding:"required,uuid"`
+}
+
+type webhookRequest struct {
+	Url string `json:"url" binding:"required,http_url" example:"https://example.com/hooks/gobudget"`
+	// transaction.updated is added once transactions can be updated
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=transaction.created account.reconciled category.overspent" example:"transaction.created,account.reconciled"`
+} //@name WebhookRequest
+
+type updateWebhookRequest struct {
+	// a pointer, unlike pgtype.Text, can be validated
+	Url *string `json:"url" binding:"omitempty,http_url" example:"https://example.com/hooks/gobudget"`
+	// transaction.updated is added once transactions can be updated
+	Events []string    `json:"events" binding:"omitempty,min=1,dive,oneof=transaction.created account.reconciled category.overspent" example:"transaction.created"`
+	Active pgtype.Bool `json:"active" example:"true" swaggertype:"boolean"`
+} //@name UpdateWebhookRequest
+
+// The secret is only returned when the webhook is created
+type webhookResponse struct {
+	ID        uuid.UUID `json:"id" example:"ea930f68-e192-407d..."`
+	BudgetID  uuid.UUID `json:"budget_id" example:"ea930f68-e192-407d..."`
+	Url       string    `json:"url" example:"https://example.com/hooks/gobudget"`
+	Secret    string    `json:"secret,omitempty" example:"4f3c2a..."`
+	Events    []string  `json:"events" example:"transaction.created,account.reconciled"`
+	Active    bool      `json:"active" example:"true"`
+	CreatedAt time.Time `json:"created_at" example:"2023-09-29T22:14:50+08:00"`
+} //@name WebhookResponse
+
+type webhookDeliveryResponse struct {
+	ID          uuid.UUID       `json:"id" example:"ea930f68-e192-407d..."`
+	WebhookID   uuid.UUID       `json:"webhook_id" example:"ea930f68-e192-407d..."`
+	Event       string          `json:"event" example:"transaction.created"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+	Redelivery  bool            `json:"redelivery" example:"false"`
+	Attempts    int32           `json:"attempts" example:"1"`
+	StatusCode  *int32          `json:"status_code" example:"200"`
+	Error       *string         `json:"error"`
+	DeliveredAt *time.Time      `json:"delivered_at" example:"2023-09-29T22:14:50+08:00"`
+	CreatedAt   time.Time       `json:"created_at" example:"2023-09-29T22:14:50+08:00"`
+} //@name WebhookDeliveryResponse
+
+type PersonalAccessTokenId struct {
+	TokenId string `uri:"token_id" binding:"required,uuid"`
+}
+
+type personalAccessTokenRequest struct {
+	Name      string     `json:"name" binding:"required,min=2" example:"Home Assistant"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1" example:"read,budget:ea930f68-e192-407d..."`
+	ExpiresAt *time.Time `json:"expires_at" example:"2024-09-29T22:14:50+08:00"`
+} //@name PersonalAccessTokenRequest
+
+// The token is only returned when it is created
+type personalAccessTokenResponse struct {
+	ID         uuid.UUID  `json:"id" example:"ea930f68-e192-407d..."`
+	Name       string     `json:"name" example:"Home Assistant"`
+	Token      string     `json:"token,omitempty" example:"gbpat_4f3c2a..."`
+	Scopes     []string   `json:"scopes" example:"read,budget:ea930f68-e192-407d..."`
+	ExpiresAt  *time.Time `json:"expires_at" example:"2024-09-29T22:14:50+08:00"`
+	LastUsedAt *time.Time `json:"last_used_at" example:"2023-09-29T22:14:50+08:00"`
+	CreatedAt  time.Time  `json:"created_at" example:"2023-09-29T22:14:50+08:00"`
+} //@name PersonalAccessTokenResponse
+
+type OAuthClientId struct {
+	ClientId string `uri:"client_id" binding:"required,uuid"`
+}
+
+type oauthClientRequest struct {
+	Name         string   `json:"name" binding:"required,min=2" example:"Budget Sheets"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,dive,url" example:"https://sheets.example.com/callback"`
+	Scopes       []string `json:"scopes" binding:"required,min=1,dive,oneof=read write" example:"read"`
+	// Confidential clients, i.e. server-side apps, get a client secret. Public clients only use PKCE.
+	Confidential bool `json:"confidential" example:"true"`
+} //@name OAuthClientRequest
+
+// The secret is only returned when the client is created
+type oauthClientResponse struct {
+	ID           uuid.UUID `json:"client_id" example:"ea930f68-e192-407d..."`
+	Name         string    `json:"name" example:"Budget Sheets"`
+	Secret       string    `json:"client_secret,omitempty" example:"4f3c2a..."`
+	RedirectURIs []string  `json:"redirect_uris" example:"https://sheets.example.com/callback"`
+	Scopes       []string  `json:"scopes" example:"read"`
+	Confidential bool      `json:"confidential" example:"true"`
+	CreatedAt    time.Time `json:"created_at" example:"2023-09-29T22:14:50+08:00"`
+} //@name OAuthClientResponse
+
+// Parameters of the authorization request (RFC 6749 4.1.1 and RFC 7636 4.3).
+// The consent decision is only used when posting the request.
+type authorizeRequest struct {
+	ResponseType        string `json:"response_type" form:"response_type" binding:"required,eq=code" example:"code"`
+	ClientID            string `json:"client_id" form:"client_id" binding:"required,uuid" example:"ea930f68-e192-407d..."`
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri" binding:"required" example:"https://sheets.example.com/callback"`
+	Scope               string `json:"scope" form:"scope" binding:"required" example:"read budget:ea930f68-e192-407d..."`
+	State               string `json:"state" form:"state" example:"af0ifjsldkj"`
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge" binding:"required" example:"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"`
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method" binding:"required,eq=S256" example:"S256"`
+	Approve             bool   `json:"approve" form:"approve" example:"true"`
+} //@name AuthorizeRequest
+
+// What the user is asked to consent to
+type authorizeResponse struct {
+	ClientID    uuid.UUID `json:"client_id" example:"ea930f68-e192-407d..."`
+	ClientName  string    `json:"client_name" example:"Budget Sheets"`
+	RedirectURI string    `json:"redirect_uri" example:"https://sheets.example.com/callback"`
+	Scopes      []string  `json:"scopes" example:"read,budget:ea930f68-e192-407d..."`
+} //@name AuthorizeResponse
+
+// Where the user agent should be sent after the consent decision
+type authorizeDecisionResponse struct {
+	RedirectURI string `json:"redirect_uri" example:"https://sheets.example.com/callback?code=4f3c2a...&state=af0ifjsldkj"`
+} //@name AuthorizeDecisionResponse
+
+// Token request (RFC 6749 4.1.3 and 6), sent form-encoded. Clients can also authenticate with HTTP basic auth.
+type oauthTokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type oauthTokenResponse struct {
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1Ni..."`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
+	RefreshToken string `json:"refresh_token,omitempty" example:"eyJhbGciOiJIUzI1Ni..."`
+	Scope        string `json:"scope" example:"read budget:ea930f68-e192-407d..."`
+} //@name OAuthTokenResponse
+
+type oauthErrorResponse struct {
+	Error            string `json:"error" example:"invalid_grant"`
+	ErrorDescription string `json:"error_description,omitempty" example:"authorization code has expired"`
+} //@name OAuthError
+
+type totpEnrollmentResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/gobudget:rjoooidggt?secret=JBSWY3DPEHPK3PXP&issuer=gobudget"`
+} //@name TOTPEnrollmentResponse
+
+type totpConfirmRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" example:"123456"`
+} //@name TOTPConfirmRequest
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k7mzq-4hp2x"`
+} //@name RecoveryCodesResponse
+
+// The code is either a TOTP code or a recovery code
+type disableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required" example:"password123456"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+} //@name DisableTwoFactorRequest
+
+type magicLinkRequest struct {
+	Email string `json:"email" binding:"required,email" example:"fname.lname@contoso.com"`
+} //@name MagicLinkRequest
+
+type verifyMagicLinkRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+type emailChangeRequest struct {
+	Email    string `json:"email" binding:"required,email" example:"fname.lname@contoso.com"`
+	Password string `json:"password" binding:"required" example:"password123456"`
+} //@name EmailChangeRequest
+
+type passwordResetPageRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// Also posted as a form by the pages of the email change links
+type emailChangeTokenRequest struct {
+	Token string `json:"token" form:"token" binding:"required" example:"4f3c2a..."`
+} //@name EmailChangeTokenRequest
+
+type oidcCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+type passwordResetRequest struct {
+	Email string `json:"email" binding:"required,email" example:"fname.lname@contoso.com"`
+} //@name PasswordResetRequest
+
+// Also posted as a form by the password reset page
+type passwordResetConfirmRequest struct {
+	Token    string `json:"token" form:"token" binding:"required" example:"4f3c2a..."`
+	Password string `json:"password" form:"password" binding:"required" example:"password123456"`
+} //@name PasswordResetConfirmRequest
+
+type SessionId struct {
+	SessionId string `uri:"session_id" binding:"required,uuid"`
+}
+
+type sessionResponse struct {
+	ID        uuid.UUID `json:"id" example:"ea930f68-e192-407d..."`
+	UserAgent string    `json:"user_agent" example:"Mozilla/5.0 (X11; Linux x86_64)..."`
+	ClientIp  string    `json:"client_ip" example:"203.0.113.7"`
+	// Set for sessions of OAuth clients
+	OAuthClientID *uuid.UUID `json:"oauth_client_id,omitempty" example:"ea930f68-e192-407d..."`
+	// Whether this is the session of the request
+	Current   bool      `json:"current" example:"true"`
+	ExpiresAt time.Time `json:"expires_at" example:"2023-10-31T22:14:50+08:00"`
+	CreatedAt time.Time `json:"created_at" example:"2023-09-29T22:14:50+08:00"`
+} //@name SessionResponse
+
+type loginEventResponse struct {
+	Success   bool   `json:"success" example:"true"`
+	UserAgent string `json:"user_agent" example:"Mozilla/5.0 (X11; Linux x86_64)..."`
+	ClientIp  string `json:"client_ip" example:"203.0.113.7"`
+	// Set for successful logins
+	SessionID *uuid.UUID `json:"session_id,omitempty" example:"ea930f68-e192-407d..."`
+	CreatedAt time.Time  `json:"created_at" example:"2023-09-29T22:14:50+08:00"`
+} //@name LoginEvent
+
+type revokeLoginRequest struct {
+	Token string `json:"token" form:"token" binding:"required" example:"4f3c2a..."`
+} //@name RevokeLoginRequest
+
+type UsernameUri struct {
+	Username string `uri:"username" binding:"required"`
+}
+
+type listUsersRequest struct {
+	Search   string `form:"search"`
+	Page     int32  `form:"page" binding:"omitempty,min=1"`
+	PageSize int32  `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// User as seen by administrators
+type adminUserResponse struct {
+	Username           string    `json:"username" example:"rjoooidggt"`
+	Email              string    `json:"email" example:"fname.lname@contoso.com"`
+	EmailVerified      bool      `json:"email_verified" example:"true"`
+	TotpEnabled        bool      `json:"totp_enabled" example:"false"`
+	Roles              []string  `json:"roles" example:"admin"`
+	CreatedAt          time.Time `json:"created_at" example:"2023-09-29T22:14:50+08:00"`
+	LastPasswordChange time.Time `json:"last_password_change" example:"2023-09-29T22:14:50+08:00"`
+} //@name AdminUser
+
+type adminUserListResponse struct {
+	Users    []adminUserResponse `json:"users"`
+	Total    int64               `json:"total" example:"42"`
+	Page     int32               `json:"page" example:"1"`
+	PageSize int32               `json:"page_size" example:"20"`
+} //@name AdminUserList
+
+type auditLogRequest struct {
+	Username string `form:"username"`
+	Page     int32  `form:"page" binding:"omitempty,min=1"`
+	PageSize int32  `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+type auditLogResponse struct {
+	ID             int64     `json:"id" example:"1"`
+	AdminUsername  string    `json:"admin_username" example:"admin"`
+	Action         string    `json:"action" example:"block_sessions"`
+	TargetUsername string    `json:"target_username" example:"rjoooidggt"`
+	Details        string    `json:"details,omitempty"`
+	ClientIp       string    `json:"client_ip" example:"127.0.0.1"`
+	CreatedAt      time.Time `json:"created_at" example:"2023-09-29T22:14:50+08:00"`
+} //@name AuditLogEntry
+
+type loanRequest struct {
+	// In milliunits, or as a decimal in the currency of the account with principal_decimal
+	Principal        int64  `json:"principal" binding:"required_without=PrincipalDecimal,min=0" example:"250000000"`
+	PrincipalDecimal string `json:"principal_decimal" example:"250000.00"`
+	// Annual interest rate, in percent
+	InterestRate float64     `json:"interest_rate" binding:"min=0,max=100" example:"6.5"`
+	TermMonths   int32       `json:"term_months" binding:"required,min=1,max=600" example:"360"`
+	StartDate    pgtype.Date `json:"start_date" binding:"required" swaggertype:"string" example:"2024-01-15"`
+	PaymentDay   int32       `json:"payment_day" binding:"required,min=1,max=28" example:"1"`
+	// Paid every month on top of the scheduled payment, in milliunits or as a decimal with extra_payment_decimal
+	ExtraPayment        int64  `json:"extra_payment" binding:"min=0" example:"100000"`
+	ExtraPaymentDecimal string `json:"extra_payment_decimal" example:"100.00"`
+}
+
+type loanPaymentResponse struct {
+	Number int `json:"number" example:"1"`
+	// Set for the payments that were made, which are transfers to the loan account
+	TransactionID *uuid.UUID  `json:"transaction_id,omitempty" example:"ea930f68-e192-407d..."`
+	Date          pgtype.Date `json:"date" swaggertype:"string" example:"2024-02-01"`
+	Amount        int64       `json:"amount" example:"1580180"`
+	Principal     int64       `json:"principal" example:"226010"`
+	Interest      int64       `json:"interest" example:"1354170"`
+	Balance       int64       `json:"balance" example:"249773990"`
+} //@name LoanPayment
+
+type loanResponse struct {
+	AccountID    uuid.UUID   `json:"account_id" example:"ea930f68-e192-407d..."`
+	Principal    int64       `json:"principal" example:"250000000"`
+	InterestRate float64     `json:"interest_rate" example:"6.5"`
+	TermMonths   int32       `json:"term_months" example:"360"`
+	StartDate    pgtype.Date `json:"start_date" swaggertype:"string" example:"2024-01-15"`
+	PaymentDay   int32       `json:"payment_day" example:"1"`
+	ExtraPayment int64       `json:"extra_payment" example:"100000"`
+	// Scheduled monthly payment, without the extra payment
+	MonthlyPayment int64 `json:"monthly_payment" example:"1580180"`
+	// Principal left after the payments that were made
+	Balance       int64       `json:"balance" example:"249773990"`
+	InterestPaid  int64       `json:"interest_paid" example:"1354170"`
+	TotalInterest int64       `json:"total_interest" example:"263865000"`
+	PayoffDate    pgtype.Date `json:"payoff_date" swaggertype:"string" example:"2049-03-01"`
+	// Payments that were made
+	Payments []loanPaymentResponse `json:"payments"`
+	// Payments left until the loan is paid off
+	Schedule []loanPaymentResponse `json:"schedule"`
+} //@name Loan
+
+type investmentTransactionRequest struct {
+	Date   pgtype.Date `json:"date" binding:"required" swaggertype:"string" example:"2024-05-02"`
+	Type   string      `json:"type" binding:"required,oneof=buy sell dividend" example:"buy"`
+	Symbol string      `json:"symbol" binding:"required,max=20" example:"VWCE"`
+	// Number of shares bought or sold, left out for dividends
+	Quantity float64 `json:"quantity" binding:"min=0" example:"10.5"`
+	// Cash paid for a buy, received for a sell or a dividend, in milliunits or as a decimal with amount_decimal
+	Amount        int64       `json:"amount" binding:"min=0" example:"1150000"`
+	AmountDecimal string      `json:"amount_decimal" example:"1150.00"`
+	Memo          pgtype.Text `json:"memo" swaggertype:"string"`
+}
+
+// Date of the holdings and the net worth, today if left out
+type asOfDateRequest struct {
+	Date string `form:"date" binding:"omitempty,datetime=2006-01-02" example:"2024-05-31"`
+}
+
+type holdingResponse struct {
+	Symbol    string  `json:"symbol" example:"VWCE"`
+	Quantity  float64 `json:"quantity" example:"10.5"`
+	CostBasis int64   `json:"cost_basis" example:"1150000"`
+	// Last price on or before the date, left out if the security has no price yet
+	Price     *int64       `json:"price,omitempty" example:"118200"`
+	PriceDate *pgtype.Date `json:"price_date,omitempty" swaggertype:"string" example:"2024-05-31"`
+	// Valued at the cost basis when there is no price
+	MarketValue int64 `json:"market_value" example:"1241100"`
+	Gain        int64 `json:"gain" example:"91100"`
+} //@name Holding
+
+type securityPriceRequest struct {
+	Symbol string      `json:"symbol" binding:"required,max=20" example:"VWCE"`
+	Date   pgtype.Date `json:"date" binding:"required" swaggertype:"string" example:"2024-05-31"`
+	// In milliunits of the currency of the budget, or as a decimal with price_decimal
+	Price        int64  `json:"price" binding:"min=0" example:"118200"`
+	PriceDecimal string `json:"price_decimal" example:"118.20"`
+}
+
+type securityPricesRequest struct {
+	Prices []securityPriceRequest `json:"prices" binding:"required,min=1,max=1000,dive"`
+}
+
+type securityPricesQuery struct {
+	Symbol string `form:"symbol" example:"VWCE"`
+}
+
+type netWorthAccountResponse struct {
+	AccountID    uuid.UUID `json:"account_id" example:"ea930f68-e192-407d..."`
+	Name         string    `json:"name" example:"Broker"`
+	Type         string    `json:"type" example:"investment"`
+	CurrencyCode string    `json:"currency_code" example:"USD"`
+	// On the date, in the currency of the account. The cash of investment accounts.
+	Balance int64 `json:"balance" example:"50000"`
+	// Market value of the holdings of investment accounts, in the currency of the account
+	MarketValue int64 `json:"market_value" example:"1241100"`
+	// In the currency of the budget
+	Total int64 `json:"total" example:"1190550"`
+} //@name NetWorthAccount
+
+type netWorthResponse struct {
+	Date         pgtype.Date               `json:"date" swaggertype:"string" example:"2024-05-31"`
+	CurrencyCode string                    `json:"currency_code" example:"EUR"`
+	Assets       int64                     `json:"assets" example:"1291100"`
+	Liabilities  int64                     `json:"liabilities" example:"-250000"`
+	NetWorth     int64                     `json:"net_worth" example:"1041100"`
+	Accounts     []netWorthAccountResponse `json:"accounts"`
+} //@name NetWorth
+
+type exchangeRateRequest struct {
+	Date pgtype.Date `json:"date" binding:"required" swaggertype:"string" example:"2024-05-31"`
+	From string      `json:"from" binding:"required,iso4217" example:"EUR"`
+	To   string      `json:"to" binding:"required,iso4217,nefield=From" example:"USD"`
+	// Worth of one unit of the from currency in the to currency
+	Rate float64 `json:"rate" binding:"required,gt=0" example:"1.0812"`
+}
+
+type exchangeRatesRequest struct {
+	Rates []exchangeRateRequest `json:"rates" binding:"required,min=1,max=1000,dive"`
+}
+
+type exchangeRatesQuery struct {
+	From string `form:"from" binding:"omitempty,iso4217" example:"EUR"`
+	To   string `form:"to" binding:"omitempty,iso4217" example:"USD"`
+}
+
+type exchangeRateResponse struct {
+	Date pgtype.Date `json:"date" swaggertype:"string" example:"2024-05-31"`
+	From string      `json:"from" example:"EUR"`
+	To   string      `json:"to" example:"USD"`
+	Rate float64     `json:"rate" example:"1.0812"`
+} //@name ExchangeRate
+
+type fxTransferResponse struct {
+	TransactionID     uuid.UUID   `json:"transaction_id" example:"ea930f68-e192-407d..."`
+	Date              pgtype.Date `json:"date" swaggertype:"string" example:"2024-05-31"`
+	AccountID         uuid.UUID   `json:"account_id" example:"ea930f68-e192-407d..."`
+	CurrencyCode      string      `json:"currency_code" example:"EUR"`
+	Amount            int64       `json:"amount" example:"-100000"`
+	TransferAccountID uuid.UUID   `json:"transfer_account_id" example:"ea930f68-e192-407d..."`
+	TransferCurrency  string      `json:"transfer_currency_code" example:"USD"`
+	TransferAmount    int64       `json:"transfer_amount" example:"107900"`
+	// Both sides in the currency of the budget at the rates of the date, and the difference between them
+	Sent     int64 `json:"sent" example:"-100000"`
+	Received int64 `json:"received" example:"99790"`
+	Gain     int64 `json:"gain" example:"-210"`
+} //@name FXTransfer
+
+type fxGainsResponse struct {
+	CurrencyCode string               `json:"currency_code" example:"EUR"`
+	Total        int64                `json:"total" example:"-210"`
+	Transfers    []fxTransferResponse `json:"transfers"`
+} //@name FXGains
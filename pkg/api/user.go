@@ -21,12 +21,13 @@ import (
 //	@Summary	Create user
 //	@Schemes
 //	@Description	Create a new user account. An email is sent asking the user to verify their email.
+//	@Description	The password must meet the password policy, otherwise the rules it fails are returned.
 //	@Tags			User
 //	@Accept			json
 //	@Param			account	body	createUserRequest	true	"Create a new user"
 //	@Produce		json
 //	@Success		201	{object}	userResponse
-//	@Failure		400	{object}	HTTPError
+//	@Failure		400	{object}	PasswordPolicyError
 //	@Failure		401	{object}	HTTPError
 //	@Failure		403	{object}	HTTPError
 //	@Failure		500	{object}	HTTPError
@@ -39,6 +40,9 @@ func (s *Server) createUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
 		return
 	}
+	if !s.checkPasswordPolicy(ctx, rqst.Password, rqst.Username, rqst.Email) {
+		return
+	}
 
 	hashedPassword, err := util.HashPassword(rqst.Password)
 	if err != nil {
@@ -89,14 +93,17 @@ func (s *Server) createUser(ctx *gin.Context) {
 //
 //	@Summary	Update user
 //	@Schemes
-//	@Description	Update the authenticated user's account.
+//	@Description	Update the authenticated user's account. Changing the password requires the current password.
+//	@Description	A new password must meet the password policy, otherwise the rules it fails are returned.
+//	@Description	The email cannot be changed here, see POST /user/email.
 //	@Tags			User
 //	@Param			account	body	updateUserRequest	true	"Update account"
 //	@Produce		json
 //	@Success		200	{object}	userResponse
-//	@Failure		400	{object}	HTTPError
+//	@Failure		400	{object}	PasswordPolicyError
 //	@Failure		401	{object}	HTTPError
 //	@Failure		404	{object}	HTTPError
+//	@Failure		429	{object}	HTTPError
 //	@Failure		500	{object}	HTTPError
 //	@Router			/user [put]
 //	@Security		Bearer
@@ -122,19 +129,32 @@ func (s *Server) updateUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse("cannot update user, invalid request"))
 		return
 	}
+	// the email is changed with a confirmation from the new address, see requestEmailChange
+	if rqst.Email.Valid && rqst.Email.String != userBefore.Email {
+		ctx.JSON(http.StatusBadRequest, errorResponse("the email is changed with POST /user/email"))
+		return
+	}
 	arg := db.UpdateUserParams{
-		Email:    rqst.Email,
 		Password: rqst.Password,
 		Username: userBefore.Username,
 	}
 
-	if arg.Email.Valid && userBefore.Email != arg.Email.String {
-		slog.Info("Update EmailVerified")
-		arg.EmailVerified.Bool = false
-		arg.EmailVerified.Valid = true
-	}
 	if arg.Password.Valid {
+		// Re-authenticate the user, so that a stolen session cannot take over the account,
+		// nor guess the password faster than the login
+		clientIp := ctx.ClientIP()
+		if s.loginThrottled(ctx, userBefore.Username, clientIp) {
+			return
+		}
+		if err := util.CheckPassword(userBefore.Password, rqst.CurrentPassword); err != nil {
+			s.recordLoginFailure(ctx, userBefore.Username, clientIp, true)
+			ctx.JSON(http.StatusUnauthorized, errorResponse("invalid current password"))
+			return
+		}
 		if err := util.CheckPassword(userBefore.Password, arg.Password.String); err != nil {
+			if !s.checkPasswordPolicy(ctx, arg.Password.String, userBefore.Username, userBefore.Email) {
+				return
+			}
 			hashedPassword, err := util.HashPassword(rqst.Password.String)
 			if err != nil {
 				ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
@@ -143,19 +163,13 @@ func (s *Server) updateUser(ctx *gin.Context) {
 			arg.Password.String = hashedPassword
 			arg.LastPasswordChange.Valid = true
 			arg.LastPasswordChange.Time = time.Now()
+		} else {
+			// same password, keep the current hash
+			arg.Password.Valid = false
 		}
 	}
 
-	fmt.Printf("Updating LastPasswordChange to: %v\n", arg.LastPasswordChange.Time)
-
-	// Call the transaction to update the user
-	afterUpdateFn := func(createdUser db.UserParams) error {
-		taskPayload := &worker.SendEmailPayload{
-			Username: createdUser.Username,
-		}
-		return s.taskDistributor.DistributeSendEmail(ctx, taskPayload, worker.TaskSendVerifyEmail)
-	}
-	updatedUser, err := s.db.UpdateUserTx(ctx, arg, afterUpdateFn)
+	updatedUser, err := s.db.UpdateUser(ctx, arg)
 	if err != nil {
 		slog.Error(err.Error())
 		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
@@ -237,3 +251,19 @@ func (s *Server) deleteUser(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"msg": "user has been deleted"})
 }
+
+// Checks a new password against the password policy. The user inputs are the username and email, which
+// weaken passwords that contain them. Writes a 400 response with the failed rules and returns false if the password is rejected.
+func (s *Server) checkPasswordPolicy(ctx *gin.Context, password string, userInputs ...string) bool {
+
+	violations := s.passwordPolicy.Check(password, userInputs...)
+	if len(violations) == 0 {
+		return true
+	}
+	ctx.JSON(http.StatusBadRequest, PasswordPolicyError{
+		Error:   "password does not meet the password policy",
+		Details: violations,
+	})
+
+	return false
+}
@@ -2,13 +2,19 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/limiter"
 	mock "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -39,6 +45,30 @@ func TestCreateUserAPI(t *testing.T) {
 				require.Equal(t, recorder.Code, http.StatusCreated)
 			},
 		},
+		{
+			name: "PasswordPolicy",
+			body: gin.H{
+				"username": "validuser",
+				"email":    "validuser@gmail.com",
+				"password": "validuser",
+			},
+			buildStubs: func(store *mock.MockStore, dist *mock.MockTaskDistributor) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var resp PasswordPolicyError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				rules := []string{}
+				for _, v := range resp.Details {
+					rules = append(rules, v.Rule)
+				}
+				require.Equal(t, []string{util.PasswordRuleMinLength, util.PasswordRuleStrength}, rules)
+			},
+		},
 		{
 			name: "InvalidEmail",
 			body: gin.H{
@@ -84,3 +114,133 @@ func TestCreateUserAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateUserAPI(t *testing.T) {
+
+	user, plainPassword := buildTestUser(t)
+	newPassword := "Corr3ct-Horse-Battery-Staple"
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		lockedOut     bool
+		buildStubs    func(store *mock.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"password":         newPassword,
+				"current_password": plainPassword,
+			},
+			buildStubs: func(store *mock.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.UpdateUserParams) (db.User, error) {
+						require.NoError(t, util.CheckPassword(arg.Password.String, newPassword))
+						require.True(t, arg.LastPasswordChange.Valid)
+						return user, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongCurrentPassword",
+			body: gin.H{
+				"password":         newPassword,
+				"current_password": "incorrectPassword",
+			},
+			buildStubs: func(store *mock.MockStore) {
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateLoginEventParams) (db.LoginEvent, error) {
+						require.False(t, arg.Success)
+						return db.LoginEvent{}, nil
+					})
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MissingCurrentPassword",
+			body: gin.H{
+				"password": newPassword,
+			},
+			buildStubs: func(store *mock.MockStore) {
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateLoginEventParams) (db.LoginEvent, error) {
+						require.False(t, arg.Success)
+						return db.LoginEvent{}, nil
+					})
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "LockedOut",
+			body: gin.H{
+				"password":         newPassword,
+				"current_password": plainPassword,
+			},
+			lockedOut: true,
+			buildStubs: func(store *mock.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUserByUsername(gomock.Any(), user.Username).
+				Times(1).
+				Return(user, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			if tc.lockedOut {
+				server.loginLimiter = limiter.NewMemoryLoginLimiter(limiter.LoginPolicy{LockoutThreshold: 1, LockoutDuration: time.Hour})
+				_, err := server.loginLimiter.RecordFailure(context.Background(), user.Username, "192.0.2.1")
+				require.NoError(t, err)
+			}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/beta/user", bytes.NewReader(data))
+			require.NoError(t, err)
+			accessToken, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: user.Username, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
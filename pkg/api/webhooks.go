@@ -0,0 +1,524 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Events that webhooks can subscribe to. Keep in sync with the oneof validation in webhookRequest. There is no
+// transaction.updated yet: transactions cannot be changed until updateTransaction exists, which is when it is added.
+const (
+	WebhookEventTransactionCreated = "transaction.created"
+	WebhookEventAccountReconciled  = "account.reconciled"
+	WebhookEventCategoryOverspent  = "category.overspent"
+)
+
+// Publishes category.overspent when a new transaction takes the money available in its category below zero. The
+// balances are only computed if a webhook of the budget is subscribed to the event.
+func (s *Server) publishCategoryOverspent(ctx *gin.Context, budget db.Budget, transaction db.Transaction) {
+
+	if !transaction.CategoryID.Valid || transaction.Amount >= 0 {
+		return
+	}
+	webhooks, err := s.db.GetSubscribedWebhooks(ctx, db.GetSubscribedWebhooksParams{
+		BudgetID: budget.ID,
+		Event:    WebhookEventCategoryOverspent,
+	})
+	if err != nil {
+		slog.Error("cannot get webhooks", "event", WebhookEventCategoryOverspent, "errmsg", err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	categories, err := s.db.GetBudgetCategories(ctx, budget.ID)
+	if err != nil {
+		slog.Error("cannot get categories", "errmsg", err)
+		return
+	}
+	accounts, err := s.db.GetAccounts(ctx, budget.ID)
+	if err != nil {
+		slog.Error("cannot get accounts", "errmsg", err)
+		return
+	}
+	payees, err := s.db.GetPayees(ctx, budget.ID)
+	if err != nil {
+		slog.Error("cannot get payees", "errmsg", err)
+		return
+	}
+	transactions, err := s.db.GetTransactions(ctx, budget.ID)
+	if err != nil {
+		slog.Error("cannot get transactions", "errmsg", err)
+		return
+	}
+	rates, err := s.loadExchangeRates(ctx, budget, accounts, pgtype.Date{})
+	if err != nil {
+		slog.Error("cannot get exchange rates", "errmsg", err)
+		return
+	}
+	transactions, err = convertTransactions(rates, budget, accounts, transactions)
+	if err != nil {
+		slog.Error("cannot convert transactions", "errmsg", err)
+		return
+	}
+
+	categoryId := uuid.UUID(transaction.CategoryID.Bytes)
+	balances, err := computeCategoryBalances(categories, accounts, payees, transactions)
+	if err != nil {
+		slog.Error("cannot compute category balances", "errmsg", err)
+		return
+	}
+	after := balances[categoryId]
+	if after.Available >= 0 {
+		return
+	}
+	others := slices.DeleteFunc(transactions, func(t db.Transaction) bool {
+		return t.ID == transaction.ID
+	})
+	balances, err = computeCategoryBalances(categories, accounts, payees, others)
+	if err != nil {
+		slog.Error("cannot compute category balances", "errmsg", err)
+		return
+	}
+	if balances[categoryId].Available < 0 {
+		// already overspent
+		return
+	}
+
+	for _, c := range categories {
+		if c.ID == categoryId {
+			s.publishWebhookEvent(ctx, budget.ID, WebhookEventCategoryOverspent, newCategoryBalanceResponse(c, after))
+			return
+		}
+	}
+}
+
+// Maximum number of deliveries returned in the delivery log
+const webhookDeliveryLogSize = 50
+
+// Body of every webhook delivery
+type webhookEvent struct {
+	Event     string    `json:"event"`
+	BudgetID  uuid.UUID `json:"budget_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Creates a delivery for every active webhook of the budget subscribed to the event and hands it to the worker.
+// Failures are logged and never affect the request that triggered the event.
+func (s *Server) publishWebhookEvent(ctx *gin.Context, budgetId uuid.UUID, event string, data any) {
+
+	webhooks, err := s.db.GetSubscribedWebhooks(ctx, db.GetSubscribedWebhooksParams{
+		BudgetID: budgetId,
+		Event:    event,
+	})
+	if err != nil {
+		slog.Error("cannot get webhooks", "event", event, "errmsg", err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(webhookEvent{
+		Event:     event,
+		BudgetID:  budgetId,
+		CreatedAt: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		slog.Error("cannot marshal webhook event", "event", event, "errmsg", err)
+		return
+	}
+
+	for _, w := range webhooks {
+		delivery, err := s.db.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			WebhookID: w.ID,
+			Event:     event,
+			Payload:   payload,
+		})
+		if err != nil {
+			slog.Error("cannot create webhook delivery", "webhook", w.ID, "errmsg", err)
+			continue
+		}
+		err = s.taskDistributor.DistributeDeliverWebhook(ctx, &worker.DeliverWebhookPayload{
+			DeliveryID: delivery.ID,
+		})
+		if err != nil {
+			slog.Error("cannot distribute webhook delivery", "delivery", delivery.ID, "errmsg", err)
+		}
+	}
+}
+
+// Parses the webhook ID in the URL and retrieves the webhook from the budget.
+// On failure, the response has already been written.
+func (s *Server) getBudgetWebhook(ctx *gin.Context, budgetId uuid.UUID) (db.Webhook, error) {
+
+	var webhookId WebhookId
+	if err := ctx.ShouldBindUri(&webhookId); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return db.Webhook{}, err
+	}
+	webhookUuid, err := uuid.Parse(webhookId.WebhookId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return db.Webhook{}, err
+	}
+
+	webhook, err := s.db.GetWebhook(ctx, db.GetWebhookParams{
+		BudgetID: budgetId,
+		ID:       webhookUuid,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse("webhook not found"))
+			return db.Webhook{}, err
+		}
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return db.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func newWebhookResponse(w db.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:        w.ID,
+		BudgetID:  w.BudgetID,
+		Url:       w.Url,
+		Events:    w.Events,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+func newWebhookDeliveryResponse(d db.WebhookDelivery) webhookDeliveryResponse {
+	resp := webhookDeliveryResponse{
+		ID:         d.ID,
+		WebhookID:  d.WebhookID,
+		Event:      d.Event,
+		Payload:    d.Payload,
+		Redelivery: d.Redelivery,
+		Attempts:   d.Attempts,
+		CreatedAt:  d.CreatedAt,
+	}
+	if d.StatusCode.Valid {
+		resp.StatusCode = &d.StatusCode.Int32
+	}
+	if d.Error.Valid {
+		resp.Error = &d.Error.String
+	}
+	if d.DeliveredAt.Valid {
+		resp.DeliveredAt = &d.DeliveredAt.Time
+	}
+	return resp
+}
+
+// getWebhooks godoc
+//
+//	@Summary	List webhooks
+//	@Schemes
+//	@Description	List all webhooks of a budget.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Tags			Webhooks
+//	@Produce		json
+//	@Success		200	{object}	[]webhookResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/webhooks [get]
+//	@Security		Bearer
+func (s *Server) getWebhooks(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	webhooks, err := s.db.GetWebhooks(ctx, budgetId)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	resp := make([]webhookResponse, len(webhooks))
+	for i := range webhooks {
+		resp[i] = newWebhookResponse(webhooks[i])
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// createWebhook godoc
+//
+//	@Summary	Create a webhook
+//	@Schemes
+//	@Description	Subscribe a URL to budget events. The response contains the secret used to sign deliveries, which is not shown again. Events: transaction.created, account.reconciled and category.overspent; transaction.updated is not available until transactions can be updated.
+//	@Param			budget_id	path	string			true	"Budget ID"
+//	@Param			webhook		body	webhookRequest	true	"Webhook details"
+//	@Tags			Webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	webhookResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/webhooks [post]
+//	@Security		Bearer
+func (s *Server) createWebhook(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+
+	var rqst webhookRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	secret, err := util.RandomSecret(32)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	webhook, err := s.db.CreateWebhook(ctx, db.CreateWebhookParams{
+		BudgetID: budgetId,
+		Url:      rqst.Url,
+		Secret:   secret,
+		Events:   rqst.Events,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	resp := newWebhookResponse(webhook)
+	resp.Secret = webhook.Secret
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// updateWebhook godoc
+//
+//	@Summary	Update a webhook
+//	@Schemes
+//	@Description	Update the URL, the events or the status of a webhook. transaction.updated cannot be subscribed to until transactions can be updated.
+//	@Param			budget_id	path	string					true	"Budget ID"
+//	@Param			webhook_id	path	string					true	"Webhook ID"
+//	@Param			webhook		body	updateWebhookRequest	true	"Webhook details"
+//	@Tags			Webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	webhookResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/webhooks/{webhook_id} [put]
+//	@Security		Bearer
+func (s *Server) updateWebhook(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+	webhook, err := s.getBudgetWebhook(ctx, budgetId)
+	if err != nil {
+		return
+	}
+
+	var rqst updateWebhookRequest
+	if err := ctx.ShouldBindJSON(&rqst); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	arg := db.UpdateWebhookParams{
+		ID:       webhook.ID,
+		BudgetID: budgetId,
+		Events:   rqst.Events,
+		Active:   rqst.Active,
+	}
+	if rqst.Url != nil {
+		arg.Url = pgtype.Text{String: *rqst.Url, Valid: true}
+	}
+
+	updatedWebhook, err := s.db.UpdateWebhook(ctx, arg)
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(updatedWebhook))
+}
+
+// deleteWebhook godoc
+//
+//	@Summary	Delete a webhook
+//	@Schemes
+//	@Description	Delete a webhook and its delivery log.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Param			webhook_id	path	string	true	"Webhook ID"
+//	@Tags			Webhooks
+//	@Produce		json
+//	@Success		200	{object}	string	"webhook deleted"
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/webhooks/{webhook_id} [delete]
+//	@Security		Bearer
+func (s *Server) deleteWebhook(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+	webhook, err := s.getBudgetWebhook(ctx, budgetId)
+	if err != nil {
+		return
+	}
+
+	if err := s.db.DeleteWebhookTx(ctx, webhook.ID); err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "webhook deleted"})
+}
+
+// getWebhookDeliveries godoc
+//
+//	@Summary	List webhook deliveries
+//	@Schemes
+//	@Description	List the most recent deliveries of a webhook, including the status code returned by the receiver.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Param			webhook_id	path	string	true	"Webhook ID"
+//	@Tags			Webhooks
+//	@Produce		json
+//	@Success		200	{object}	[]webhookDeliveryResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/webhooks/{webhook_id}/deliveries [get]
+//	@Security		Bearer
+func (s *Server) getWebhookDeliveries(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+	webhook, err := s.getBudgetWebhook(ctx, budgetId)
+	if err != nil {
+		return
+	}
+
+	deliveries, err := s.db.GetWebhookDeliveries(ctx, db.GetWebhookDeliveriesParams{
+		WebhookID: webhook.ID,
+		Limit:     webhookDeliveryLogSize,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	resp := make([]webhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		resp[i] = newWebhookDeliveryResponse(deliveries[i])
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// redeliverWebhook godoc
+//
+//	@Summary	Redeliver a webhook
+//	@Schemes
+//	@Description	Send a previous delivery again. A new entry is added to the delivery log.
+//	@Param			budget_id	path	string	true	"Budget ID"
+//	@Param			webhook_id	path	string	true	"Webhook ID"
+//	@Param			delivery_id	path	string	true	"Delivery ID"
+//	@Tags			Webhooks
+//	@Produce		json
+//	@Success		202	{object}	webhookDeliveryResponse
+//	@Failure		400	{object}	HTTPError
+//	@Failure		404	{object}	HTTPError
+//	@Failure		500	{object}	HTTPError
+//	@Router			/budgets/{budget_id}/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver [post]
+//	@Security		Bearer
+func (s *Server) redeliverWebhook(ctx *gin.Context) {
+
+	var budgetId uuid.UUID
+	if err := s.ValidateBudgetOwnership(ctx, &budgetId); err != nil {
+		return
+	}
+	webhook, err := s.getBudgetWebhook(ctx, budgetId)
+	if err != nil {
+		return
+	}
+
+	var deliveryId WebhookDeliveryId
+	if err := ctx.ShouldBindUri(&deliveryId); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+	deliveryUuid, err := uuid.Parse(deliveryId.DeliveryId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid request"))
+		return
+	}
+
+	// Make sure the delivery belongs to the webhook
+	delivery, err := s.db.GetWebhookDelivery(ctx, deliveryUuid)
+	if err != nil && err != pgx.ErrNoRows {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	if err == pgx.ErrNoRows || delivery.WebhookID != webhook.ID {
+		ctx.JSON(http.StatusNotFound, errorResponse("delivery not found"))
+		return
+	}
+
+	redelivery, err := s.db.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+		WebhookID:  webhook.ID,
+		Event:      delivery.Event,
+		Payload:    delivery.Payload,
+		Redelivery: true,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+	err = s.taskDistributor.DistributeDeliverWebhook(ctx, &worker.DeliverWebhookPayload{
+		DeliveryID: redelivery.ID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		ctx.JSON(http.StatusInternalServerError, errorResponse(internal_error_message))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newWebhookDeliveryResponse(redelivery))
+}
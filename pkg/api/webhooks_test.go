@@ -0,0 +1,264 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mock "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateWebhookAPI(t *testing.T) {
+
+	budget := db.Budget{
+		ID:            uuid.New(),
+		OwnerUsername: util.RandomUsername(),
+		Name:          "My Budget",
+		CurrencyCode:  "EUR",
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mock.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"url":    "https://example.com/hooks",
+				"events": []string{WebhookEventTransactionCreated, WebhookEventAccountReconciled},
+			},
+			buildStubs: func(store *mock.MockStore) {
+				store.EXPECT().
+					GetBudget(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(budget, nil)
+				store.EXPECT().
+					CreateWebhook(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateWebhookParams) (db.Webhook, error) {
+						return db.Webhook{
+							ID:       uuid.New(),
+							BudgetID: arg.BudgetID,
+							Url:      arg.Url,
+							Secret:   arg.Secret,
+							Events:   arg.Events,
+							Active:   true,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var resp webhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, budget.ID, resp.BudgetID)
+				require.Len(t, resp.Secret, 64)
+			},
+		},
+		{
+			name: "InvalidEvent",
+			body: gin.H{
+				"url":    "https://example.com/hooks",
+				"events": []string{"budget.exploded"},
+			},
+			buildStubs: func(store *mock.MockStore) {
+				store.EXPECT().
+					GetBudget(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(budget, nil)
+				store.EXPECT().
+					CreateWebhook(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidURL",
+			body: gin.H{
+				"url":    "ftp://example.com/hooks",
+				"events": []string{WebhookEventTransactionCreated},
+			},
+			buildStubs: func(store *mock.MockStore) {
+				store.EXPECT().
+					GetBudget(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(budget, nil)
+				store.EXPECT().
+					CreateWebhook(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/beta/budgets/" + budget.ID.String() + "/webhooks"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			token, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: budget.OwnerUsername, Duration: time.Duration(time.Minute * 15), Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateWebhookAPI(t *testing.T) {
+
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: util.RandomUsername(), Name: "My Budget", CurrencyCode: "EUR"}
+	webhook := db.Webhook{ID: uuid.New(), BudgetID: budget.ID, Url: "https://example.com/hooks", Events: []string{WebhookEventTransactionCreated}, Active: true}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mock.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"url": "https://example.com/hooks/v2"},
+			buildStubs: func(store *mock.MockStore) {
+				store.EXPECT().
+					UpdateWebhook(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.UpdateWebhookParams) (db.Webhook, error) {
+						require.Equal(t, "https://example.com/hooks/v2", arg.Url.String)
+						require.True(t, arg.Url.Valid)
+						return webhook, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidURL",
+			body: gin.H{"url": "ftp://example.com/hooks"},
+			buildStubs: func(store *mock.MockStore) {
+				store.EXPECT().
+					UpdateWebhook(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EventNotEmitted",
+			body: gin.H{"events": []string{"transaction.updated"}},
+			buildStubs: func(store *mock.MockStore) {
+				store.EXPECT().
+					UpdateWebhook(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock.NewMockStore(ctrl)
+			store.EXPECT().
+				GetBudget(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(budget, nil)
+			store.EXPECT().
+				GetWebhook(gomock.Any(), db.GetWebhookParams{BudgetID: budget.ID, ID: webhook.ID}).
+				Times(1).
+				Return(webhook, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/beta/budgets/" + budget.ID.String() + "/webhooks/" + webhook.ID.String()
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			token, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: budget.OwnerUsername, Duration: time.Minute, Purpose: token.PurposeAccess})
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDeleteWebhookAPI(t *testing.T) {
+
+	budget := db.Budget{ID: uuid.New(), OwnerUsername: util.RandomUsername(), Name: "My Budget", CurrencyCode: "EUR"}
+	webhook := db.Webhook{ID: uuid.New(), BudgetID: budget.ID, Url: "https://example.com/hooks", Events: []string{WebhookEventTransactionCreated}, Active: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockStore(ctrl)
+	store.EXPECT().
+		GetBudget(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(budget, nil)
+	store.EXPECT().
+		GetWebhook(gomock.Any(), db.GetWebhookParams{BudgetID: budget.ID, ID: webhook.ID}).
+		Times(1).
+		Return(webhook, nil)
+	store.EXPECT().
+		DeleteWebhookTx(gomock.Any(), webhook.ID).
+		Times(1).
+		Return(nil)
+
+	server := NewTestServer(t, store, nil)
+	recorder := httptest.NewRecorder()
+
+	url := "/beta/budgets/" + budget.ID.String() + "/webhooks/" + webhook.ID.String()
+	request, err := http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	token, _, err := server.tokenBuilder.CreateToken(token.CreateTokenParams{Username: budget.OwnerUsername, Duration: time.Minute, Purpose: token.PurposeAccess})
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
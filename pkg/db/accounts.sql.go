@@ -18,17 +18,19 @@ INSERT INTO accounts (
     budget_id,
     name,
     type,
-    balance
+    balance,
+    currency_code
 ) VALUES (
-    $1, $2, $3, $4
-) RETURNING id, budget_id, name, type, closed, note, balance, cleared_balance, uncleared_balance, last_reconciled_at
+    $1, $2, $3, $4, $5
+) RETURNING id, budget_id, name, type, closed, note, balance, cleared_balance, uncleared_balance, last_reconciled_at, on_budget, currency_code
 `
 
 type CreateAccountParams struct {
-	BudgetID uuid.UUID `json:"budget_id"`
-	Name     string    `json:"name"`
-	Type     string    `json:"type"`
-	Balance  int32     `json:"balance"`
+	BudgetID     uuid.UUID `json:"budget_id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	Balance      int64     `json:"balance"`
+	CurrencyCode string    `json:"currency_code"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
@@ -37,6 +39,7 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		arg.Name,
 		arg.Type,
 		arg.Balance,
+		arg.CurrencyCode,
 	)
 	var i Account
 	err := row.Scan(
@@ -50,6 +53,8 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.ClearedBalance,
 		&i.UnclearedBalance,
 		&i.LastReconciledAt,
+		&i.OnBudget,
+		&i.CurrencyCode,
 	)
 	return i, err
 }
@@ -73,7 +78,7 @@ func (q *Queries) DeleteAccounts(ctx context.Context, budgetID uuid.UUID) error
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, budget_id, name, type, closed, note, balance, cleared_balance, uncleared_balance, last_reconciled_at FROM accounts WHERE budget_id = $1 and id = $2
+SELECT id, budget_id, name, type, closed, note, balance, cleared_balance, uncleared_balance, last_reconciled_at, on_budget, currency_code FROM accounts WHERE budget_id = $1 and id = $2
 `
 
 type GetAccountParams struct {
@@ -95,12 +100,59 @@ func (q *Queries) GetAccount(ctx context.Context, arg GetAccountParams) (Account
 		&i.ClearedBalance,
 		&i.UnclearedBalance,
 		&i.LastReconciledAt,
+		&i.OnBudget,
+		&i.CurrencyCode,
 	)
 	return i, err
 }
 
+const getAccountChangesAfter = `-- name: GetAccountChangesAfter :many
+SELECT c.account_id::uuid AS account_id, SUM(c.amount)::bigint AS amount FROM (
+    SELECT t.account_id, t.amount FROM transactions t
+    JOIN accounts a ON t.account_id = a.id
+    WHERE a.budget_id = $1 AND t.date > $2
+    UNION ALL
+    SELECT p.transfer_account_id, COALESCE(t.transfer_amount, -t.amount) FROM transactions t
+    JOIN payees p ON t.payee_id = p.id
+    WHERE p.budget_id = $1 AND p.transfer_account_id IS NOT NULL AND t.date > $2
+) c
+GROUP BY c.account_id
+`
+
+type GetAccountChangesAfterParams struct {
+	BudgetID uuid.UUID   `json:"budget_id"`
+	Date     pgtype.Date `json:"date"`
+}
+
+type GetAccountChangesAfterRow struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Amount    int64     `json:"amount"`
+}
+
+// What the transactions after a date changed in the balance of each account of a budget. Transfers change the account
+// of the transaction and the account of its payee, which gets the transfer amount between currencies.
+func (q *Queries) GetAccountChangesAfter(ctx context.Context, arg GetAccountChangesAfterParams) ([]GetAccountChangesAfterRow, error) {
+	rows, err := q.db.Query(ctx, getAccountChangesAfter, arg.BudgetID, arg.Date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAccountChangesAfterRow{}
+	for rows.Next() {
+		var i GetAccountChangesAfterRow
+		if err := rows.Scan(&i.AccountID, &i.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAccounts = `-- name: GetAccounts :many
-SELECT id, budget_id, name, type, closed, note, balance, cleared_balance, uncleared_balance, last_reconciled_at FROM accounts WHERE budget_id = $1
+SELECT id, budget_id, name, type, closed, note, balance, cleared_balance, uncleared_balance, last_reconciled_at, on_budget, currency_code FROM accounts WHERE budget_id = $1
 `
 
 func (q *Queries) GetAccounts(ctx context.Context, budgetID uuid.UUID) ([]Account, error) {
@@ -123,6 +175,8 @@ func (q *Queries) GetAccounts(ctx context.Context, budgetID uuid.UUID) ([]Accoun
 			&i.ClearedBalance,
 			&i.UnclearedBalance,
 			&i.LastReconciledAt,
+			&i.OnBudget,
+			&i.CurrencyCode,
 		); err != nil {
 			return nil, err
 		}
@@ -135,7 +189,7 @@ func (q *Queries) GetAccounts(ctx context.Context, budgetID uuid.UUID) ([]Accoun
 }
 
 const getBudgetAccount = `-- name: GetBudgetAccount :one
-SELECT b.id, owner_username, b.name, currency_code, a.id, budget_id, a.name, type, closed, note, balance, cleared_balance, uncleared_balance, last_reconciled_at FROM budgets b, accounts a
+SELECT b.id, owner_username, b.name, b.currency_code, a.id, budget_id, a.name, type, closed, note, balance, cleared_balance, uncleared_balance, last_reconciled_at, on_budget, a.currency_code FROM budgets b, accounts a
 WHERE b.id = a.budget_id and b.id = $1 and a.id = $2 and b.owner_username = $3
 `
 
@@ -156,10 +210,12 @@ type GetBudgetAccountRow struct {
 	Type             string      `json:"type"`
 	Closed           bool        `json:"closed"`
 	Note             pgtype.Text `json:"note"`
-	Balance          int32       `json:"balance"`
-	ClearedBalance   int32       `json:"cleared_balance"`
-	UnclearedBalance int32       `json:"uncleared_balance"`
+	Balance          int64       `json:"balance"`
+	ClearedBalance   int64       `json:"cleared_balance"`
+	UnclearedBalance int64       `json:"uncleared_balance"`
 	LastReconciledAt time.Time   `json:"last_reconciled_at"`
+	OnBudget         bool        `json:"on_budget"`
+	CurrencyCode_2   string      `json:"currency_code_2"`
 }
 
 func (q *Queries) GetBudgetAccount(ctx context.Context, arg GetBudgetAccountParams) (GetBudgetAccountRow, error) {
@@ -180,6 +236,8 @@ func (q *Queries) GetBudgetAccount(ctx context.Context, arg GetBudgetAccountPara
 		&i.ClearedBalance,
 		&i.UnclearedBalance,
 		&i.LastReconciledAt,
+		&i.OnBudget,
+		&i.CurrencyCode_2,
 	)
 	return i, err
 }
@@ -196,7 +254,7 @@ SET
     uncleared_balance = COALESCE($9, uncleared_balance),
     last_reconciled_at = COALESCE($10, last_reconciled_at)
 WHERE id = $1 AND budget_id = $2
-RETURNING id, budget_id, name, type, closed, note, balance, cleared_balance, uncleared_balance, last_reconciled_at
+RETURNING id, budget_id, name, type, closed, note, balance, cleared_balance, uncleared_balance, last_reconciled_at, on_budget, currency_code
 `
 
 type UpdateAccountParams struct {
@@ -206,9 +264,9 @@ type UpdateAccountParams struct {
 	Type             pgtype.Text        `json:"type"`
 	Closed           pgtype.Bool        `json:"closed"`
 	Note             pgtype.Text        `json:"note"`
-	Balance          pgtype.Int4        `json:"balance"`
-	ClearedBalance   pgtype.Int4        `json:"cleared_balance"`
-	UnclearedBalance pgtype.Int4        `json:"uncleared_balance"`
+	Balance          pgtype.Int8        `json:"balance"`
+	ClearedBalance   pgtype.Int8        `json:"cleared_balance"`
+	UnclearedBalance pgtype.Int8        `json:"uncleared_balance"`
 	LastReconciledAt pgtype.Timestamptz `json:"last_reconciled_at"`
 }
 
@@ -237,6 +295,8 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.ClearedBalance,
 		&i.UnclearedBalance,
 		&i.LastReconciledAt,
+		&i.OnBudget,
+		&i.CurrencyCode,
 	)
 	return i, err
 }
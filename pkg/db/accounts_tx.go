@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Name of the category group of the credit card payment categories
+const CreditCardPaymentsGroupName = "Credit Card Payments"
+
+// Database transaction for creating an account, along with the payee of the transfers to it.
+// Credit card accounts also get a payment category, in a category group created with the first one.
+func (s *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error) {
+
+	var account Account
+
+	txErr := s.execTransaction(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg.Account)
+		if err != nil {
+			return err
+		}
+		accountId := pgtype.UUID{Bytes: account.ID, Valid: true}
+
+		// Create the transfer payee
+		_, err = q.CreateTransferPayee(ctx, CreateTransferPayeeParams{
+			BudgetID:          account.BudgetID,
+			Name:              "Transfer : " + account.Name,
+			TransferAccountID: accountId,
+		})
+		if err != nil {
+			return err
+		}
+		if !arg.PaymentCategory {
+			return nil
+		}
+
+		// Create the payment category
+		group, err := q.GetCategoryGroupByName(ctx, GetCategoryGroupByNameParams{
+			BudgetID: account.BudgetID,
+			Name:     CreditCardPaymentsGroupName,
+		})
+		if err == pgx.ErrNoRows {
+			group, err = q.CreateCategoryGroup(ctx, CreateCategoryGroupParams{
+				BudgetID: account.BudgetID,
+				Name:     CreditCardPaymentsGroupName,
+			})
+		}
+		if err != nil {
+			return err
+		}
+		_, err = q.CreatePaymentCategory(ctx, CreatePaymentCategoryParams{
+			CategoryGroupID:  group.ID,
+			Name:             account.Name,
+			PaymentAccountID: accountId,
+		})
+		return err
+	})
+
+	return account, txErr
+}
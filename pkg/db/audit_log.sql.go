@@ -0,0 +1,92 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: audit_log.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createAuditLog = `-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+    admin_username,
+    action,
+    target_username,
+    details,
+    client_ip
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING id, admin_username, action, target_username, details, client_ip, created_at
+`
+
+type CreateAuditLogParams struct {
+	AdminUsername  string `json:"admin_username"`
+	Action         string `json:"action"`
+	TargetUsername string `json:"target_username"`
+	Details        string `json:"details"`
+	ClientIp       string `json:"client_ip"`
+}
+
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error) {
+	row := q.db.QueryRow(ctx, createAuditLog,
+		arg.AdminUsername,
+		arg.Action,
+		arg.TargetUsername,
+		arg.Details,
+		arg.ClientIp,
+	)
+	var i AuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.AdminUsername,
+		&i.Action,
+		&i.TargetUsername,
+		&i.Details,
+		&i.ClientIp,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAuditLogs = `-- name: GetAuditLogs :many
+SELECT id, admin_username, action, target_username, details, client_ip, created_at FROM audit_log
+WHERE $1::varchar = '' OR target_username = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $3 OFFSET $2
+`
+
+type GetAuditLogsParams struct {
+	TargetUsername string `json:"target_username"`
+	PageOffset     int32  `json:"page_offset"`
+	PageSize       int32  `json:"page_size"`
+}
+
+func (q *Queries) GetAuditLogs(ctx context.Context, arg GetAuditLogsParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, getAuditLogs, arg.TargetUsername, arg.PageOffset, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLog{}
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.AdminUsername,
+			&i.Action,
+			&i.TargetUsername,
+			&i.Details,
+			&i.ClientIp,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
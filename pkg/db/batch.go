@@ -0,0 +1,83 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: batch.go
+
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrBatchAlreadyClosed = errors.New("batch already closed")
+)
+
+const upsertExchangeRate = `-- name: UpsertExchangeRate :batchexec
+INSERT INTO exchange_rates (
+    budget_id,
+    date,
+    from_currency,
+    to_currency,
+    rate
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (budget_id, from_currency, to_currency, date) DO UPDATE SET rate = EXCLUDED.rate
+`
+
+type UpsertExchangeRateBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type UpsertExchangeRateParams struct {
+	BudgetID     uuid.UUID   `json:"budget_id"`
+	Date         pgtype.Date `json:"date"`
+	FromCurrency string      `json:"from_currency"`
+	ToCurrency   string      `json:"to_currency"`
+	Rate         float64     `json:"rate"`
+}
+
+func (q *Queries) UpsertExchangeRate(ctx context.Context, arg []UpsertExchangeRateParams) *UpsertExchangeRateBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.BudgetID,
+			a.Date,
+			a.FromCurrency,
+			a.ToCurrency,
+			a.Rate,
+		}
+		batch.Queue(upsertExchangeRate, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &UpsertExchangeRateBatchResults{br, len(arg), false}
+}
+
+func (b *UpsertExchangeRateBatchResults) Exec(f func(int, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		if b.closed {
+			if f != nil {
+				f(t, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		_, err := b.br.Exec()
+		if f != nil {
+			f(t, err)
+		}
+	}
+}
+
+func (b *UpsertExchangeRateBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
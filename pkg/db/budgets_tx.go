@@ -10,38 +10,47 @@ import (
 func (s *SQLStore) DeleteBudgetTx(ctx context.Context, budgetId uuid.UUID) error {
 
 	txErr := s.execTransaction(ctx, func(q *Queries) error {
-
-		// Delete payees
-		// Delete the transactions
-		// TODO
-		// Delete category groups
-		cg, err := q.GetCategoryGroupsByBudgetId(ctx, budgetId)
-		if err != nil {
-			return err
-		}
-		cgIds := make([]uuid.UUID, len(cg))
-		for c := range cg {
-			cgIds[c] = cg[c].ID
-		}
-		if err := q.DeleteCategoryGroups(ctx, budgetId); err != nil {
-			return err
-		}
-		// Delete categories
-		for c := range cgIds {
-			if err := q.DeleteCategories(ctx, cgIds[c]); err != nil {
-				return err
-			}
-		}
-		// Delete the accounts
-		if err := q.DeleteAccounts(ctx, budgetId); err != nil {
+		if err := purgeBudget(ctx, q, budgetId); err != nil {
 			return err
 		}
 		// Delete the budget
-		if err := q.DeleteBudget(ctx, budgetId); err != nil {
-			return err
-		}
-
-		return nil
+		return q.DeleteBudget(ctx, budgetId)
 	})
 	return txErr
 }
+
+// Deletes what belongs to a budget, which can then be deleted. Transactions go first as they reference the accounts,
+// payees and categories.
+func purgeBudget(ctx context.Context, q *Queries, budgetId uuid.UUID) error {
+
+	// Delete the transactions
+	if err := q.DeleteBudgetTransactions(ctx, budgetId); err != nil {
+		return err
+	}
+	// Delete payees
+	if err := q.DeletePayees(ctx, budgetId); err != nil {
+		return err
+	}
+	// Delete categories, then their groups
+	cg, err := q.GetCategoryGroupsByBudgetId(ctx, budgetId)
+	if err != nil {
+		return err
+	}
+	for c := range cg {
+		if err := q.DeleteCategories(ctx, cg[c].ID); err != nil {
+			return err
+		}
+	}
+	if err := q.DeleteCategoryGroups(ctx, budgetId); err != nil {
+		return err
+	}
+	// Delete the webhooks and their delivery logs
+	if err := q.DeleteBudgetWebhookDeliveries(ctx, budgetId); err != nil {
+		return err
+	}
+	if err := q.DeleteWebhooks(ctx, budgetId); err != nil {
+		return err
+	}
+	// Delete the accounts
+	return q.DeleteAccounts(ctx, budgetId)
+}
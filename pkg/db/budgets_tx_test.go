@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// Creates a user with a budget holding a checking account, a credit card, and a payment from one to the other
+func createTestBudget(t *testing.T, store Store) (User, Budget) {
+
+	ctx := context.Background()
+	username := util.RandomUsername()
+	user, err := store.CreateUser(ctx, CreateUserParams{
+		Username:           username,
+		Password:           "hash",
+		Email:              username + "@example.com",
+		LastPasswordChange: time.Now(),
+	})
+	require.NoError(t, err)
+	budget, err := store.CreateBudget(ctx, CreateBudgetParams{OwnerUsername: user.Username, Name: "My Budget", CurrencyCode: "EUR"})
+	require.NoError(t, err)
+
+	checking, err := store.CreateAccountTx(ctx, CreateAccountTxParams{
+		Account: CreateAccountParams{BudgetID: budget.ID, Name: "Chase", Type: "checking", CurrencyCode: "EUR"},
+	})
+	require.NoError(t, err)
+	card, err := store.CreateAccountTx(ctx, CreateAccountTxParams{
+		Account:         CreateAccountParams{BudgetID: budget.ID, Name: "Visa", Type: "credit_card", CurrencyCode: "EUR"},
+		PaymentCategory: true,
+	})
+	require.NoError(t, err)
+
+	payees, err := store.GetPayees(ctx, budget.ID)
+	require.NoError(t, err)
+	var toCard Payee
+	for _, p := range payees {
+		if p.TransferAccountID.Valid && uuid.UUID(p.TransferAccountID.Bytes) == card.ID {
+			toCard = p
+		}
+	}
+	require.NotEqual(t, uuid.Nil, toCard.ID)
+	_, err = store.CreateTransaction(ctx, CreateTransactionParams{
+		AccountID: checking.ID,
+		Date:      pgtype.Date{Time: time.Now(), Valid: true},
+		PayeeID:   toCard.ID,
+		Amount:    -10000,
+	})
+	require.NoError(t, err)
+
+	return user, budget
+}
+
+func TestDeleteBudgetTx(t *testing.T) {
+
+	store := newTestStore(t)
+	ctx := context.Background()
+	user, budget := createTestBudget(t, store)
+
+	require.NoError(t, store.DeleteBudgetTx(ctx, budget.ID))
+
+	_, err := store.GetBudget(ctx, GetBudgetParams{ID: budget.ID, OwnerUsername: user.Username})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	payees, err := store.GetPayees(ctx, budget.ID)
+	require.NoError(t, err)
+	require.Empty(t, payees)
+}
+
+func TestDeleteUserTx(t *testing.T) {
+
+	store := newTestStore(t)
+	ctx := context.Background()
+	user, budget := createTestBudget(t, store)
+
+	err := store.DeleteUserTx(ctx, UserParams{Username: user.Username, Email: user.Email}, []uuid.UUID{budget.ID}, func(UserParams) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = store.GetBudget(ctx, GetBudgetParams{ID: budget.ID, OwnerUsername: user.Username})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
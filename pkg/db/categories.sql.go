@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createCategory = `-- name: CreateCategory :one
@@ -18,7 +19,7 @@ INSERT INTO categories (
 ) VALUES (
     $1, $2
 )
-RETURNING id, category_group_id, name
+RETURNING id, category_group_id, name, assigned, payment_account_id
 `
 
 type CreateCategoryParams struct {
@@ -29,7 +30,43 @@ type CreateCategoryParams struct {
 func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
 	row := q.db.QueryRow(ctx, createCategory, arg.CategoryGroupID, arg.Name)
 	var i Category
-	err := row.Scan(&i.ID, &i.CategoryGroupID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryGroupID,
+		&i.Name,
+		&i.Assigned,
+		&i.PaymentAccountID,
+	)
+	return i, err
+}
+
+const createPaymentCategory = `-- name: CreatePaymentCategory :one
+INSERT INTO categories (
+    category_group_id,
+    name,
+    payment_account_id
+) VALUES (
+    $1, $2, $3
+)
+RETURNING id, category_group_id, name, assigned, payment_account_id
+`
+
+type CreatePaymentCategoryParams struct {
+	CategoryGroupID  uuid.UUID   `json:"category_group_id"`
+	Name             string      `json:"name"`
+	PaymentAccountID pgtype.UUID `json:"payment_account_id"`
+}
+
+func (q *Queries) CreatePaymentCategory(ctx context.Context, arg CreatePaymentCategoryParams) (Category, error) {
+	row := q.db.QueryRow(ctx, createPaymentCategory, arg.CategoryGroupID, arg.Name, arg.PaymentAccountID)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryGroupID,
+		&i.Name,
+		&i.Assigned,
+		&i.PaymentAccountID,
+	)
 	return i, err
 }
 
@@ -51,8 +88,62 @@ func (q *Queries) DeleteCategory(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const getBudgetCategories = `-- name: GetBudgetCategories :many
+SELECT c.id, c.category_group_id, c.name, c.assigned, c.payment_account_id FROM categories c, category_groups g
+WHERE c.category_group_id = g.id AND g.budget_id = $1
+`
+
+func (q *Queries) GetBudgetCategories(ctx context.Context, budgetID uuid.UUID) ([]Category, error) {
+	rows, err := q.db.Query(ctx, getBudgetCategories, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryGroupID,
+			&i.Name,
+			&i.Assigned,
+			&i.PaymentAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBudgetCategory = `-- name: GetBudgetCategory :one
+SELECT c.id, c.category_group_id, c.name, c.assigned, c.payment_account_id FROM categories c, category_groups g
+WHERE c.category_group_id = g.id AND c.id = $1 AND g.budget_id = $2
+`
+
+type GetBudgetCategoryParams struct {
+	ID       uuid.UUID `json:"id"`
+	BudgetID uuid.UUID `json:"budget_id"`
+}
+
+func (q *Queries) GetBudgetCategory(ctx context.Context, arg GetBudgetCategoryParams) (Category, error) {
+	row := q.db.QueryRow(ctx, getBudgetCategory, arg.ID, arg.BudgetID)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryGroupID,
+		&i.Name,
+		&i.Assigned,
+		&i.PaymentAccountID,
+	)
+	return i, err
+}
+
 const getCategories = `-- name: GetCategories :many
-SELECT id, category_group_id, name FROM categories WHERE category_group_id = $1
+SELECT id, category_group_id, name, assigned, payment_account_id FROM categories WHERE category_group_id = $1
 `
 
 func (q *Queries) GetCategories(ctx context.Context, categoryGroupID uuid.UUID) ([]Category, error) {
@@ -64,7 +155,13 @@ func (q *Queries) GetCategories(ctx context.Context, categoryGroupID uuid.UUID)
 	items := []Category{}
 	for rows.Next() {
 		var i Category
-		if err := rows.Scan(&i.ID, &i.CategoryGroupID, &i.Name); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryGroupID,
+			&i.Name,
+			&i.Assigned,
+			&i.PaymentAccountID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -76,28 +173,46 @@ func (q *Queries) GetCategories(ctx context.Context, categoryGroupID uuid.UUID)
 }
 
 const getCategory = `-- name: GetCategory :one
-SELECT id, category_group_id, name FROM categories WHERE id = $1
+SELECT id, category_group_id, name, assigned, payment_account_id FROM categories WHERE id = $1
 `
 
 func (q *Queries) GetCategory(ctx context.Context, id uuid.UUID) (Category, error) {
 	row := q.db.QueryRow(ctx, getCategory, id)
 	var i Category
-	err := row.Scan(&i.ID, &i.CategoryGroupID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryGroupID,
+		&i.Name,
+		&i.Assigned,
+		&i.PaymentAccountID,
+	)
 	return i, err
 }
 
 const updateCategory = `-- name: UpdateCategory :one
-UPDATE categories SET name = $1 WHERE id = $2 RETURNING id, category_group_id, name
+UPDATE categories
+SET
+    name = COALESCE($1, name),
+    assigned = COALESCE($2, assigned)
+WHERE id = $3
+RETURNING id, category_group_id, name, assigned, payment_account_id
 `
 
 type UpdateCategoryParams struct {
-	Name string    `json:"name"`
-	ID   uuid.UUID `json:"id"`
+	Name     pgtype.Text `json:"name"`
+	Assigned pgtype.Int8 `json:"assigned"`
+	ID       uuid.UUID   `json:"id"`
 }
 
 func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error) {
-	row := q.db.QueryRow(ctx, updateCategory, arg.Name, arg.ID)
+	row := q.db.QueryRow(ctx, updateCategory, arg.Name, arg.Assigned, arg.ID)
 	var i Category
-	err := row.Scan(&i.ID, &i.CategoryGroupID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryGroupID,
+		&i.Name,
+		&i.Assigned,
+		&i.PaymentAccountID,
+	)
 	return i, err
 }
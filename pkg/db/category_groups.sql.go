@@ -62,6 +62,24 @@ func (q *Queries) GetCategoryGroup(ctx context.Context, id uuid.UUID) (CategoryG
 	return i, err
 }
 
+const getCategoryGroupByName = `-- name: GetCategoryGroupByName :one
+SELECT id, budget_id, name FROM category_groups WHERE budget_id = $1 AND name = $2
+ORDER BY id
+LIMIT 1
+`
+
+type GetCategoryGroupByNameParams struct {
+	BudgetID uuid.UUID `json:"budget_id"`
+	Name     string    `json:"name"`
+}
+
+func (q *Queries) GetCategoryGroupByName(ctx context.Context, arg GetCategoryGroupByNameParams) (CategoryGroup, error) {
+	row := q.db.QueryRow(ctx, getCategoryGroupByName, arg.BudgetID, arg.Name)
+	var i CategoryGroup
+	err := row.Scan(&i.ID, &i.BudgetID, &i.Name)
+	return i, err
+}
+
 const getCategoryGroupsByBudgetId = `-- name: GetCategoryGroupsByBudgetId :many
 SELECT id, budget_id, name FROM category_groups WHERE budget_id = $1
 `
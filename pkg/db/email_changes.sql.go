@@ -0,0 +1,129 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: email_changes.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createEmailChange = `-- name: CreateEmailChange :one
+INSERT INTO email_changes (
+    username,
+    old_email,
+    new_email,
+    token_hash,
+    cancel_token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, username, old_email, new_email, token_hash, cancel_token_hash, used, expires_at, created_at
+`
+
+type CreateEmailChangeParams struct {
+	Username        string    `json:"username"`
+	OldEmail        string    `json:"old_email"`
+	NewEmail        string    `json:"new_email"`
+	TokenHash       string    `json:"token_hash"`
+	CancelTokenHash string    `json:"cancel_token_hash"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateEmailChange(ctx context.Context, arg CreateEmailChangeParams) (EmailChange, error) {
+	row := q.db.QueryRow(ctx, createEmailChange,
+		arg.Username,
+		arg.OldEmail,
+		arg.NewEmail,
+		arg.TokenHash,
+		arg.CancelTokenHash,
+		arg.ExpiresAt,
+	)
+	var i EmailChange
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.OldEmail,
+		&i.NewEmail,
+		&i.TokenHash,
+		&i.CancelTokenHash,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteEmailChanges = `-- name: DeleteEmailChanges :exec
+DELETE FROM email_changes WHERE username = $1
+`
+
+func (q *Queries) DeleteEmailChanges(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, deleteEmailChanges, username)
+	return err
+}
+
+const getEmailChangeByCancelHash = `-- name: GetEmailChangeByCancelHash :one
+SELECT id, username, old_email, new_email, token_hash, cancel_token_hash, used, expires_at, created_at FROM email_changes WHERE cancel_token_hash = $1
+`
+
+func (q *Queries) GetEmailChangeByCancelHash(ctx context.Context, cancelTokenHash string) (EmailChange, error) {
+	row := q.db.QueryRow(ctx, getEmailChangeByCancelHash, cancelTokenHash)
+	var i EmailChange
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.OldEmail,
+		&i.NewEmail,
+		&i.TokenHash,
+		&i.CancelTokenHash,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getEmailChangeByHash = `-- name: GetEmailChangeByHash :one
+SELECT id, username, old_email, new_email, token_hash, cancel_token_hash, used, expires_at, created_at FROM email_changes WHERE token_hash = $1
+`
+
+func (q *Queries) GetEmailChangeByHash(ctx context.Context, tokenHash string) (EmailChange, error) {
+	row := q.db.QueryRow(ctx, getEmailChangeByHash, tokenHash)
+	var i EmailChange
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.OldEmail,
+		&i.NewEmail,
+		&i.TokenHash,
+		&i.CancelTokenHash,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useEmailChange = `-- name: UseEmailChange :execrows
+UPDATE email_changes SET used = true WHERE id = $1 AND used = false
+`
+
+func (q *Queries) UseEmailChange(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, useEmailChange, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const useEmailChanges = `-- name: UseEmailChanges :exec
+UPDATE email_changes SET used = true WHERE username = $1 AND used = false
+`
+
+func (q *Queries) UseEmailChanges(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, useEmailChanges, username)
+	return err
+}
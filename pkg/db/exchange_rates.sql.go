@@ -0,0 +1,93 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: exchange_rates.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getExchangeRates = `-- name: GetExchangeRates :many
+SELECT budget_id, date, from_currency, to_currency, rate, created_at FROM exchange_rates
+WHERE budget_id = $1
+  AND ($2::varchar IS NULL OR from_currency = $2)
+  AND ($3::varchar IS NULL OR to_currency = $3)
+ORDER BY from_currency, to_currency, date
+`
+
+type GetExchangeRatesParams struct {
+	BudgetID     uuid.UUID   `json:"budget_id"`
+	FromCurrency pgtype.Text `json:"from_currency"`
+	ToCurrency   pgtype.Text `json:"to_currency"`
+}
+
+func (q *Queries) GetExchangeRates(ctx context.Context, arg GetExchangeRatesParams) ([]ExchangeRate, error) {
+	rows, err := q.db.Query(ctx, getExchangeRates, arg.BudgetID, arg.FromCurrency, arg.ToCurrency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ExchangeRate{}
+	for rows.Next() {
+		var i ExchangeRate
+		if err := rows.Scan(
+			&i.BudgetID,
+			&i.Date,
+			&i.FromCurrency,
+			&i.ToCurrency,
+			&i.Rate,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExchangeRatesUntil = `-- name: GetExchangeRatesUntil :many
+SELECT budget_id, date, from_currency, to_currency, rate, created_at FROM exchange_rates
+WHERE budget_id = $1 AND ($2::date IS NULL OR date <= $2)
+ORDER BY date
+`
+
+type GetExchangeRatesUntilParams struct {
+	BudgetID uuid.UUID   `json:"budget_id"`
+	Date     pgtype.Date `json:"date"`
+}
+
+// The rates on or before a date, or all of them
+func (q *Queries) GetExchangeRatesUntil(ctx context.Context, arg GetExchangeRatesUntilParams) ([]ExchangeRate, error) {
+	rows, err := q.db.Query(ctx, getExchangeRatesUntil, arg.BudgetID, arg.Date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ExchangeRate{}
+	for rows.Next() {
+		var i ExchangeRate
+		if err := rows.Scan(
+			&i.BudgetID,
+			&i.Date,
+			&i.FromCurrency,
+			&i.ToCurrency,
+			&i.Rate,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
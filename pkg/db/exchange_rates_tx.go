@@ -0,0 +1,20 @@
+package db
+
+import "context"
+
+// Database transaction for setting exchange rates, so that an import is saved whole or not at all. The rates are
+// sent in one batch rather than a round trip each.
+func (s *SQLStore) UpsertExchangeRatesTx(ctx context.Context, rates []UpsertExchangeRateParams) error {
+
+	txErr := s.execTransaction(ctx, func(q *Queries) error {
+		var batchErr error
+		q.UpsertExchangeRate(ctx, rates).Exec(func(_ int, err error) {
+			if err != nil && batchErr == nil {
+				batchErr = err
+			}
+		})
+		return batchErr
+	})
+
+	return txErr
+}
@@ -0,0 +1,243 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: investments.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createInvestmentTransaction = `-- name: CreateInvestmentTransaction :one
+INSERT INTO investment_transactions (
+    account_id,
+    date,
+    type,
+    symbol,
+    quantity,
+    amount,
+    memo
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, account_id, date, type, symbol, quantity, amount, memo, created_at
+`
+
+type CreateInvestmentTransactionParams struct {
+	AccountID uuid.UUID   `json:"account_id"`
+	Date      pgtype.Date `json:"date"`
+	Type      string      `json:"type"`
+	Symbol    string      `json:"symbol"`
+	Quantity  float64     `json:"quantity"`
+	Amount    int64       `json:"amount"`
+	Memo      pgtype.Text `json:"memo"`
+}
+
+func (q *Queries) CreateInvestmentTransaction(ctx context.Context, arg CreateInvestmentTransactionParams) (InvestmentTransaction, error) {
+	row := q.db.QueryRow(ctx, createInvestmentTransaction,
+		arg.AccountID,
+		arg.Date,
+		arg.Type,
+		arg.Symbol,
+		arg.Quantity,
+		arg.Amount,
+		arg.Memo,
+	)
+	var i InvestmentTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Date,
+		&i.Type,
+		&i.Symbol,
+		&i.Quantity,
+		&i.Amount,
+		&i.Memo,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getBudgetInvestmentTransactions = `-- name: GetBudgetInvestmentTransactions :many
+SELECT t.id, t.account_id, t.date, t.type, t.symbol, t.quantity, t.amount, t.memo, t.created_at FROM investment_transactions t
+JOIN accounts a ON t.account_id = a.id
+WHERE a.budget_id = $1 AND t.date <= $2
+ORDER BY t.date, t.created_at
+`
+
+type GetBudgetInvestmentTransactionsParams struct {
+	BudgetID uuid.UUID   `json:"budget_id"`
+	Date     pgtype.Date `json:"date"`
+}
+
+func (q *Queries) GetBudgetInvestmentTransactions(ctx context.Context, arg GetBudgetInvestmentTransactionsParams) ([]InvestmentTransaction, error) {
+	rows, err := q.db.Query(ctx, getBudgetInvestmentTransactions, arg.BudgetID, arg.Date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InvestmentTransaction{}
+	for rows.Next() {
+		var i InvestmentTransaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Date,
+			&i.Type,
+			&i.Symbol,
+			&i.Quantity,
+			&i.Amount,
+			&i.Memo,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInvestmentTransactions = `-- name: GetInvestmentTransactions :many
+SELECT id, account_id, date, type, symbol, quantity, amount, memo, created_at FROM investment_transactions WHERE account_id = $1
+ORDER BY date, created_at
+`
+
+func (q *Queries) GetInvestmentTransactions(ctx context.Context, accountID uuid.UUID) ([]InvestmentTransaction, error) {
+	rows, err := q.db.Query(ctx, getInvestmentTransactions, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InvestmentTransaction{}
+	for rows.Next() {
+		var i InvestmentTransaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Date,
+			&i.Type,
+			&i.Symbol,
+			&i.Quantity,
+			&i.Amount,
+			&i.Memo,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestSecurityPrices = `-- name: GetLatestSecurityPrices :many
+SELECT DISTINCT ON (symbol) budget_id, symbol, date, price, created_at FROM security_prices
+WHERE budget_id = $1 AND date <= $2
+ORDER BY symbol, date DESC
+`
+
+type GetLatestSecurityPricesParams struct {
+	BudgetID uuid.UUID   `json:"budget_id"`
+	Date     pgtype.Date `json:"date"`
+}
+
+// The last price of each security on or before a date
+func (q *Queries) GetLatestSecurityPrices(ctx context.Context, arg GetLatestSecurityPricesParams) ([]SecurityPrice, error) {
+	rows, err := q.db.Query(ctx, getLatestSecurityPrices, arg.BudgetID, arg.Date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SecurityPrice{}
+	for rows.Next() {
+		var i SecurityPrice
+		if err := rows.Scan(
+			&i.BudgetID,
+			&i.Symbol,
+			&i.Date,
+			&i.Price,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSecurityPrices = `-- name: GetSecurityPrices :many
+SELECT budget_id, symbol, date, price, created_at FROM security_prices
+WHERE budget_id = $1 AND ($2::varchar IS NULL OR symbol = $2)
+ORDER BY symbol, date
+`
+
+type GetSecurityPricesParams struct {
+	BudgetID uuid.UUID   `json:"budget_id"`
+	Symbol   pgtype.Text `json:"symbol"`
+}
+
+func (q *Queries) GetSecurityPrices(ctx context.Context, arg GetSecurityPricesParams) ([]SecurityPrice, error) {
+	rows, err := q.db.Query(ctx, getSecurityPrices, arg.BudgetID, arg.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SecurityPrice{}
+	for rows.Next() {
+		var i SecurityPrice
+		if err := rows.Scan(
+			&i.BudgetID,
+			&i.Symbol,
+			&i.Date,
+			&i.Price,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertSecurityPrice = `-- name: UpsertSecurityPrice :exec
+INSERT INTO security_prices (
+    budget_id,
+    symbol,
+    date,
+    price
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (budget_id, symbol, date) DO UPDATE SET price = EXCLUDED.price
+`
+
+type UpsertSecurityPriceParams struct {
+	BudgetID uuid.UUID   `json:"budget_id"`
+	Symbol   string      `json:"symbol"`
+	Date     pgtype.Date `json:"date"`
+	Price    int64       `json:"price"`
+}
+
+func (q *Queries) UpsertSecurityPrice(ctx context.Context, arg UpsertSecurityPriceParams) error {
+	_, err := q.db.Exec(ctx, upsertSecurityPrice,
+		arg.BudgetID,
+		arg.Symbol,
+		arg.Date,
+		arg.Price,
+	)
+	return err
+}
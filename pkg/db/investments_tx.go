@@ -0,0 +1,18 @@
+package db
+
+import "context"
+
+// Database transaction for setting the prices of securities, so that an import is saved whole or not at all
+func (s *SQLStore) UpsertSecurityPricesTx(ctx context.Context, prices []UpsertSecurityPriceParams) error {
+
+	txErr := s.execTransaction(ctx, func(q *Queries) error {
+		for i := range prices {
+			if err := q.UpsertSecurityPrice(ctx, prices[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return txErr
+}
@@ -0,0 +1,140 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: loans.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteLoan = `-- name: DeleteLoan :exec
+DELETE FROM loans WHERE account_id = $1
+`
+
+func (q *Queries) DeleteLoan(ctx context.Context, accountID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteLoan, accountID)
+	return err
+}
+
+const getLoan = `-- name: GetLoan :one
+SELECT account_id, principal, interest_rate, term_months, start_date, payment_day, extra_payment, created_at FROM loans WHERE account_id = $1
+`
+
+func (q *Queries) GetLoan(ctx context.Context, accountID uuid.UUID) (Loan, error) {
+	row := q.db.QueryRow(ctx, getLoan, accountID)
+	var i Loan
+	err := row.Scan(
+		&i.AccountID,
+		&i.Principal,
+		&i.InterestRate,
+		&i.TermMonths,
+		&i.StartDate,
+		&i.PaymentDay,
+		&i.ExtraPayment,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLoanPayments = `-- name: GetLoanPayments :many
+SELECT t.id, t.account_id, t.date, t.payee_id, t.category_id, t.memo, t.amount, t.approved, t.cleared, t.reconciled, t.transfer_amount FROM transactions t
+JOIN payees p ON t.payee_id = p.id
+WHERE (p.transfer_account_id = $1::uuid AND t.account_id <> $1::uuid AND t.amount < 0)
+   OR (t.account_id = $1::uuid AND p.transfer_account_id IS NOT NULL AND t.amount > 0)
+ORDER BY t.date, t.id
+`
+
+// Transfers paying a loan account: outflows of other accounts to its transfer payee, and inflows recorded on the loan
+// account from a transfer payee.
+func (q *Queries) GetLoanPayments(ctx context.Context, accountID uuid.UUID) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getLoanPayments, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Date,
+			&i.PayeeID,
+			&i.CategoryID,
+			&i.Memo,
+			&i.Amount,
+			&i.Approved,
+			&i.Cleared,
+			&i.Reconciled,
+			&i.TransferAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertLoan = `-- name: UpsertLoan :one
+INSERT INTO loans (
+    account_id,
+    principal,
+    interest_rate,
+    term_months,
+    start_date,
+    payment_day,
+    extra_payment
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (account_id) DO UPDATE SET
+    principal = EXCLUDED.principal,
+    interest_rate = EXCLUDED.interest_rate,
+    term_months = EXCLUDED.term_months,
+    start_date = EXCLUDED.start_date,
+    payment_day = EXCLUDED.payment_day,
+    extra_payment = EXCLUDED.extra_payment
+RETURNING account_id, principal, interest_rate, term_months, start_date, payment_day, extra_payment, created_at
+`
+
+type UpsertLoanParams struct {
+	AccountID    uuid.UUID   `json:"account_id"`
+	Principal    int64       `json:"principal"`
+	InterestRate float64     `json:"interest_rate"`
+	TermMonths   int32       `json:"term_months"`
+	StartDate    pgtype.Date `json:"start_date"`
+	PaymentDay   int32       `json:"payment_day"`
+	ExtraPayment int64       `json:"extra_payment"`
+}
+
+func (q *Queries) UpsertLoan(ctx context.Context, arg UpsertLoanParams) (Loan, error) {
+	row := q.db.QueryRow(ctx, upsertLoan,
+		arg.AccountID,
+		arg.Principal,
+		arg.InterestRate,
+		arg.TermMonths,
+		arg.StartDate,
+		arg.PaymentDay,
+		arg.ExtraPayment,
+	)
+	var i Loan
+	err := row.Scan(
+		&i.AccountID,
+		&i.Principal,
+		&i.InterestRate,
+		&i.TermMonths,
+		&i.StartDate,
+		&i.PaymentDay,
+		&i.ExtraPayment,
+		&i.CreatedAt,
+	)
+	return i, err
+}
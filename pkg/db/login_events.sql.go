@@ -0,0 +1,173 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: login_events.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createLoginEvent = `-- name: CreateLoginEvent :one
+INSERT INTO login_events (
+    username,
+    success,
+    user_agent,
+    client_ip,
+    session_id
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING id, username, success, user_agent, client_ip, session_id, revoke_token_hash, created_at
+`
+
+type CreateLoginEventParams struct {
+	Username  string      `json:"username"`
+	Success   bool        `json:"success"`
+	UserAgent string      `json:"user_agent"`
+	ClientIp  string      `json:"client_ip"`
+	SessionID pgtype.UUID `json:"session_id"`
+}
+
+func (q *Queries) CreateLoginEvent(ctx context.Context, arg CreateLoginEventParams) (LoginEvent, error) {
+	row := q.db.QueryRow(ctx, createLoginEvent,
+		arg.Username,
+		arg.Success,
+		arg.UserAgent,
+		arg.ClientIp,
+		arg.SessionID,
+	)
+	var i LoginEvent
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Success,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.SessionID,
+		&i.RevokeTokenHash,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteLoginEvents = `-- name: DeleteLoginEvents :exec
+DELETE FROM login_events WHERE username = $1
+`
+
+func (q *Queries) DeleteLoginEvents(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, deleteLoginEvents, username)
+	return err
+}
+
+const getLoginDevice = `-- name: GetLoginDevice :one
+SELECT
+    EXISTS (
+        SELECT 1 FROM login_events l
+        WHERE l.username = $1 AND l.success = true
+    ) AS has_logged_in,
+    EXISTS (
+        SELECT 1 FROM login_events l
+        WHERE l.username = $1 AND l.success = true
+        AND l.user_agent = $2 AND l.client_ip = $3
+    ) AS is_known
+`
+
+type GetLoginDeviceParams struct {
+	Username  string `json:"username"`
+	UserAgent string `json:"user_agent"`
+	ClientIp  string `json:"client_ip"`
+}
+
+type GetLoginDeviceRow struct {
+	HasLoggedIn bool `json:"has_logged_in"`
+	IsKnown     bool `json:"is_known"`
+}
+
+func (q *Queries) GetLoginDevice(ctx context.Context, arg GetLoginDeviceParams) (GetLoginDeviceRow, error) {
+	row := q.db.QueryRow(ctx, getLoginDevice, arg.Username, arg.UserAgent, arg.ClientIp)
+	var i GetLoginDeviceRow
+	err := row.Scan(&i.HasLoggedIn, &i.IsKnown)
+	return i, err
+}
+
+const getLoginEventByRevokeHash = `-- name: GetLoginEventByRevokeHash :one
+SELECT id, username, success, user_agent, client_ip, session_id, revoke_token_hash, created_at FROM login_events WHERE revoke_token_hash = $1
+`
+
+func (q *Queries) GetLoginEventByRevokeHash(ctx context.Context, revokeTokenHash pgtype.Text) (LoginEvent, error) {
+	row := q.db.QueryRow(ctx, getLoginEventByRevokeHash, revokeTokenHash)
+	var i LoginEvent
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Success,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.SessionID,
+		&i.RevokeTokenHash,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLoginEvents = `-- name: GetLoginEvents :many
+SELECT id, username, success, user_agent, client_ip, session_id, revoke_token_hash, created_at FROM login_events
+WHERE username = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+`
+
+type GetLoginEventsParams struct {
+	Username string `json:"username"`
+	Limit    int32  `json:"limit"`
+}
+
+func (q *Queries) GetLoginEvents(ctx context.Context, arg GetLoginEventsParams) ([]LoginEvent, error) {
+	rows, err := q.db.Query(ctx, getLoginEvents, arg.Username, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LoginEvent{}
+	for rows.Next() {
+		var i LoginEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Success,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.SessionID,
+			&i.RevokeTokenHash,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setLoginEventRevokeToken = `-- name: SetLoginEventRevokeToken :execrows
+UPDATE login_events SET revoke_token_hash = $2
+WHERE session_id = $1
+`
+
+type SetLoginEventRevokeTokenParams struct {
+	SessionID       pgtype.UUID `json:"session_id"`
+	RevokeTokenHash pgtype.Text `json:"revoke_token_hash"`
+}
+
+func (q *Queries) SetLoginEventRevokeToken(ctx context.Context, arg SetLoginEventRevokeTokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setLoginEventRevokeToken, arg.SessionID, arg.RevokeTokenHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
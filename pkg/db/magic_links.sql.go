@@ -0,0 +1,89 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: magic_links.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createMagicLink = `-- name: CreateMagicLink :one
+INSERT INTO magic_links (
+    username,
+    token_hash,
+    nonce_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, username, token_hash, nonce_hash, used, expires_at, created_at
+`
+
+type CreateMagicLinkParams struct {
+	Username  string    `json:"username"`
+	TokenHash string    `json:"token_hash"`
+	NonceHash string    `json:"nonce_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateMagicLink(ctx context.Context, arg CreateMagicLinkParams) (MagicLink, error) {
+	row := q.db.QueryRow(ctx, createMagicLink,
+		arg.Username,
+		arg.TokenHash,
+		arg.NonceHash,
+		arg.ExpiresAt,
+	)
+	var i MagicLink
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.TokenHash,
+		&i.NonceHash,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteMagicLinks = `-- name: DeleteMagicLinks :exec
+DELETE FROM magic_links WHERE username = $1
+`
+
+func (q *Queries) DeleteMagicLinks(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, deleteMagicLinks, username)
+	return err
+}
+
+const getMagicLinkByHash = `-- name: GetMagicLinkByHash :one
+SELECT id, username, token_hash, nonce_hash, used, expires_at, created_at FROM magic_links WHERE token_hash = $1
+`
+
+func (q *Queries) GetMagicLinkByHash(ctx context.Context, tokenHash string) (MagicLink, error) {
+	row := q.db.QueryRow(ctx, getMagicLinkByHash, tokenHash)
+	var i MagicLink
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.TokenHash,
+		&i.NonceHash,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useMagicLink = `-- name: UseMagicLink :execrows
+UPDATE magic_links SET used = true WHERE id = $1 AND used = false
+`
+
+func (q *Queries) UseMagicLink(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, useMagicLink, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -18,10 +18,22 @@ type Account struct {
 	Type             string      `json:"type"`
 	Closed           bool        `json:"closed"`
 	Note             pgtype.Text `json:"note"`
-	Balance          int32       `json:"balance"`
-	ClearedBalance   int32       `json:"cleared_balance"`
-	UnclearedBalance int32       `json:"uncleared_balance"`
+	Balance          int64       `json:"balance"`
+	ClearedBalance   int64       `json:"cleared_balance"`
+	UnclearedBalance int64       `json:"uncleared_balance"`
 	LastReconciledAt time.Time   `json:"last_reconciled_at"`
+	OnBudget         bool        `json:"on_budget"`
+	CurrencyCode     string      `json:"currency_code"`
+}
+
+type AuditLog struct {
+	ID             int64     `json:"id"`
+	AdminUsername  string    `json:"admin_username"`
+	Action         string    `json:"action"`
+	TargetUsername string    `json:"target_username"`
+	Details        string    `json:"details"`
+	ClientIp       string    `json:"client_ip"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 type Budget struct {
@@ -32,9 +44,11 @@ type Budget struct {
 }
 
 type Category struct {
-	ID              uuid.UUID `json:"id"`
-	CategoryGroupID uuid.UUID `json:"category_group_id"`
-	Name            string    `json:"name"`
+	ID               uuid.UUID   `json:"id"`
+	CategoryGroupID  uuid.UUID   `json:"category_group_id"`
+	Name             string      `json:"name"`
+	Assigned         int64       `json:"assigned"`
+	PaymentAccountID pgtype.UUID `json:"payment_account_id"`
 }
 
 type CategoryGroup struct {
@@ -43,34 +57,161 @@ type CategoryGroup struct {
 	Name     string    `json:"name"`
 }
 
+type EmailChange struct {
+	ID              int64     `json:"id"`
+	Username        string    `json:"username"`
+	OldEmail        string    `json:"old_email"`
+	NewEmail        string    `json:"new_email"`
+	TokenHash       string    `json:"token_hash"`
+	CancelTokenHash string    `json:"cancel_token_hash"`
+	Used            bool      `json:"used"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ExchangeRate struct {
+	BudgetID     uuid.UUID   `json:"budget_id"`
+	Date         pgtype.Date `json:"date"`
+	FromCurrency string      `json:"from_currency"`
+	ToCurrency   string      `json:"to_currency"`
+	Rate         float64     `json:"rate"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+type InvestmentTransaction struct {
+	ID        uuid.UUID   `json:"id"`
+	AccountID uuid.UUID   `json:"account_id"`
+	Date      pgtype.Date `json:"date"`
+	Type      string      `json:"type"`
+	Symbol    string      `json:"symbol"`
+	Quantity  float64     `json:"quantity"`
+	Amount    int64       `json:"amount"`
+	Memo      pgtype.Text `json:"memo"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type Loan struct {
+	AccountID    uuid.UUID   `json:"account_id"`
+	Principal    int64       `json:"principal"`
+	InterestRate float64     `json:"interest_rate"`
+	TermMonths   int32       `json:"term_months"`
+	StartDate    pgtype.Date `json:"start_date"`
+	PaymentDay   int32       `json:"payment_day"`
+	ExtraPayment int64       `json:"extra_payment"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+type LoginEvent struct {
+	ID              int64       `json:"id"`
+	Username        string      `json:"username"`
+	Success         bool        `json:"success"`
+	UserAgent       string      `json:"user_agent"`
+	ClientIp        string      `json:"client_ip"`
+	SessionID       pgtype.UUID `json:"session_id"`
+	RevokeTokenHash pgtype.Text `json:"revoke_token_hash"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+type MagicLink struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	TokenHash string    `json:"token_hash"`
+	NonceHash string    `json:"nonce_hash"`
+	Used      bool      `json:"used"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OauthAuthorizationCode struct {
+	CodeHash      string    `json:"code_hash"`
+	ClientID      uuid.UUID `json:"client_id"`
+	Username      string    `json:"username"`
+	RedirectUri   string    `json:"redirect_uri"`
+	Scopes        []string  `json:"scopes"`
+	CodeChallenge string    `json:"code_challenge"`
+	Used          bool      `json:"used"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type OauthClient struct {
+	ID            uuid.UUID   `json:"id"`
+	OwnerUsername string      `json:"owner_username"`
+	Name          string      `json:"name"`
+	SecretHash    pgtype.Text `json:"secret_hash"`
+	RedirectUris  []string    `json:"redirect_uris"`
+	Scopes        []string    `json:"scopes"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+type PasswordReset struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	TokenHash string    `json:"token_hash"`
+	Used      bool      `json:"used"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Payee struct {
-	ID       uuid.UUID `json:"id"`
-	BudgetID uuid.UUID `json:"budget_id"`
-	Name     string    `json:"name"`
+	ID                uuid.UUID   `json:"id"`
+	BudgetID          uuid.UUID   `json:"budget_id"`
+	Name              string      `json:"name"`
+	TransferAccountID pgtype.UUID `json:"transfer_account_id"`
+}
+
+type PersonalAccessToken struct {
+	ID         uuid.UUID          `json:"id"`
+	Username   string             `json:"username"`
+	Name       string             `json:"name"`
+	TokenHash  string             `json:"token_hash"`
+	Scopes     []string           `json:"scopes"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID        int64              `json:"id"`
+	Username  string             `json:"username"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type SecurityPrice struct {
+	BudgetID  uuid.UUID   `json:"budget_id"`
+	Symbol    string      `json:"symbol"`
+	Date      pgtype.Date `json:"date"`
+	Price     int64       `json:"price"`
+	CreatedAt time.Time   `json:"created_at"`
 }
 
 type Session struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           uuid.UUID   `json:"id"`
+	Username     string      `json:"username"`
+	RefreshToken string      `json:"refresh_token"`
+	UserAgent    string      `json:"user_agent"`
+	ClientIp     string      `json:"client_ip"`
+	IsBlocked    bool        `json:"is_blocked"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+	CreatedAt    time.Time   `json:"created_at"`
+	ClientID     pgtype.UUID `json:"client_id"`
+	Scopes       []string    `json:"scopes"`
 }
 
 type Transaction struct {
-	ID         uuid.UUID   `json:"id"`
-	AccountID  uuid.UUID   `json:"account_id"`
-	Date       pgtype.Date `json:"date"`
-	PayeeID    uuid.UUID   `json:"payee_id"`
-	CategoryID pgtype.UUID `json:"category_id"`
-	Memo       pgtype.Text `json:"memo"`
-	Amount     int32       `json:"amount"`
-	Approved   bool        `json:"approved"`
-	Cleared    bool        `json:"cleared"`
-	Reconciled bool        `json:"reconciled"`
+	ID             uuid.UUID   `json:"id"`
+	AccountID      uuid.UUID   `json:"account_id"`
+	Date           pgtype.Date `json:"date"`
+	PayeeID        uuid.UUID   `json:"payee_id"`
+	CategoryID     pgtype.UUID `json:"category_id"`
+	Memo           pgtype.Text `json:"memo"`
+	Amount         int64       `json:"amount"`
+	Approved       bool        `json:"approved"`
+	Cleared        bool        `json:"cleared"`
+	Reconciled     bool        `json:"reconciled"`
+	TransferAmount pgtype.Int8 `json:"transfer_amount"`
 }
 
 type TransactionsView struct {
@@ -82,22 +223,45 @@ type TransactionsView struct {
 	PayeeID      uuid.UUID   `json:"payee_id"`
 	PayeeName    string      `json:"payee_name"`
 	CategoryID   pgtype.UUID `json:"category_id"`
-	CategoryName string      `json:"category_name"`
+	CategoryName pgtype.Text `json:"category_name"`
 	Memo         pgtype.Text `json:"memo"`
-	Amount       int32       `json:"amount"`
+	Amount       int64       `json:"amount"`
 	Approved     bool        `json:"approved"`
 	Cleared      bool        `json:"cleared"`
 	Reconciled   bool        `json:"reconciled"`
+	CurrencyCode string      `json:"currency_code"`
+}
+
+type TwoFactorChallenge struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	Attempts  int32     `json:"attempts"`
+	Used      bool      `json:"used"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type User struct {
-	ID                 uuid.UUID `json:"id"`
-	Username           string    `json:"username"`
-	Password           string    `json:"password"`
-	Email              string    `json:"email"`
-	EmailVerified      bool      `json:"email_verified"`
-	CreatedAt          time.Time `json:"created_at"`
-	LastPasswordChange time.Time `json:"last_password_change"`
+	ID                 uuid.UUID   `json:"id"`
+	Username           string      `json:"username"`
+	Password           string      `json:"password"`
+	Email              string      `json:"email"`
+	EmailVerified      bool        `json:"email_verified"`
+	CreatedAt          time.Time   `json:"created_at"`
+	LastPasswordChange time.Time   `json:"last_password_change"`
+	TotpSecret         pgtype.Text `json:"totp_secret"`
+	TotpEnabled        bool        `json:"totp_enabled"`
+	Roles              []string    `json:"roles"`
+	TotpLastStep       int64       `json:"totp_last_step"`
+}
+
+type UserIdentity struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type VerifyEmail struct {
@@ -109,3 +273,26 @@ type VerifyEmail struct {
 	CreatedAt time.Time `json:"created_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
+
+type Webhook struct {
+	ID        uuid.UUID `json:"id"`
+	BudgetID  uuid.UUID `json:"budget_id"`
+	Url       string    `json:"url"`
+	Secret    string    `json:"secret"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID          uuid.UUID          `json:"id"`
+	WebhookID   uuid.UUID          `json:"webhook_id"`
+	Event       string             `json:"event"`
+	Payload     []byte             `json:"payload"`
+	Redelivery  bool               `json:"redelivery"`
+	Attempts    int32              `json:"attempts"`
+	StatusCode  pgtype.Int4        `json:"status_code"`
+	Error       pgtype.Text        `json:"error"`
+	DeliveredAt pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
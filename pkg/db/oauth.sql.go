@@ -0,0 +1,224 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: oauth.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createOAuthAuthorizationCode = `-- name: CreateOAuthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (
+    code_hash,
+    client_id,
+    username,
+    redirect_uri,
+    scopes,
+    code_challenge,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING code_hash, client_id, username, redirect_uri, scopes, code_challenge, used, expires_at, created_at
+`
+
+type CreateOAuthAuthorizationCodeParams struct {
+	CodeHash      string    `json:"code_hash"`
+	ClientID      uuid.UUID `json:"client_id"`
+	Username      string    `json:"username"`
+	RedirectUri   string    `json:"redirect_uri"`
+	Scopes        []string  `json:"scopes"`
+	CodeChallenge string    `json:"code_challenge"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OauthAuthorizationCode, error) {
+	row := q.db.QueryRow(ctx, createOAuthAuthorizationCode,
+		arg.CodeHash,
+		arg.ClientID,
+		arg.Username,
+		arg.RedirectUri,
+		arg.Scopes,
+		arg.CodeChallenge,
+		arg.ExpiresAt,
+	)
+	var i OauthAuthorizationCode
+	err := row.Scan(
+		&i.CodeHash,
+		&i.ClientID,
+		&i.Username,
+		&i.RedirectUri,
+		&i.Scopes,
+		&i.CodeChallenge,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOAuthClient = `-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (
+    owner_username,
+    name,
+    secret_hash,
+    redirect_uris,
+    scopes
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING id, owner_username, name, secret_hash, redirect_uris, scopes, created_at
+`
+
+type CreateOAuthClientParams struct {
+	OwnerUsername string      `json:"owner_username"`
+	Name          string      `json:"name"`
+	SecretHash    pgtype.Text `json:"secret_hash"`
+	RedirectUris  []string    `json:"redirect_uris"`
+	Scopes        []string    `json:"scopes"`
+}
+
+func (q *Queries) CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OauthClient, error) {
+	row := q.db.QueryRow(ctx, createOAuthClient,
+		arg.OwnerUsername,
+		arg.Name,
+		arg.SecretHash,
+		arg.RedirectUris,
+		arg.Scopes,
+	)
+	var i OauthClient
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerUsername,
+		&i.Name,
+		&i.SecretHash,
+		&i.RedirectUris,
+		&i.Scopes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteOAuthAuthorizationCodes = `-- name: DeleteOAuthAuthorizationCodes :exec
+DELETE FROM oauth_authorization_codes WHERE client_id = $1
+`
+
+func (q *Queries) DeleteOAuthAuthorizationCodes(ctx context.Context, clientID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteOAuthAuthorizationCodes, clientID)
+	return err
+}
+
+const deleteOAuthClient = `-- name: DeleteOAuthClient :exec
+DELETE FROM oauth_clients WHERE id = $1
+`
+
+func (q *Queries) DeleteOAuthClient(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteOAuthClient, id)
+	return err
+}
+
+const deleteOAuthClients = `-- name: DeleteOAuthClients :exec
+DELETE FROM oauth_clients WHERE owner_username = $1
+`
+
+func (q *Queries) DeleteOAuthClients(ctx context.Context, ownerUsername string) error {
+	_, err := q.db.Exec(ctx, deleteOAuthClients, ownerUsername)
+	return err
+}
+
+const deleteUserOAuthAuthorizationCodes = `-- name: DeleteUserOAuthAuthorizationCodes :exec
+DELETE FROM oauth_authorization_codes WHERE username = $1
+`
+
+func (q *Queries) DeleteUserOAuthAuthorizationCodes(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, deleteUserOAuthAuthorizationCodes, username)
+	return err
+}
+
+const getOAuthAuthorizationCode = `-- name: GetOAuthAuthorizationCode :one
+SELECT code_hash, client_id, username, redirect_uri, scopes, code_challenge, used, expires_at, created_at FROM oauth_authorization_codes WHERE code_hash = $1
+`
+
+func (q *Queries) GetOAuthAuthorizationCode(ctx context.Context, codeHash string) (OauthAuthorizationCode, error) {
+	row := q.db.QueryRow(ctx, getOAuthAuthorizationCode, codeHash)
+	var i OauthAuthorizationCode
+	err := row.Scan(
+		&i.CodeHash,
+		&i.ClientID,
+		&i.Username,
+		&i.RedirectUri,
+		&i.Scopes,
+		&i.CodeChallenge,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuthClient = `-- name: GetOAuthClient :one
+SELECT id, owner_username, name, secret_hash, redirect_uris, scopes, created_at FROM oauth_clients WHERE id = $1
+`
+
+func (q *Queries) GetOAuthClient(ctx context.Context, id uuid.UUID) (OauthClient, error) {
+	row := q.db.QueryRow(ctx, getOAuthClient, id)
+	var i OauthClient
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerUsername,
+		&i.Name,
+		&i.SecretHash,
+		&i.RedirectUris,
+		&i.Scopes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuthClients = `-- name: GetOAuthClients :many
+SELECT id, owner_username, name, secret_hash, redirect_uris, scopes, created_at FROM oauth_clients WHERE owner_username = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetOAuthClients(ctx context.Context, ownerUsername string) ([]OauthClient, error) {
+	rows, err := q.db.Query(ctx, getOAuthClients, ownerUsername)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OauthClient{}
+	for rows.Next() {
+		var i OauthClient
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerUsername,
+			&i.Name,
+			&i.SecretHash,
+			&i.RedirectUris,
+			&i.Scopes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const useOAuthAuthorizationCode = `-- name: UseOAuthAuthorizationCode :execrows
+UPDATE oauth_authorization_codes SET used = true WHERE code_hash = $1 AND used = false
+`
+
+func (q *Queries) UseOAuthAuthorizationCode(ctx context.Context, codeHash string) (int64, error) {
+	result, err := q.db.Exec(ctx, useOAuthAuthorizationCode, codeHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
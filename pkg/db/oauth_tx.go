@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Database transaction for deleting an OAuth client, along with its authorization codes and sessions.
+func (s *SQLStore) DeleteOAuthClientTx(ctx context.Context, clientId uuid.UUID) error {
+
+	txErr := s.execTransaction(ctx, func(q *Queries) error {
+		return purgeOAuthClient(ctx, q, clientId)
+	})
+
+	return txErr
+}
+
+func purgeOAuthClient(ctx context.Context, q *Queries, clientId uuid.UUID) error {
+
+	if err := q.DeleteOAuthAuthorizationCodes(ctx, clientId); err != nil {
+		return err
+	}
+	// Sessions issued to the client, which revokes its refresh tokens
+	if err := q.DeleteClientSessions(ctx, pgtype.UUID{Bytes: clientId, Valid: true}); err != nil {
+		return err
+	}
+	return q.DeleteOAuthClient(ctx, clientId)
+}
@@ -0,0 +1,89 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: password_resets.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createPasswordReset = `-- name: CreatePasswordReset :one
+INSERT INTO password_resets (
+    username,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING id, username, token_hash, used, expires_at, created_at
+`
+
+type CreatePasswordResetParams struct {
+	Username  string    `json:"username"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error) {
+	row := q.db.QueryRow(ctx, createPasswordReset, arg.Username, arg.TokenHash, arg.ExpiresAt)
+	var i PasswordReset
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.TokenHash,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deletePasswordResets = `-- name: DeletePasswordResets :exec
+DELETE FROM password_resets WHERE username = $1
+`
+
+func (q *Queries) DeletePasswordResets(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, deletePasswordResets, username)
+	return err
+}
+
+const getPasswordResetByHash = `-- name: GetPasswordResetByHash :one
+SELECT id, username, token_hash, used, expires_at, created_at FROM password_resets WHERE token_hash = $1
+`
+
+func (q *Queries) GetPasswordResetByHash(ctx context.Context, tokenHash string) (PasswordReset, error) {
+	row := q.db.QueryRow(ctx, getPasswordResetByHash, tokenHash)
+	var i PasswordReset
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.TokenHash,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const usePasswordReset = `-- name: UsePasswordReset :execrows
+UPDATE password_resets SET used = true WHERE id = $1 AND used = false
+`
+
+func (q *Queries) UsePasswordReset(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, usePasswordReset, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const usePasswordResets = `-- name: UsePasswordResets :exec
+UPDATE password_resets SET used = true WHERE username = $1 AND used = false
+`
+
+func (q *Queries) UsePasswordResets(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, usePasswordResets, username)
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createPayee = `-- name: CreatePayee :one
@@ -17,7 +18,7 @@ INSERT INTO payees (
     name
 ) VALUES (
     $1, $2
-) RETURNING id, budget_id, name
+) RETURNING id, budget_id, name, transfer_account_id
 `
 
 type CreatePayeeParams struct {
@@ -28,7 +29,40 @@ type CreatePayeeParams struct {
 func (q *Queries) CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error) {
 	row := q.db.QueryRow(ctx, createPayee, arg.BudgetID, arg.Name)
 	var i Payee
-	err := row.Scan(&i.ID, &i.BudgetID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Name,
+		&i.TransferAccountID,
+	)
+	return i, err
+}
+
+const createTransferPayee = `-- name: CreateTransferPayee :one
+INSERT INTO payees (
+    budget_id,
+    name,
+    transfer_account_id
+) VALUES (
+    $1, $2, $3
+) RETURNING id, budget_id, name, transfer_account_id
+`
+
+type CreateTransferPayeeParams struct {
+	BudgetID          uuid.UUID   `json:"budget_id"`
+	Name              string      `json:"name"`
+	TransferAccountID pgtype.UUID `json:"transfer_account_id"`
+}
+
+func (q *Queries) CreateTransferPayee(ctx context.Context, arg CreateTransferPayeeParams) (Payee, error) {
+	row := q.db.QueryRow(ctx, createTransferPayee, arg.BudgetID, arg.Name, arg.TransferAccountID)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Name,
+		&i.TransferAccountID,
+	)
 	return i, err
 }
 
@@ -46,19 +80,33 @@ func (q *Queries) DeletePayee(ctx context.Context, arg DeletePayeeParams) error
 	return err
 }
 
+const deletePayees = `-- name: DeletePayees :exec
+DELETE FROM payees WHERE budget_id = $1
+`
+
+func (q *Queries) DeletePayees(ctx context.Context, budgetID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deletePayees, budgetID)
+	return err
+}
+
 const getPayeeById = `-- name: GetPayeeById :one
-SELECT id, budget_id, name FROM payees WHERE id = $1
+SELECT id, budget_id, name, transfer_account_id FROM payees WHERE id = $1
 `
 
 func (q *Queries) GetPayeeById(ctx context.Context, id uuid.UUID) (Payee, error) {
 	row := q.db.QueryRow(ctx, getPayeeById, id)
 	var i Payee
-	err := row.Scan(&i.ID, &i.BudgetID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Name,
+		&i.TransferAccountID,
+	)
 	return i, err
 }
 
 const getPayees = `-- name: GetPayees :many
-SELECT id, budget_id, name FROM payees WHERE budget_id = $1
+SELECT id, budget_id, name, transfer_account_id FROM payees WHERE budget_id = $1
 `
 
 func (q *Queries) GetPayees(ctx context.Context, budgetID uuid.UUID) ([]Payee, error) {
@@ -70,7 +118,12 @@ func (q *Queries) GetPayees(ctx context.Context, budgetID uuid.UUID) ([]Payee, e
 	items := []Payee{}
 	for rows.Next() {
 		var i Payee
-		if err := rows.Scan(&i.ID, &i.BudgetID, &i.Name); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
+			&i.Name,
+			&i.TransferAccountID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -82,7 +135,7 @@ func (q *Queries) GetPayees(ctx context.Context, budgetID uuid.UUID) ([]Payee, e
 }
 
 const updatePayee = `-- name: UpdatePayee :one
-UPDATE payees SET name = $1 WHERE budget_id = $2 AND id = $3 RETURNING id, budget_id, name
+UPDATE payees SET name = $1 WHERE budget_id = $2 AND id = $3 RETURNING id, budget_id, name, transfer_account_id
 `
 
 type UpdatePayeeParams struct {
@@ -94,6 +147,11 @@ type UpdatePayeeParams struct {
 func (q *Queries) UpdatePayee(ctx context.Context, arg UpdatePayeeParams) (Payee, error) {
 	row := q.db.QueryRow(ctx, updatePayee, arg.Name, arg.BudgetID, arg.ID)
 	var i Payee
-	err := row.Scan(&i.ID, &i.BudgetID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Name,
+		&i.TransferAccountID,
+	)
 	return i, err
 }
@@ -8,45 +8,124 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddUserRole(ctx context.Context, arg AddUserRoleParams) (int64, error)
+	// Counts an attempt at a challenge. Returns no rows once it is used, expired or out of attempts.
+	AttemptTwoFactorChallenge(ctx context.Context, arg AttemptTwoFactorChallengeParams) (TwoFactorChallenge, error)
+	BlockSession(ctx context.Context, arg BlockSessionParams) (int64, error)
+	BlockUserSessions(ctx context.Context, username string) error
+	CountUsers(ctx context.Context, search string) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
 	CreateBudget(ctx context.Context, arg CreateBudgetParams) (Budget, error)
 	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
 	CreateCategoryGroup(ctx context.Context, arg CreateCategoryGroupParams) (CategoryGroup, error)
+	CreateEmailChange(ctx context.Context, arg CreateEmailChangeParams) (EmailChange, error)
+	CreateInvestmentTransaction(ctx context.Context, arg CreateInvestmentTransactionParams) (InvestmentTransaction, error)
+	CreateLoginEvent(ctx context.Context, arg CreateLoginEventParams) (LoginEvent, error)
+	CreateMagicLink(ctx context.Context, arg CreateMagicLinkParams) (MagicLink, error)
+	CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OauthAuthorizationCode, error)
+	CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OauthClient, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
 	CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error)
+	CreatePaymentCategory(ctx context.Context, arg CreatePaymentCategoryParams) (Category, error)
+	CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (PersonalAccessToken, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
+	CreateTransferPayee(ctx context.Context, arg CreateTransferPayeeParams) (Payee, error)
+	CreateTwoFactorChallenge(ctx context.Context, arg CreateTwoFactorChallengeParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
 	CreateVerifyEmails(ctx context.Context, arg CreateVerifyEmailsParams) (VerifyEmail, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
 	DeleteAccount(ctx context.Context, id uuid.UUID) error
 	DeleteAccounts(ctx context.Context, budgetID uuid.UUID) error
 	DeleteBudget(ctx context.Context, id uuid.UUID) error
+	DeleteBudgetTransactions(ctx context.Context, budgetID uuid.UUID) error
+	DeleteBudgetWebhookDeliveries(ctx context.Context, budgetID uuid.UUID) error
 	DeleteBudgets(ctx context.Context, ownerUsername string) error
 	DeleteCategories(ctx context.Context, categoryGroupID uuid.UUID) error
 	DeleteCategory(ctx context.Context, id uuid.UUID) error
 	DeleteCategoryGroup(ctx context.Context, id uuid.UUID) error
 	DeleteCategoryGroups(ctx context.Context, budgetID uuid.UUID) error
+	DeleteClientSessions(ctx context.Context, clientID pgtype.UUID) error
+	DeleteEmailChanges(ctx context.Context, username string) error
+	DeleteLoan(ctx context.Context, accountID uuid.UUID) error
+	DeleteLoginEvents(ctx context.Context, username string) error
+	DeleteMagicLinks(ctx context.Context, username string) error
+	DeleteOAuthAuthorizationCodes(ctx context.Context, clientID uuid.UUID) error
+	DeleteOAuthClient(ctx context.Context, id uuid.UUID) error
+	DeleteOAuthClients(ctx context.Context, ownerUsername string) error
+	DeletePasswordResets(ctx context.Context, username string) error
 	DeletePayee(ctx context.Context, arg DeletePayeeParams) error
+	DeletePayees(ctx context.Context, budgetID uuid.UUID) error
+	DeletePersonalAccessToken(ctx context.Context, arg DeletePersonalAccessTokenParams) (int64, error)
+	DeletePersonalAccessTokens(ctx context.Context, username string) error
+	DeleteRecoveryCodes(ctx context.Context, username string) error
 	DeleteTransaction(ctx context.Context, id uuid.UUID) error
 	DeleteUser(ctx context.Context, username string) error
+	DeleteUserIdentities(ctx context.Context, username string) error
+	DeleteUserOAuthAuthorizationCodes(ctx context.Context, username string) error
 	DeleteUserSessions(ctx context.Context, username string) error
 	DeleteVerifyEmails(ctx context.Context, username string) error
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	DeleteWebhookDeliveries(ctx context.Context, webhookID uuid.UUID) error
+	DeleteWebhooks(ctx context.Context, budgetID uuid.UUID) error
+	DisableUserTOTP(ctx context.Context, username string) error
+	EnableUserTOTP(ctx context.Context, username string) error
 	GetAccount(ctx context.Context, arg GetAccountParams) (Account, error)
+	// What the transactions after a date changed in the balance of each account of a budget. Transfers change the account
+	// of the transaction and the account of its payee, which gets the transfer amount between currencies.
+	GetAccountChangesAfter(ctx context.Context, arg GetAccountChangesAfterParams) ([]GetAccountChangesAfterRow, error)
 	GetAccounts(ctx context.Context, budgetID uuid.UUID) ([]Account, error)
+	GetAuditLogs(ctx context.Context, arg GetAuditLogsParams) ([]AuditLog, error)
 	GetBudget(ctx context.Context, arg GetBudgetParams) (Budget, error)
 	GetBudgetAccount(ctx context.Context, arg GetBudgetAccountParams) (GetBudgetAccountRow, error)
+	GetBudgetCategories(ctx context.Context, budgetID uuid.UUID) ([]Category, error)
+	GetBudgetCategory(ctx context.Context, arg GetBudgetCategoryParams) (Category, error)
 	GetBudgetDetails(ctx context.Context, arg GetBudgetDetailsParams) (Budget, error)
+	GetBudgetInvestmentTransactions(ctx context.Context, arg GetBudgetInvestmentTransactionsParams) ([]InvestmentTransaction, error)
 	GetBudgets(ctx context.Context, ownerUsername string) ([]Budget, error)
 	GetCategories(ctx context.Context, categoryGroupID uuid.UUID) ([]Category, error)
 	GetCategory(ctx context.Context, id uuid.UUID) (Category, error)
 	GetCategoryGroup(ctx context.Context, id uuid.UUID) (CategoryGroup, error)
+	GetCategoryGroupByName(ctx context.Context, arg GetCategoryGroupByNameParams) (CategoryGroup, error)
 	GetCategoryGroupsByBudgetId(ctx context.Context, budgetID uuid.UUID) ([]CategoryGroup, error)
+	// Transfers between accounts in different currencies
+	GetCurrencyTransfers(ctx context.Context, budgetID uuid.UUID) ([]GetCurrencyTransfersRow, error)
+	GetEmailChangeByCancelHash(ctx context.Context, cancelTokenHash string) (EmailChange, error)
+	GetEmailChangeByHash(ctx context.Context, tokenHash string) (EmailChange, error)
+	GetExchangeRates(ctx context.Context, arg GetExchangeRatesParams) ([]ExchangeRate, error)
+	// The rates on or before a date, or all of them
+	GetExchangeRatesUntil(ctx context.Context, arg GetExchangeRatesUntilParams) ([]ExchangeRate, error)
+	GetInvestmentTransactions(ctx context.Context, accountID uuid.UUID) ([]InvestmentTransaction, error)
+	// The last price of each security on or before a date
+	GetLatestSecurityPrices(ctx context.Context, arg GetLatestSecurityPricesParams) ([]SecurityPrice, error)
+	GetLoan(ctx context.Context, accountID uuid.UUID) (Loan, error)
+	// Transfers paying a loan account: outflows of other accounts to its transfer payee, and inflows recorded on the loan
+	// account from a transfer payee.
+	GetLoanPayments(ctx context.Context, accountID uuid.UUID) ([]Transaction, error)
+	GetLoginDevice(ctx context.Context, arg GetLoginDeviceParams) (GetLoginDeviceRow, error)
+	GetLoginEventByRevokeHash(ctx context.Context, revokeTokenHash pgtype.Text) (LoginEvent, error)
+	GetLoginEvents(ctx context.Context, arg GetLoginEventsParams) ([]LoginEvent, error)
+	GetMagicLinkByHash(ctx context.Context, tokenHash string) (MagicLink, error)
+	GetOAuthAuthorizationCode(ctx context.Context, codeHash string) (OauthAuthorizationCode, error)
+	GetOAuthClient(ctx context.Context, id uuid.UUID) (OauthClient, error)
+	GetOAuthClients(ctx context.Context, ownerUsername string) ([]OauthClient, error)
+	GetPasswordResetByHash(ctx context.Context, tokenHash string) (PasswordReset, error)
 	GetPayeeById(ctx context.Context, id uuid.UUID) (Payee, error)
 	GetPayees(ctx context.Context, budgetID uuid.UUID) ([]Payee, error)
 	GetPendingVerifyEmails(ctx context.Context, arg GetPendingVerifyEmailsParams) ([]VerifyEmail, error)
+	GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (PersonalAccessToken, error)
+	GetPersonalAccessTokens(ctx context.Context, username string) ([]PersonalAccessToken, error)
+	GetSecurityPrices(ctx context.Context, arg GetSecurityPricesParams) ([]SecurityPrice, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSubscribedWebhooks(ctx context.Context, arg GetSubscribedWebhooksParams) ([]Webhook, error)
 	GetTransactions(ctx context.Context, budgetID uuid.UUID) ([]Transaction, error)
 	GetTransactionsById(ctx context.Context, id uuid.UUID) (Transaction, error)
 	GetTransactionsView(ctx context.Context, budgetID uuid.UUID) ([]TransactionsView, error)
@@ -54,15 +133,46 @@ type Querier interface {
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserById(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetUserIdentities(ctx context.Context, username string) ([]UserIdentity, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	GetUserSessions(ctx context.Context, username string) ([]Session, error)
 	GetUsers(ctx context.Context) ([]User, error)
 	GetVerifyEmails(ctx context.Context, arg GetVerifyEmailsParams) (VerifyEmail, error)
+	GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error)
+	GetWebhookById(ctx context.Context, id uuid.UUID) (Webhook, error)
+	GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, id uuid.UUID) (WebhookDelivery, error)
+	GetWebhooks(ctx context.Context, budgetID uuid.UUID) ([]Webhook, error)
+	// The search is a LIKE pattern matched against the username and the email
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	RotateSessionRefreshToken(ctx context.Context, arg RotateSessionRefreshTokenParams) (int64, error)
+	SetLoginEventRevokeToken(ctx context.Context, arg SetLoginEventRevokeTokenParams) (int64, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
 	UpdateCategoryGroup(ctx context.Context, arg UpdateCategoryGroupParams) (CategoryGroup, error)
 	UpdateCodeUsed(ctx context.Context, code string) (VerifyEmail, error)
 	UpdatePayee(ctx context.Context, arg UpdatePayeeParams) (Payee, error)
+	UpdatePersonalAccessTokenLastUsed(ctx context.Context, id uuid.UUID) error
 	UpdateTransaction(ctx context.Context, arg UpdateTransactionParams) (Transaction, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	// Only replaces the old hash, so that a concurrent password change is not overwritten
+	UpdateUserPasswordHash(ctx context.Context, arg UpdateUserPasswordHashParams) error
+	// Only moves forward, so that a TOTP code used by a concurrent request is not accepted twice
+	UpdateUserTOTPLastStep(ctx context.Context, arg UpdateUserTOTPLastStepParams) (int64, error)
+	UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) error
+	UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error)
+	UpdateWebhookDeliveryResult(ctx context.Context, arg UpdateWebhookDeliveryResultParams) (WebhookDelivery, error)
+	UpsertExchangeRate(ctx context.Context, arg []UpsertExchangeRateParams) *UpsertExchangeRateBatchResults
+	UpsertLoan(ctx context.Context, arg UpsertLoanParams) (Loan, error)
+	UpsertSecurityPrice(ctx context.Context, arg UpsertSecurityPriceParams) error
+	UseEmailChange(ctx context.Context, id int64) (int64, error)
+	UseEmailChanges(ctx context.Context, username string) error
+	UseMagicLink(ctx context.Context, id int64) (int64, error)
+	UseOAuthAuthorizationCode(ctx context.Context, codeHash string) (int64, error)
+	UsePasswordReset(ctx context.Context, id int64) (int64, error)
+	UsePasswordResets(ctx context.Context, username string) error
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	UseTwoFactorChallenge(ctx context.Context, id uuid.UUID) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -10,8 +10,35 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const blockSession = `-- name: BlockSession :execrows
+UPDATE sessions SET is_blocked = true WHERE id = $1 AND username = $2 AND is_blocked = false
+`
+
+type BlockSessionParams struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+func (q *Queries) BlockSession(ctx context.Context, arg BlockSessionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, blockSession, arg.ID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const blockUserSessions = `-- name: BlockUserSessions :exec
+UPDATE sessions SET is_blocked = true WHERE username = $1
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, blockUserSessions, username)
+	return err
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (
     id,
@@ -20,20 +47,24 @@ INSERT INTO sessions (
     user_agent,
     client_ip,
     is_blocked,
-    expires_at
+    expires_at,
+    client_id,
+    scopes
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9::varchar[], '{}')
+) RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, client_id, scopes
 `
 
 type CreateSessionParams struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
+	ID           uuid.UUID   `json:"id"`
+	Username     string      `json:"username"`
+	RefreshToken string      `json:"refresh_token"`
+	UserAgent    string      `json:"user_agent"`
+	ClientIp     string      `json:"client_ip"`
+	IsBlocked    bool        `json:"is_blocked"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+	ClientID     pgtype.UUID `json:"client_id"`
+	Scopes       []string    `json:"scopes"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
@@ -45,6 +76,8 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		arg.ClientIp,
 		arg.IsBlocked,
 		arg.ExpiresAt,
+		arg.ClientID,
+		arg.Scopes,
 	)
 	var i Session
 	err := row.Scan(
@@ -56,10 +89,21 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.ClientID,
+		&i.Scopes,
 	)
 	return i, err
 }
 
+const deleteClientSessions = `-- name: DeleteClientSessions :exec
+DELETE from sessions WHERE client_id = $1
+`
+
+func (q *Queries) DeleteClientSessions(ctx context.Context, clientID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteClientSessions, clientID)
+	return err
+}
+
 const deleteUserSessions = `-- name: DeleteUserSessions :exec
 DELETE from sessions WHERE username = $1
 `
@@ -70,7 +114,7 @@ func (q *Queries) DeleteUserSessions(ctx context.Context, username string) error
 }
 
 const getSession = `-- name: GetSession :one
-SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions WHERE id = $1
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, client_id, scopes FROM sessions WHERE id = $1
 `
 
 func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
@@ -85,6 +129,64 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.ClientID,
+		&i.Scopes,
 	)
 	return i, err
 }
+
+const getUserSessions = `-- name: GetUserSessions :many
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, client_id, scopes FROM sessions
+WHERE username = $1 AND is_blocked = false AND expires_at > now()
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetUserSessions(ctx context.Context, username string) ([]Session, error) {
+	rows, err := q.db.Query(ctx, getUserSessions, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.ClientID,
+			&i.Scopes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateSessionRefreshToken = `-- name: RotateSessionRefreshToken :execrows
+UPDATE sessions SET refresh_token = $1
+WHERE id = $2 AND refresh_token = $3 AND is_blocked = false
+`
+
+type RotateSessionRefreshTokenParams struct {
+	NewRefreshToken string    `json:"new_refresh_token"`
+	ID              uuid.UUID `json:"id"`
+	RefreshToken    string    `json:"refresh_token"`
+}
+
+func (q *Queries) RotateSessionRefreshToken(ctx context.Context, arg RotateSessionRefreshTokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rotateSessionRefreshToken, arg.NewRefreshToken, arg.ID, arg.RefreshToken)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
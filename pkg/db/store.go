@@ -10,10 +10,19 @@ import (
 type Store interface {
 	Querier
 	CreateUserTx(ctx context.Context, arg CreateUserParams, fn func(createdUser UserParams) error) (User, error)
-	UpdateUserTx(ctx context.Context, arg UpdateUserParams, fn func(createdUser UserParams) error) (User, error)
+	CreateUserWithIdentityTx(ctx context.Context, arg CreateUserWithIdentityTxParams) (User, error)
 	DeleteUserTx(ctx context.Context, userArg UserParams, budgetIds []uuid.UUID, afterDeleteFn func(deleteUser UserParams) error) error
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) error
+	BlockUserAccessTx(ctx context.Context, username string) error
+	EnableTOTPTx(ctx context.Context, username string, recoveryCodeHashes []string) error
+	DisableTOTPTx(ctx context.Context, username string) error
 	DeleteBudgetTx(ctx context.Context, budgetId uuid.UUID) error
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error)
 	DeleteCategoryGroupTx(ctx context.Context, categoryGroupId uuid.UUID) error
+	DeleteOAuthClientTx(ctx context.Context, clientId uuid.UUID) error
+	DeleteWebhookTx(ctx context.Context, webhookId uuid.UUID) error
+	UpsertSecurityPricesTx(ctx context.Context, prices []UpsertSecurityPriceParams) error
+	UpsertExchangeRatesTx(ctx context.Context, rates []UpsertExchangeRateParams) error
 }
 
 type SQLStore struct {
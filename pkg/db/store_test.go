@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a store on the database of DB_CONNSTRING, migrated up. Tests that need one are skipped without it.
+func newTestStore(t *testing.T) Store {
+
+	if testing.Short() {
+		t.Skip("skipping database test in short mode")
+	}
+	connString := os.Getenv("DB_CONNSTRING")
+	if connString == "" {
+		t.Skip("DB_CONNSTRING is not set")
+	}
+	pool, err := pgxpool.New(context.Background(), connString)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	return NewStore(pool)
+}
@@ -21,21 +21,23 @@ INSERT INTO transactions (
     memo,
     amount,
     cleared,
-    reconciled
+    reconciled,
+    transfer_amount
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
-) RETURNING id, account_id, date, payee_id, category_id, memo, amount, approved, cleared, reconciled
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, account_id, date, payee_id, category_id, memo, amount, approved, cleared, reconciled, transfer_amount
 `
 
 type CreateTransactionParams struct {
-	AccountID  uuid.UUID   `json:"account_id"`
-	Date       pgtype.Date `json:"date"`
-	PayeeID    uuid.UUID   `json:"payee_id"`
-	CategoryID pgtype.UUID `json:"category_id"`
-	Memo       pgtype.Text `json:"memo"`
-	Amount     int32       `json:"amount"`
-	Cleared    bool        `json:"cleared"`
-	Reconciled bool        `json:"reconciled"`
+	AccountID      uuid.UUID   `json:"account_id"`
+	Date           pgtype.Date `json:"date"`
+	PayeeID        uuid.UUID   `json:"payee_id"`
+	CategoryID     pgtype.UUID `json:"category_id"`
+	Memo           pgtype.Text `json:"memo"`
+	Amount         int64       `json:"amount"`
+	Cleared        bool        `json:"cleared"`
+	Reconciled     bool        `json:"reconciled"`
+	TransferAmount pgtype.Int8 `json:"transfer_amount"`
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
@@ -48,6 +50,7 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.Amount,
 		arg.Cleared,
 		arg.Reconciled,
+		arg.TransferAmount,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -61,10 +64,20 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.Approved,
 		&i.Cleared,
 		&i.Reconciled,
+		&i.TransferAmount,
 	)
 	return i, err
 }
 
+const deleteBudgetTransactions = `-- name: DeleteBudgetTransactions :exec
+DELETE FROM transactions WHERE account_id IN (SELECT id FROM accounts WHERE budget_id = $1)
+`
+
+func (q *Queries) DeleteBudgetTransactions(ctx context.Context, budgetID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteBudgetTransactions, budgetID)
+	return err
+}
+
 const deleteTransaction = `-- name: DeleteTransaction :exec
 DELETE FROM transactions WHERE id = $1
 `
@@ -74,8 +87,54 @@ func (q *Queries) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const getCurrencyTransfers = `-- name: GetCurrencyTransfers :many
+SELECT t.id, t.date, t.account_id, p.transfer_account_id::uuid AS transfer_account_id, t.amount, t.transfer_amount::bigint AS transfer_amount
+FROM transactions t
+JOIN accounts a ON t.account_id = a.id
+JOIN payees p ON t.payee_id = p.id
+WHERE a.budget_id = $1 AND t.transfer_amount IS NOT NULL AND p.transfer_account_id IS NOT NULL
+ORDER BY t.date, t.id
+`
+
+type GetCurrencyTransfersRow struct {
+	ID                uuid.UUID   `json:"id"`
+	Date              pgtype.Date `json:"date"`
+	AccountID         uuid.UUID   `json:"account_id"`
+	TransferAccountID uuid.UUID   `json:"transfer_account_id"`
+	Amount            int64       `json:"amount"`
+	TransferAmount    int64       `json:"transfer_amount"`
+}
+
+// Transfers between accounts in different currencies
+func (q *Queries) GetCurrencyTransfers(ctx context.Context, budgetID uuid.UUID) ([]GetCurrencyTransfersRow, error) {
+	rows, err := q.db.Query(ctx, getCurrencyTransfers, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCurrencyTransfersRow{}
+	for rows.Next() {
+		var i GetCurrencyTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Date,
+			&i.AccountID,
+			&i.TransferAccountID,
+			&i.Amount,
+			&i.TransferAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransactions = `-- name: GetTransactions :many
-select trans.id, trans.account_id, trans.date, trans.payee_id, trans.category_id, trans.memo, trans.amount, trans.approved, trans.cleared, trans.reconciled
+select trans.id, trans.account_id, trans.date, trans.payee_id, trans.category_id, trans.memo, trans.amount, trans.approved, trans.cleared, trans.reconciled, trans.transfer_amount
 from transactions trans, accounts accts
 where trans.account_id = accts.id AND accts.budget_id = $1
 `
@@ -100,6 +159,7 @@ func (q *Queries) GetTransactions(ctx context.Context, budgetID uuid.UUID) ([]Tr
 			&i.Approved,
 			&i.Cleared,
 			&i.Reconciled,
+			&i.TransferAmount,
 		); err != nil {
 			return nil, err
 		}
@@ -112,7 +172,7 @@ func (q *Queries) GetTransactions(ctx context.Context, budgetID uuid.UUID) ([]Tr
 }
 
 const getTransactionsById = `-- name: GetTransactionsById :one
-SELECT id, account_id, date, payee_id, category_id, memo, amount, approved, cleared, reconciled FROM transactions WHERE id = $1
+SELECT id, account_id, date, payee_id, category_id, memo, amount, approved, cleared, reconciled, transfer_amount FROM transactions WHERE id = $1
 `
 
 func (q *Queries) GetTransactionsById(ctx context.Context, id uuid.UUID) (Transaction, error) {
@@ -129,12 +189,13 @@ func (q *Queries) GetTransactionsById(ctx context.Context, id uuid.UUID) (Transa
 		&i.Approved,
 		&i.Cleared,
 		&i.Reconciled,
+		&i.TransferAmount,
 	)
 	return i, err
 }
 
 const getTransactionsView = `-- name: GetTransactionsView :many
-SELECT id, account_id, account_name, budget_id, date, payee_id, payee_name, category_id, category_name, memo, amount, approved, cleared, reconciled FROM transactions_view WHERE budget_id = $1
+SELECT id, account_id, account_name, budget_id, date, payee_id, payee_name, category_id, category_name, memo, amount, approved, cleared, reconciled, currency_code FROM transactions_view WHERE budget_id = $1
 `
 
 func (q *Queries) GetTransactionsView(ctx context.Context, budgetID uuid.UUID) ([]TransactionsView, error) {
@@ -161,6 +222,7 @@ func (q *Queries) GetTransactionsView(ctx context.Context, budgetID uuid.UUID) (
 			&i.Approved,
 			&i.Cleared,
 			&i.Reconciled,
+			&i.CurrencyCode,
 		); err != nil {
 			return nil, err
 		}
@@ -173,7 +235,7 @@ func (q *Queries) GetTransactionsView(ctx context.Context, budgetID uuid.UUID) (
 }
 
 const getTransactionsViewById = `-- name: GetTransactionsViewById :one
-SELECT id, account_id, account_name, budget_id, date, payee_id, payee_name, category_id, category_name, memo, amount, approved, cleared, reconciled FROM transactions_view WHERE id = $1
+SELECT id, account_id, account_name, budget_id, date, payee_id, payee_name, category_id, category_name, memo, amount, approved, cleared, reconciled, currency_code FROM transactions_view WHERE id = $1
 `
 
 func (q *Queries) GetTransactionsViewById(ctx context.Context, id uuid.UUID) (TransactionsView, error) {
@@ -194,6 +256,7 @@ func (q *Queries) GetTransactionsViewById(ctx context.Context, id uuid.UUID) (Tr
 		&i.Approved,
 		&i.Cleared,
 		&i.Reconciled,
+		&i.CurrencyCode,
 	)
 	return i, err
 }
@@ -211,7 +274,7 @@ SET
     cleared = COALESCE($9, cleared),
     reconciled = COALESCE($10, reconciled)
 WHERE id = $1
-RETURNING id, account_id, date, payee_id, category_id, memo, amount, approved, cleared, reconciled
+RETURNING id, account_id, date, payee_id, category_id, memo, amount, approved, cleared, reconciled, transfer_amount
 `
 
 type UpdateTransactionParams struct {
@@ -221,7 +284,7 @@ type UpdateTransactionParams struct {
 	PayeeID    pgtype.UUID `json:"payee_id"`
 	CategoryID pgtype.UUID `json:"category_id"`
 	Memo       pgtype.Text `json:"memo"`
-	Amount     pgtype.Int4 `json:"amount"`
+	Amount     pgtype.Int8 `json:"amount"`
 	Approved   pgtype.Bool `json:"approved"`
 	Cleared    pgtype.Bool `json:"cleared"`
 	Reconciled pgtype.Bool `json:"reconciled"`
@@ -252,6 +315,7 @@ func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionPa
 		&i.Approved,
 		&i.Cleared,
 		&i.Reconciled,
+		&i.TransferAmount,
 	)
 	return i, err
 }
@@ -0,0 +1,72 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: two_factor_challenges.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const attemptTwoFactorChallenge = `-- name: AttemptTwoFactorChallenge :one
+UPDATE two_factor_challenges SET attempts = attempts + 1
+WHERE id = $1 AND NOT used AND expires_at > now() AND attempts < $2::int
+RETURNING id, username, attempts, used, expires_at, created_at
+`
+
+type AttemptTwoFactorChallengeParams struct {
+	ID          uuid.UUID `json:"id"`
+	MaxAttempts int32     `json:"max_attempts"`
+}
+
+// Counts an attempt at a challenge. Returns no rows once it is used, expired or out of attempts.
+func (q *Queries) AttemptTwoFactorChallenge(ctx context.Context, arg AttemptTwoFactorChallengeParams) (TwoFactorChallenge, error) {
+	row := q.db.QueryRow(ctx, attemptTwoFactorChallenge, arg.ID, arg.MaxAttempts)
+	var i TwoFactorChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Attempts,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTwoFactorChallenge = `-- name: CreateTwoFactorChallenge :exec
+INSERT INTO two_factor_challenges (
+    id,
+    username,
+    expires_at
+) VALUES (
+    $1, $2, $3
+)
+`
+
+type CreateTwoFactorChallengeParams struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateTwoFactorChallenge(ctx context.Context, arg CreateTwoFactorChallengeParams) error {
+	_, err := q.db.Exec(ctx, createTwoFactorChallenge, arg.ID, arg.Username, arg.ExpiresAt)
+	return err
+}
+
+const useTwoFactorChallenge = `-- name: UseTwoFactorChallenge :execrows
+UPDATE two_factor_challenges SET used = true WHERE id = $1 AND NOT used
+`
+
+func (q *Queries) UseTwoFactorChallenge(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, useTwoFactorChallenge, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -1,5 +1,7 @@
 package db
 
+import "errors"
+
 // Parameter with just a username and email
 type UserParams struct {
 	Username string `json:"username"`
@@ -9,3 +11,29 @@ type UserParams struct {
 type UserTxResult struct {
 	User User
 }
+
+// Parameters of the transaction which creates a user with an external identity
+type CreateUserWithIdentityTxParams struct {
+	User CreateUserParams
+	// Issuer and subject of the identity
+	Issuer  string
+	Subject string
+}
+
+// Parameters of the transaction which creates an account
+type CreateAccountTxParams struct {
+	Account CreateAccountParams
+	// Whether to create a credit card payment category for the account
+	PaymentCategory bool
+}
+
+// Parameters of the transaction which resets a password
+type ResetPasswordTxParams struct {
+	ResetID  int64
+	Username string
+	// The new password, already hashed
+	Password string
+}
+
+// Returned when a password reset link has already been used
+var ErrPasswordResetUsed = errors.New("password reset has already been used")
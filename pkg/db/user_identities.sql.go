@@ -0,0 +1,110 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: user_identities.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createUserIdentity = `-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+    username,
+    issuer,
+    subject,
+    email
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, username, issuer, subject, email, created_at
+`
+
+type CreateUserIdentityParams struct {
+	Username string `json:"username"`
+	Issuer   string `json:"issuer"`
+	Subject  string `json:"subject"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRow(ctx, createUserIdentity,
+		arg.Username,
+		arg.Issuer,
+		arg.Subject,
+		arg.Email,
+	)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Issuer,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteUserIdentities = `-- name: DeleteUserIdentities :exec
+DELETE FROM user_identities WHERE username = $1
+`
+
+func (q *Queries) DeleteUserIdentities(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, deleteUserIdentities, username)
+	return err
+}
+
+const getUserIdentities = `-- name: GetUserIdentities :many
+SELECT id, username, issuer, subject, email, created_at FROM user_identities WHERE username = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetUserIdentities(ctx context.Context, username string) ([]UserIdentity, error) {
+	rows, err := q.db.Query(ctx, getUserIdentities, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UserIdentity{}
+	for rows.Next() {
+		var i UserIdentity
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Issuer,
+			&i.Subject,
+			&i.Email,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserIdentity = `-- name: GetUserIdentity :one
+SELECT id, username, issuer, subject, email, created_at FROM user_identities WHERE issuer = $1 AND subject = $2
+`
+
+type GetUserIdentityParams struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRow(ctx, getUserIdentity, arg.Issuer, arg.Subject)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Issuer,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -13,6 +13,36 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addUserRole = `-- name: AddUserRole :execrows
+UPDATE users SET roles = array_append(roles, $1::varchar)
+WHERE username = $2 AND NOT ($1::varchar = ANY(roles))
+`
+
+type AddUserRoleParams struct {
+	Role     string `json:"role"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) AddUserRole(ctx context.Context, arg AddUserRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addUserRole, arg.Role, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT count(*) FROM users
+WHERE $1::varchar = '' OR username ILIKE $1 OR email ILIKE $1
+`
+
+func (q *Queries) CountUsers(ctx context.Context, search string) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, search)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     username,
@@ -22,7 +52,7 @@ INSERT INTO users (
     last_password_change
 ) VALUES (
     $1, $2, $3, $4, $5
-) RETURNING id, username, password, email, email_verified, created_at, last_password_change
+) RETURNING id, username, password, email, email_verified, created_at, last_password_change, totp_secret, totp_enabled, roles, totp_last_step
 `
 
 type CreateUserParams struct {
@@ -50,6 +80,10 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.EmailVerified,
 		&i.CreatedAt,
 		&i.LastPasswordChange,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Roles,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
@@ -63,8 +97,26 @@ func (q *Queries) DeleteUser(ctx context.Context, username string) error {
 	return err
 }
 
+const disableUserTOTP = `-- name: DisableUserTOTP :exec
+UPDATE users SET totp_secret = NULL, totp_enabled = false WHERE username = $1
+`
+
+func (q *Queries) DisableUserTOTP(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, disableUserTOTP, username)
+	return err
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :exec
+UPDATE users SET totp_enabled = true WHERE username = $1
+`
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, enableUserTOTP, username)
+	return err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, password, email, email_verified, created_at, last_password_change FROM users WHERE email = $1
+SELECT id, username, password, email, email_verified, created_at, last_password_change, totp_secret, totp_enabled, roles, totp_last_step FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -78,12 +130,16 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.EmailVerified,
 		&i.CreatedAt,
 		&i.LastPasswordChange,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Roles,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, username, password, email, email_verified, created_at, last_password_change FROM users WHERE id = $1
+SELECT id, username, password, email, email_verified, created_at, last_password_change, totp_secret, totp_enabled, roles, totp_last_step FROM users WHERE id = $1
 `
 
 func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
@@ -97,12 +153,16 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.EmailVerified,
 		&i.CreatedAt,
 		&i.LastPasswordChange,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Roles,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, password, email, email_verified, created_at, last_password_change FROM users WHERE username = $1
+SELECT id, username, password, email, email_verified, created_at, last_password_change, totp_secret, totp_enabled, roles, totp_last_step FROM users WHERE username = $1
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
@@ -116,12 +176,16 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.EmailVerified,
 		&i.CreatedAt,
 		&i.LastPasswordChange,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Roles,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
 
 const getUsers = `-- name: GetUsers :many
-SELECT id, username, password, email, email_verified, created_at, last_password_change FROM users
+SELECT id, username, password, email, email_verified, created_at, last_password_change, totp_secret, totp_enabled, roles, totp_last_step FROM users
 `
 
 func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
@@ -141,6 +205,56 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 			&i.EmailVerified,
 			&i.CreatedAt,
 			&i.LastPasswordChange,
+			&i.TotpSecret,
+			&i.TotpEnabled,
+			&i.Roles,
+			&i.TotpLastStep,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, username, password, email, email_verified, created_at, last_password_change, totp_secret, totp_enabled, roles, totp_last_step FROM users
+WHERE $1::varchar = '' OR username ILIKE $1 OR email ILIKE $1
+ORDER BY username
+LIMIT $3 OFFSET $2
+`
+
+type ListUsersParams struct {
+	Search     string `json:"search"`
+	PageOffset int32  `json:"page_offset"`
+	PageSize   int32  `json:"page_size"`
+}
+
+// The search is a LIKE pattern matched against the username and the email
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers, arg.Search, arg.PageOffset, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Password,
+			&i.Email,
+			&i.EmailVerified,
+			&i.CreatedAt,
+			&i.LastPasswordChange,
+			&i.TotpSecret,
+			&i.TotpEnabled,
+			&i.Roles,
+			&i.TotpLastStep,
 		); err != nil {
 			return nil, err
 		}
@@ -160,7 +274,7 @@ SET
     email_verified = COALESCE($3, email_verified),
     last_password_change = COALESCE($4, last_password_change)
 WHERE username = $5
-RETURNING id, username, password, email, email_verified, created_at, last_password_change
+RETURNING id, username, password, email, email_verified, created_at, last_password_change, totp_secret, totp_enabled, roles, totp_last_step
 `
 
 type UpdateUserParams struct {
@@ -188,6 +302,58 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.EmailVerified,
 		&i.CreatedAt,
 		&i.LastPasswordChange,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Roles,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
+
+const updateUserPasswordHash = `-- name: UpdateUserPasswordHash :exec
+UPDATE users SET password = $1 WHERE username = $2 AND password = $3
+`
+
+type UpdateUserPasswordHashParams struct {
+	NewPassword string `json:"new_password"`
+	Username    string `json:"username"`
+	OldPassword string `json:"old_password"`
+}
+
+// Only replaces the old hash, so that a concurrent password change is not overwritten
+func (q *Queries) UpdateUserPasswordHash(ctx context.Context, arg UpdateUserPasswordHashParams) error {
+	_, err := q.db.Exec(ctx, updateUserPasswordHash, arg.NewPassword, arg.Username, arg.OldPassword)
+	return err
+}
+
+const updateUserTOTPLastStep = `-- name: UpdateUserTOTPLastStep :execrows
+UPDATE users SET totp_last_step = $1 WHERE username = $2 AND totp_last_step < $1
+`
+
+type UpdateUserTOTPLastStepParams struct {
+	Step     int64  `json:"step"`
+	Username string `json:"username"`
+}
+
+// Only moves forward, so that a TOTP code used by a concurrent request is not accepted twice
+func (q *Queries) UpdateUserTOTPLastStep(ctx context.Context, arg UpdateUserTOTPLastStepParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUserTOTPLastStep, arg.Step, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateUserTOTPSecret = `-- name: UpdateUserTOTPSecret :exec
+UPDATE users SET totp_secret = $2, totp_enabled = false WHERE username = $1
+`
+
+type UpdateUserTOTPSecretParams struct {
+	Username   string      `json:"username"`
+	TotpSecret pgtype.Text `json:"totp_secret"`
+}
+
+func (q *Queries) UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) error {
+	_, err := q.db.Exec(ctx, updateUserTOTPSecret, arg.Username, arg.TotpSecret)
+	return err
+}
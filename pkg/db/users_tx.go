@@ -2,8 +2,10 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // Database transaction for creating a user.
@@ -31,26 +33,24 @@ func (s *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams, fn fu
 	return txUser.User, txErr
 }
 
-// Database transaction for updating a user
-func (s *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserParams, fn func(createdUser UserParams) error) (User, error) {
+// Database transaction for creating a user who signed in with an external identity provider, linked to their identity.
+func (s *SQLStore) CreateUserWithIdentityTx(ctx context.Context, arg CreateUserWithIdentityTxParams) (User, error) {
 
 	var txUser UserTxResult
 
 	txErr := s.execTransaction(ctx, func(q *Queries) error {
 		var err error
-		txUser.User, err = q.UpdateUser(ctx, arg)
+		txUser.User, err = q.CreateUser(ctx, arg.User)
 		if err != nil {
 			return err
 		}
-		// only call the task if we are changing the email
-		if arg.Email.Valid && arg.EmailVerified.Valid {
-			if err := fn(UserParams{
-				Username: txUser.User.Username,
-			}); err != nil {
-				return err
-			}
-		}
-		return nil
+		_, err = q.CreateUserIdentity(ctx, CreateUserIdentityParams{
+			Username: txUser.User.Username,
+			Issuer:   arg.Issuer,
+			Subject:  arg.Subject,
+			Email:    arg.User.Email,
+		})
+		return err
 	})
 
 	return txUser.User, txErr
@@ -64,36 +64,54 @@ func (s *SQLStore) DeleteUserTx(ctx context.Context, userArg UserParams, budgetI
 		if err := q.DeleteVerifyEmails(ctx, userArg.Username); err != nil {
 			return err
 		}
+		// Delete the login history
+		if err := q.DeleteLoginEvents(ctx, userArg.Username); err != nil {
+			return err
+		}
 		// Delete sessions
 		if err := q.DeleteUserSessions(ctx, userArg.Username); err != nil {
 			return err
 		}
-
-		for i := range budgetIds {
-			// Delete payees
-			// TODO
-			// Delete the transactions
-			// TODO
-			// Delete category groups
-			cg, err := q.GetCategoryGroupsByBudgetId(ctx, budgetIds[i])
-			if err != nil {
-				return err
-			}
-			cgIds := make([]uuid.UUID, len(cg))
-			for c := range cg {
-				cgIds[c] = cg[c].ID
-			}
-			if err := q.DeleteCategoryGroups(ctx, budgetIds[i]); err != nil {
+		// Delete password resets
+		if err := q.DeletePasswordResets(ctx, userArg.Username); err != nil {
+			return err
+		}
+		// Delete the links to external identities
+		if err := q.DeleteUserIdentities(ctx, userArg.Username); err != nil {
+			return err
+		}
+		// Delete pending email changes
+		if err := q.DeleteEmailChanges(ctx, userArg.Username); err != nil {
+			return err
+		}
+		// Delete magic links
+		if err := q.DeleteMagicLinks(ctx, userArg.Username); err != nil {
+			return err
+		}
+		// Delete two-factor recovery codes
+		if err := q.DeleteRecoveryCodes(ctx, userArg.Username); err != nil {
+			return err
+		}
+		// Delete personal access tokens
+		if err := q.DeletePersonalAccessTokens(ctx, userArg.Username); err != nil {
+			return err
+		}
+		// Delete OAuth authorization codes, and the OAuth clients of the user along with what was issued to them
+		if err := q.DeleteUserOAuthAuthorizationCodes(ctx, userArg.Username); err != nil {
+			return err
+		}
+		clients, err := q.GetOAuthClients(ctx, userArg.Username)
+		if err != nil {
+			return err
+		}
+		for c := range clients {
+			if err := purgeOAuthClient(ctx, q, clients[c].ID); err != nil {
 				return err
 			}
-			// Delete categories
-			for c := range cgIds {
-				if err := q.DeleteCategories(ctx, cgIds[c]); err != nil {
-					return err
-				}
-			}
-			// Delete the accounts
-			if err := q.DeleteAccounts(ctx, budgetIds[i]); err != nil {
+		}
+
+		for i := range budgetIds {
+			if err := purgeBudget(ctx, q, budgetIds[i]); err != nil {
 				return err
 			}
 		}
@@ -107,3 +125,87 @@ func (s *SQLStore) DeleteUserTx(ctx context.Context, userArg UserParams, budgetI
 	})
 	return txError
 }
+
+// Database transaction for resetting a password. Uses up the reset and any other pending one, sets the new password,
+// blocks all sessions of the user and revokes their personal access tokens.
+func (s *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) error {
+
+	txErr := s.execTransaction(ctx, func(q *Queries) error {
+		// fails if a concurrent request used the reset first
+		n, err := q.UsePasswordReset(ctx, arg.ResetID)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrPasswordResetUsed
+		}
+		if err := q.UsePasswordResets(ctx, arg.Username); err != nil {
+			return err
+		}
+		_, err = q.UpdateUser(ctx, UpdateUserParams{
+			Username:           arg.Username,
+			Password:           pgtype.Text{String: arg.Password, Valid: true},
+			LastPasswordChange: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if err := q.BlockUserSessions(ctx, arg.Username); err != nil {
+			return err
+		}
+		return q.DeletePersonalAccessTokens(ctx, arg.Username)
+	})
+
+	return txErr
+}
+
+// Database transaction for cutting off the access of a user: blocks all their sessions and revokes their personal
+// access tokens.
+func (s *SQLStore) BlockUserAccessTx(ctx context.Context, username string) error {
+
+	txErr := s.execTransaction(ctx, func(q *Queries) error {
+		if err := q.BlockUserSessions(ctx, username); err != nil {
+			return err
+		}
+		return q.DeletePersonalAccessTokens(ctx, username)
+	})
+
+	return txErr
+}
+
+// Database transaction for enabling two-factor authentication, which replaces the recovery codes of the user.
+func (s *SQLStore) EnableTOTPTx(ctx context.Context, username string, recoveryCodeHashes []string) error {
+
+	txErr := s.execTransaction(ctx, func(q *Queries) error {
+		if err := q.EnableUserTOTP(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteRecoveryCodes(ctx, username); err != nil {
+			return err
+		}
+		for i := range recoveryCodeHashes {
+			if err := q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				Username: username,
+				CodeHash: recoveryCodeHashes[i],
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return txErr
+}
+
+// Database transaction for disabling two-factor authentication
+func (s *SQLStore) DisableTOTPTx(ctx context.Context, username string) error {
+
+	txErr := s.execTransaction(ctx, func(q *Queries) error {
+		if err := q.DisableUserTOTP(ctx, username); err != nil {
+			return err
+		}
+		return q.DeleteRecoveryCodes(ctx, username)
+	})
+
+	return txErr
+}
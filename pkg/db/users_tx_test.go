@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockUserAccessTx(t *testing.T) {
+
+	store := newTestStore(t)
+	ctx := context.Background()
+	user, _ := createTestBudget(t, store)
+
+	_, err := store.CreatePersonalAccessToken(ctx, CreatePersonalAccessTokenParams{
+		Username:  user.Username,
+		Name:      "CI",
+		TokenHash: util.RandomString(32, ""),
+		Scopes:    []string{"read"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.BlockUserAccessTx(ctx, user.Username))
+
+	tokens, err := store.GetPersonalAccessTokens(ctx, user.Username)
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+}
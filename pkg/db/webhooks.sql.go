@@ -0,0 +1,387 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: webhooks.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (
+    budget_id,
+    url,
+    secret,
+    events
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, budget_id, url, secret, events, active, created_at
+`
+
+type CreateWebhookParams struct {
+	BudgetID uuid.UUID `json:"budget_id"`
+	Url      string    `json:"url"`
+	Secret   string    `json:"secret"`
+	Events   []string  `json:"events"`
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, createWebhook,
+		arg.BudgetID,
+		arg.Url,
+		arg.Secret,
+		arg.Events,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+    webhook_id,
+    event,
+    payload,
+    redelivery
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, webhook_id, event, payload, redelivery, attempts, status_code, error, delivered_at, created_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	WebhookID  uuid.UUID `json:"webhook_id"`
+	Event      string    `json:"event"`
+	Payload    []byte    `json:"payload"`
+	Redelivery bool      `json:"redelivery"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, createWebhookDelivery,
+		arg.WebhookID,
+		arg.Event,
+		arg.Payload,
+		arg.Redelivery,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.Event,
+		&i.Payload,
+		&i.Redelivery,
+		&i.Attempts,
+		&i.StatusCode,
+		&i.Error,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteBudgetWebhookDeliveries = `-- name: DeleteBudgetWebhookDeliveries :exec
+DELETE FROM webhook_deliveries
+WHERE webhook_id IN (SELECT id FROM webhooks WHERE budget_id = $1)
+`
+
+func (q *Queries) DeleteBudgetWebhookDeliveries(ctx context.Context, budgetID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteBudgetWebhookDeliveries, budgetID)
+	return err
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :exec
+DELETE FROM webhooks WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteWebhook, id)
+	return err
+}
+
+const deleteWebhookDeliveries = `-- name: DeleteWebhookDeliveries :exec
+DELETE FROM webhook_deliveries WHERE webhook_id = $1
+`
+
+func (q *Queries) DeleteWebhookDeliveries(ctx context.Context, webhookID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteWebhookDeliveries, webhookID)
+	return err
+}
+
+const deleteWebhooks = `-- name: DeleteWebhooks :exec
+DELETE FROM webhooks WHERE budget_id = $1
+`
+
+func (q *Queries) DeleteWebhooks(ctx context.Context, budgetID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteWebhooks, budgetID)
+	return err
+}
+
+const getSubscribedWebhooks = `-- name: GetSubscribedWebhooks :many
+SELECT id, budget_id, url, secret, events, active, created_at FROM webhooks
+WHERE budget_id = $1 AND active = true AND $2::varchar = ANY(events)
+`
+
+type GetSubscribedWebhooksParams struct {
+	BudgetID uuid.UUID `json:"budget_id"`
+	Event    string    `json:"event"`
+}
+
+func (q *Queries) GetSubscribedWebhooks(ctx context.Context, arg GetSubscribedWebhooksParams) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, getSubscribedWebhooks, arg.BudgetID, arg.Event)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Webhook{}
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhook = `-- name: GetWebhook :one
+SELECT id, budget_id, url, secret, events, active, created_at FROM webhooks WHERE budget_id = $1 AND id = $2
+`
+
+type GetWebhookParams struct {
+	BudgetID uuid.UUID `json:"budget_id"`
+	ID       uuid.UUID `json:"id"`
+}
+
+func (q *Queries) GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, getWebhook, arg.BudgetID, arg.ID)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWebhookById = `-- name: GetWebhookById :one
+SELECT id, budget_id, url, secret, events, active, created_at FROM webhooks WHERE id = $1
+`
+
+func (q *Queries) GetWebhookById(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	row := q.db.QueryRow(ctx, getWebhookById, id)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWebhookDeliveries = `-- name: GetWebhookDeliveries :many
+SELECT id, webhook_id, event, payload, redelivery, attempts, status_code, error, delivered_at, created_at FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type GetWebhookDeliveriesParams struct {
+	WebhookID uuid.UUID `json:"webhook_id"`
+	Limit     int32     `json:"limit"`
+}
+
+func (q *Queries) GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, getWebhookDeliveries, arg.WebhookID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.Event,
+			&i.Payload,
+			&i.Redelivery,
+			&i.Attempts,
+			&i.StatusCode,
+			&i.Error,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT id, webhook_id, event, payload, redelivery, attempts, status_code, error, delivered_at, created_at FROM webhook_deliveries WHERE id = $1
+`
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, id uuid.UUID) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, getWebhookDelivery, id)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.Event,
+		&i.Payload,
+		&i.Redelivery,
+		&i.Attempts,
+		&i.StatusCode,
+		&i.Error,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWebhooks = `-- name: GetWebhooks :many
+SELECT id, budget_id, url, secret, events, active, created_at FROM webhooks WHERE budget_id = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetWebhooks(ctx context.Context, budgetID uuid.UUID) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, getWebhooks, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Webhook{}
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateWebhook = `-- name: UpdateWebhook :one
+UPDATE webhooks
+SET
+    url = COALESCE($3, url),
+    events = COALESCE($4, events),
+    active = COALESCE($5, active)
+WHERE id = $1 AND budget_id = $2
+RETURNING id, budget_id, url, secret, events, active, created_at
+`
+
+type UpdateWebhookParams struct {
+	ID       uuid.UUID   `json:"id"`
+	BudgetID uuid.UUID   `json:"budget_id"`
+	Url      pgtype.Text `json:"url"`
+	Events   []string    `json:"events"`
+	Active   pgtype.Bool `json:"active"`
+}
+
+func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, updateWebhook,
+		arg.ID,
+		arg.BudgetID,
+		arg.Url,
+		arg.Events,
+		arg.Active,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateWebhookDeliveryResult = `-- name: UpdateWebhookDeliveryResult :one
+UPDATE webhook_deliveries
+SET
+    attempts = attempts + 1,
+    status_code = $2,
+    error = $3,
+    delivered_at = $4
+WHERE id = $1
+RETURNING id, webhook_id, event, payload, redelivery, attempts, status_code, error, delivered_at, created_at
+`
+
+type UpdateWebhookDeliveryResultParams struct {
+	ID          uuid.UUID          `json:"id"`
+	StatusCode  pgtype.Int4        `json:"status_code"`
+	Error       pgtype.Text        `json:"error"`
+	DeliveredAt pgtype.Timestamptz `json:"delivered_at"`
+}
+
+func (q *Queries) UpdateWebhookDeliveryResult(ctx context.Context, arg UpdateWebhookDeliveryResultParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, updateWebhookDeliveryResult,
+		arg.ID,
+		arg.StatusCode,
+		arg.Error,
+		arg.DeliveredAt,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.Event,
+		&i.Payload,
+		&i.Redelivery,
+		&i.Attempts,
+		&i.StatusCode,
+		&i.Error,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
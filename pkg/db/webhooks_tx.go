@@ -0,0 +1,20 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Database transaction for deleting a webhook along with its deliveries.
+func (s *SQLStore) DeleteWebhookTx(ctx context.Context, webhookId uuid.UUID) error {
+
+	txErr := s.execTransaction(ctx, func(q *Queries) error {
+		if err := q.DeleteWebhookDeliveries(ctx, webhookId); err != nil {
+			return err
+		}
+		return q.DeleteWebhook(ctx, webhookId)
+	})
+
+	return txErr
+}
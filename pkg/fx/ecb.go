@@ -0,0 +1,81 @@
+package fx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Currency of the reference rates of the European Central Bank
+const ECBBaseCurrency = "EUR"
+
+// Parses a file of reference rates of the European Central Bank, such as eurofxref-daily.xml or eurofxref-hist.xml,
+// which are the worth of one euro in other currencies. The rates are in Cube elements, of days in a Cube element:
+// the file is read as a stream, which stops once it has more than maxRates rates. The errors are meant for the user.
+func ParseECB(r io.Reader, maxRates int) ([]Rate, error) {
+
+	decoder := xml.NewDecoder(r)
+	var rates []Rate
+	var date time.Time
+	// depth of the Cube elements: 1 holds the days, 2 is a day and 3 a rate
+	depth := 0
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("invalid XML")
+		}
+		switch el := tok.(type) {
+		case xml.StartElement:
+			if el.Name.Local != "Cube" {
+				continue
+			}
+			depth++
+			switch depth {
+			case 2:
+				day := attr(el, "time")
+				if date, err = time.Parse(time.DateOnly, day); err != nil {
+					return nil, fmt.Errorf("invalid date %q", day)
+				}
+			case 3:
+				day := date.Format(time.DateOnly)
+				currency := attr(el, "currency")
+				if len(currency) != 3 || currency == ECBBaseCurrency {
+					return nil, fmt.Errorf("%s: invalid currency %q", day, currency)
+				}
+				rate, err := strconv.ParseFloat(attr(el, "rate"), 64)
+				if err != nil || rate <= 0 {
+					return nil, fmt.Errorf("%s: invalid rate %q for %s", day, attr(el, "rate"), currency)
+				}
+				if len(rates) == maxRates {
+					return nil, fmt.Errorf("the file has more than %d rates", maxRates)
+				}
+				rates = append(rates, Rate{Date: date, From: ECBBaseCurrency, To: currency, Rate: rate})
+			}
+		case xml.EndElement:
+			if el.Name.Local == "Cube" {
+				depth--
+			}
+		}
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("the file has no rates")
+	}
+
+	return rates, nil
+}
+
+// Returns the value of an attribute of an element, empty if it has none
+func attr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+// Package fx converts amounts between currencies with exchange rates on dates. Amounts are in milliunits of their
+// currency.
+package fx
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/guerzon/gobudget-api/pkg/money"
+)
+
+// One unit of the From currency is worth Rate units of the To currency on a date
+type Rate struct {
+	Date time.Time
+	From string
+	To   string
+	Rate float64
+}
+
+// Returned when there is no rate to convert between two currencies on a date
+type MissingRateError struct {
+	From string
+	To   string
+	Date time.Time
+}
+
+func (e *MissingRateError) Error() string {
+	return fmt.Sprintf("no exchange rate from %s to %s on or before %s", e.From, e.To, e.Date.Format(time.DateOnly))
+}
+
+type pair struct {
+	from string
+	to   string
+}
+
+// Exchange rates on dates. A conversion on a date uses the last rate on or before it.
+type Table struct {
+	// the rates of each pair of currencies, by date
+	rates      map[pair][]Rate
+	currencies []string
+}
+
+func NewTable(rates []Rate) *Table {
+
+	t := &Table{rates: make(map[pair][]Rate)}
+	for _, r := range rates {
+		p := pair{from: r.From, to: r.To}
+		t.rates[p] = append(t.rates[p], r)
+		for _, c := range []string{r.From, r.To} {
+			if !slices.Contains(t.currencies, c) {
+				t.currencies = append(t.currencies, c)
+			}
+		}
+	}
+	for p := range t.rates {
+		slices.SortStableFunc(t.rates[p], func(a, b Rate) int {
+			return a.Date.Compare(b.Date)
+		})
+	}
+	slices.Sort(t.currencies)
+
+	return t
+}
+
+// Returns the last rate of a pair of currencies on or before a date
+func (t *Table) last(p pair, date time.Time) (float64, bool) {
+	rates := t.rates[p]
+	i, _ := slices.BinarySearchFunc(rates, date, func(r Rate, date time.Time) int {
+		if r.Date.After(date) {
+			return 1
+		}
+		return -1
+	})
+	if i == 0 {
+		return 0, false
+	}
+	return rates[i-1].Rate, true
+}
+
+// Returns the rate from a currency to another, or the inverse of the rate the other way
+func (t *Table) direct(from, to string, date time.Time) (float64, bool) {
+	if r, ok := t.last(pair{from: from, to: to}, date); ok {
+		return r, true
+	}
+	if r, ok := t.last(pair{from: to, to: from}, date); ok {
+		return 1 / r, true
+	}
+	return 0, false
+}
+
+// Returns the rate from a currency to another on a date. Without a rate between the two, it crosses the rates of both
+// with a third currency, such as the euro of the rates of the ECB.
+func (t *Table) Rate(from, to string, date time.Time) (float64, error) {
+
+	if from == to {
+		return 1, nil
+	}
+	if r, ok := t.direct(from, to, date); ok {
+		return r, nil
+	}
+	for _, c := range t.currencies {
+		if c == from || c == to {
+			continue
+		}
+		r1, ok := t.direct(from, c, date)
+		if !ok {
+			continue
+		}
+		if r2, ok := t.direct(c, to, date); ok {
+			return r1 * r2, nil
+		}
+	}
+
+	return 0, &MissingRateError{From: from, To: to, Date: date}
+}
+
+// Converts an amount from a currency into another at the rate of a date, rounded to the smallest amount of the other
+// currency.
+func (t *Table) Convert(amount int64, from, to string, date time.Time) (int64, error) {
+
+	if from == to {
+		return amount, nil
+	}
+	rate, err := t.Rate(from, to, date)
+	if err != nil {
+		return 0, err
+	}
+	converted, err := money.FromFloat(float64(amount) * rate)
+	if err != nil {
+		return 0, err
+	}
+
+	return money.Round(converted, to)
+}
@@ -0,0 +1,116 @@
+package fx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestConvert(t *testing.T) {
+
+	table := NewTable([]Rate{
+		{Date: day(2), From: "EUR", To: "USD", Rate: 1.10},
+		{Date: day(1), From: "EUR", To: "USD", Rate: 1.08},
+		{Date: day(1), From: "EUR", To: "JPY", Rate: 168.5},
+		{Date: day(1), From: "EUR", To: "KWD", Rate: 0.333},
+	})
+
+	testCases := []struct {
+		name     string
+		amount   int64
+		from     string
+		to       string
+		date     time.Time
+		expected int64
+	}{
+		{name: "same currency", amount: 12345, from: "EUR", to: "EUR", date: day(1), expected: 12345},
+		{name: "direct", amount: 10000, from: "EUR", to: "USD", date: day(1), expected: 10800},
+		{name: "last rate before the date", amount: 10000, from: "EUR", to: "USD", date: day(20), expected: 11000},
+		{name: "inverse", amount: 11000, from: "USD", to: "EUR", date: day(2), expected: 10000},
+		{name: "to a currency without decimals", amount: 10000, from: "EUR", to: "JPY", date: day(1), expected: 1685000},
+		{name: "rounded to the currency", amount: 12345, from: "EUR", to: "JPY", date: day(1), expected: 2080000},
+		{name: "to a currency with three decimals", amount: 10000, from: "EUR", to: "KWD", date: day(1), expected: 3330},
+		{name: "crossed through the euro", amount: 10800, from: "USD", to: "JPY", date: day(1), expected: 1685000},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := table.Convert(tc.amount, tc.from, tc.to, tc.date)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+
+	t.Run("before the first rate", func(t *testing.T) {
+		_, err := table.Convert(10000, "EUR", "USD", day(1).AddDate(0, 0, -1))
+		var missing *MissingRateError
+		require.True(t, errors.As(err, &missing))
+		require.Equal(t, "no exchange rate from EUR to USD on or before 2024-04-30", err.Error())
+	})
+
+	t.Run("unknown currency", func(t *testing.T) {
+		_, err := table.Convert(10000, "EUR", "GBP", day(1))
+		require.Error(t, err)
+	})
+}
+
+func TestParseECB(t *testing.T) {
+
+	t.Run("OK", func(t *testing.T) {
+		file := `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time='2024-05-31'>
+			<Cube currency='USD' rate='1.0812'/>
+			<Cube currency='JPY' rate='170.08'/>
+		</Cube>
+		<Cube time='2024-05-30'>
+			<Cube currency='USD' rate='1.0826'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+		rates, err := ParseECB(strings.NewReader(file), 10)
+		require.NoError(t, err)
+		require.Equal(t, []Rate{
+			{Date: day(31), From: "EUR", To: "USD", Rate: 1.0812},
+			{Date: day(31), From: "EUR", To: "JPY", Rate: 170.08},
+			{Date: day(30), From: "EUR", To: "USD", Rate: 1.0826},
+		}, rates)
+	})
+
+	t.Run("TooManyRates", func(t *testing.T) {
+		// the reading stops at the rate over the limit, before the rest of the file
+		file := "<Envelope><Cube><Cube time='2024-05-31'><Cube currency='USD' rate='1.0812'/><Cube currency='JPY' rate='170.08'/><Cube"
+		_, err := ParseECB(strings.NewReader(file), 1)
+		require.EqualError(t, err, "the file has more than 1 rates")
+	})
+
+	testCases := []struct {
+		name string
+		file string
+	}{
+		{name: "not XML", file: "date,rate\n2024-05-31,1.08"},
+		{name: "no rates", file: "<Envelope><Cube></Cube></Envelope>"},
+		{name: "invalid date", file: "<Envelope><Cube><Cube time='31.05.2024'><Cube currency='USD' rate='1.08'/></Cube></Cube></Envelope>"},
+		{name: "invalid rate", file: "<Envelope><Cube><Cube time='2024-05-31'><Cube currency='USD' rate='-1'/></Cube></Cube></Envelope>"},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseECB(strings.NewReader(tc.file), 10)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Failed logins are free up to this number, after which each attempt has to wait exponentially longer.
+const (
+	freeLoginAttempts = 3
+	baseLoginDelay    = time.Second
+	maxLoginDelay     = time.Minute
+)
+
+// Defaults of LoginPolicy
+const (
+	DefaultLockoutThreshold = 10
+	DefaultLockoutDuration  = 15 * time.Minute
+)
+
+// LoginLimiter slows down password guessing by tracking the failed logins of each username and client IP.
+// Usernames are tracked whether or not they exist, so that the responses do not reveal it.
+type LoginLimiter interface {
+	// Returns how long the client has to wait before trying to log in again, zero if it can try now.
+	Check(ctx context.Context, username string, ip string) (time.Duration, error)
+	// Records a failed login. Returns true if it locked the username out.
+	RecordFailure(ctx context.Context, username string, ip string) (bool, error)
+	// Clears the failed logins and the lockout of a username, after a successful login or by an admin.
+	Reset(ctx context.Context, username string) error
+}
+
+// When usernames are locked out. Failed logins are forgotten after the lockout duration without failures.
+type LoginPolicy struct {
+	// Number of failed logins after which the username is locked out
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+}
+
+func (p LoginPolicy) withDefaults() LoginPolicy {
+	if p.LockoutThreshold <= 0 {
+		p.LockoutThreshold = DefaultLockoutThreshold
+	}
+	if p.LockoutDuration <= 0 {
+		p.LockoutDuration = DefaultLockoutDuration
+	}
+	return p
+}
+
+// Delay after the last of a number of failed logins.
+func loginDelay(failures int64) time.Duration {
+
+	if failures <= freeLoginAttempts {
+		return 0
+	}
+	delay := baseLoginDelay
+	for i := int64(freeLoginAttempts + 1); i < failures; i++ {
+		delay *= 2
+		if delay >= maxLoginDelay {
+			return maxLoginDelay
+		}
+	}
+	return delay
+}
+
+// Remaining wait after failed logins, the last one at time last.
+func remainingLoginDelay(failures int64, last time.Time, now time.Time) time.Duration {
+	return max(0, loginDelay(failures)-now.Sub(last))
+}
+
+// RedisLoginLimiter shares the failed logins between all instances of the API. Each username and IP has a counter
+// whose expiration is reset on every failure, so the time of the last failure is derived from the remaining TTL.
+type RedisLoginLimiter struct {
+	client *redis.Client
+	policy LoginPolicy
+}
+
+func NewRedisLoginLimiter(client *redis.Client, policy LoginPolicy) LoginLimiter {
+	return &RedisLoginLimiter{
+		client: client,
+		policy: policy.withDefaults(),
+	}
+}
+
+func loginUserKey(username string) string { return "login:failures:user:" + username }
+func loginIPKey(ip string) string         { return "login:failures:ip:" + ip }
+func loginLockKey(username string) string { return "login:lockout:" + username }
+
+func (l *RedisLoginLimiter) Check(ctx context.Context, username string, ip string) (time.Duration, error) {
+
+	pipe := l.client.Pipeline()
+	lock := pipe.PTTL(ctx, loginLockKey(username))
+	userFailures := pipe.Get(ctx, loginUserKey(username))
+	userTTL := pipe.PTTL(ctx, loginUserKey(username))
+	ipFailures := pipe.Get(ctx, loginIPKey(ip))
+	ipTTL := pipe.PTTL(ctx, loginIPKey(ip))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+
+	if ttl := lock.Val(); ttl > 0 {
+		return ttl, nil
+	}
+
+	now := time.Now()
+	wait := time.Duration(0)
+	for _, c := range []struct {
+		failures *redis.StringCmd
+		ttl      *redis.DurationCmd
+	}{{userFailures, userTTL}, {ipFailures, ipTTL}} {
+		failures, err := c.failures.Int64()
+		if err != nil || c.ttl.Val() <= 0 {
+			continue
+		}
+		last := now.Add(c.ttl.Val() - l.policy.LockoutDuration)
+		wait = max(wait, remainingLoginDelay(failures, last, now))
+	}
+
+	return wait, nil
+}
+
+func (l *RedisLoginLimiter) RecordFailure(ctx context.Context, username string, ip string) (bool, error) {
+
+	pipe := l.client.TxPipeline()
+	userFailures := pipe.Incr(ctx, loginUserKey(username))
+	pipe.PExpire(ctx, loginUserKey(username), l.policy.LockoutDuration)
+	pipe.Incr(ctx, loginIPKey(ip))
+	pipe.PExpire(ctx, loginIPKey(ip), l.policy.LockoutDuration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+
+	if userFailures.Val() < int64(l.policy.LockoutThreshold) {
+		return false, nil
+	}
+
+	// only the request which sets the lock reports it
+	return l.client.SetNX(ctx, loginLockKey(username), 1, l.policy.LockoutDuration).Result()
+}
+
+func (l *RedisLoginLimiter) Reset(ctx context.Context, username string) error {
+	return l.client.Del(ctx, loginUserKey(username), loginLockKey(username)).Err()
+}
+
+// MemoryLoginLimiter keeps the failed logins in memory, for tests and single instances without Redis.
+type MemoryLoginLimiter struct {
+	mu       sync.Mutex
+	policy   LoginPolicy
+	failures map[string]loginFailures
+	lockouts map[string]time.Time
+	now      func() time.Time
+}
+
+type loginFailures struct {
+	count int64
+	last  time.Time
+}
+
+func NewMemoryLoginLimiter(policy LoginPolicy) LoginLimiter {
+	return &MemoryLoginLimiter{
+		policy:   policy.withDefaults(),
+		failures: make(map[string]loginFailures),
+		lockouts: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+func (l *MemoryLoginLimiter) Check(ctx context.Context, username string, ip string) (time.Duration, error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if until, exists := l.lockouts[username]; exists && now.Before(until) {
+		return until.Sub(now), nil
+	}
+
+	wait := time.Duration(0)
+	for _, key := range []string{loginUserKey(username), loginIPKey(ip)} {
+		if f, exists := l.failures[key]; exists && now.Sub(f.last) < l.policy.LockoutDuration {
+			wait = max(wait, remainingLoginDelay(f.count, f.last, now))
+		}
+	}
+
+	return wait, nil
+}
+
+func (l *MemoryLoginLimiter) RecordFailure(ctx context.Context, username string, ip string) (bool, error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var userFailures int64
+	for _, key := range []string{loginUserKey(username), loginIPKey(ip)} {
+		f := l.failures[key]
+		if now.Sub(f.last) >= l.policy.LockoutDuration {
+			f.count = 0
+		}
+		f.count++
+		f.last = now
+		l.failures[key] = f
+		if key == loginUserKey(username) {
+			userFailures = f.count
+		}
+	}
+
+	if userFailures < int64(l.policy.LockoutThreshold) {
+		return false, nil
+	}
+	if until, exists := l.lockouts[username]; exists && now.Before(until) {
+		return false, nil
+	}
+	l.lockouts[username] = now.Add(l.policy.LockoutDuration)
+
+	return true, nil
+}
+
+func (l *MemoryLoginLimiter) Reset(ctx context.Context, username string) error {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, loginUserKey(username))
+	delete(l.lockouts, username)
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginDelay(t *testing.T) {
+
+	require.Zero(t, loginDelay(0))
+	require.Zero(t, loginDelay(freeLoginAttempts))
+	require.Equal(t, baseLoginDelay, loginDelay(freeLoginAttempts+1))
+	require.Equal(t, 2*baseLoginDelay, loginDelay(freeLoginAttempts+2))
+	require.Equal(t, 4*baseLoginDelay, loginDelay(freeLoginAttempts+3))
+	require.Equal(t, maxLoginDelay, loginDelay(freeLoginAttempts+100))
+}
+
+func newTestLoginLimiter(policy LoginPolicy) (*MemoryLoginLimiter, *time.Time) {
+
+	now := time.Now()
+	l := NewMemoryLoginLimiter(policy).(*MemoryLoginLimiter)
+	l.now = func() time.Time { return now }
+
+	return l, &now
+}
+
+func TestMemoryLoginLimiterDelays(t *testing.T) {
+
+	ctx := context.Background()
+	l, now := newTestLoginLimiter(LoginPolicy{LockoutThreshold: 100, LockoutDuration: time.Hour})
+
+	for i := 0; i < freeLoginAttempts; i++ {
+		wait, err := l.Check(ctx, "alice", "10.0.0.1")
+		require.NoError(t, err)
+		require.Zero(t, wait)
+		locked, err := l.RecordFailure(ctx, "alice", "10.0.0.1")
+		require.NoError(t, err)
+		require.False(t, locked)
+	}
+
+	// the next failure has to wait
+	_, err := l.RecordFailure(ctx, "alice", "10.0.0.1")
+	require.NoError(t, err)
+	wait, err := l.Check(ctx, "alice", "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, baseLoginDelay, wait)
+
+	// the IP is delayed for other usernames too
+	wait, err = l.Check(ctx, "bob", "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, baseLoginDelay, wait)
+
+	// and the username from other IPs
+	wait, err = l.Check(ctx, "alice", "10.0.0.2")
+	require.NoError(t, err)
+	require.Equal(t, baseLoginDelay, wait)
+
+	*now = now.Add(baseLoginDelay)
+	wait, err = l.Check(ctx, "alice", "10.0.0.1")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	// failures are forgotten after the lockout duration
+	_, err = l.RecordFailure(ctx, "alice", "10.0.0.1")
+	require.NoError(t, err)
+	*now = now.Add(time.Hour)
+	_, err = l.RecordFailure(ctx, "alice", "10.0.0.1")
+	require.NoError(t, err)
+	wait, err = l.Check(ctx, "alice", "10.0.0.1")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+}
+
+func TestMemoryLoginLimiterLockout(t *testing.T) {
+
+	ctx := context.Background()
+	l, now := newTestLoginLimiter(LoginPolicy{LockoutThreshold: 2, LockoutDuration: time.Hour})
+
+	locked, err := l.RecordFailure(ctx, "alice", "10.0.0.1")
+	require.NoError(t, err)
+	require.False(t, locked)
+	locked, err = l.RecordFailure(ctx, "alice", "10.0.0.2")
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	// only reported once
+	locked, err = l.RecordFailure(ctx, "alice", "10.0.0.3")
+	require.NoError(t, err)
+	require.False(t, locked)
+
+	// from any IP
+	wait, err := l.Check(ctx, "alice", "10.0.0.4")
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, wait)
+
+	// until it expires
+	*now = now.Add(time.Hour)
+	wait, err = l.Check(ctx, "alice", "10.0.0.4")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+}
+
+func TestMemoryLoginLimiterReset(t *testing.T) {
+
+	ctx := context.Background()
+	l, _ := newTestLoginLimiter(LoginPolicy{LockoutThreshold: 1})
+
+	locked, err := l.RecordFailure(ctx, "alice", "10.0.0.1")
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	require.NoError(t, l.Reset(ctx, "alice"))
+	wait, err := l.Check(ctx, "alice", "10.0.0.2")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+}
@@ -0,0 +1,164 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// A limit of requests per time window
+type Policy struct {
+	Limit  int
+	Window time.Duration
+}
+
+// Parses a policy in the format limit/window, e.g. 100/1m.
+func ParsePolicy(s string) (Policy, error) {
+
+	limit, window, found := strings.Cut(s, "/")
+	if !found {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: must be in the format limit/window, e.g. 100/1m", s)
+	}
+	n, err := strconv.Atoi(limit)
+	if err != nil || n <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: the limit must be a positive number", s)
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d < time.Second {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: the window must be a duration of at least 1s", s)
+	}
+
+	return Policy{Limit: n, Window: d}, nil
+}
+
+// The outcome of a request against a policy
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Time until the current window ends
+	Reset time.Duration
+}
+
+// RateLimiter counts the requests of each key with a sliding window: the count of the previous window is weighted
+// by how much of it still overlaps the sliding window, which smooths out bursts at window boundaries.
+type RateLimiter interface {
+	// Counts a request of key, and reports whether the policy allows it.
+	Allow(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// Computes the result of a request, given the counts of the previous and current windows including the request.
+func slidingWindow(policy Policy, previous int64, current int64, elapsed time.Duration) Result {
+
+	weight := 1 - float64(elapsed)/float64(policy.Window)
+	count := int64(float64(previous)*weight) + current
+
+	return Result{
+		Allowed:   count <= int64(policy.Limit),
+		Limit:     policy.Limit,
+		Remaining: int(max(0, int64(policy.Limit)-count)),
+		Reset:     policy.Window - elapsed,
+	}
+}
+
+// Index of the window of time t, and the time elapsed since the start of the window.
+func window(policy Policy, t time.Time) (int64, time.Duration) {
+	n := t.UnixNano()
+	return n / int64(policy.Window), time.Duration(n % int64(policy.Window))
+}
+
+// RedisRateLimiter shares the counts between all instances of the API.
+type RedisRateLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisRateLimiter(client *redis.Client) RateLimiter {
+	return &RedisRateLimiter{
+		client: client,
+	}
+}
+
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string, policy Policy) (Result, error) {
+
+	w, elapsed := window(policy, time.Now())
+	currentKey := fmt.Sprintf("ratelimit:%s:%d", key, w)
+	previousKey := fmt.Sprintf("ratelimit:%s:%d", key, w-1)
+
+	pipe := l.client.TxPipeline()
+	current := pipe.Incr(ctx, currentKey)
+	// the count is needed until the end of the next window
+	pipe.PExpire(ctx, currentKey, 2*policy.Window)
+	previous := pipe.Get(ctx, previousKey)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return Result{}, err
+	}
+	prev, _ := previous.Int64()
+
+	return slidingWindow(policy, prev, current.Val(), elapsed), nil
+}
+
+// MemoryRateLimiter keeps the counts in memory, for tests and single instances without Redis.
+type MemoryRateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*windowCounts
+	calls   int
+	now     func() time.Time
+}
+
+type windowCounts struct {
+	window   time.Duration
+	index    int64
+	previous int64
+	current  int64
+}
+
+// Stale counts are removed every this many requests
+const memorySweepInterval = 1000
+
+func NewMemoryRateLimiter() RateLimiter {
+	return &MemoryRateLimiter{
+		windows: make(map[string]*windowCounts),
+		now:     time.Now,
+	}
+}
+
+func (l *MemoryRateLimiter) Allow(ctx context.Context, key string, policy Policy) (Result, error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, elapsed := window(policy, l.now())
+
+	c, exists := l.windows[key]
+	if !exists {
+		c = &windowCounts{window: policy.Window, index: w}
+		l.windows[key] = c
+	}
+	switch {
+	case c.index == w-1:
+		c.previous, c.current = c.current, 0
+	case c.index < w-1:
+		c.previous, c.current = 0, 0
+	}
+	c.index = w
+	c.current++
+
+	// counts are stale once the window after theirs is over
+	l.calls++
+	if l.calls%memorySweepInterval == 0 {
+		now := l.now().UnixNano()
+		for k, v := range l.windows {
+			if now >= (v.index+2)*int64(v.window) {
+				delete(l.windows, k)
+			}
+		}
+	}
+
+	return slidingWindow(policy, c.previous, c.current, elapsed), nil
+}
@@ -0,0 +1,95 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+
+	testCases := []struct {
+		name     string
+		setting  string
+		expected Policy
+		valid    bool
+	}{
+		{name: "per minute", setting: "100/1m", expected: Policy{Limit: 100, Window: time.Minute}, valid: true},
+		{name: "per hour", setting: "5000/1h", expected: Policy{Limit: 5000, Window: time.Hour}, valid: true},
+		{name: "no window", setting: "100", valid: false},
+		{name: "zero limit", setting: "0/1m", valid: false},
+		{name: "invalid limit", setting: "many/1m", valid: false},
+		{name: "invalid window", setting: "100/minute", valid: false},
+		{name: "window too short", setting: "100/10ms", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := ParsePolicy(tc.setting)
+			if !tc.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, policy)
+		})
+	}
+}
+
+func newTestRateLimiter(start time.Time) (*MemoryRateLimiter, *time.Time) {
+
+	now := start
+	l := NewMemoryRateLimiter().(*MemoryRateLimiter)
+	l.now = func() time.Time { return now }
+
+	return l, &now
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+
+	ctx := context.Background()
+	policy := Policy{Limit: 3, Window: time.Minute}
+	// the start of a window
+	l, now := newTestRateLimiter(time.Now().Truncate(time.Minute))
+
+	for i := 0; i < policy.Limit; i++ {
+		res, err := l.Allow(ctx, "alice", policy)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, policy.Limit, res.Limit)
+		require.Equal(t, policy.Limit-i-1, res.Remaining)
+		require.Equal(t, time.Minute, res.Reset)
+	}
+
+	res, err := l.Allow(ctx, "alice", policy)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Zero(t, res.Remaining)
+
+	// other keys have their own counts
+	res, err = l.Allow(ctx, "bob", policy)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	// at the start of the next window, the previous one still counts fully
+	*now = now.Add(time.Minute)
+	res, err = l.Allow(ctx, "alice", policy)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+
+	// and less as the window slides
+	*now = now.Add(50 * time.Second)
+	res, err = l.Allow(ctx, "alice", policy)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 10*time.Second, res.Reset)
+
+	// counts older than the previous window are forgotten
+	*now = now.Add(2 * time.Minute)
+	res, err = l.Allow(ctx, "alice", policy)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, policy.Limit-1, res.Remaining)
+}
@@ -0,0 +1,137 @@
+// Package loan computes the amortization of fixed-rate loans. Amounts are in milliunits of the currency.
+package loan
+
+import (
+	"math"
+	"time"
+)
+
+// Terms of a fixed-rate loan paid monthly
+type Terms struct {
+	Principal int64
+	// Annual interest rate, in percent
+	AnnualRate float64
+	TermMonths int
+	// Payments are due from the month after the start of the loan
+	StartDate  time.Time
+	PaymentDay int
+	// Paid every month on top of the scheduled payment
+	ExtraPayment int64
+}
+
+// A payment of a loan, split into interest and the principal it pays back
+type Payment struct {
+	// Payment period, the nth payment is due in the nth period
+	Number    int
+	Date      time.Time
+	Amount    int64
+	Principal int64
+	Interest  int64
+	// Principal left once the payment is made
+	Balance int64
+}
+
+// A payment that was made on a loan
+type Paid struct {
+	Date   time.Time
+	Amount int64
+}
+
+func monthlyRate(annualRate float64) float64 {
+	return annualRate / 100 / 12
+}
+
+// Returns the scheduled monthly payment which pays off the principal in the given number of months.
+func MonthlyPayment(principal int64, annualRate float64, months int) int64 {
+	if months <= 0 {
+		return principal
+	}
+	r := monthlyRate(annualRate)
+	if r == 0 {
+		return (principal + int64(months) - 1) / int64(months)
+	}
+	return int64(math.Round(float64(principal) * r / (1 - math.Pow(1+r, -float64(months)))))
+}
+
+// Returns the interest of a month on a balance.
+func Interest(balance int64, annualRate float64) int64 {
+	return int64(math.Round(float64(balance) * monthlyRate(annualRate)))
+}
+
+// Returns the due date of the nth payment of a loan.
+func PaymentDate(t Terms, n int) time.Time {
+	return time.Date(t.StartDate.Year(), t.StartDate.Month()+time.Month(n), t.PaymentDay, 0, 0, 0, 0, time.UTC)
+}
+
+// Returns the payment period of a date: the nth period runs from the day after the due date of the n-1th payment until
+// the due date of the nth payment. Dates before the first due date are in the first period.
+func PaymentPeriod(t Terms, date time.Time) int {
+
+	n := max(1, (date.Year()-t.StartDate.Year())*12+int(date.Month())-int(t.StartDate.Month()))
+	for date.After(PaymentDate(t, n)) {
+		n++
+	}
+	for n > 1 && !date.After(PaymentDate(t, n-1)) {
+		n--
+	}
+
+	return n
+}
+
+// Splits the payments made on a loan, in order, into interest and principal. The interest of a period accrues once, on
+// the balance at its start, however many payments are made in it, and the periods without payments accrue theirs too.
+// Payments pay the interest due before the principal. Returns the split payments and the principal left.
+func Apply(t Terms, paid []Paid) ([]Payment, int64) {
+
+	balance := t.Principal
+	var interestDue int64
+	period := 0
+	payments := make([]Payment, 0, len(paid))
+	for _, p := range paid {
+		for n := PaymentPeriod(t, p.Date); period < n; period++ {
+			interestDue += Interest(balance, t.AnnualRate)
+		}
+		interest := min(interestDue, p.Amount)
+		interestDue -= interest
+		principal := p.Amount - interest
+		balance -= principal
+		payments = append(payments, Payment{
+			Number:    period,
+			Date:      p.Date,
+			Amount:    p.Amount,
+			Principal: principal,
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+
+	return payments, balance
+}
+
+// Returns the amortization schedule of the balance of a loan, from the nth payment until it is paid off. Every payment
+// is the scheduled one plus the extra payment, and the last payment, at the latest at the end of the term, pays off
+// what is left.
+func Schedule(t Terms, balance int64, n int) []Payment {
+
+	scheduled := MonthlyPayment(t.Principal, t.AnnualRate, t.TermMonths) + t.ExtraPayment
+	var payments []Payment
+	for ; balance > 0; n++ {
+		interest := Interest(balance, t.AnnualRate)
+		amount := scheduled
+		if n >= t.TermMonths || amount > balance+interest {
+			amount = balance + interest
+		}
+		principal := amount - interest
+		balance -= principal
+		payments = append(payments, Payment{
+			Number:    n,
+			Date:      PaymentDate(t, n),
+			Amount:    amount,
+			Principal: principal,
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+
+	return payments
+}
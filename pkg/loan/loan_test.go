@@ -0,0 +1,175 @@
+package loan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthlyPayment(t *testing.T) {
+
+	testCases := []struct {
+		name       string
+		principal  int64
+		annualRate float64
+		months     int
+		expected   int64
+	}{
+		{name: "30 year mortgage", principal: 20000000, annualRate: 6, months: 360, expected: 119910},
+		{name: "5 year auto loan", principal: 2500000, annualRate: 4.5, months: 60, expected: 46608},
+		{name: "no interest", principal: 100000, annualRate: 0, months: 12, expected: 8334},
+		{name: "no term", principal: 100000, annualRate: 5, months: 0, expected: 100000},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, MonthlyPayment(tc.principal, tc.annualRate, tc.months))
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+
+	terms := Terms{
+		Principal:  2500000,
+		AnnualRate: 4.5,
+		TermMonths: 60,
+		StartDate:  time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+		PaymentDay: 1,
+	}
+
+	t.Run("pays off at the end of the term", func(t *testing.T) {
+		schedule := Schedule(terms, terms.Principal, 1)
+		require.Len(t, schedule, 60)
+
+		first := schedule[0]
+		require.Equal(t, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), first.Date)
+		require.Equal(t, int64(46608), first.Amount)
+		require.Equal(t, int64(9375), first.Interest)
+		require.Equal(t, first.Amount-first.Interest, first.Principal)
+
+		last := schedule[len(schedule)-1]
+		require.Equal(t, 60, last.Number)
+		require.Equal(t, time.Date(2029, time.January, 1, 0, 0, 0, 0, time.UTC), last.Date)
+		require.Zero(t, last.Balance)
+
+		var principal int64
+		for _, p := range schedule {
+			principal += p.Principal
+		}
+		require.Equal(t, terms.Principal, principal)
+	})
+
+	t.Run("extra payments shorten the loan", func(t *testing.T) {
+		extra := terms
+		extra.ExtraPayment = 20000
+		schedule := Schedule(extra, extra.Principal, 1)
+		require.Less(t, len(schedule), 60)
+		require.Zero(t, schedule[len(schedule)-1].Balance)
+	})
+
+	t.Run("paid off", func(t *testing.T) {
+		require.Empty(t, Schedule(terms, 0, 61))
+	})
+
+	t.Run("past the term", func(t *testing.T) {
+		schedule := Schedule(terms, 100000, 61)
+		require.Len(t, schedule, 1)
+		require.Equal(t, int64(100000)+schedule[0].Interest, schedule[0].Amount)
+		require.Zero(t, schedule[0].Balance)
+	})
+}
+
+func TestApply(t *testing.T) {
+
+	terms := Terms{
+		Principal:  1200000,
+		AnnualRate: 12,
+		TermMonths: 12,
+		StartDate:  time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		PaymentDay: 1,
+	}
+	paid := []Paid{
+		{Date: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), Amount: 106619},
+		{Date: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), Amount: 206619},
+		// does not cover the interest
+		{Date: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), Amount: 5000},
+	}
+
+	payments, balance := Apply(terms, paid)
+	require.Len(t, payments, 3)
+
+	require.Equal(t, int64(12000), payments[0].Interest)
+	require.Equal(t, int64(94619), payments[0].Principal)
+	require.Equal(t, int64(1105381), payments[0].Balance)
+
+	require.Equal(t, int64(11054), payments[1].Interest)
+	require.Equal(t, int64(195565), payments[1].Principal)
+	require.Equal(t, int64(909816), payments[1].Balance)
+
+	require.Equal(t, int64(5000), payments[2].Interest)
+	require.Zero(t, payments[2].Principal)
+	require.Equal(t, int64(909816), balance)
+}
+
+func TestApplyByPeriod(t *testing.T) {
+
+	terms := Terms{
+		Principal:  1200000,
+		AnnualRate: 12,
+		TermMonths: 12,
+		StartDate:  time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		PaymentDay: 1,
+	}
+	paid := []Paid{
+		// two payments in the period due on February 1st
+		{Date: time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), Amount: 50000},
+		{Date: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), Amount: 56619},
+		// nothing paid in the period due on March 1st
+		{Date: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), Amount: 106619},
+	}
+
+	payments, balance := Apply(terms, paid)
+	require.Len(t, payments, 3)
+
+	// the interest of the period is only charged once
+	require.Equal(t, 1, payments[0].Number)
+	require.Equal(t, int64(12000), payments[0].Interest)
+	require.Equal(t, int64(38000), payments[0].Principal)
+	require.Equal(t, 1, payments[1].Number)
+	require.Zero(t, payments[1].Interest)
+	require.Equal(t, int64(56619), payments[1].Principal)
+	require.Equal(t, int64(1105381), payments[1].Balance)
+
+	// the period without payment accrued its interest
+	require.Equal(t, 3, payments[2].Number)
+	require.Equal(t, int64(2*11054), payments[2].Interest)
+	require.Equal(t, int64(84511), payments[2].Principal)
+	require.Equal(t, int64(1020870), balance)
+}
+
+func TestPaymentPeriod(t *testing.T) {
+
+	terms := Terms{
+		StartDate:  time.Date(2024, time.January, 20, 0, 0, 0, 0, time.UTC),
+		PaymentDay: 5,
+	}
+
+	testCases := []struct {
+		date     time.Time
+		expected int
+	}{
+		{date: time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC), expected: 1},
+		{date: time.Date(2024, time.January, 20, 0, 0, 0, 0, time.UTC), expected: 1},
+		{date: time.Date(2024, time.February, 5, 0, 0, 0, 0, time.UTC), expected: 1},
+		{date: time.Date(2024, time.February, 6, 0, 0, 0, 0, time.UTC), expected: 2},
+		{date: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), expected: 2},
+		{date: time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC), expected: 13},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, PaymentPeriod(terms, tc.date), tc.date)
+	}
+}
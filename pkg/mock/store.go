@@ -15,6 +15,7 @@ import (
 
 	uuid "github.com/google/uuid"
 	db "github.com/guerzon/gobudget-api/pkg/db"
+	pgtype "github.com/jackc/pgx/v5/pgtype"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,6 +42,94 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AddUserRole mocks base method.
+func (m *MockStore) AddUserRole(arg0 context.Context, arg1 db.AddUserRoleParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUserRole", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddUserRole indicates an expected call of AddUserRole.
+func (mr *MockStoreMockRecorder) AddUserRole(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserRole", reflect.TypeOf((*MockStore)(nil).AddUserRole), arg0, arg1)
+}
+
+// AttemptTwoFactorChallenge mocks base method.
+func (m *MockStore) AttemptTwoFactorChallenge(arg0 context.Context, arg1 db.AttemptTwoFactorChallengeParams) (db.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttemptTwoFactorChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.TwoFactorChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttemptTwoFactorChallenge indicates an expected call of AttemptTwoFactorChallenge.
+func (mr *MockStoreMockRecorder) AttemptTwoFactorChallenge(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttemptTwoFactorChallenge", reflect.TypeOf((*MockStore)(nil).AttemptTwoFactorChallenge), arg0, arg1)
+}
+
+// BlockSession mocks base method.
+func (m *MockStore) BlockSession(arg0 context.Context, arg1 db.BlockSessionParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSession", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockSession indicates an expected call of BlockSession.
+func (mr *MockStoreMockRecorder) BlockSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSession", reflect.TypeOf((*MockStore)(nil).BlockSession), arg0, arg1)
+}
+
+// BlockUserAccessTx mocks base method.
+func (m *MockStore) BlockUserAccessTx(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserAccessTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockUserAccessTx indicates an expected call of BlockUserAccessTx.
+func (mr *MockStoreMockRecorder) BlockUserAccessTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserAccessTx", reflect.TypeOf((*MockStore)(nil).BlockUserAccessTx), arg0, arg1)
+}
+
+// BlockUserSessions mocks base method.
+func (m *MockStore) BlockUserSessions(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockStoreMockRecorder) BlockUserSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockStore)(nil).BlockUserSessions), arg0, arg1)
+}
+
+// CountUsers mocks base method.
+func (m *MockStore) CountUsers(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsers", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsers indicates an expected call of CountUsers.
+func (mr *MockStoreMockRecorder) CountUsers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockStore)(nil).CountUsers), arg0, arg1)
+}
+
 // CreateAccount mocks base method.
 func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +145,36 @@ func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
 }
 
+// CreateAccountTx mocks base method.
+func (m *MockStore) CreateAccountTx(arg0 context.Context, arg1 db.CreateAccountTxParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountTx indicates an expected call of CreateAccountTx.
+func (mr *MockStoreMockRecorder) CreateAccountTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountTx", reflect.TypeOf((*MockStore)(nil).CreateAccountTx), arg0, arg1)
+}
+
+// CreateAuditLog mocks base method.
+func (m *MockStore) CreateAuditLog(arg0 context.Context, arg1 db.CreateAuditLogParams) (db.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLog", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockStoreMockRecorder) CreateAuditLog(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockStore)(nil).CreateAuditLog), arg0, arg1)
+}
+
 // CreateBudget mocks base method.
 func (m *MockStore) CreateBudget(arg0 context.Context, arg1 db.CreateBudgetParams) (db.Budget, error) {
 	m.ctrl.T.Helper()
@@ -101,6 +220,111 @@ func (mr *MockStoreMockRecorder) CreateCategoryGroup(arg0, arg1 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategoryGroup", reflect.TypeOf((*MockStore)(nil).CreateCategoryGroup), arg0, arg1)
 }
 
+// CreateEmailChange mocks base method.
+func (m *MockStore) CreateEmailChange(arg0 context.Context, arg1 db.CreateEmailChangeParams) (db.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailChange", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailChange indicates an expected call of CreateEmailChange.
+func (mr *MockStoreMockRecorder) CreateEmailChange(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailChange", reflect.TypeOf((*MockStore)(nil).CreateEmailChange), arg0, arg1)
+}
+
+// CreateInvestmentTransaction mocks base method.
+func (m *MockStore) CreateInvestmentTransaction(arg0 context.Context, arg1 db.CreateInvestmentTransactionParams) (db.InvestmentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvestmentTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.InvestmentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvestmentTransaction indicates an expected call of CreateInvestmentTransaction.
+func (mr *MockStoreMockRecorder) CreateInvestmentTransaction(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvestmentTransaction", reflect.TypeOf((*MockStore)(nil).CreateInvestmentTransaction), arg0, arg1)
+}
+
+// CreateLoginEvent mocks base method.
+func (m *MockStore) CreateLoginEvent(arg0 context.Context, arg1 db.CreateLoginEventParams) (db.LoginEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoginEvent indicates an expected call of CreateLoginEvent.
+func (mr *MockStoreMockRecorder) CreateLoginEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginEvent", reflect.TypeOf((*MockStore)(nil).CreateLoginEvent), arg0, arg1)
+}
+
+// CreateMagicLink mocks base method.
+func (m *MockStore) CreateMagicLink(arg0 context.Context, arg1 db.CreateMagicLinkParams) (db.MagicLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMagicLink", arg0, arg1)
+	ret0, _ := ret[0].(db.MagicLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMagicLink indicates an expected call of CreateMagicLink.
+func (mr *MockStoreMockRecorder) CreateMagicLink(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMagicLink", reflect.TypeOf((*MockStore)(nil).CreateMagicLink), arg0, arg1)
+}
+
+// CreateOAuthAuthorizationCode mocks base method.
+func (m *MockStore) CreateOAuthAuthorizationCode(arg0 context.Context, arg1 db.CreateOAuthAuthorizationCodeParams) (db.OauthAuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(db.OauthAuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthAuthorizationCode indicates an expected call of CreateOAuthAuthorizationCode.
+func (mr *MockStoreMockRecorder) CreateOAuthAuthorizationCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthAuthorizationCode", reflect.TypeOf((*MockStore)(nil).CreateOAuthAuthorizationCode), arg0, arg1)
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockStore) CreateOAuthClient(arg0 context.Context, arg1 db.CreateOAuthClientParams) (db.OauthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClient", arg0, arg1)
+	ret0, _ := ret[0].(db.OauthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockStoreMockRecorder) CreateOAuthClient(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockStore)(nil).CreateOAuthClient), arg0, arg1)
+}
+
+// CreatePasswordReset mocks base method.
+func (m *MockStore) CreatePasswordReset(arg0 context.Context, arg1 db.CreatePasswordResetParams) (db.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockStoreMockRecorder) CreatePasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockStore)(nil).CreatePasswordReset), arg0, arg1)
+}
+
 // CreatePayee mocks base method.
 func (m *MockStore) CreatePayee(arg0 context.Context, arg1 db.CreatePayeeParams) (db.Payee, error) {
 	m.ctrl.T.Helper()
@@ -116,6 +340,50 @@ func (mr *MockStoreMockRecorder) CreatePayee(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayee", reflect.TypeOf((*MockStore)(nil).CreatePayee), arg0, arg1)
 }
 
+// CreatePaymentCategory mocks base method.
+func (m *MockStore) CreatePaymentCategory(arg0 context.Context, arg1 db.CreatePaymentCategoryParams) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentCategory indicates an expected call of CreatePaymentCategory.
+func (mr *MockStoreMockRecorder) CreatePaymentCategory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentCategory", reflect.TypeOf((*MockStore)(nil).CreatePaymentCategory), arg0, arg1)
+}
+
+// CreatePersonalAccessToken mocks base method.
+func (m *MockStore) CreatePersonalAccessToken(arg0 context.Context, arg1 db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(db.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePersonalAccessToken indicates an expected call of CreatePersonalAccessToken.
+func (mr *MockStoreMockRecorder) CreatePersonalAccessToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePersonalAccessToken", reflect.TypeOf((*MockStore)(nil).CreatePersonalAccessToken), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
 // CreateSession mocks base method.
 func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
@@ -146,6 +414,35 @@ func (mr *MockStoreMockRecorder) CreateTransaction(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockStore)(nil).CreateTransaction), arg0, arg1)
 }
 
+// CreateTransferPayee mocks base method.
+func (m *MockStore) CreateTransferPayee(arg0 context.Context, arg1 db.CreateTransferPayeeParams) (db.Payee, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferPayee", arg0, arg1)
+	ret0, _ := ret[0].(db.Payee)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferPayee indicates an expected call of CreateTransferPayee.
+func (mr *MockStoreMockRecorder) CreateTransferPayee(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferPayee", reflect.TypeOf((*MockStore)(nil).CreateTransferPayee), arg0, arg1)
+}
+
+// CreateTwoFactorChallenge mocks base method.
+func (m *MockStore) CreateTwoFactorChallenge(arg0 context.Context, arg1 db.CreateTwoFactorChallengeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTwoFactorChallenge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTwoFactorChallenge indicates an expected call of CreateTwoFactorChallenge.
+func (mr *MockStoreMockRecorder) CreateTwoFactorChallenge(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTwoFactorChallenge", reflect.TypeOf((*MockStore)(nil).CreateTwoFactorChallenge), arg0, arg1)
+}
+
 // CreateUser mocks base method.
 func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -161,6 +458,21 @@ func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
 }
 
+// CreateUserIdentity mocks base method.
+func (m *MockStore) CreateUserIdentity(arg0 context.Context, arg1 db.CreateUserIdentityParams) (db.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserIdentity", arg0, arg1)
+	ret0, _ := ret[0].(db.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserIdentity indicates an expected call of CreateUserIdentity.
+func (mr *MockStoreMockRecorder) CreateUserIdentity(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserIdentity", reflect.TypeOf((*MockStore)(nil).CreateUserIdentity), arg0, arg1)
+}
+
 // CreateUserTx mocks base method.
 func (m *MockStore) CreateUserTx(arg0 context.Context, arg1 db.CreateUserParams, arg2 func(db.UserParams) error) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -176,6 +488,21 @@ func (mr *MockStoreMockRecorder) CreateUserTx(arg0, arg1, arg2 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserTx", reflect.TypeOf((*MockStore)(nil).CreateUserTx), arg0, arg1, arg2)
 }
 
+// CreateUserWithIdentityTx mocks base method.
+func (m *MockStore) CreateUserWithIdentityTx(arg0 context.Context, arg1 db.CreateUserWithIdentityTxParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserWithIdentityTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserWithIdentityTx indicates an expected call of CreateUserWithIdentityTx.
+func (mr *MockStoreMockRecorder) CreateUserWithIdentityTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserWithIdentityTx", reflect.TypeOf((*MockStore)(nil).CreateUserWithIdentityTx), arg0, arg1)
+}
+
 // CreateVerifyEmails mocks base method.
 func (m *MockStore) CreateVerifyEmails(arg0 context.Context, arg1 db.CreateVerifyEmailsParams) (db.VerifyEmail, error) {
 	m.ctrl.T.Helper()
@@ -191,6 +518,36 @@ func (mr *MockStoreMockRecorder) CreateVerifyEmails(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerifyEmails", reflect.TypeOf((*MockStore)(nil).CreateVerifyEmails), arg0, arg1)
 }
 
+// CreateWebhook mocks base method.
+func (m *MockStore) CreateWebhook(arg0 context.Context, arg1 db.CreateWebhookParams) (db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockStoreMockRecorder) CreateWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockStore)(nil).CreateWebhook), arg0, arg1)
+}
+
+// CreateWebhookDelivery mocks base method.
+func (m *MockStore) CreateWebhookDelivery(arg0 context.Context, arg1 db.CreateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery.
+func (mr *MockStoreMockRecorder) CreateWebhookDelivery(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockStore)(nil).CreateWebhookDelivery), arg0, arg1)
+}
+
 // DeleteAccount mocks base method.
 func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -233,6 +590,20 @@ func (mr *MockStoreMockRecorder) DeleteBudget(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBudget", reflect.TypeOf((*MockStore)(nil).DeleteBudget), arg0, arg1)
 }
 
+// DeleteBudgetTransactions mocks base method.
+func (m *MockStore) DeleteBudgetTransactions(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBudgetTransactions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBudgetTransactions indicates an expected call of DeleteBudgetTransactions.
+func (mr *MockStoreMockRecorder) DeleteBudgetTransactions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBudgetTransactions", reflect.TypeOf((*MockStore)(nil).DeleteBudgetTransactions), arg0, arg1)
+}
+
 // DeleteBudgetTx mocks base method.
 func (m *MockStore) DeleteBudgetTx(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -247,6 +618,20 @@ func (mr *MockStoreMockRecorder) DeleteBudgetTx(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBudgetTx", reflect.TypeOf((*MockStore)(nil).DeleteBudgetTx), arg0, arg1)
 }
 
+// DeleteBudgetWebhookDeliveries mocks base method.
+func (m *MockStore) DeleteBudgetWebhookDeliveries(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBudgetWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBudgetWebhookDeliveries indicates an expected call of DeleteBudgetWebhookDeliveries.
+func (mr *MockStoreMockRecorder) DeleteBudgetWebhookDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBudgetWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).DeleteBudgetWebhookDeliveries), arg0, arg1)
+}
+
 // DeleteBudgets mocks base method.
 func (m *MockStore) DeleteBudgets(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -331,97 +716,434 @@ func (mr *MockStoreMockRecorder) DeleteCategoryGroups(arg0, arg1 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryGroups", reflect.TypeOf((*MockStore)(nil).DeleteCategoryGroups), arg0, arg1)
 }
 
-// DeletePayee mocks base method.
-func (m *MockStore) DeletePayee(arg0 context.Context, arg1 db.DeletePayeeParams) error {
+// DeleteClientSessions mocks base method.
+func (m *MockStore) DeleteClientSessions(arg0 context.Context, arg1 pgtype.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePayee", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteClientSessions", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeletePayee indicates an expected call of DeletePayee.
-func (mr *MockStoreMockRecorder) DeletePayee(arg0, arg1 any) *gomock.Call {
+// DeleteClientSessions indicates an expected call of DeleteClientSessions.
+func (mr *MockStoreMockRecorder) DeleteClientSessions(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePayee", reflect.TypeOf((*MockStore)(nil).DeletePayee), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteClientSessions", reflect.TypeOf((*MockStore)(nil).DeleteClientSessions), arg0, arg1)
 }
 
-// DeleteTransaction mocks base method.
-func (m *MockStore) DeleteTransaction(arg0 context.Context, arg1 uuid.UUID) error {
+// DeleteEmailChanges mocks base method.
+func (m *MockStore) DeleteEmailChanges(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTransaction", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteEmailChanges", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteTransaction indicates an expected call of DeleteTransaction.
-func (mr *MockStoreMockRecorder) DeleteTransaction(arg0, arg1 any) *gomock.Call {
+// DeleteEmailChanges indicates an expected call of DeleteEmailChanges.
+func (mr *MockStoreMockRecorder) DeleteEmailChanges(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTransaction", reflect.TypeOf((*MockStore)(nil).DeleteTransaction), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmailChanges", reflect.TypeOf((*MockStore)(nil).DeleteEmailChanges), arg0, arg1)
 }
 
-// DeleteUser mocks base method.
-func (m *MockStore) DeleteUser(arg0 context.Context, arg1 string) error {
+// DeleteLoan mocks base method.
+func (m *MockStore) DeleteLoan(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteLoan", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 any) *gomock.Call {
+// DeleteLoan indicates an expected call of DeleteLoan.
+func (mr *MockStoreMockRecorder) DeleteLoan(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoan", reflect.TypeOf((*MockStore)(nil).DeleteLoan), arg0, arg1)
 }
 
-// DeleteUserSessions mocks base method.
-func (m *MockStore) DeleteUserSessions(arg0 context.Context, arg1 string) error {
+// DeleteLoginEvents mocks base method.
+func (m *MockStore) DeleteLoginEvents(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUserSessions", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteLoginEvents", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteUserSessions indicates an expected call of DeleteUserSessions.
-func (mr *MockStoreMockRecorder) DeleteUserSessions(arg0, arg1 any) *gomock.Call {
+// DeleteLoginEvents indicates an expected call of DeleteLoginEvents.
+func (mr *MockStoreMockRecorder) DeleteLoginEvents(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockStore)(nil).DeleteUserSessions), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginEvents", reflect.TypeOf((*MockStore)(nil).DeleteLoginEvents), arg0, arg1)
 }
 
-// DeleteUserTx mocks base method.
-func (m *MockStore) DeleteUserTx(arg0 context.Context, arg1 db.UserParams, arg2 []uuid.UUID, arg3 func(db.UserParams) error) error {
+// DeleteMagicLinks mocks base method.
+func (m *MockStore) DeleteMagicLinks(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUserTx", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "DeleteMagicLinks", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteUserTx indicates an expected call of DeleteUserTx.
-func (mr *MockStoreMockRecorder) DeleteUserTx(arg0, arg1, arg2, arg3 any) *gomock.Call {
+// DeleteMagicLinks indicates an expected call of DeleteMagicLinks.
+func (mr *MockStoreMockRecorder) DeleteMagicLinks(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTx", reflect.TypeOf((*MockStore)(nil).DeleteUserTx), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMagicLinks", reflect.TypeOf((*MockStore)(nil).DeleteMagicLinks), arg0, arg1)
 }
 
-// DeleteVerifyEmails mocks base method.
-func (m *MockStore) DeleteVerifyEmails(arg0 context.Context, arg1 string) error {
+// DeleteOAuthAuthorizationCodes mocks base method.
+func (m *MockStore) DeleteOAuthAuthorizationCodes(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteVerifyEmails", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteOAuthAuthorizationCodes", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteVerifyEmails indicates an expected call of DeleteVerifyEmails.
-func (mr *MockStoreMockRecorder) DeleteVerifyEmails(arg0, arg1 any) *gomock.Call {
+// DeleteOAuthAuthorizationCodes indicates an expected call of DeleteOAuthAuthorizationCodes.
+func (mr *MockStoreMockRecorder) DeleteOAuthAuthorizationCodes(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVerifyEmails", reflect.TypeOf((*MockStore)(nil).DeleteVerifyEmails), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthAuthorizationCodes", reflect.TypeOf((*MockStore)(nil).DeleteOAuthAuthorizationCodes), arg0, arg1)
 }
 
-// GetAccount mocks base method.
-func (m *MockStore) GetAccount(arg0 context.Context, arg1 db.GetAccountParams) (db.Account, error) {
+// DeleteOAuthClient mocks base method.
+func (m *MockStore) DeleteOAuthClient(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteOAuthClient", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuthClient indicates an expected call of DeleteOAuthClient.
+func (mr *MockStoreMockRecorder) DeleteOAuthClient(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthClient", reflect.TypeOf((*MockStore)(nil).DeleteOAuthClient), arg0, arg1)
+}
+
+// DeleteOAuthClientTx mocks base method.
+func (m *MockStore) DeleteOAuthClientTx(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuthClientTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuthClientTx indicates an expected call of DeleteOAuthClientTx.
+func (mr *MockStoreMockRecorder) DeleteOAuthClientTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthClientTx", reflect.TypeOf((*MockStore)(nil).DeleteOAuthClientTx), arg0, arg1)
+}
+
+// DeleteOAuthClients mocks base method.
+func (m *MockStore) DeleteOAuthClients(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuthClients", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuthClients indicates an expected call of DeleteOAuthClients.
+func (mr *MockStoreMockRecorder) DeleteOAuthClients(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthClients", reflect.TypeOf((*MockStore)(nil).DeleteOAuthClients), arg0, arg1)
+}
+
+// DeletePasswordResets mocks base method.
+func (m *MockStore) DeletePasswordResets(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasswordResets", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasswordResets indicates an expected call of DeletePasswordResets.
+func (mr *MockStoreMockRecorder) DeletePasswordResets(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasswordResets", reflect.TypeOf((*MockStore)(nil).DeletePasswordResets), arg0, arg1)
+}
+
+// DeletePayee mocks base method.
+func (m *MockStore) DeletePayee(arg0 context.Context, arg1 db.DeletePayeeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePayee", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePayee indicates an expected call of DeletePayee.
+func (mr *MockStoreMockRecorder) DeletePayee(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePayee", reflect.TypeOf((*MockStore)(nil).DeletePayee), arg0, arg1)
+}
+
+// DeletePayees mocks base method.
+func (m *MockStore) DeletePayees(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePayees", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePayees indicates an expected call of DeletePayees.
+func (mr *MockStoreMockRecorder) DeletePayees(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePayees", reflect.TypeOf((*MockStore)(nil).DeletePayees), arg0, arg1)
+}
+
+// DeletePersonalAccessToken mocks base method.
+func (m *MockStore) DeletePersonalAccessToken(arg0 context.Context, arg1 db.DeletePersonalAccessTokenParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePersonalAccessToken indicates an expected call of DeletePersonalAccessToken.
+func (mr *MockStoreMockRecorder) DeletePersonalAccessToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePersonalAccessToken", reflect.TypeOf((*MockStore)(nil).DeletePersonalAccessToken), arg0, arg1)
+}
+
+// DeletePersonalAccessTokens mocks base method.
+func (m *MockStore) DeletePersonalAccessTokens(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePersonalAccessTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePersonalAccessTokens indicates an expected call of DeletePersonalAccessTokens.
+func (mr *MockStoreMockRecorder) DeletePersonalAccessTokens(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePersonalAccessTokens", reflect.TypeOf((*MockStore)(nil).DeletePersonalAccessTokens), arg0, arg1)
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockStore) DeleteRecoveryCodes(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteRecoveryCodes(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteRecoveryCodes), arg0, arg1)
+}
+
+// DeleteTransaction mocks base method.
+func (m *MockStore) DeleteTransaction(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTransaction", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTransaction indicates an expected call of DeleteTransaction.
+func (mr *MockStoreMockRecorder) DeleteTransaction(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTransaction", reflect.TypeOf((*MockStore)(nil).DeleteTransaction), arg0, arg1)
+}
+
+// DeleteUser mocks base method.
+func (m *MockStore) DeleteUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+}
+
+// DeleteUserIdentities mocks base method.
+func (m *MockStore) DeleteUserIdentities(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserIdentities", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserIdentities indicates an expected call of DeleteUserIdentities.
+func (mr *MockStoreMockRecorder) DeleteUserIdentities(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserIdentities", reflect.TypeOf((*MockStore)(nil).DeleteUserIdentities), arg0, arg1)
+}
+
+// DeleteUserOAuthAuthorizationCodes mocks base method.
+func (m *MockStore) DeleteUserOAuthAuthorizationCodes(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserOAuthAuthorizationCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserOAuthAuthorizationCodes indicates an expected call of DeleteUserOAuthAuthorizationCodes.
+func (mr *MockStoreMockRecorder) DeleteUserOAuthAuthorizationCodes(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserOAuthAuthorizationCodes", reflect.TypeOf((*MockStore)(nil).DeleteUserOAuthAuthorizationCodes), arg0, arg1)
+}
+
+// DeleteUserSessions mocks base method.
+func (m *MockStore) DeleteUserSessions(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserSessions indicates an expected call of DeleteUserSessions.
+func (mr *MockStoreMockRecorder) DeleteUserSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockStore)(nil).DeleteUserSessions), arg0, arg1)
+}
+
+// DeleteUserTx mocks base method.
+func (m *MockStore) DeleteUserTx(arg0 context.Context, arg1 db.UserParams, arg2 []uuid.UUID, arg3 func(db.UserParams) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTx", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTx indicates an expected call of DeleteUserTx.
+func (mr *MockStoreMockRecorder) DeleteUserTx(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTx", reflect.TypeOf((*MockStore)(nil).DeleteUserTx), arg0, arg1, arg2, arg3)
+}
+
+// DeleteVerifyEmails mocks base method.
+func (m *MockStore) DeleteVerifyEmails(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVerifyEmails", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVerifyEmails indicates an expected call of DeleteVerifyEmails.
+func (mr *MockStoreMockRecorder) DeleteVerifyEmails(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVerifyEmails", reflect.TypeOf((*MockStore)(nil).DeleteVerifyEmails), arg0, arg1)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockStore) DeleteWebhook(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockStoreMockRecorder) DeleteWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockStore)(nil).DeleteWebhook), arg0, arg1)
+}
+
+// DeleteWebhookDeliveries mocks base method.
+func (m *MockStore) DeleteWebhookDeliveries(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookDeliveries indicates an expected call of DeleteWebhookDeliveries.
+func (mr *MockStoreMockRecorder) DeleteWebhookDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).DeleteWebhookDeliveries), arg0, arg1)
+}
+
+// DeleteWebhookTx mocks base method.
+func (m *MockStore) DeleteWebhookTx(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookTx indicates an expected call of DeleteWebhookTx.
+func (mr *MockStoreMockRecorder) DeleteWebhookTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookTx", reflect.TypeOf((*MockStore)(nil).DeleteWebhookTx), arg0, arg1)
+}
+
+// DeleteWebhooks mocks base method.
+func (m *MockStore) DeleteWebhooks(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhooks", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhooks indicates an expected call of DeleteWebhooks.
+func (mr *MockStoreMockRecorder) DeleteWebhooks(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhooks", reflect.TypeOf((*MockStore)(nil).DeleteWebhooks), arg0, arg1)
+}
+
+// DisableTOTPTx mocks base method.
+func (m *MockStore) DisableTOTPTx(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTPTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTPTx indicates an expected call of DisableTOTPTx.
+func (mr *MockStoreMockRecorder) DisableTOTPTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTPTx", reflect.TypeOf((*MockStore)(nil).DisableTOTPTx), arg0, arg1)
+}
+
+// DisableUserTOTP mocks base method.
+func (m *MockStore) DisableUserTOTP(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableUserTOTP indicates an expected call of DisableUserTOTP.
+func (mr *MockStoreMockRecorder) DisableUserTOTP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUserTOTP", reflect.TypeOf((*MockStore)(nil).DisableUserTOTP), arg0, arg1)
+}
+
+// EnableTOTPTx mocks base method.
+func (m *MockStore) EnableTOTPTx(arg0 context.Context, arg1 string, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTPTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTPTx indicates an expected call of EnableTOTPTx.
+func (mr *MockStoreMockRecorder) EnableTOTPTx(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTPTx", reflect.TypeOf((*MockStore)(nil).EnableTOTPTx), arg0, arg1, arg2)
+}
+
+// EnableUserTOTP mocks base method.
+func (m *MockStore) EnableUserTOTP(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableUserTOTP indicates an expected call of EnableUserTOTP.
+func (mr *MockStoreMockRecorder) EnableUserTOTP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUserTOTP", reflect.TypeOf((*MockStore)(nil).EnableUserTOTP), arg0, arg1)
+}
+
+// GetAccount mocks base method.
+func (m *MockStore) GetAccount(arg0 context.Context, arg1 db.GetAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GetAccount indicates an expected call of GetAccount.
@@ -430,6 +1152,21 @@ func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
 }
 
+// GetAccountChangesAfter mocks base method.
+func (m *MockStore) GetAccountChangesAfter(arg0 context.Context, arg1 db.GetAccountChangesAfterParams) ([]db.GetAccountChangesAfterRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountChangesAfter", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetAccountChangesAfterRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountChangesAfter indicates an expected call of GetAccountChangesAfter.
+func (mr *MockStoreMockRecorder) GetAccountChangesAfter(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountChangesAfter", reflect.TypeOf((*MockStore)(nil).GetAccountChangesAfter), arg0, arg1)
+}
+
 // GetAccounts mocks base method.
 func (m *MockStore) GetAccounts(arg0 context.Context, arg1 uuid.UUID) ([]db.Account, error) {
 	m.ctrl.T.Helper()
@@ -445,6 +1182,21 @@ func (mr *MockStoreMockRecorder) GetAccounts(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockStore)(nil).GetAccounts), arg0, arg1)
 }
 
+// GetAuditLogs mocks base method.
+func (m *MockStore) GetAuditLogs(arg0 context.Context, arg1 db.GetAuditLogsParams) ([]db.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogs", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogs indicates an expected call of GetAuditLogs.
+func (mr *MockStoreMockRecorder) GetAuditLogs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogs", reflect.TypeOf((*MockStore)(nil).GetAuditLogs), arg0, arg1)
+}
+
 // GetBudget mocks base method.
 func (m *MockStore) GetBudget(arg0 context.Context, arg1 db.GetBudgetParams) (db.Budget, error) {
 	m.ctrl.T.Helper()
@@ -475,6 +1227,36 @@ func (mr *MockStoreMockRecorder) GetBudgetAccount(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudgetAccount", reflect.TypeOf((*MockStore)(nil).GetBudgetAccount), arg0, arg1)
 }
 
+// GetBudgetCategories mocks base method.
+func (m *MockStore) GetBudgetCategories(arg0 context.Context, arg1 uuid.UUID) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudgetCategories", arg0, arg1)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudgetCategories indicates an expected call of GetBudgetCategories.
+func (mr *MockStoreMockRecorder) GetBudgetCategories(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudgetCategories", reflect.TypeOf((*MockStore)(nil).GetBudgetCategories), arg0, arg1)
+}
+
+// GetBudgetCategory mocks base method.
+func (m *MockStore) GetBudgetCategory(arg0 context.Context, arg1 db.GetBudgetCategoryParams) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudgetCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudgetCategory indicates an expected call of GetBudgetCategory.
+func (mr *MockStoreMockRecorder) GetBudgetCategory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudgetCategory", reflect.TypeOf((*MockStore)(nil).GetBudgetCategory), arg0, arg1)
+}
+
 // GetBudgetDetails mocks base method.
 func (m *MockStore) GetBudgetDetails(arg0 context.Context, arg1 db.GetBudgetDetailsParams) (db.Budget, error) {
 	m.ctrl.T.Helper()
@@ -490,6 +1272,21 @@ func (mr *MockStoreMockRecorder) GetBudgetDetails(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudgetDetails", reflect.TypeOf((*MockStore)(nil).GetBudgetDetails), arg0, arg1)
 }
 
+// GetBudgetInvestmentTransactions mocks base method.
+func (m *MockStore) GetBudgetInvestmentTransactions(arg0 context.Context, arg1 db.GetBudgetInvestmentTransactionsParams) ([]db.InvestmentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudgetInvestmentTransactions", arg0, arg1)
+	ret0, _ := ret[0].([]db.InvestmentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudgetInvestmentTransactions indicates an expected call of GetBudgetInvestmentTransactions.
+func (mr *MockStoreMockRecorder) GetBudgetInvestmentTransactions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudgetInvestmentTransactions", reflect.TypeOf((*MockStore)(nil).GetBudgetInvestmentTransactions), arg0, arg1)
+}
+
 // GetBudgets mocks base method.
 func (m *MockStore) GetBudgets(arg0 context.Context, arg1 string) ([]db.Budget, error) {
 	m.ctrl.T.Helper()
@@ -550,6 +1347,21 @@ func (mr *MockStoreMockRecorder) GetCategoryGroup(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryGroup", reflect.TypeOf((*MockStore)(nil).GetCategoryGroup), arg0, arg1)
 }
 
+// GetCategoryGroupByName mocks base method.
+func (m *MockStore) GetCategoryGroupByName(arg0 context.Context, arg1 db.GetCategoryGroupByNameParams) (db.CategoryGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryGroupByName", arg0, arg1)
+	ret0, _ := ret[0].(db.CategoryGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryGroupByName indicates an expected call of GetCategoryGroupByName.
+func (mr *MockStoreMockRecorder) GetCategoryGroupByName(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryGroupByName", reflect.TypeOf((*MockStore)(nil).GetCategoryGroupByName), arg0, arg1)
+}
+
 // GetCategoryGroupsByBudgetId mocks base method.
 func (m *MockStore) GetCategoryGroupsByBudgetId(arg0 context.Context, arg1 uuid.UUID) ([]db.CategoryGroup, error) {
 	m.ctrl.T.Helper()
@@ -565,6 +1377,261 @@ func (mr *MockStoreMockRecorder) GetCategoryGroupsByBudgetId(arg0, arg1 any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryGroupsByBudgetId", reflect.TypeOf((*MockStore)(nil).GetCategoryGroupsByBudgetId), arg0, arg1)
 }
 
+// GetCurrencyTransfers mocks base method.
+func (m *MockStore) GetCurrencyTransfers(arg0 context.Context, arg1 uuid.UUID) ([]db.GetCurrencyTransfersRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrencyTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetCurrencyTransfersRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrencyTransfers indicates an expected call of GetCurrencyTransfers.
+func (mr *MockStoreMockRecorder) GetCurrencyTransfers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrencyTransfers", reflect.TypeOf((*MockStore)(nil).GetCurrencyTransfers), arg0, arg1)
+}
+
+// GetEmailChangeByCancelHash mocks base method.
+func (m *MockStore) GetEmailChangeByCancelHash(arg0 context.Context, arg1 string) (db.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailChangeByCancelHash", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailChangeByCancelHash indicates an expected call of GetEmailChangeByCancelHash.
+func (mr *MockStoreMockRecorder) GetEmailChangeByCancelHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailChangeByCancelHash", reflect.TypeOf((*MockStore)(nil).GetEmailChangeByCancelHash), arg0, arg1)
+}
+
+// GetEmailChangeByHash mocks base method.
+func (m *MockStore) GetEmailChangeByHash(arg0 context.Context, arg1 string) (db.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailChangeByHash", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailChangeByHash indicates an expected call of GetEmailChangeByHash.
+func (mr *MockStoreMockRecorder) GetEmailChangeByHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailChangeByHash", reflect.TypeOf((*MockStore)(nil).GetEmailChangeByHash), arg0, arg1)
+}
+
+// GetExchangeRates mocks base method.
+func (m *MockStore) GetExchangeRates(arg0 context.Context, arg1 db.GetExchangeRatesParams) ([]db.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExchangeRates", arg0, arg1)
+	ret0, _ := ret[0].([]db.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExchangeRates indicates an expected call of GetExchangeRates.
+func (mr *MockStoreMockRecorder) GetExchangeRates(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExchangeRates", reflect.TypeOf((*MockStore)(nil).GetExchangeRates), arg0, arg1)
+}
+
+// GetExchangeRatesUntil mocks base method.
+func (m *MockStore) GetExchangeRatesUntil(arg0 context.Context, arg1 db.GetExchangeRatesUntilParams) ([]db.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExchangeRatesUntil", arg0, arg1)
+	ret0, _ := ret[0].([]db.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExchangeRatesUntil indicates an expected call of GetExchangeRatesUntil.
+func (mr *MockStoreMockRecorder) GetExchangeRatesUntil(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExchangeRatesUntil", reflect.TypeOf((*MockStore)(nil).GetExchangeRatesUntil), arg0, arg1)
+}
+
+// GetInvestmentTransactions mocks base method.
+func (m *MockStore) GetInvestmentTransactions(arg0 context.Context, arg1 uuid.UUID) ([]db.InvestmentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvestmentTransactions", arg0, arg1)
+	ret0, _ := ret[0].([]db.InvestmentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvestmentTransactions indicates an expected call of GetInvestmentTransactions.
+func (mr *MockStoreMockRecorder) GetInvestmentTransactions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvestmentTransactions", reflect.TypeOf((*MockStore)(nil).GetInvestmentTransactions), arg0, arg1)
+}
+
+// GetLatestSecurityPrices mocks base method.
+func (m *MockStore) GetLatestSecurityPrices(arg0 context.Context, arg1 db.GetLatestSecurityPricesParams) ([]db.SecurityPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestSecurityPrices", arg0, arg1)
+	ret0, _ := ret[0].([]db.SecurityPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestSecurityPrices indicates an expected call of GetLatestSecurityPrices.
+func (mr *MockStoreMockRecorder) GetLatestSecurityPrices(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSecurityPrices", reflect.TypeOf((*MockStore)(nil).GetLatestSecurityPrices), arg0, arg1)
+}
+
+// GetLoan mocks base method.
+func (m *MockStore) GetLoan(arg0 context.Context, arg1 uuid.UUID) (db.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoan", arg0, arg1)
+	ret0, _ := ret[0].(db.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoan indicates an expected call of GetLoan.
+func (mr *MockStoreMockRecorder) GetLoan(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoan", reflect.TypeOf((*MockStore)(nil).GetLoan), arg0, arg1)
+}
+
+// GetLoanPayments mocks base method.
+func (m *MockStore) GetLoanPayments(arg0 context.Context, arg1 uuid.UUID) ([]db.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoanPayments", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoanPayments indicates an expected call of GetLoanPayments.
+func (mr *MockStoreMockRecorder) GetLoanPayments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoanPayments", reflect.TypeOf((*MockStore)(nil).GetLoanPayments), arg0, arg1)
+}
+
+// GetLoginDevice mocks base method.
+func (m *MockStore) GetLoginDevice(arg0 context.Context, arg1 db.GetLoginDeviceParams) (db.GetLoginDeviceRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginDevice", arg0, arg1)
+	ret0, _ := ret[0].(db.GetLoginDeviceRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginDevice indicates an expected call of GetLoginDevice.
+func (mr *MockStoreMockRecorder) GetLoginDevice(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginDevice", reflect.TypeOf((*MockStore)(nil).GetLoginDevice), arg0, arg1)
+}
+
+// GetLoginEventByRevokeHash mocks base method.
+func (m *MockStore) GetLoginEventByRevokeHash(arg0 context.Context, arg1 pgtype.Text) (db.LoginEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginEventByRevokeHash", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginEventByRevokeHash indicates an expected call of GetLoginEventByRevokeHash.
+func (mr *MockStoreMockRecorder) GetLoginEventByRevokeHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginEventByRevokeHash", reflect.TypeOf((*MockStore)(nil).GetLoginEventByRevokeHash), arg0, arg1)
+}
+
+// GetLoginEvents mocks base method.
+func (m *MockStore) GetLoginEvents(arg0 context.Context, arg1 db.GetLoginEventsParams) ([]db.LoginEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.LoginEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginEvents indicates an expected call of GetLoginEvents.
+func (mr *MockStoreMockRecorder) GetLoginEvents(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginEvents", reflect.TypeOf((*MockStore)(nil).GetLoginEvents), arg0, arg1)
+}
+
+// GetMagicLinkByHash mocks base method.
+func (m *MockStore) GetMagicLinkByHash(arg0 context.Context, arg1 string) (db.MagicLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMagicLinkByHash", arg0, arg1)
+	ret0, _ := ret[0].(db.MagicLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMagicLinkByHash indicates an expected call of GetMagicLinkByHash.
+func (mr *MockStoreMockRecorder) GetMagicLinkByHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMagicLinkByHash", reflect.TypeOf((*MockStore)(nil).GetMagicLinkByHash), arg0, arg1)
+}
+
+// GetOAuthAuthorizationCode mocks base method.
+func (m *MockStore) GetOAuthAuthorizationCode(arg0 context.Context, arg1 string) (db.OauthAuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(db.OauthAuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthAuthorizationCode indicates an expected call of GetOAuthAuthorizationCode.
+func (mr *MockStoreMockRecorder) GetOAuthAuthorizationCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthAuthorizationCode", reflect.TypeOf((*MockStore)(nil).GetOAuthAuthorizationCode), arg0, arg1)
+}
+
+// GetOAuthClient mocks base method.
+func (m *MockStore) GetOAuthClient(arg0 context.Context, arg1 uuid.UUID) (db.OauthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthClient", arg0, arg1)
+	ret0, _ := ret[0].(db.OauthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthClient indicates an expected call of GetOAuthClient.
+func (mr *MockStoreMockRecorder) GetOAuthClient(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthClient", reflect.TypeOf((*MockStore)(nil).GetOAuthClient), arg0, arg1)
+}
+
+// GetOAuthClients mocks base method.
+func (m *MockStore) GetOAuthClients(arg0 context.Context, arg1 string) ([]db.OauthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthClients", arg0, arg1)
+	ret0, _ := ret[0].([]db.OauthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthClients indicates an expected call of GetOAuthClients.
+func (mr *MockStoreMockRecorder) GetOAuthClients(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthClients", reflect.TypeOf((*MockStore)(nil).GetOAuthClients), arg0, arg1)
+}
+
+// GetPasswordResetByHash mocks base method.
+func (m *MockStore) GetPasswordResetByHash(arg0 context.Context, arg1 string) (db.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetByHash", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetByHash indicates an expected call of GetPasswordResetByHash.
+func (mr *MockStoreMockRecorder) GetPasswordResetByHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetByHash", reflect.TypeOf((*MockStore)(nil).GetPasswordResetByHash), arg0, arg1)
+}
+
 // GetPayeeById mocks base method.
 func (m *MockStore) GetPayeeById(arg0 context.Context, arg1 uuid.UUID) (db.Payee, error) {
 	m.ctrl.T.Helper()
@@ -595,19 +1662,64 @@ func (mr *MockStoreMockRecorder) GetPayees(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayees", reflect.TypeOf((*MockStore)(nil).GetPayees), arg0, arg1)
 }
 
-// GetPendingVerifyEmails mocks base method.
-func (m *MockStore) GetPendingVerifyEmails(arg0 context.Context, arg1 db.GetPendingVerifyEmailsParams) ([]db.VerifyEmail, error) {
+// GetPendingVerifyEmails mocks base method.
+func (m *MockStore) GetPendingVerifyEmails(arg0 context.Context, arg1 db.GetPendingVerifyEmailsParams) ([]db.VerifyEmail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingVerifyEmails", arg0, arg1)
+	ret0, _ := ret[0].([]db.VerifyEmail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingVerifyEmails indicates an expected call of GetPendingVerifyEmails.
+func (mr *MockStoreMockRecorder) GetPendingVerifyEmails(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingVerifyEmails", reflect.TypeOf((*MockStore)(nil).GetPendingVerifyEmails), arg0, arg1)
+}
+
+// GetPersonalAccessTokenByHash mocks base method.
+func (m *MockStore) GetPersonalAccessTokenByHash(arg0 context.Context, arg1 string) (db.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessTokenByHash", arg0, arg1)
+	ret0, _ := ret[0].(db.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalAccessTokenByHash indicates an expected call of GetPersonalAccessTokenByHash.
+func (mr *MockStoreMockRecorder) GetPersonalAccessTokenByHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessTokenByHash", reflect.TypeOf((*MockStore)(nil).GetPersonalAccessTokenByHash), arg0, arg1)
+}
+
+// GetPersonalAccessTokens mocks base method.
+func (m *MockStore) GetPersonalAccessTokens(arg0 context.Context, arg1 string) ([]db.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessTokens", arg0, arg1)
+	ret0, _ := ret[0].([]db.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalAccessTokens indicates an expected call of GetPersonalAccessTokens.
+func (mr *MockStoreMockRecorder) GetPersonalAccessTokens(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessTokens", reflect.TypeOf((*MockStore)(nil).GetPersonalAccessTokens), arg0, arg1)
+}
+
+// GetSecurityPrices mocks base method.
+func (m *MockStore) GetSecurityPrices(arg0 context.Context, arg1 db.GetSecurityPricesParams) ([]db.SecurityPrice, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPendingVerifyEmails", arg0, arg1)
-	ret0, _ := ret[0].([]db.VerifyEmail)
+	ret := m.ctrl.Call(m, "GetSecurityPrices", arg0, arg1)
+	ret0, _ := ret[0].([]db.SecurityPrice)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPendingVerifyEmails indicates an expected call of GetPendingVerifyEmails.
-func (mr *MockStoreMockRecorder) GetPendingVerifyEmails(arg0, arg1 any) *gomock.Call {
+// GetSecurityPrices indicates an expected call of GetSecurityPrices.
+func (mr *MockStoreMockRecorder) GetSecurityPrices(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingVerifyEmails", reflect.TypeOf((*MockStore)(nil).GetPendingVerifyEmails), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecurityPrices", reflect.TypeOf((*MockStore)(nil).GetSecurityPrices), arg0, arg1)
 }
 
 // GetSession mocks base method.
@@ -625,6 +1737,21 @@ func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
 }
 
+// GetSubscribedWebhooks mocks base method.
+func (m *MockStore) GetSubscribedWebhooks(arg0 context.Context, arg1 db.GetSubscribedWebhooksParams) ([]db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscribedWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscribedWebhooks indicates an expected call of GetSubscribedWebhooks.
+func (mr *MockStoreMockRecorder) GetSubscribedWebhooks(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscribedWebhooks", reflect.TypeOf((*MockStore)(nil).GetSubscribedWebhooks), arg0, arg1)
+}
+
 // GetTransactions mocks base method.
 func (m *MockStore) GetTransactions(arg0 context.Context, arg1 uuid.UUID) ([]db.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -730,6 +1857,51 @@ func (mr *MockStoreMockRecorder) GetUserByUsername(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockStore)(nil).GetUserByUsername), arg0, arg1)
 }
 
+// GetUserIdentities mocks base method.
+func (m *MockStore) GetUserIdentities(arg0 context.Context, arg1 string) ([]db.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentities", arg0, arg1)
+	ret0, _ := ret[0].([]db.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentities indicates an expected call of GetUserIdentities.
+func (mr *MockStoreMockRecorder) GetUserIdentities(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentities", reflect.TypeOf((*MockStore)(nil).GetUserIdentities), arg0, arg1)
+}
+
+// GetUserIdentity mocks base method.
+func (m *MockStore) GetUserIdentity(arg0 context.Context, arg1 db.GetUserIdentityParams) (db.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentity", arg0, arg1)
+	ret0, _ := ret[0].(db.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentity indicates an expected call of GetUserIdentity.
+func (mr *MockStoreMockRecorder) GetUserIdentity(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentity", reflect.TypeOf((*MockStore)(nil).GetUserIdentity), arg0, arg1)
+}
+
+// GetUserSessions mocks base method.
+func (m *MockStore) GetUserSessions(arg0 context.Context, arg1 string) ([]db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSessions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSessions indicates an expected call of GetUserSessions.
+func (mr *MockStoreMockRecorder) GetUserSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSessions", reflect.TypeOf((*MockStore)(nil).GetUserSessions), arg0, arg1)
+}
+
 // GetUsers mocks base method.
 func (m *MockStore) GetUsers(arg0 context.Context) ([]db.User, error) {
 	m.ctrl.T.Helper()
@@ -760,6 +1932,140 @@ func (mr *MockStoreMockRecorder) GetVerifyEmails(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVerifyEmails", reflect.TypeOf((*MockStore)(nil).GetVerifyEmails), arg0, arg1)
 }
 
+// GetWebhook mocks base method.
+func (m *MockStore) GetWebhook(arg0 context.Context, arg1 db.GetWebhookParams) (db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", arg0, arg1)
+	ret0, _ := ret[0].(db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockStoreMockRecorder) GetWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockStore)(nil).GetWebhook), arg0, arg1)
+}
+
+// GetWebhookById mocks base method.
+func (m *MockStore) GetWebhookById(arg0 context.Context, arg1 uuid.UUID) (db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookById", arg0, arg1)
+	ret0, _ := ret[0].(db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookById indicates an expected call of GetWebhookById.
+func (mr *MockStoreMockRecorder) GetWebhookById(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookById", reflect.TypeOf((*MockStore)(nil).GetWebhookById), arg0, arg1)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockStore) GetWebhookDeliveries(arg0 context.Context, arg1 db.GetWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockStoreMockRecorder) GetWebhookDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).GetWebhookDeliveries), arg0, arg1)
+}
+
+// GetWebhookDelivery mocks base method.
+func (m *MockStore) GetWebhookDelivery(arg0 context.Context, arg1 uuid.UUID) (db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDelivery indicates an expected call of GetWebhookDelivery.
+func (mr *MockStoreMockRecorder) GetWebhookDelivery(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDelivery", reflect.TypeOf((*MockStore)(nil).GetWebhookDelivery), arg0, arg1)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockStore) GetWebhooks(arg0 context.Context, arg1 uuid.UUID) ([]db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockStoreMockRecorder) GetWebhooks(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockStore)(nil).GetWebhooks), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockStore) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockStoreMockRecorder) ListUsers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), arg0, arg1)
+}
+
+// ResetPasswordTx mocks base method.
+func (m *MockStore) ResetPasswordTx(arg0 context.Context, arg1 db.ResetPasswordTxParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPasswordTx indicates an expected call of ResetPasswordTx.
+func (mr *MockStoreMockRecorder) ResetPasswordTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPasswordTx", reflect.TypeOf((*MockStore)(nil).ResetPasswordTx), arg0, arg1)
+}
+
+// RotateSessionRefreshToken mocks base method.
+func (m *MockStore) RotateSessionRefreshToken(arg0 context.Context, arg1 db.RotateSessionRefreshTokenParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSessionRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSessionRefreshToken indicates an expected call of RotateSessionRefreshToken.
+func (mr *MockStoreMockRecorder) RotateSessionRefreshToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSessionRefreshToken", reflect.TypeOf((*MockStore)(nil).RotateSessionRefreshToken), arg0, arg1)
+}
+
+// SetLoginEventRevokeToken mocks base method.
+func (m *MockStore) SetLoginEventRevokeToken(arg0 context.Context, arg1 db.SetLoginEventRevokeTokenParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLoginEventRevokeToken", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLoginEventRevokeToken indicates an expected call of SetLoginEventRevokeToken.
+func (mr *MockStoreMockRecorder) SetLoginEventRevokeToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoginEventRevokeToken", reflect.TypeOf((*MockStore)(nil).SetLoginEventRevokeToken), arg0, arg1)
+}
+
 // UpdateAccount mocks base method.
 func (m *MockStore) UpdateAccount(arg0 context.Context, arg1 db.UpdateAccountParams) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -835,6 +2141,20 @@ func (mr *MockStoreMockRecorder) UpdatePayee(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePayee", reflect.TypeOf((*MockStore)(nil).UpdatePayee), arg0, arg1)
 }
 
+// UpdatePersonalAccessTokenLastUsed mocks base method.
+func (m *MockStore) UpdatePersonalAccessTokenLastUsed(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePersonalAccessTokenLastUsed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePersonalAccessTokenLastUsed indicates an expected call of UpdatePersonalAccessTokenLastUsed.
+func (mr *MockStoreMockRecorder) UpdatePersonalAccessTokenLastUsed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePersonalAccessTokenLastUsed", reflect.TypeOf((*MockStore)(nil).UpdatePersonalAccessTokenLastUsed), arg0, arg1)
+}
+
 // UpdateTransaction mocks base method.
 func (m *MockStore) UpdateTransaction(arg0 context.Context, arg1 db.UpdateTransactionParams) (db.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -865,17 +2185,264 @@ func (mr *MockStoreMockRecorder) UpdateUser(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), arg0, arg1)
 }
 
-// UpdateUserTx mocks base method.
-func (m *MockStore) UpdateUserTx(arg0 context.Context, arg1 db.UpdateUserParams, arg2 func(db.UserParams) error) (db.User, error) {
+// UpdateUserPasswordHash mocks base method.
+func (m *MockStore) UpdateUserPasswordHash(arg0 context.Context, arg1 db.UpdateUserPasswordHashParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUserTx", arg0, arg1, arg2)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "UpdateUserPasswordHash", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPasswordHash indicates an expected call of UpdateUserPasswordHash.
+func (mr *MockStoreMockRecorder) UpdateUserPasswordHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPasswordHash", reflect.TypeOf((*MockStore)(nil).UpdateUserPasswordHash), arg0, arg1)
+}
+
+// UpdateUserTOTPLastStep mocks base method.
+func (m *MockStore) UpdateUserTOTPLastStep(arg0 context.Context, arg1 db.UpdateUserTOTPLastStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTPLastStep", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTOTPLastStep indicates an expected call of UpdateUserTOTPLastStep.
+func (mr *MockStoreMockRecorder) UpdateUserTOTPLastStep(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTPLastStep", reflect.TypeOf((*MockStore)(nil).UpdateUserTOTPLastStep), arg0, arg1)
+}
+
+// UpdateUserTOTPSecret mocks base method.
+func (m *MockStore) UpdateUserTOTPSecret(arg0 context.Context, arg1 db.UpdateUserTOTPSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTPSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserTOTPSecret indicates an expected call of UpdateUserTOTPSecret.
+func (mr *MockStoreMockRecorder) UpdateUserTOTPSecret(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTPSecret", reflect.TypeOf((*MockStore)(nil).UpdateUserTOTPSecret), arg0, arg1)
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockStore) UpdateWebhook(arg0 context.Context, arg1 db.UpdateWebhookParams) (db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockStoreMockRecorder) UpdateWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockStore)(nil).UpdateWebhook), arg0, arg1)
+}
+
+// UpdateWebhookDeliveryResult mocks base method.
+func (m *MockStore) UpdateWebhookDeliveryResult(arg0 context.Context, arg1 db.UpdateWebhookDeliveryResultParams) (db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDeliveryResult", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhookDeliveryResult indicates an expected call of UpdateWebhookDeliveryResult.
+func (mr *MockStoreMockRecorder) UpdateWebhookDeliveryResult(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDeliveryResult", reflect.TypeOf((*MockStore)(nil).UpdateWebhookDeliveryResult), arg0, arg1)
+}
+
+// UpsertExchangeRate mocks base method.
+func (m *MockStore) UpsertExchangeRate(arg0 context.Context, arg1 []db.UpsertExchangeRateParams) *db.UpsertExchangeRateBatchResults {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertExchangeRate", arg0, arg1)
+	ret0, _ := ret[0].(*db.UpsertExchangeRateBatchResults)
+	return ret0
+}
+
+// UpsertExchangeRate indicates an expected call of UpsertExchangeRate.
+func (mr *MockStoreMockRecorder) UpsertExchangeRate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertExchangeRate", reflect.TypeOf((*MockStore)(nil).UpsertExchangeRate), arg0, arg1)
+}
+
+// UpsertExchangeRatesTx mocks base method.
+func (m *MockStore) UpsertExchangeRatesTx(arg0 context.Context, arg1 []db.UpsertExchangeRateParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertExchangeRatesTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertExchangeRatesTx indicates an expected call of UpsertExchangeRatesTx.
+func (mr *MockStoreMockRecorder) UpsertExchangeRatesTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertExchangeRatesTx", reflect.TypeOf((*MockStore)(nil).UpsertExchangeRatesTx), arg0, arg1)
+}
+
+// UpsertLoan mocks base method.
+func (m *MockStore) UpsertLoan(arg0 context.Context, arg1 db.UpsertLoanParams) (db.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertLoan", arg0, arg1)
+	ret0, _ := ret[0].(db.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertLoan indicates an expected call of UpsertLoan.
+func (mr *MockStoreMockRecorder) UpsertLoan(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertLoan", reflect.TypeOf((*MockStore)(nil).UpsertLoan), arg0, arg1)
+}
+
+// UpsertSecurityPrice mocks base method.
+func (m *MockStore) UpsertSecurityPrice(arg0 context.Context, arg1 db.UpsertSecurityPriceParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertSecurityPrice", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertSecurityPrice indicates an expected call of UpsertSecurityPrice.
+func (mr *MockStoreMockRecorder) UpsertSecurityPrice(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertSecurityPrice", reflect.TypeOf((*MockStore)(nil).UpsertSecurityPrice), arg0, arg1)
+}
+
+// UpsertSecurityPricesTx mocks base method.
+func (m *MockStore) UpsertSecurityPricesTx(arg0 context.Context, arg1 []db.UpsertSecurityPriceParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertSecurityPricesTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertSecurityPricesTx indicates an expected call of UpsertSecurityPricesTx.
+func (mr *MockStoreMockRecorder) UpsertSecurityPricesTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertSecurityPricesTx", reflect.TypeOf((*MockStore)(nil).UpsertSecurityPricesTx), arg0, arg1)
+}
+
+// UseEmailChange mocks base method.
+func (m *MockStore) UseEmailChange(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseEmailChange", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseEmailChange indicates an expected call of UseEmailChange.
+func (mr *MockStoreMockRecorder) UseEmailChange(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseEmailChange", reflect.TypeOf((*MockStore)(nil).UseEmailChange), arg0, arg1)
+}
+
+// UseEmailChanges mocks base method.
+func (m *MockStore) UseEmailChanges(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseEmailChanges", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseEmailChanges indicates an expected call of UseEmailChanges.
+func (mr *MockStoreMockRecorder) UseEmailChanges(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseEmailChanges", reflect.TypeOf((*MockStore)(nil).UseEmailChanges), arg0, arg1)
+}
+
+// UseMagicLink mocks base method.
+func (m *MockStore) UseMagicLink(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseMagicLink", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseMagicLink indicates an expected call of UseMagicLink.
+func (mr *MockStoreMockRecorder) UseMagicLink(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseMagicLink", reflect.TypeOf((*MockStore)(nil).UseMagicLink), arg0, arg1)
+}
+
+// UseOAuthAuthorizationCode mocks base method.
+func (m *MockStore) UseOAuthAuthorizationCode(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOAuthAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseOAuthAuthorizationCode indicates an expected call of UseOAuthAuthorizationCode.
+func (mr *MockStoreMockRecorder) UseOAuthAuthorizationCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOAuthAuthorizationCode", reflect.TypeOf((*MockStore)(nil).UseOAuthAuthorizationCode), arg0, arg1)
+}
+
+// UsePasswordReset mocks base method.
+func (m *MockStore) UsePasswordReset(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsePasswordReset indicates an expected call of UsePasswordReset.
+func (mr *MockStoreMockRecorder) UsePasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePasswordReset", reflect.TypeOf((*MockStore)(nil).UsePasswordReset), arg0, arg1)
+}
+
+// UsePasswordResets mocks base method.
+func (m *MockStore) UsePasswordResets(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePasswordResets", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UsePasswordResets indicates an expected call of UsePasswordResets.
+func (mr *MockStoreMockRecorder) UsePasswordResets(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePasswordResets", reflect.TypeOf((*MockStore)(nil).UsePasswordResets), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseTwoFactorChallenge mocks base method.
+func (m *MockStore) UseTwoFactorChallenge(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTwoFactorChallenge", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateUserTx indicates an expected call of UpdateUserTx.
-func (mr *MockStoreMockRecorder) UpdateUserTx(arg0, arg1, arg2 any) *gomock.Call {
+// UseTwoFactorChallenge indicates an expected call of UseTwoFactorChallenge.
+func (mr *MockStoreMockRecorder) UseTwoFactorChallenge(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTx", reflect.TypeOf((*MockStore)(nil).UpdateUserTx), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTwoFactorChallenge", reflect.TypeOf((*MockStore)(nil).UseTwoFactorChallenge), arg0, arg1)
 }
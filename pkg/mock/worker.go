@@ -40,6 +40,20 @@ func (m *MockTaskDistributor) EXPECT() *MockTaskDistributorMockRecorder {
 	return m.recorder
 }
 
+// DistributeDeliverWebhook mocks base method.
+func (m *MockTaskDistributor) DistributeDeliverWebhook(arg0 context.Context, arg1 *worker.DeliverWebhookPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DistributeDeliverWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeDeliverWebhook indicates an expected call of DistributeDeliverWebhook.
+func (mr *MockTaskDistributorMockRecorder) DistributeDeliverWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeDeliverWebhook", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeDeliverWebhook), arg0, arg1)
+}
+
 // DistributeSendEmail mocks base method.
 func (m *MockTaskDistributor) DistributeSendEmail(arg0 context.Context, arg1 *worker.SendEmailPayload, arg2 string) error {
 	m.ctrl.T.Helper()
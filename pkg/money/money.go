@@ -0,0 +1,196 @@
+// Package money handles amounts of money as 64-bit milliunits: thousandths of the unit of their currency, whatever
+// the decimals of its minor unit. 1.50 EUR is 1500, and so is 1.5 KWD, while 1500 JPY is 1500000.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Milliunits in one unit of a currency
+const Milliunits = 1000
+
+// Decimals of an amount in milliunits
+const milliunitDecimals = 3
+
+// Returned when the result of an operation does not fit in 64 bits
+var ErrOverflow = errors.New("amount out of range")
+
+// Currencies whose minor unit is not the hundredth, from ISO 4217
+var decimals = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// Returns the number of decimals of the minor unit of a currency.
+func Decimals(currency string) int {
+	if n, ok := decimals[currency]; ok {
+		return n
+	}
+	return 2
+}
+
+// Returns the smallest amount of a currency, in milliunits. Milliunits are the smallest amount of currencies with
+// more than three decimals.
+func unit(currency string) int64 {
+	u := int64(1)
+	for d := Decimals(currency); d < milliunitDecimals; d++ {
+		u *= 10
+	}
+	return u
+}
+
+func Add(a, b int64) (int64, error) {
+	s := a + b
+	if (s > a) != (b > 0) {
+		return 0, ErrOverflow
+	}
+	return s, nil
+}
+
+func Sub(a, b int64) (int64, error) {
+	d := a - b
+	if (d < a) != (b > 0) {
+		return 0, ErrOverflow
+	}
+	return d, nil
+}
+
+func Mul(a, n int64) (int64, error) {
+	if a == 0 || n == 0 {
+		return 0, nil
+	}
+	p := a * n
+	if p/n != a || (a == -1 && n == math.MinInt64) || (n == -1 && a == math.MinInt64) {
+		return 0, ErrOverflow
+	}
+	return p, nil
+}
+
+func Neg(a int64) (int64, error) {
+	if a == math.MinInt64 {
+		return 0, ErrOverflow
+	}
+	return -a, nil
+}
+
+// Returns the sum of amounts.
+func Sum(amounts ...int64) (int64, error) {
+	var sum int64
+	for _, a := range amounts {
+		var err error
+		if sum, err = Add(sum, a); err != nil {
+			return 0, err
+		}
+	}
+	return sum, nil
+}
+
+// Returns the amount in milliunits nearest to a float, such as the result of a conversion at an exchange rate.
+func FromFloat(f float64) (int64, error) {
+	r := math.Round(f)
+	if math.IsNaN(r) || r < math.MinInt64 || r >= math.MaxInt64 {
+		return 0, ErrOverflow
+	}
+	return int64(r), nil
+}
+
+// Rounds an amount to the smallest amount of a currency, half away from zero: 1.235 EUR is 1.24 EUR.
+func Round(amount int64, currency string) (int64, error) {
+	u := unit(currency)
+	r := amount % u
+	if r == 0 {
+		return amount, nil
+	}
+	amount -= r
+	if 2*r >= u {
+		return Add(amount, u)
+	}
+	if 2*r <= -u {
+		return Sub(amount, u)
+	}
+	return amount, nil
+}
+
+// Parses a decimal amount of a currency, such as "-1234.56", into milliunits. It has at most the decimals of the
+// currency. The errors are meant for the user.
+func Parse(s, currency string) (int64, error) {
+
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	if negative || strings.HasPrefix(text, "+") {
+		text = text[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" || !digits(whole) || (strings.Contains(text, ".") && (fraction == "" || !digits(fraction))) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(fraction) > min(Decimals(currency), milliunitDecimals) {
+		return 0, fmt.Errorf("invalid amount %q: %s has %d decimals", s, currency, Decimals(currency))
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, ErrOverflow
+	}
+	amount, err := Mul(units, Milliunits)
+	if err != nil {
+		return 0, err
+	}
+	if fraction != "" {
+		f, _ := strconv.ParseInt(fraction+strings.Repeat("0", milliunitDecimals-len(fraction)), 10, 64)
+		if amount, err = Add(amount, f); err != nil {
+			return 0, err
+		}
+	}
+	if negative {
+		return Neg(amount)
+	}
+
+	return amount, nil
+}
+
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Formats an amount in milliunits as a decimal with the decimals of its currency, such as "-1234.56". Amounts more
+// precise than the currency are rounded half away from zero.
+func Format(amount int64, currency string) string {
+
+	// the absolute value of math.MinInt64 only fits unsigned
+	abs := uint64(amount)
+	sign := ""
+	if amount < 0 {
+		abs = -abs
+		sign = "-"
+	}
+
+	d := Decimals(currency)
+	u := uint64(unit(currency))
+	abs = (abs + u/2) / u * u
+	if abs == 0 {
+		sign = ""
+	}
+
+	s := sign + strconv.FormatUint(abs/Milliunits, 10)
+	if d == 0 {
+		return s
+	}
+	fraction := fmt.Sprintf("%03d", abs%Milliunits)
+	if d < milliunitDecimals {
+		return s + "." + fraction[:d]
+	}
+	return s + "." + fraction + strings.Repeat("0", d-milliunitDecimals)
+}
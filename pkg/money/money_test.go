@@ -0,0 +1,113 @@
+package money
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArithmetic(t *testing.T) {
+
+	sum, err := Sum(1500, -250, 30)
+	require.NoError(t, err)
+	require.Equal(t, int64(1280), sum)
+
+	_, err = Add(math.MaxInt64, 1)
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = Add(math.MinInt64, -1)
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = Sub(math.MinInt64, 1)
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = Mul(math.MaxInt64/2+1, 2)
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = Mul(math.MinInt64, -1)
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = Neg(math.MinInt64)
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = FromFloat(1e19)
+	require.ErrorIs(t, err, ErrOverflow)
+
+	p, err := Mul(-1500, 3)
+	require.NoError(t, err)
+	require.Equal(t, int64(-4500), p)
+}
+
+func TestRound(t *testing.T) {
+
+	testCases := []struct {
+		amount   int64
+		currency string
+		expected int64
+	}{
+		{amount: 1235, currency: "EUR", expected: 1240},
+		{amount: 1234, currency: "EUR", expected: 1230},
+		{amount: -1235, currency: "EUR", expected: -1240},
+		{amount: 1499, currency: "JPY", expected: 1000},
+		{amount: -1500, currency: "JPY", expected: -2000},
+		{amount: 1235, currency: "KWD", expected: 1235},
+	}
+	for _, tc := range testCases {
+		rounded, err := Round(tc.amount, tc.currency)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, rounded)
+	}
+
+	_, err := Round(math.MaxInt64, "EUR")
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestParse(t *testing.T) {
+
+	testCases := []struct {
+		text     string
+		currency string
+		expected int64
+	}{
+		{text: "1234.56", currency: "EUR", expected: 1234560},
+		{text: "-0.5", currency: "USD", expected: -500},
+		{text: " +12 ", currency: "EUR", expected: 12000},
+		{text: "1500", currency: "JPY", expected: 1500000},
+		{text: "1.234", currency: "KWD", expected: 1234},
+	}
+	for _, tc := range testCases {
+		amount, err := Parse(tc.text, tc.currency)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, amount)
+	}
+
+	for _, text := range []string{"", "-", "abc", "1,234.56", "1.", ".5", "1.2.3", "--1", "-+1", "1e3"} {
+		_, err := Parse(text, "EUR")
+		require.Error(t, err, text)
+	}
+
+	_, err := Parse("1.234", "EUR")
+	require.EqualError(t, err, `invalid amount "1.234": EUR has 2 decimals`)
+	_, err = Parse("1500.5", "JPY")
+	require.Error(t, err)
+	_, err = Parse("99999999999999999999", "EUR")
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = Parse("9300000000000000", "EUR")
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestFormat(t *testing.T) {
+
+	testCases := []struct {
+		amount   int64
+		currency string
+		expected string
+	}{
+		{amount: 1234560, currency: "EUR", expected: "1234.56"},
+		{amount: -500, currency: "USD", expected: "-0.50"},
+		{amount: 1235, currency: "EUR", expected: "1.24"},
+		{amount: -4, currency: "EUR", expected: "0.00"},
+		{amount: 1500000, currency: "JPY", expected: "1500"},
+		{amount: 1234, currency: "KWD", expected: "1.234"},
+		{amount: 1234, currency: "CLF", expected: "1.2340"},
+		{amount: math.MinInt64, currency: "KWD", expected: "-9223372036854775.808"},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, Format(tc.amount, tc.currency))
+	}
+}
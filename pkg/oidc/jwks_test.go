@@ -0,0 +1,53 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicKeys(t *testing.T) {
+
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	smallRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ecX := make([]byte, 32)
+	ecY := make([]byte, 32)
+	ecKey.X.FillBytes(ecX)
+	ecKey.Y.FillBytes(ecY)
+	offCurve := make([]byte, 32)
+	new(big.Int).Add(ecKey.Y, big.NewInt(1)).FillBytes(offCurve)
+
+	set := jsonWebKeySet{Keys: []jsonWebKey{
+		{Kty: "RSA", Kid: "rsa", Use: "sig", N: b64(rsaKey.N.Bytes()), E: "AQAB"},
+		{Kty: "EC", Kid: "ec", Crv: "P-256", X: b64(ecX), Y: b64(ecY)},
+		{Kty: "OKP", Kid: "ed", Crv: "Ed25519", X: b64(edKey)},
+		// skipped
+		{Kty: "RSA", Kid: "enc", Use: "enc", N: b64(rsaKey.N.Bytes()), E: "AQAB"},
+		{Kty: "RSA", Kid: "small", N: b64(smallRSAKey.N.Bytes()), E: "AQAB"},
+		{Kty: "EC", Kid: "offcurve", Crv: "P-256", X: b64(ecX), Y: b64(offCurve)},
+		{Kty: "EC", Kid: "secp256k1", Crv: "secp256k1", X: b64(ecX), Y: b64(ecY)},
+		{Kty: "oct", Kid: "hmac", N: "c2VjcmV0"},
+	}}
+
+	keys := set.publicKeys()
+	require.Len(t, keys, 3)
+	require.True(t, rsaKey.PublicKey.Equal(keys["rsa"]))
+	require.True(t, ecKey.PublicKey.Equal(keys["ec"]))
+	require.True(t, edKey.Equal(keys["ed"]))
+}
@@ -0,0 +1,323 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Timeout of the requests to the provider, unless a client is given
+const defaultTimeout = 10 * time.Second
+
+// Keys are fetched again for unknown key IDs, but not more often than this, so that
+// tokens with made up key IDs cannot flood the provider
+const minKeysRefreshInterval = time.Minute
+
+// Clock skew allowed when checking the dates of ID tokens
+const clockSkew = time.Minute
+
+// Signing algorithms accepted for ID tokens. HS256 is not, since it would use the client secret as the key.
+var signingAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// Config of the client of an OpenID Connect provider
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// URL of the callback endpoint, as registered with the provider
+	RedirectURL string
+	// Scopes to ask for, DefaultScopes if empty
+	Scopes []string
+	// Client of the requests to the provider
+	HTTPClient *http.Client
+}
+
+// Provider is the client of an OpenID Connect provider, using the authorization code flow with PKCE.
+// The metadata of the provider is discovered on first use, and its keys are cached.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *providerMetadata
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// Provider metadata (OpenID Connect Discovery 1.0)
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Claims of an ID token which identify the user
+type Claims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Nonce             string `json:"nonce"`
+	AuthorizedParty   string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// Creates the client of a provider. Nothing is fetched from the provider until it is used.
+func NewProvider(config Config) (*Provider, error) {
+
+	if config.IssuerURL == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("the issuer URL, client ID and redirect URL of the OpenID Connect provider are required")
+	}
+	if _, err := url.ParseRequestURI(config.IssuerURL); err != nil {
+		return nil, fmt.Errorf("invalid issuer URL: %s", err)
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = DefaultScopes
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+
+	return &Provider{
+		config: config,
+		client: client,
+	}, nil
+}
+
+// Issuer of the ID tokens of the provider
+func (p *Provider) Issuer() string {
+	return p.config.IssuerURL
+}
+
+// Generates a PKCE code verifier (RFC 7636)
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// S256 code challenge of a code verifier
+func CodeChallenge(codeVerifier string) string {
+	h := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+// Returns the URL of the authorization endpoint where the user logs in. The state protects the callback
+// against CSRF, the nonce binds the ID token to the login, and the code verifier binds the code to it.
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeVerifier string) (string, error) {
+
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(md.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %s", err)
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.config.ClientID)
+	q.Set("redirect_uri", p.config.RedirectURL)
+	q.Set("scope", strings.Join(p.config.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", CodeChallenge(codeVerifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Exchanges an authorization code for the ID token of the user, and verifies it.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*Claims, error) {
+
+	md, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.config.ClientID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		// RFC 6749 2.3.1
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach the token endpoint: %s", err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResp struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokenResp); err != nil {
+		return nil, fmt.Errorf("invalid response from the token endpoint: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, tokenResp.Error, tokenResp.ErrorDescription)
+	}
+	if tokenResp.IDToken == "" {
+		return nil, fmt.Errorf("token endpoint did not return an ID token")
+	}
+
+	return p.VerifyIDToken(ctx, tokenResp.IDToken, nonce)
+}
+
+// Verifies the signature, issuer, audience, dates and nonce of an ID token.
+func (p *Provider) VerifyIDToken(ctx context.Context, idToken string, nonce string) (*Claims, error) {
+
+	md, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keyFunc := func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, md, kid)
+	}
+	parsed, err := jwt.ParseWithClaims(idToken, &Claims{}, keyFunc,
+		jwt.WithValidMethods(signingAlgorithms),
+		jwt.WithIssuer(md.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	claims, ok := parsed.Claims.(*Claims)
+	if !ok {
+		return nil, fmt.Errorf("invalid ID token: cannot convert claims")
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("invalid ID token: no subject")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return nil, fmt.Errorf("invalid ID token: issued to another party")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("invalid ID token: nonce does not match")
+	}
+
+	return claims, nil
+}
+
+// Fetches the metadata of the provider, once it succeeds.
+func (p *Provider) discover(ctx context.Context) (*providerMetadata, error) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var md providerMetadata
+	wellKnown := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, &md); err != nil {
+		return nil, fmt.Errorf("cannot discover the OpenID Connect provider: %s", err)
+	}
+	// the issuer must be the one configured, or anyone serving the document could issue tokens
+	if md.Issuer != p.config.IssuerURL {
+		return nil, fmt.Errorf("issuer %q of the OpenID Connect provider does not match %q", md.Issuer, p.config.IssuerURL)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, fmt.Errorf("the metadata of the OpenID Connect provider is incomplete")
+	}
+	p.metadata = &md
+
+	return p.metadata, nil
+}
+
+// Returns the key with an ID, fetching the keys again if it is unknown, e.g. after a key rotation.
+// Tokens without a key ID are accepted if the provider has a single key.
+func (p *Provider) key(ctx context.Context, md *providerMetadata, kid string) (crypto.PublicKey, error) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	find := func() (crypto.PublicKey, bool) {
+		if kid == "" && len(p.keys) == 1 {
+			for _, k := range p.keys {
+				return k, true
+			}
+		}
+		k, exists := p.keys[kid]
+		return k, exists
+	}
+
+	if k, exists := find(); exists {
+		return k, nil
+	}
+	if time.Since(p.keysFetchedAt) < minKeysRefreshInterval {
+		return nil, errors.New("unknown signing key")
+	}
+
+	var jwks jsonWebKeySet
+	if err := p.getJSON(ctx, md.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("cannot fetch the keys of the OpenID Connect provider: %s", err)
+	}
+	p.keys = jwks.publicKeys()
+	p.keysFetchedAt = time.Now()
+
+	if k, exists := find(); exists {
+		return k, nil
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+func (p *Provider) getJSON(ctx context.Context, u string, v any) error {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", u, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
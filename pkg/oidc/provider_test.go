@@ -0,0 +1,147 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/guerzon/gobudget-api/pkg/oidc"
+	"github.com/guerzon/gobudget-api/pkg/oidc/oidctest"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURL = "http://localhost:8080/beta/login/oidc/callback"
+
+func newProvider(t *testing.T, mock *oidctest.Provider) *oidc.Provider {
+	p, err := oidc.NewProvider(oidc.Config{
+		IssuerURL:    mock.Issuer(),
+		ClientID:     mock.ClientID,
+		ClientSecret: mock.ClientSecret,
+		RedirectURL:  redirectURL,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func TestNewProvider(t *testing.T) {
+
+	_, err := oidc.NewProvider(oidc.Config{ClientID: "gobudget", RedirectURL: redirectURL})
+	require.Error(t, err)
+	_, err = oidc.NewProvider(oidc.Config{IssuerURL: "not a url", ClientID: "gobudget", RedirectURL: redirectURL})
+	require.Error(t, err)
+	_, err = oidc.NewProvider(oidc.Config{IssuerURL: "https://accounts.example.com", ClientID: "gobudget", RedirectURL: redirectURL})
+	require.NoError(t, err)
+}
+
+func TestCodeChallenge(t *testing.T) {
+
+	// RFC 7636 appendix B
+	require.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", oidc.CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+
+	v1, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	v2, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	require.Len(t, v1, 43)
+	require.NotEqual(t, v1, v2)
+}
+
+func TestAuthCodeFlow(t *testing.T) {
+
+	mock := oidctest.NewProvider(t, "gobudget", "secret")
+	p := newProvider(t, mock)
+	identity := oidctest.Identity{Subject: "248289761001", Email: "charles@example.com", EmailVerified: true, PreferredUsername: "charles"}
+
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	authURL, err := p.AuthCodeURL(context.Background(), "state", "nonce", verifier)
+	require.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, mock.Issuer()+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	require.Equal(t, redirectURL, u.Query().Get("redirect_uri"))
+	require.Equal(t, "openid email profile", u.Query().Get("scope"))
+	require.Equal(t, oidc.CodeChallenge(verifier), u.Query().Get("code_challenge"))
+
+	code, state, err := mock.Authorize(authURL, identity)
+	require.NoError(t, err)
+	require.Equal(t, "state", state)
+
+	claims, err := p.Exchange(context.Background(), code, verifier, "nonce")
+	require.NoError(t, err)
+	require.Equal(t, mock.Issuer(), claims.Issuer)
+	require.Equal(t, identity.Subject, claims.Subject)
+	require.Equal(t, identity.Email, claims.Email)
+	require.True(t, claims.EmailVerified)
+	require.Equal(t, identity.PreferredUsername, claims.PreferredUsername)
+
+	// codes are single-use
+	_, err = p.Exchange(context.Background(), code, verifier, "nonce")
+	require.Error(t, err)
+}
+
+func TestExchangeErrors(t *testing.T) {
+
+	identity := oidctest.Identity{Subject: "248289761001"}
+
+	testCases := []struct {
+		name         string
+		verifier     string
+		nonce        string
+		modifyClaims func(claims jwt.MapClaims)
+	}{
+		{name: "WrongCodeVerifier", verifier: "wrong", nonce: "nonce"},
+		{name: "WrongNonce", nonce: "othernonce"},
+		{name: "WrongAudience", nonce: "nonce", modifyClaims: func(c jwt.MapClaims) { c["aud"] = "otherclient" }},
+		{name: "OtherAuthorizedParty", nonce: "nonce", modifyClaims: func(c jwt.MapClaims) {
+			c["aud"] = []string{"gobudget", "otherclient"}
+			c["azp"] = "otherclient"
+		}},
+		{name: "WrongIssuer", nonce: "nonce", modifyClaims: func(c jwt.MapClaims) { c["iss"] = "https://accounts.example.com" }},
+		{name: "Expired", nonce: "nonce", modifyClaims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "NoExpiration", nonce: "nonce", modifyClaims: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "NoSubject", nonce: "nonce", modifyClaims: func(c jwt.MapClaims) { delete(c, "sub") }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := oidctest.NewProvider(t, "gobudget", "")
+			mock.ModifyClaims = tc.modifyClaims
+			p := newProvider(t, mock)
+
+			verifier, err := oidc.NewCodeVerifier()
+			require.NoError(t, err)
+			authURL, err := p.AuthCodeURL(context.Background(), "state", "nonce", verifier)
+			require.NoError(t, err)
+			code, _, err := mock.Authorize(authURL, identity)
+			require.NoError(t, err)
+
+			if tc.verifier != "" {
+				verifier = tc.verifier
+			}
+			_, err = p.Exchange(context.Background(), code, verifier, tc.nonce)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+
+	mock := oidctest.NewProvider(t, "gobudget", "")
+
+	// serves the metadata of the mock provider under another issuer URL
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, mock.Issuer()+r.URL.Path, http.StatusFound)
+	}))
+	defer proxy.Close()
+
+	p, err := oidc.NewProvider(oidc.Config{IssuerURL: proxy.URL, ClientID: "gobudget", RedirectURL: redirectURL})
+	require.NoError(t, err)
+	_, err = p.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	require.ErrorContains(t, err, "does not match")
+}
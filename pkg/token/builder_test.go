@@ -0,0 +1,99 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+// Runs the tests that every implementation of Builder must pass. newBuilder returns a builder with a new random key.
+func testBuilder(t *testing.T, newBuilder func(t *testing.T) Builder) {
+
+	t.Run("CreateAndVerify", func(t *testing.T) {
+		maker := newBuilder(t)
+
+		// these are for verification purposes against the created token:
+		issuedAt := time.Now()
+		expiredAt := issuedAt.Add(time.Hour * 24)
+
+		params := CreateTokenParams{
+			Username:  "tst" + util.RandomUsername(),
+			Duration:  time.Duration(time.Hour * 24),
+			Purpose:   PurposeRefresh,
+			SessionID: uuid.New(),
+			Scopes:    []string{ScopeRead},
+			Roles:     []string{RoleAdmin},
+		}
+		token, payload, err := maker.CreateToken(params)
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+		require.NotEmpty(t, payload)
+
+		payload, err = maker.VerifyToken(token)
+		require.NoError(t, err)
+		require.NotEmpty(t, payload)
+		// inspect the fields
+		require.NotZero(t, payload.ID)
+		require.Equal(t, params.Username, payload.Username)
+		require.Equal(t, params.Purpose, payload.Purpose)
+		require.Equal(t, params.SessionID, payload.SessionID)
+		require.Equal(t, params.Scopes, payload.Scopes)
+		require.Equal(t, params.Roles, payload.Roles)
+		require.True(t, payload.HasRole(RoleAdmin))
+		require.WithinDuration(t, issuedAt, payload.IssuedAt.Time, time.Second)   // issuance should happen within 1 second
+		require.WithinDuration(t, expiredAt, payload.ExpiresAt.Time, time.Second) // expiration should be within 1 second of diff
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		maker := newBuilder(t)
+
+		token, payload, err := maker.CreateToken(CreateTokenParams{Username: "tst" + util.RandomUsername(), Duration: -1, Purpose: PurposeAccess})
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+		require.NotEmpty(t, payload)
+
+		payload, err = maker.VerifyToken(token)
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrExpiredToken)
+		require.Nil(t, payload)
+	})
+
+	t.Run("NoPurpose", func(t *testing.T) {
+		maker := newBuilder(t)
+
+		_, _, err := maker.CreateToken(CreateTokenParams{Username: "tst" + util.RandomUsername(), Duration: time.Minute})
+		require.Error(t, err)
+	})
+
+	t.Run("TamperedToken", func(t *testing.T) {
+		maker := newBuilder(t)
+
+		token, _, err := maker.CreateToken(CreateTokenParams{Username: "tst" + util.RandomUsername(), Duration: time.Minute, Purpose: PurposeAccess})
+		require.NoError(t, err)
+
+		// flip a character in the middle of the token
+		b := []byte(token)
+		i := len(b) / 2
+		if b[i] == 'A' {
+			b[i] = 'B'
+		} else {
+			b[i] = 'A'
+		}
+
+		payload, err := maker.VerifyToken(string(b))
+		require.Error(t, err)
+		require.Nil(t, payload)
+	})
+
+	t.Run("OtherKey", func(t *testing.T) {
+		token, _, err := newBuilder(t).CreateToken(CreateTokenParams{Username: "tst" + util.RandomUsername(), Duration: time.Minute, Purpose: PurposeAccess})
+		require.NoError(t, err)
+
+		payload, err := newBuilder(t).VerifyToken(token)
+		require.Error(t, err)
+		require.Nil(t, payload)
+	})
+}
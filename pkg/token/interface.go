@@ -1,10 +1,53 @@
 package token
 
-import "time"
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
 
 // This is the Token maker interface, to make it easier to switch
-// between JWT and PASETO if I decide to use it in the future
+// between JWT and PASETO
 type Builder interface {
-	CreateToken(username string, duration time.Duration) (string, *TokenPayload, error)
+	CreateToken(params CreateTokenParams) (string, *TokenPayload, error)
 	VerifyToken(token string) (*TokenPayload, error)
 }
+
+// What a token can be used for. Every token has exactly one purpose, and is rejected anywhere else.
+type Purpose string
+
+const (
+	// Authenticates API requests
+	PurposeAccess Purpose = "access"
+	// Renews the access token of a session
+	PurposeRefresh Purpose = "refresh"
+	// Confirms the email address of a user, in links sent by email
+	PurposeEmailVerification Purpose = "email-verification"
+	// Sets a new password, in links sent by email
+	PurposePasswordReset Purpose = "password-reset"
+	// Completes the second step of a login with two-factor authentication
+	PurposeChallenge Purpose = "challenge"
+)
+
+// Roles of users, which grant access to endpoints beyond the user's own data.
+// Regular users have no roles.
+const (
+	RoleAdmin = "admin"
+)
+
+// Returned by VerifyToken for expired tokens, whatever the builder
+var ErrExpiredToken = jwt.ErrTokenExpired
+
+// What goes in a new token
+type CreateTokenParams struct {
+	Username string
+	Duration time.Duration
+	Purpose  Purpose
+	// Session the token belongs to. Access tokens of a blocked session are rejected.
+	SessionID uuid.UUID
+	// Scopes restricting the token. Tokens without scopes have full access.
+	Scopes []string
+	// Roles of the user, only given to tokens of login sessions
+	Roles []string
+}
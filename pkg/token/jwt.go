@@ -2,7 +2,6 @@ package token
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -27,10 +26,10 @@ func NewJWTBuilder(secretKey string) (Builder, error) {
 }
 
 // Create a token using the symmetric signing algorithm HS256 (HMAC + SHA256). Returns the signed token string, the payload used, and possibly an error.
-func (j *JWTBuilder) CreateToken(username string, duration time.Duration) (string, *TokenPayload, error) {
+func (j *JWTBuilder) CreateToken(params CreateTokenParams) (string, *TokenPayload, error) {
 
 	// Create the payload to in include in the token
-	claims, err := NewTokenPayload(username, duration)
+	claims, err := NewTokenPayload(params)
 	if err != nil {
 		return "", nil, fmt.Errorf("cannot create a token: %s", err)
 	}
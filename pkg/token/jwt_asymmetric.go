@@ -0,0 +1,261 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Minimum size of RSA keys
+const minRSAKeyBits = 2048
+
+// A key used to sign or verify tokens, identified by the kid header of the tokens.
+// Retired keys only need the public key.
+type JWTKey struct {
+	ID         string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// AsymmetricJWTBuilder signs tokens with EdDSA (Ed25519) or RS256, depending on the type of the active key.
+// Tokens signed by other keys are still accepted during a grace period, so that keys can be rotated without
+// logging everyone out. The public keys can be published for other services to verify tokens.
+type AsymmetricJWTBuilder struct {
+	keys      map[string]JWTKey
+	activeKey JWTKey
+	// How long after their issuance tokens signed by retired keys are accepted.
+	// Should be at least the duration of the refresh tokens.
+	gracePeriod time.Duration
+}
+
+// Creates a new AsymmetricJWTBuilder, signing with the key activeKeyID.
+func NewAsymmetricJWTBuilder(keys []JWTKey, activeKeyID string, gracePeriod time.Duration) (*AsymmetricJWTBuilder, error) {
+
+	if gracePeriod < 0 {
+		return nil, fmt.Errorf("invalid grace period: must not be negative")
+	}
+
+	b := &AsymmetricJWTBuilder{
+		keys:        make(map[string]JWTKey, len(keys)),
+		gracePeriod: gracePeriod,
+	}
+	for _, k := range keys {
+		if _, err := signingMethod(k.PublicKey); err != nil {
+			return nil, fmt.Errorf("invalid key %s: %s", k.ID, err)
+		}
+		if _, exists := b.keys[k.ID]; exists {
+			return nil, fmt.Errorf("duplicate key %s", k.ID)
+		}
+		b.keys[k.ID] = k
+	}
+
+	active, exists := b.keys[activeKeyID]
+	if !exists {
+		return nil, fmt.Errorf("active key %s not found", activeKeyID)
+	}
+	if active.PrivateKey == nil {
+		return nil, fmt.Errorf("active key %s has no private key", activeKeyID)
+	}
+	b.activeKey = active
+
+	return b, nil
+}
+
+// Create a token signed by the active key. Returns the signed token string, the payload used, and possibly an error.
+func (b *AsymmetricJWTBuilder) CreateToken(params CreateTokenParams) (string, *TokenPayload, error) {
+
+	claims, err := NewTokenPayload(params)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot create a token: %s", err)
+	}
+
+	method, _ := signingMethod(b.activeKey.PublicKey)
+	unsignedToken := jwt.NewWithClaims(method, claims)
+	unsignedToken.Header["kid"] = b.activeKey.ID
+
+	signedToken, err := unsignedToken.SignedString(b.activeKey.PrivateKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot sign token: %s", err)
+	}
+
+	return signedToken, claims, nil
+}
+
+// VerifyToken checks the token against the key in its kid header
+func (b *AsymmetricJWTBuilder) VerifyToken(token string) (*TokenPayload, error) {
+
+	var key JWTKey
+	keyFunc := func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		k, exists := b.keys[kid]
+		if !exists {
+			return nil, fmt.Errorf("unknown signing key")
+		}
+		// the algorithm must be the one of the key, never what the token says
+		method, _ := signingMethod(k.PublicKey)
+		if t.Method.Alg() != method.Alg() {
+			return nil, jwt.ErrTokenUnverifiable
+		}
+		key = k
+		return k.PublicKey, nil
+	}
+
+	parsedToken, err := jwt.ParseWithClaims(token, &TokenPayload{}, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, ok := parsedToken.Claims.(*TokenPayload)
+	if !ok {
+		return nil, fmt.Errorf("invalid token: cannot convert payload")
+	}
+
+	if key.ID != b.activeKey.ID {
+		if payload.IssuedAt == nil || time.Since(payload.IssuedAt.Time) > b.gracePeriod {
+			return nil, fmt.Errorf("token is signed with a retired key")
+		}
+	}
+
+	return payload, nil
+}
+
+// A JSON Web Key Set (RFC 7517) with the public keys of the builder
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// A public key in JWK format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// Returns the public keys of the active and retired keys, sorted by key ID.
+func (b *AsymmetricJWTBuilder) JWKS() JWKS {
+
+	jwks := JWKS{Keys: []JWK{}}
+	for _, k := range b.keys {
+		method, _ := signingMethod(k.PublicKey)
+		jwk := JWK{
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: method.Alg(),
+		}
+		switch pub := k.PublicKey.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID })
+
+	return jwks
+}
+
+// Loads the keys in the PEM files of a directory. The key ID is the name of the file without the .pem extension.
+// Files contain either a PKCS #8 private key or, for retired keys, a PKIX public key.
+func LoadJWTKeys(dir string) ([]JWTKey, error) {
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no keys found in %s", dir)
+	}
+
+	keys := make([]JWTKey, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseJWTKey(strings.TrimSuffix(filepath.Base(file), ".pem"), data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load %s: %s", file, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Parses a PEM encoded PKCS #8 private key or PKIX public key.
+func ParseJWTKey(id string, data []byte) (JWTKey, error) {
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return JWTKey{}, fmt.Errorf("no PEM data found")
+	}
+
+	key := JWTKey{ID: id}
+	switch block.Type {
+	case "PRIVATE KEY":
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return JWTKey{}, err
+		}
+		signer, ok := k.(crypto.Signer)
+		if !ok {
+			return JWTKey{}, fmt.Errorf("unsupported private key")
+		}
+		key.PrivateKey = signer
+		key.PublicKey = signer.Public()
+	case "PUBLIC KEY":
+		k, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return JWTKey{}, err
+		}
+		key.PublicKey = k
+	default:
+		return JWTKey{}, fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+
+	if _, err := signingMethod(key.PublicKey); err != nil {
+		return JWTKey{}, err
+	}
+
+	return key, nil
+}
+
+// Returns the signing method of a public key: EdDSA for Ed25519 keys and RS256 for RSA keys.
+func signingMethod(pub crypto.PublicKey) (jwt.SigningMethod, error) {
+
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+		}
+		return jwt.SigningMethodRS256, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T: only Ed25519 and RSA keys are supported", pub)
+	}
+}
@@ -0,0 +1,173 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newEd25519Key(t *testing.T, id string) JWTKey {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return JWTKey{ID: id, PrivateKey: priv, PublicKey: pub}
+}
+
+func newRSAKey(t *testing.T, id string) JWTKey {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return JWTKey{ID: id, PrivateKey: priv, PublicKey: &priv.PublicKey}
+}
+
+func TestAsymmetricJWTBuilder(t *testing.T) {
+
+	testCases := []struct {
+		name string
+		key  func(t *testing.T) JWTKey
+		alg  string
+	}{
+		{
+			name: "EdDSA",
+			key:  func(t *testing.T) JWTKey { return newEd25519Key(t, "ed") },
+			alg:  "EdDSA",
+		},
+		{
+			name: "RS256",
+			key:  func(t *testing.T) JWTKey { return newRSAKey(t, "rsa") },
+			alg:  "RS256",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			testBuilder(t, func(t *testing.T) Builder {
+				key := tc.key(t)
+				maker, err := NewAsymmetricJWTBuilder([]JWTKey{key}, key.ID, time.Hour)
+				require.NoError(t, err)
+				return maker
+			})
+
+			key := tc.key(t)
+			maker, err := NewAsymmetricJWTBuilder([]JWTKey{key}, key.ID, time.Hour)
+			require.NoError(t, err)
+
+			username := "tst" + util.RandomUsername()
+			token, _, err := maker.CreateToken(CreateTokenParams{Username: username, Duration: time.Minute, Purpose: PurposeAccess})
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &TokenPayload{})
+			require.NoError(t, err)
+			require.Equal(t, tc.alg, parsed.Method.Alg())
+			require.Equal(t, key.ID, parsed.Header["kid"])
+
+			payload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+
+			jwks := maker.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			require.Equal(t, key.ID, jwks.Keys[0].KeyID)
+			require.Equal(t, tc.alg, jwks.Keys[0].Algorithm)
+		})
+	}
+}
+
+func TestAsymmetricJWTBuilderKeyRotation(t *testing.T) {
+
+	oldKey := newEd25519Key(t, "2024-01")
+	newKey := newEd25519Key(t, "2024-02")
+
+	oldMaker, err := NewAsymmetricJWTBuilder([]JWTKey{oldKey}, oldKey.ID, time.Hour)
+	require.NoError(t, err)
+	oldToken, _, err := oldMaker.CreateToken(CreateTokenParams{Username: util.RandomUsername(), Duration: time.Minute, Purpose: PurposeAccess})
+	require.NoError(t, err)
+
+	// The retired key only needs the public key
+	retiredKey := JWTKey{ID: oldKey.ID, PublicKey: oldKey.PublicKey}
+
+	// Within the grace period
+	maker, err := NewAsymmetricJWTBuilder([]JWTKey{retiredKey, newKey}, newKey.ID, time.Hour)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+	require.Len(t, maker.JWKS().Keys, 2)
+
+	// After the grace period
+	maker, err = NewAsymmetricJWTBuilder([]JWTKey{retiredKey, newKey}, newKey.ID, 0)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, err = maker.VerifyToken(oldToken)
+	require.Error(t, err)
+
+	// Removed key
+	maker, err = NewAsymmetricJWTBuilder([]JWTKey{newKey}, newKey.ID, time.Hour)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(oldToken)
+	require.Error(t, err)
+}
+
+func TestAsymmetricJWTBuilderInvalid(t *testing.T) {
+
+	key := newEd25519Key(t, "ed")
+
+	// The active key must have a private key
+	_, err := NewAsymmetricJWTBuilder([]JWTKey{{ID: key.ID, PublicKey: key.PublicKey}}, key.ID, time.Hour)
+	require.Error(t, err)
+
+	_, err = NewAsymmetricJWTBuilder([]JWTKey{key}, "unknown", time.Hour)
+	require.Error(t, err)
+
+	// Symmetric tokens are not accepted, even if signed with the public key
+	maker, err := NewAsymmetricJWTBuilder([]JWTKey{key}, key.ID, time.Hour)
+	require.NoError(t, err)
+	claims, err := NewTokenPayload(CreateTokenParams{Username: util.RandomUsername(), Duration: time.Minute, Purpose: PurposeAccess})
+	require.NoError(t, err)
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	unsigned.Header["kid"] = key.ID
+	token, err := unsigned.SignedString([]byte(key.PublicKey.(ed25519.PublicKey)))
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(token)
+	require.ErrorIs(t, err, jwt.ErrTokenUnverifiable)
+}
+
+func TestLoadJWTKeys(t *testing.T) {
+
+	dir := t.TempDir()
+
+	active := newEd25519Key(t, "active")
+	der, err := x509.MarshalPKCS8PrivateKey(active.PrivateKey)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "active.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	require.NoError(t, err)
+
+	retired := newRSAKey(t, "retired")
+	der, err = x509.MarshalPKIXPublicKey(retired.PublicKey)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "retired.pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600)
+	require.NoError(t, err)
+
+	keys, err := LoadJWTKeys(dir)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	maker, err := NewAsymmetricJWTBuilder(keys, "active", time.Hour)
+	require.NoError(t, err)
+	token, _, err := maker.CreateToken(CreateTokenParams{Username: util.RandomUsername(), Duration: time.Minute, Purpose: PurposeAccess})
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+
+	_, err = LoadJWTKeys(t.TempDir())
+	require.Error(t, err)
+}
@@ -0,0 +1,32 @@
+package token
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// Generates a random OAuth secret, i.e. an authorization code or a client secret. Returns the secret,
+// which is given to the client once, and its hash, which is the only thing stored.
+func NewOAuthSecret() (string, string, error) {
+	return NewOpaqueToken("")
+}
+
+// Returns the hash of an OAuth secret.
+func HashOAuthSecret(secret string) string {
+	return HashOpaqueToken(secret)
+}
+
+// Checks a PKCE code verifier against the S256 code challenge sent in the authorization request (RFC 7636).
+func VerifyCodeChallenge(verifier, challenge string) bool {
+
+	// RFC 7636 4.1: the verifier is between 43 and 128 characters
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	h := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(h[:])
+
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
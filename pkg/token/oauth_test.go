@@ -0,0 +1,26 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+
+	// RFC 7636 Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	require.True(t, VerifyCodeChallenge(verifier, challenge))
+	require.False(t, VerifyCodeChallenge(verifier+"x", challenge))
+	require.False(t, VerifyCodeChallenge("tooshort", challenge))
+}
+
+func TestNewOAuthSecret(t *testing.T) {
+
+	secret, hash, err := NewOAuthSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 64)
+	require.Equal(t, hash, HashOAuthSecret(secret))
+}
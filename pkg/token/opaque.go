@@ -0,0 +1,31 @@
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/guerzon/gobudget-api/pkg/util"
+)
+
+// Number of random bytes in opaque tokens
+const opaqueTokenSize = 32
+
+// Generates an opaque token, i.e. a random string which is looked up in the database instead of being verified
+// with a key. Returns the token, which is given out once, and its hash, which is the only thing stored.
+func NewOpaqueToken(prefix string) (string, string, error) {
+
+	secret, err := util.RandomSecret(opaqueTokenSize)
+	if err != nil {
+		return "", "", err
+	}
+	t := prefix + secret
+
+	return t, HashOpaqueToken(t), nil
+}
+
+// Returns the hex-encoded SHA-256 hash of an opaque token. Tokens have enough entropy that a salt
+// or a slow hash is not needed, and a plain hash allows looking them up.
+func HashOpaqueToken(t string) string {
+	h := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(h[:])
+}
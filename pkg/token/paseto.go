@@ -0,0 +1,337 @@
+package token
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	cryptorand "crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+)
+
+// PASETO v4 (https://github.com/paseto-standard/paseto-spec) headers
+const (
+	pasetoLocalHeader  = "v4.local."
+	pasetoPublicHeader = "v4.public."
+)
+
+// Size of the symmetric key of v4.local tokens
+const PasetoLocalKeySize = 32
+
+var errInvalidPaseto = fmt.Errorf("invalid token")
+
+// Tokens are decoded strictly, so that each token has a single encoding: the unused bits of the last character must be zero.
+var pasetoEncoding = base64.RawURLEncoding.Strict()
+
+// PasetoLocalBuilder builds v4.local tokens, which are encrypted and authenticated with a symmetric key.
+// Unlike JWTs, the payload cannot be read without the key.
+type PasetoLocalBuilder struct {
+	key []byte
+}
+
+// Creates a new PasetoLocalBuilder.
+func NewPasetoLocalBuilder(key []byte) (Builder, error) {
+
+	if len(key) != PasetoLocalKeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d bytes", PasetoLocalKeySize)
+	}
+
+	return &PasetoLocalBuilder{
+		key: key,
+	}, nil
+}
+
+// Create an encrypted token. Returns the token string, the payload used, and possibly an error.
+func (p *PasetoLocalBuilder) CreateToken(params CreateTokenParams) (string, *TokenPayload, error) {
+
+	claims, err := NewTokenPayload(params)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot create a token: %s", err)
+	}
+	m, err := json.Marshal(newPasetoClaims(claims))
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot create a token: %s", err)
+	}
+
+	n := make([]byte, 32)
+	if _, err := cryptorand.Read(n); err != nil {
+		return "", nil, fmt.Errorf("cannot create a token: %s", err)
+	}
+
+	// the tokens have no footer nor implicit assertion
+	token, err := pasetoEncrypt(p.key, n, m, nil, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot encrypt token: %s", err)
+	}
+
+	return token, claims, nil
+}
+
+// VerifyToken decrypts the token and checks that it is still valid
+func (p *PasetoLocalBuilder) VerifyToken(token string) (*TokenPayload, error) {
+
+	m, err := pasetoDecrypt(p.key, token, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePasetoClaims(m)
+}
+
+// Encrypts the message m of a v4.local token with the key and the random nonce n, and authenticates it with
+// the footer f and the implicit assertion i.
+func pasetoEncrypt(key []byte, n []byte, m []byte, f []byte, i []byte) (string, error) {
+
+	encKey, nonce, authKey := pasetoSplitKey(key, n)
+
+	c := make([]byte, len(m))
+	cipher, err := chacha20.NewUnauthenticatedCipher(encKey, nonce)
+	if err != nil {
+		return "", err
+	}
+	cipher.XORKeyStream(c, m)
+
+	mac, _ := blake2b.New256(authKey)
+	mac.Write(pae([]byte(pasetoLocalHeader), n, c, f, i))
+	t := mac.Sum(nil)
+
+	body := append(append(append([]byte{}, n...), c...), t...)
+
+	return pasetoToken(pasetoLocalHeader, body, f), nil
+}
+
+// Authenticates and decrypts a v4.local token, which must have the footer f and be bound to the implicit
+// assertion i. Returns the message.
+func pasetoDecrypt(key []byte, token string, f []byte, i []byte) ([]byte, error) {
+
+	body, err := pasetoBody(token, pasetoLocalHeader, f)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < 64 {
+		return nil, errInvalidPaseto
+	}
+	n, c, t := body[:32], body[32:len(body)-32], body[len(body)-32:]
+	encKey, nonce, authKey := pasetoSplitKey(key, n)
+
+	// Authenticate before decrypting
+	mac, _ := blake2b.New256(authKey)
+	mac.Write(pae([]byte(pasetoLocalHeader), n, c, f, i))
+	if subtle.ConstantTimeCompare(t, mac.Sum(nil)) != 1 {
+		return nil, errInvalidPaseto
+	}
+
+	m := make([]byte, len(c))
+	cipher, err := chacha20.NewUnauthenticatedCipher(encKey, nonce)
+	if err != nil {
+		return nil, errInvalidPaseto
+	}
+	cipher.XORKeyStream(m, c)
+
+	return m, nil
+}
+
+// Derives the encryption key, the XChaCha20 nonce and the authentication key of a token from its random nonce n.
+func pasetoSplitKey(key []byte, n []byte) ([]byte, []byte, []byte) {
+
+	h, _ := blake2b.New(56, key)
+	h.Write([]byte("paseto-encryption-key"))
+	h.Write(n)
+	tmp := h.Sum(nil)
+
+	a, _ := blake2b.New256(key)
+	a.Write([]byte("paseto-auth-key-for-aead"))
+	a.Write(n)
+
+	return tmp[:32], tmp[32:], a.Sum(nil)
+}
+
+// PasetoPublicBuilder builds v4.public tokens, which are signed with Ed25519.
+// The payload is readable by anyone, and the tokens can be verified with the public key only.
+type PasetoPublicBuilder struct {
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// Creates a new PasetoPublicBuilder.
+func NewPasetoPublicBuilder(privateKey ed25519.PrivateKey) (Builder, error) {
+
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 private key")
+	}
+
+	return &PasetoPublicBuilder{
+		privateKey: privateKey,
+		publicKey:  privateKey.Public().(ed25519.PublicKey),
+	}, nil
+}
+
+// Create a signed token. Returns the token string, the payload used, and possibly an error.
+func (p *PasetoPublicBuilder) CreateToken(params CreateTokenParams) (string, *TokenPayload, error) {
+
+	claims, err := NewTokenPayload(params)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot create a token: %s", err)
+	}
+	m, err := json.Marshal(newPasetoClaims(claims))
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot create a token: %s", err)
+	}
+
+	// the tokens have no footer nor implicit assertion
+	return pasetoSign(p.privateKey, m, nil, nil), claims, nil
+}
+
+// VerifyToken checks the signature of the token and that it is still valid
+func (p *PasetoPublicBuilder) VerifyToken(token string) (*TokenPayload, error) {
+
+	m, err := pasetoVerify(p.publicKey, token, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePasetoClaims(m)
+}
+
+// Signs the message m of a v4.public token, with the footer f and the implicit assertion i.
+func pasetoSign(privateKey ed25519.PrivateKey, m []byte, f []byte, i []byte) string {
+
+	sig := ed25519.Sign(privateKey, pae([]byte(pasetoPublicHeader), m, f, i))
+	body := append(append([]byte{}, m...), sig...)
+
+	return pasetoToken(pasetoPublicHeader, body, f)
+}
+
+// Checks the signature of a v4.public token, which must have the footer f and be bound to the implicit
+// assertion i. Returns the message.
+func pasetoVerify(publicKey ed25519.PublicKey, token string, f []byte, i []byte) ([]byte, error) {
+
+	body, err := pasetoBody(token, pasetoPublicHeader, f)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < ed25519.SignatureSize {
+		return nil, errInvalidPaseto
+	}
+	m, sig := body[:len(body)-ed25519.SignatureSize], body[len(body)-ed25519.SignatureSize:]
+
+	if !ed25519.Verify(publicKey, pae([]byte(pasetoPublicHeader), m, f, i), sig) {
+		return nil, errInvalidPaseto
+	}
+
+	return m, nil
+}
+
+// Claims as encoded in PASETO tokens, which use RFC 3339 dates instead of the numeric dates of JWTs
+type pasetoClaims struct {
+	ID        uuid.UUID `json:"jti"`
+	Username  string    `json:"sub"`
+	Purpose   Purpose   `json:"purpose"`
+	SessionID uuid.UUID `json:"session_id"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	Roles     []string  `json:"roles,omitempty"`
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+func newPasetoClaims(payload *TokenPayload) pasetoClaims {
+	return pasetoClaims{
+		ID:        payload.ID,
+		Username:  payload.Username,
+		Purpose:   payload.Purpose,
+		SessionID: payload.SessionID,
+		Scopes:    payload.Scopes,
+		Roles:     payload.Roles,
+		IssuedAt:  payload.IssuedAt.Time,
+		ExpiresAt: payload.ExpiresAt.Time,
+	}
+}
+
+// Decodes the claims of a verified token and checks that the token has not expired.
+func parsePasetoClaims(m []byte) (*TokenPayload, error) {
+
+	var claims pasetoClaims
+	if err := json.Unmarshal(m, &claims); err != nil {
+		return nil, errInvalidPaseto
+	}
+	if !time.Now().Before(claims.ExpiresAt) {
+		return nil, ErrExpiredToken
+	}
+
+	payload := &TokenPayload{
+		ID:        claims.ID,
+		Username:  claims.Username,
+		Purpose:   claims.Purpose,
+		SessionID: claims.SessionID,
+		Scopes:    claims.Scopes,
+		Roles:     claims.Roles,
+	}
+	payload.IssuedAt = jwt.NewNumericDate(claims.IssuedAt)
+	payload.ExpiresAt = jwt.NewNumericDate(claims.ExpiresAt)
+
+	return payload, nil
+}
+
+// Encodes a token, the footer is only appended when there is one
+func pasetoToken(header string, body []byte, f []byte) string {
+
+	token := header + base64.RawURLEncoding.EncodeToString(body)
+	if len(f) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(f)
+	}
+
+	return token
+}
+
+// Checks the header and the footer of a token, which must be the expected footer f, and decodes its body.
+// The builders issue tokens without footer, so they reject tokens with one.
+func pasetoBody(token string, header string, f []byte) ([]byte, error) {
+
+	if !strings.HasPrefix(token, header) {
+		return nil, errInvalidPaseto
+	}
+	encoded, footer, hasFooter := strings.Cut(strings.TrimPrefix(token, header), ".")
+	if hasFooter != (len(f) > 0) {
+		return nil, errInvalidPaseto
+	}
+	if hasFooter {
+		decoded, err := pasetoEncoding.DecodeString(footer)
+		if err != nil || subtle.ConstantTimeCompare(decoded, f) != 1 {
+			return nil, errInvalidPaseto
+		}
+	}
+	body, err := pasetoEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidPaseto
+	}
+
+	return body, nil
+}
+
+// Pre-Authentication Encoding, which makes the concatenation of the pieces unambiguous
+func pae(pieces ...[]byte) []byte {
+
+	var buf bytes.Buffer
+	le64 := func(n int) {
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, uint64(n)&(1<<63-1))
+		buf.Write(b)
+	}
+
+	le64(len(pieces))
+	for _, p := range pieces {
+		le64(len(p))
+		buf.Write(p)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,146 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guerzon/gobudget-api/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newPasetoLocalBuilder(t *testing.T) Builder {
+	key := make([]byte, PasetoLocalKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	maker, err := NewPasetoLocalBuilder(key)
+	require.NoError(t, err)
+	return maker
+}
+
+func newPasetoPublicBuilder(t *testing.T) Builder {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	maker, err := NewPasetoPublicBuilder(priv)
+	require.NoError(t, err)
+	return maker
+}
+
+func TestPasetoLocalBuilder(t *testing.T) {
+
+	testBuilder(t, newPasetoLocalBuilder)
+
+	token, _, err := newPasetoLocalBuilder(t).CreateToken(CreateTokenParams{Username: util.RandomUsername(), Duration: time.Minute, Purpose: PurposeAccess})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.local."))
+
+	_, err = NewPasetoLocalBuilder([]byte(util.RandomString(16, "")))
+	require.Error(t, err)
+}
+
+func TestPasetoPublicBuilder(t *testing.T) {
+
+	testBuilder(t, newPasetoPublicBuilder)
+
+	token, _, err := newPasetoPublicBuilder(t).CreateToken(CreateTokenParams{Username: util.RandomUsername(), Duration: time.Minute, Purpose: PurposeAccess})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+}
+
+func TestPasetoWrongVersion(t *testing.T) {
+
+	local := newPasetoLocalBuilder(t)
+	public := newPasetoPublicBuilder(t)
+
+	token, _, err := public.CreateToken(CreateTokenParams{Username: util.RandomUsername(), Duration: time.Minute, Purpose: PurposeAccess})
+	require.NoError(t, err)
+	_, err = local.VerifyToken(token)
+	require.Error(t, err)
+
+	// Tokens with a footer are never issued
+	_, err = public.VerifyToken(token + ".Zm9vdGVy")
+	require.Error(t, err)
+}
+
+// Examples of the PASETO specification
+func TestPAE(t *testing.T) {
+
+	require.Equal(t, []byte("\x00\x00\x00\x00\x00\x00\x00\x00"), pae())
+	require.Equal(t, []byte("\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"), pae([]byte("")))
+	require.Equal(t, []byte("\x01\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00test"), pae([]byte("test")))
+}
+
+// Official test vectors of the specification (https://github.com/paseto-standard/test-vectors), 4-E-* for v4.local,
+// 4-S-* for v4.public and 4-F-* for tokens which must be rejected
+func TestPasetoTestVectors(t *testing.T) {
+
+	data, err := os.ReadFile("testdata/paseto_v4.json")
+	require.NoError(t, err)
+	var vectors struct {
+		Tests []struct {
+			Name              string  `json:"name"`
+			ExpectFail        bool    `json:"expect-fail"`
+			Key               string  `json:"key"`
+			Nonce             string  `json:"nonce"`
+			PublicKey         string  `json:"public-key"`
+			SecretKey         string  `json:"secret-key"`
+			Token             string  `json:"token"`
+			Payload           *string `json:"payload"`
+			Footer            string  `json:"footer"`
+			ImplicitAssertion string  `json:"implicit-assertion"`
+		} `json:"tests"`
+	}
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	require.NotEmpty(t, vectors.Tests)
+
+	decode := func(t *testing.T, s string) []byte {
+		b, err := hex.DecodeString(s)
+		require.NoError(t, err)
+		return b
+	}
+
+	for _, v := range vectors.Tests {
+		t.Run(v.Name, func(t *testing.T) {
+			f, i := []byte(v.Footer), []byte(v.ImplicitAssertion)
+
+			if v.Key != "" {
+				key := decode(t, v.Key)
+				m, err := pasetoDecrypt(key, v.Token, f, i)
+				if v.ExpectFail {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, *v.Payload, string(m))
+
+				token, err := pasetoEncrypt(key, decode(t, v.Nonce), []byte(*v.Payload), f, i)
+				require.NoError(t, err)
+				require.Equal(t, v.Token, token)
+
+				// the footer and the implicit assertion are authenticated
+				_, err = pasetoDecrypt(key, v.Token, f, append(i, 'x'))
+				require.Error(t, err)
+				return
+			}
+
+			m, err := pasetoVerify(decode(t, v.PublicKey), v.Token, f, i)
+			if v.ExpectFail {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, *v.Payload, string(m))
+
+			// Ed25519 signatures are deterministic
+			require.Equal(t, v.Token, pasetoSign(decode(t, v.SecretKey), []byte(*v.Payload), f, i))
+
+			_, err = pasetoVerify(decode(t, v.PublicKey), v.Token, f, append(i, 'x'))
+			require.Error(t, err)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package token
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,25 +16,45 @@ type TokenPayload struct {
 	ID uuid.UUID `json:"id"`
 	// Username
 	Username string `json:"username"`
+	// What the token can be used for
+	Purpose Purpose `json:"purpose"`
+	// Session the token belongs to, uuid.Nil if none
+	SessionID uuid.UUID `json:"session_id"`
+	// Scopes restricting what the token can be used for. Empty means full access.
+	Scopes []string `json:"scopes,omitempty"`
+	// Roles of the user
+	Roles []string `json:"roles,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// NewTokenPayload is used to build a claim, adding any useful information defined in the TokenPayload struct. It takes in a username, the token duration, and a slice of roles and adds them to the claim.
-func NewTokenPayload(username string, duration time.Duration) (*TokenPayload, error) {
+// NewTokenPayload is used to build a claim, adding any useful information defined in the TokenPayload struct. It takes in the parameters of the token and adds them to the claim.
+func NewTokenPayload(params CreateTokenParams) (*TokenPayload, error) {
+
+	if params.Purpose == "" {
+		return nil, fmt.Errorf("token purpose is required")
+	}
 
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
 	}
 	tokenPayload := &TokenPayload{
-		tokenID,
-		username,
-		jwt.RegisteredClaims{
+		ID:        tokenID,
+		Username:  params.Username,
+		Purpose:   params.Purpose,
+		SessionID: params.SessionID,
+		Scopes:    params.Scopes,
+		Roles:     params.Roles,
+		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
-			Audience:  []string{"user"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(params.Duration)),
 		},
 	}
 
 	return tokenPayload, nil
 }
+
+// Reports whether the user of the token has a role.
+func (p *TokenPayload) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
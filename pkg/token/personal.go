@@ -0,0 +1,22 @@
+package token
+
+import "strings"
+
+// Personal access tokens are opaque random strings with this prefix, which makes them easy to tell apart
+// from signed tokens and to find in leaked files.
+const PersonalAccessTokenPrefix = "gbpat_"
+
+// Generates a new personal access token. Returns the token, which is shown to the user once, and its hash.
+func NewPersonalAccessToken() (string, string, error) {
+	return NewOpaqueToken(PersonalAccessTokenPrefix)
+}
+
+// Returns the hash of a personal access token, used to look it up.
+func HashPersonalAccessToken(t string) string {
+	return HashOpaqueToken(t)
+}
+
+// Reports whether a bearer token is a personal access token.
+func IsPersonalAccessToken(t string) bool {
+	return strings.HasPrefix(t, PersonalAccessTokenPrefix)
+}
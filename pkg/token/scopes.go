@@ -0,0 +1,86 @@
+package token
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Scopes that can be granted to tokens which are not issued by the login flow.
+const (
+	// Allows GET requests
+	ScopeRead = "read"
+	// Allows all requests, implies ScopeRead
+	ScopeWrite = "write"
+	// Restricts the token to a budget, e.g. budget:ea930f68-e192-407d-...
+	ScopeBudgetPrefix = "budget:"
+)
+
+// Returns the scope restricting a token to a single budget.
+func BudgetScope(budgetId uuid.UUID) string {
+	return ScopeBudgetPrefix + budgetId.String()
+}
+
+// Checks that all scopes are known and that at least read or write access is granted.
+func ValidateScopes(scopes []string) error {
+
+	access := false
+	for _, s := range scopes {
+		switch {
+		case s == ScopeRead || s == ScopeWrite:
+			access = true
+		case strings.HasPrefix(s, ScopeBudgetPrefix):
+			if _, err := uuid.Parse(strings.TrimPrefix(s, ScopeBudgetPrefix)); err != nil {
+				return fmt.Errorf("invalid budget in scope %s", s)
+			}
+		default:
+			return fmt.Errorf("unknown scope %s", s)
+		}
+	}
+	if !access {
+		return fmt.Errorf("scopes must include %s or %s", ScopeRead, ScopeWrite)
+	}
+
+	return nil
+}
+
+// Returns the budgets the payload is restricted to. An empty slice means all budgets of the user.
+func (p *TokenPayload) BudgetScopes() []uuid.UUID {
+
+	var budgets []uuid.UUID
+	for _, s := range p.Scopes {
+		if b, ok := strings.CutPrefix(s, ScopeBudgetPrefix); ok {
+			if id, err := uuid.Parse(b); err == nil {
+				budgets = append(budgets, id)
+			}
+		}
+	}
+	return budgets
+}
+
+// Reports whether the token was issued with restricted scopes, as opposed to a login session which has full access.
+func (p *TokenPayload) IsScoped() bool {
+	return len(p.Scopes) > 0
+}
+
+// Reports whether the payload scopes allow a request. Read-only requests need the read or write scope,
+// all others need the write scope. If the token is restricted to some budgets, budgetId must be one of them.
+// Pass uuid.Nil for requests that do not target a budget.
+func (p *TokenPayload) Allows(readOnly bool, budgetId uuid.UUID) bool {
+
+	if !p.IsScoped() {
+		return true
+	}
+
+	if !slices.Contains(p.Scopes, ScopeWrite) && !(readOnly && slices.Contains(p.Scopes, ScopeRead)) {
+		return false
+	}
+
+	if budgets := p.BudgetScopes(); len(budgets) > 0 {
+		return slices.Contains(budgets, budgetId)
+	}
+
+	return true
+}
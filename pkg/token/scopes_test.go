@@ -0,0 +1,49 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateScopes(t *testing.T) {
+
+	require.NoError(t, ValidateScopes([]string{ScopeRead}))
+	require.NoError(t, ValidateScopes([]string{ScopeWrite, BudgetScope(uuid.New())}))
+
+	require.Error(t, ValidateScopes([]string{BudgetScope(uuid.New())}))
+	require.Error(t, ValidateScopes([]string{ScopeRead, "budget:not-a-uuid"}))
+	require.Error(t, ValidateScopes([]string{ScopeRead, "admin"}))
+}
+
+func TestPayloadAllows(t *testing.T) {
+
+	budgetId := uuid.New()
+
+	session := TokenPayload{}
+	require.True(t, session.Allows(false, budgetId))
+
+	read := TokenPayload{Scopes: []string{ScopeRead}}
+	require.True(t, read.Allows(true, budgetId))
+	require.False(t, read.Allows(false, budgetId))
+
+	write := TokenPayload{Scopes: []string{ScopeWrite, BudgetScope(budgetId)}}
+	require.True(t, write.Allows(true, budgetId))
+	require.True(t, write.Allows(false, budgetId))
+	require.False(t, write.Allows(true, uuid.New()))
+	require.False(t, write.Allows(true, uuid.Nil))
+}
+
+func TestPersonalAccessToken(t *testing.T) {
+
+	pat, hash, err := NewPersonalAccessToken()
+	require.NoError(t, err)
+	require.True(t, IsPersonalAccessToken(pat))
+	require.Equal(t, hash, HashPersonalAccessToken(pat))
+	require.NotContains(t, hash, pat)
+
+	other, _, err := NewPersonalAccessToken()
+	require.NoError(t, err)
+	require.NotEqual(t, pat, other)
+}
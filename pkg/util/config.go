@@ -7,21 +7,44 @@ import (
 )
 
 type Config struct {
-	DBConnString         string        `mapstructure:"DB_CONNSTRING"`
-	DBMigrationFiles     string        `mapstructure:"DB_MIGRATION_FILES"`
-	AppURL               string        `mapstructure:"APP_URL"`
-	ListenAddr           string        `mapstructure:"LISTEN_ADDR"`
-	ListenPort           string        `mapstructure:"LISTEN_PORT"`
-	Environment          string        `mapstructure:"ENVIRONMENT"`
-	SecretKey            string        `mapstructure:"SECRET_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	RedisAddress         string        `mapstructure:"REDIS_ADDRESS"`
-	EmailSenderName      string        `mapstructure:"EMAIL_SENDER_NAME"`
-	GmailSenderAddress   string        `mapstructure:"GMAIL_SENDER_ADDRESS"`
-	GmailSenderPassword  string        `mapstructure:"GMAIL_SENDER_PASSWORD"`
-	MailhogHost          string        `mapstructure:"MAILHOG_HOST"`
-	MailhogSenderAddress string        `mapstructure:"MAILHOG_SENDER_ADDRESS"`
+	DBConnString           string        `mapstructure:"DB_CONNSTRING"`
+	DBMigrationFiles       string        `mapstructure:"DB_MIGRATION_FILES"`
+	AppURL                 string        `mapstructure:"APP_URL"`
+	ListenAddr             string        `mapstructure:"LISTEN_ADDR"`
+	ListenPort             string        `mapstructure:"LISTEN_PORT"`
+	Environment            string        `mapstructure:"ENVIRONMENT"`
+	SecretKey              string        `mapstructure:"SECRET_KEY"`
+	TokenType              string        `mapstructure:"TOKEN_TYPE"`
+	TokenKeysDir           string        `mapstructure:"TOKEN_KEYS_DIR"`
+	TokenActiveKeyID       string        `mapstructure:"TOKEN_ACTIVE_KEY_ID"`
+	TokenKeyGracePeriod    time.Duration `mapstructure:"TOKEN_KEY_GRACE_PERIOD"`
+	AccessTokenDuration    time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration   time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	RedisAddress           string        `mapstructure:"REDIS_ADDRESS"`
+	LoginLockoutThreshold  int           `mapstructure:"LOGIN_LOCKOUT_THRESHOLD"`
+	LoginLockoutDuration   time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	Argon2Memory           uint32        `mapstructure:"ARGON2_MEMORY"`
+	Argon2Iterations       uint32        `mapstructure:"ARGON2_ITERATIONS"`
+	Argon2Parallelism      uint8         `mapstructure:"ARGON2_PARALLELISM"`
+	PasswordMinLength      int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength      int           `mapstructure:"PASSWORD_MAX_LENGTH"`
+	PasswordMinStrength    int           `mapstructure:"PASSWORD_MIN_STRENGTH"`
+	PasswordBreachedFile   string        `mapstructure:"PASSWORD_BREACHED_FILE"`
+	RateLimitPublic        string        `mapstructure:"RATE_LIMIT_PUBLIC"`
+	RateLimitAuthenticated string        `mapstructure:"RATE_LIMIT_AUTHENTICATED"`
+	RateLimitExpensive     string        `mapstructure:"RATE_LIMIT_EXPENSIVE"`
+	OIDCIssuerURL          string        `mapstructure:"OIDC_ISSUER_URL"`
+	OIDCClientID           string        `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret       string        `mapstructure:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL        string        `mapstructure:"OIDC_REDIRECT_URL"`
+	OIDCScopes             string        `mapstructure:"OIDC_SCOPES"`
+	OIDCAllowSignup        bool          `mapstructure:"OIDC_ALLOW_SIGNUP"`
+	AdminBootstrapUsername string        `mapstructure:"ADMIN_BOOTSTRAP_USERNAME"`
+	EmailSenderName        string        `mapstructure:"EMAIL_SENDER_NAME"`
+	GmailSenderAddress     string        `mapstructure:"GMAIL_SENDER_ADDRESS"`
+	GmailSenderPassword    string        `mapstructure:"GMAIL_SENDER_PASSWORD"`
+	MailhogHost            string        `mapstructure:"MAILHOG_HOST"`
+	MailhogSenderAddress   string        `mapstructure:"MAILHOG_SENDER_ADDRESS"`
 }
 
 // viper loads values etiher from app.env or from environment variables
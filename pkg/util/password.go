@@ -1,23 +1,164 @@
 package util
 
 import (
+	cryptorand "crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// Returns the bcrypt hash of plain
+// Returned by CheckPassword when the password does not match the hash
+var ErrPasswordMismatch = errors.New("password does not match")
+
+// Parameters of Argon2id. Hashes keep the parameters they were created with, so they can be changed at any time:
+// existing hashes are upgraded the next time their user logs in.
+type Argon2Params struct {
+	// Memory in KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// The OWASP recommendation of 19 MiB of memory, 2 iterations and 1 degree of parallelism
+var DefaultArgon2Params = Argon2Params{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+var (
+	argon2ParamsMu sync.RWMutex
+	argon2Params   = DefaultArgon2Params
+)
+
+// Sets the parameters of new hashes. Parameters left at zero take their default value.
+func SetArgon2Params(p Argon2Params) {
+
+	if p.Memory == 0 {
+		p.Memory = DefaultArgon2Params.Memory
+	}
+	if p.Iterations == 0 {
+		p.Iterations = DefaultArgon2Params.Iterations
+	}
+	if p.Parallelism == 0 {
+		p.Parallelism = DefaultArgon2Params.Parallelism
+	}
+	if p.SaltLength == 0 {
+		p.SaltLength = DefaultArgon2Params.SaltLength
+	}
+	if p.KeyLength == 0 {
+		p.KeyLength = DefaultArgon2Params.KeyLength
+	}
+
+	argon2ParamsMu.Lock()
+	defer argon2ParamsMu.Unlock()
+	argon2Params = p
+}
+
+func currentArgon2Params() Argon2Params {
+	argon2ParamsMu.RLock()
+	defer argon2ParamsMu.RUnlock()
+	return argon2Params
+}
+
+const argon2idPrefix = "$argon2id$"
+
+// Returns the Argon2id hash of plain in the PHC string format, e.g.
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
 func HashPassword(plain string) (string, error) {
 
-	hashBytes, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
-	if err != nil {
-		return "", fmt.Errorf("failed to hash password %s: %s", plain, err)
+	p := currentArgon2Params()
+	salt := make([]byte, p.SaltLength)
+	if _, err := cryptorand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash password: %s", err)
 	}
+	key := argon2.IDKey([]byte(plain), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
 
-	return string(hashBytes), nil
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
 }
 
 // Compares a password hash with a plain string. Returns nil on success, or an error on failure.
+// Both Argon2id hashes and the bcrypt hashes of older versions are accepted.
 func CheckPassword(hashedPassword string, plain string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plain))
+
+	if !strings.HasPrefix(hashedPassword, argon2idPrefix) {
+		err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plain))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrPasswordMismatch
+		}
+		return err
+	}
+
+	p, salt, key, err := parseArgon2Hash(hashedPassword)
+	if err != nil {
+		return err
+	}
+	other := argon2.IDKey([]byte(plain), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrPasswordMismatch
+	}
+
+	return nil
+}
+
+// Reports whether a hash should be replaced after a successful login, i.e. it is not an Argon2id hash
+// with the current parameters.
+func NeedsRehash(hashedPassword string) bool {
+
+	p, salt, key, err := parseArgon2Hash(hashedPassword)
+	if err != nil {
+		return true
+	}
+	current := currentArgon2Params()
+
+	return p.Memory != current.Memory || p.Iterations != current.Iterations || p.Parallelism != current.Parallelism ||
+		uint32(len(salt)) != current.SaltLength || uint32(len(key)) != current.KeyLength
+}
+
+// Parses an Argon2id hash in the PHC string format. Returns the parameters, the salt and the key.
+func parseArgon2Hash(hashedPassword string) (Argon2Params, []byte, []byte, error) {
+
+	invalid := errors.New("invalid Argon2id hash")
+
+	// "", "argon2id", version, parameters, salt, key
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2Params{}, nil, nil, invalid
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, invalid
+	}
+	var p Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, invalid
+	}
+	if p.Memory == 0 || p.Iterations == 0 || p.Parallelism == 0 {
+		return Argon2Params{}, nil, nil, invalid
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(salt) == 0 {
+		return Argon2Params{}, nil, nil, invalid
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2Params{}, nil, nil, invalid
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+
+	return p, salt, key, nil
 }
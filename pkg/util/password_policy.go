@@ -0,0 +1,283 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules of the password policy
+const (
+	PasswordRuleMinLength = "min_length"
+	PasswordRuleMaxLength = "max_length"
+	PasswordRuleStrength  = "strength"
+	PasswordRuleBreached  = "breached"
+)
+
+// Defaults of PasswordPolicy
+const (
+	DefaultPasswordMinLength   = 10
+	DefaultPasswordMaxLength   = 128
+	DefaultPasswordMinStrength = 2
+)
+
+// A rule of the password policy which a password fails
+type PasswordViolation struct {
+	Rule    string `json:"rule" example:"min_length"`
+	Message string `json:"message" example:"must be at least 10 characters long"`
+} //@name PasswordViolation
+
+// PasswordPolicy decides which passwords users can choose
+type PasswordPolicy struct {
+	// Lengths in characters
+	MinLength int
+	MaxLength int
+	// Minimum score of PasswordStrength
+	MinStrength int
+	// Passwords known from data breaches, or nil to not check them
+	Breached *BreachedPasswords
+}
+
+func (p PasswordPolicy) withDefaults() PasswordPolicy {
+	if p.MinLength <= 0 {
+		p.MinLength = DefaultPasswordMinLength
+	}
+	if p.MaxLength <= 0 {
+		p.MaxLength = DefaultPasswordMaxLength
+	}
+	if p.MinStrength <= 0 {
+		p.MinStrength = DefaultPasswordMinStrength
+	}
+	return p
+}
+
+// Checks a password against the policy. The user inputs, e.g. the username and the email, make passwords that
+// contain them weaker. Returns the rules the password fails, none if it is accepted.
+func (p PasswordPolicy) Check(password string, userInputs ...string) []PasswordViolation {
+
+	p = p.withDefaults()
+	violations := []PasswordViolation{}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, PasswordViolation{
+			Rule:    PasswordRuleMinLength,
+			Message: fmt.Sprintf("must be at least %d characters long", p.MinLength),
+		})
+	}
+	if length > p.MaxLength {
+		violations = append(violations, PasswordViolation{
+			Rule:    PasswordRuleMaxLength,
+			Message: fmt.Sprintf("must be at most %d characters long", p.MaxLength),
+		})
+	}
+	if PasswordStrength(password, userInputs...) < p.MinStrength {
+		violations = append(violations, PasswordViolation{
+			Rule:    PasswordRuleStrength,
+			Message: "is too easy to guess: avoid repeated characters, sequences and personal information, or make it longer",
+		})
+	}
+	if p.Breached != nil && p.Breached.Contains(password) {
+		violations = append(violations, PasswordViolation{
+			Rule:    PasswordRuleBreached,
+			Message: "has appeared in a data breach and must not be used",
+		})
+	}
+
+	return violations
+}
+
+// Estimates the strength of a password, from 0 (too guessable) to 4 (very unguessable), like the scores of zxcvbn.
+// The estimate is based on the entropy of the characters, with repeated characters, sequences like abc or 321,
+// and the user inputs counting as much as a single character.
+func PasswordStrength(password string, userInputs ...string) int {
+
+	bits := passwordEntropy(password, userInputs)
+	switch {
+	case bits < 30:
+		return 0
+	case bits < 40:
+		return 1
+	case bits < 50:
+		return 2
+	case bits < 65:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// Bits of entropy of a password, see PasswordStrength
+func passwordEntropy(password string, userInputs []string) float64 {
+
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	// size of the alphabet the characters seem to be taken from
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < utf8.RuneSelf:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	alphabet := 0
+	for _, c := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if c.used {
+			alphabet += c.size
+		}
+	}
+	perCharacter := math.Log2(float64(alphabet))
+
+	// characters covered by the user inputs
+	covered := make([]bool, len(runes))
+	bits := 0.0
+	lowered := []rune(strings.ToLower(password))
+	if len(lowered) != len(runes) {
+		lowered = runes
+	}
+	for _, input := range userInputs {
+		for _, part := range []string{input, strings.Split(input, "@")[0]} {
+			in := []rune(strings.ToLower(part))
+			if len(in) < 3 {
+				continue
+			}
+			for i := 0; i+len(in) <= len(lowered); i++ {
+				if string(lowered[i:i+len(in)]) == string(in) && !covered[i] {
+					for j := i; j < i+len(in); j++ {
+						covered[j] = true
+					}
+					bits += perCharacter
+				}
+			}
+		}
+	}
+
+	// runs of repeated characters (aaa) and sequences (abc, 321) of at least 3 characters
+	for i := 0; i < len(runes); {
+		if covered[i] {
+			i++
+			continue
+		}
+		n := 1
+		if i+1 < len(runes) && !covered[i+1] {
+			if d := unicode.ToLower(runes[i+1]) - unicode.ToLower(runes[i]); d >= -1 && d <= 1 {
+				n = 2
+				for i+n < len(runes) && !covered[i+n] && unicode.ToLower(runes[i+n])-unicode.ToLower(runes[i+n-1]) == d {
+					n++
+				}
+			}
+		}
+		if n >= 3 {
+			bits += perCharacter + math.Log2(float64(n))
+			i += n
+		} else {
+			bits += perCharacter
+			i++
+		}
+	}
+
+	return bits
+}
+
+// BreachedPasswords is a corpus of the SHA-1 hashes of passwords known from data breaches, e.g. from
+// Have I Been Pwned. Like its k-anonymity range API, the hashes are indexed by their first 5 hex characters
+// (20 bits), and only the hashes with the same prefix are searched.
+type BreachedPasswords struct {
+	// sorted hashes
+	hashes [][sha1.Size]byte
+	// the hashes of prefix p are hashes[offsets[p]:offsets[p+1]]
+	offsets []uint32
+}
+
+const breachedPrefixBits = 20
+
+// Loads a corpus of breached passwords from a file with one hex-encoded SHA-1 hash per line, optionally followed
+// by a colon and the number of times it was seen, e.g. the files of the Have I Been Pwned password downloader.
+func LoadBreachedPasswords(path string) (*BreachedPasswords, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hashes := [][sha1.Size]byte{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		hexHash, _, _ := strings.Cut(text, ":")
+		var h [sha1.Size]byte
+		if n, err := hex.Decode(h[:], []byte(hexHash)); err != nil || n != sha1.Size || len(hexHash) != 2*sha1.Size {
+			return nil, fmt.Errorf("invalid SHA-1 hash on line %d of %s", line, path)
+		}
+		hashes = append(hashes, h)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return newBreachedPasswords(hashes), nil
+}
+
+func newBreachedPasswords(hashes [][sha1.Size]byte) *BreachedPasswords {
+
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+
+	offsets := make([]uint32, 1<<breachedPrefixBits+1)
+	for _, h := range hashes {
+		offsets[breachedPrefix(h)+1]++
+	}
+	for p := 1; p < len(offsets); p++ {
+		offsets[p] += offsets[p-1]
+	}
+
+	return &BreachedPasswords{
+		hashes:  hashes,
+		offsets: offsets,
+	}
+}
+
+func breachedPrefix(h [sha1.Size]byte) uint32 {
+	return uint32(h[0])<<12 | uint32(h[1])<<4 | uint32(h[2])>>4
+}
+
+// Number of passwords in the corpus
+func (b *BreachedPasswords) Len() int {
+	return len(b.hashes)
+}
+
+// Reports whether a password is in the corpus
+func (b *BreachedPasswords) Contains(password string) bool {
+
+	h := sha1.Sum([]byte(password))
+	p := breachedPrefix(h)
+	candidates := b.hashes[b.offsets[p]:b.offsets[p+1]]
+	i := sort.Search(len(candidates), func(i int) bool { return bytes.Compare(candidates[i][:], h[:]) >= 0 })
+
+	return i < len(candidates) && candidates[i] == h
+}
@@ -0,0 +1,116 @@
+package util
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordStrength(t *testing.T) {
+
+	testCases := []struct {
+		password   string
+		userInputs []string
+		maxScore   int
+		minScore   int
+	}{
+		{password: "", maxScore: 0},
+		{password: "aaaaaaaaaaaaaaaa", maxScore: 0},
+		{password: "1234567890", maxScore: 0},
+		{password: "abcdefghijklmnop", maxScore: 0},
+		{password: "qwerty", maxScore: 1},
+		{password: "password123456", minScore: 2, maxScore: 2},
+		{password: "charlesleclerc2024", userInputs: []string{"charlesleclerc@gmail.com"}, maxScore: 1},
+		{password: "charlesleclerc2024", minScore: 3, maxScore: 4},
+		{password: "Ch@ngem333Pleaseeeee", minScore: 4, maxScore: 4},
+		{password: "correct horse battery staple", minScore: 4, maxScore: 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.password, func(t *testing.T) {
+			score := PasswordStrength(tc.password, tc.userInputs...)
+			require.GreaterOrEqual(t, score, tc.minScore)
+			require.LessOrEqual(t, score, tc.maxScore)
+		})
+	}
+}
+
+func writeBreachedPasswords(t *testing.T, passwords ...string) string {
+
+	var sb strings.Builder
+	for i, p := range passwords {
+		h := sha1.Sum([]byte(p))
+		fmt.Fprintf(&sb, "%s:%d\n", strings.ToUpper(hex.EncodeToString(h[:])), i+1)
+	}
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, []byte(sb.String()), 0600))
+
+	return path
+}
+
+func TestBreachedPasswords(t *testing.T) {
+
+	breached := []string{"password123456", "iloveyou12345", "Summer2024!!", "correct horse battery staple"}
+	b, err := LoadBreachedPasswords(writeBreachedPasswords(t, breached...))
+	require.NoError(t, err)
+	require.Equal(t, len(breached), b.Len())
+
+	for _, p := range breached {
+		require.True(t, b.Contains(p), p)
+	}
+	require.False(t, b.Contains("Password123456"))
+	require.False(t, b.Contains(RandomPassword()))
+
+	// invalid files
+	for _, content := range []string{"nothex\n", "ABCDEF:1\n", strings.Repeat("A", 41) + "\n"} {
+		path := filepath.Join(t.TempDir(), "breached.txt")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		_, err := LoadBreachedPasswords(path)
+		require.Error(t, err)
+	}
+	_, err = LoadBreachedPasswords(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+}
+
+func TestPasswordPolicy(t *testing.T) {
+
+	b, err := LoadBreachedPasswords(writeBreachedPasswords(t, "correct horse battery staple"))
+	require.NoError(t, err)
+	policy := PasswordPolicy{MinLength: 12, MaxLength: 40, Breached: b}
+
+	testCases := []struct {
+		name       string
+		password   string
+		userInputs []string
+		rules      []string
+	}{
+		{name: "OK", password: "Ch@ngem333Pleaseeeee", rules: []string{}},
+		{name: "TooShort", password: "xK9#mQ2$vL", rules: []string{PasswordRuleMinLength}},
+		{name: "TooLong", password: RandomString(41, letters+numbers+specialCharacters), rules: []string{PasswordRuleMaxLength}},
+		{name: "Weak", password: "aaaaaaaaaaaaaaaa", rules: []string{PasswordRuleStrength}},
+		{name: "ShortAndWeak", password: "aaaa", rules: []string{PasswordRuleMinLength, PasswordRuleStrength}},
+		{name: "UserInput", password: "charlesleclerc1", userInputs: []string{"charlesleclerc"}, rules: []string{PasswordRuleStrength}},
+		{name: "Breached", password: "correct horse battery staple", rules: []string{PasswordRuleBreached}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := policy.Check(tc.password, tc.userInputs...)
+			rules := []string{}
+			for _, v := range violations {
+				require.NotEmpty(t, v.Message)
+				rules = append(rules, v.Rule)
+			}
+			require.Equal(t, tc.rules, rules)
+		})
+	}
+
+	// without a corpus, breached passwords are not checked
+	require.Empty(t, PasswordPolicy{}.Check("correct horse battery staple"))
+}
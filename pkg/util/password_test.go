@@ -1,6 +1,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -14,11 +15,76 @@ func TestPassword(t *testing.T) {
 	hashedPassword, err := HashPassword(password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword)
+	require.True(t, strings.HasPrefix(hashedPassword, "$argon2id$v=19$m=19456,t=2,p=1$"))
+	require.False(t, NeedsRehash(hashedPassword))
 
 	err = CheckPassword(hashedPassword, password)
 	require.NoError(t, err)
 
 	wrongPassword := RandomPassword()
 	err = CheckPassword(hashedPassword, wrongPassword)
-	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+	require.ErrorIs(t, err, ErrPasswordMismatch)
+
+	// salted, so hashing twice gives different hashes
+	otherHash, err := HashPassword(password)
+	require.NoError(t, err)
+	require.NotEqual(t, hashedPassword, otherHash)
+}
+
+func TestPasswordLongerThanBcrypt(t *testing.T) {
+
+	// bcrypt only uses the first 72 bytes
+	prefix := RandomString(72, "")
+	hashedPassword, err := HashPassword(prefix + "a")
+	require.NoError(t, err)
+
+	require.NoError(t, CheckPassword(hashedPassword, prefix+"a"))
+	require.ErrorIs(t, CheckPassword(hashedPassword, prefix+"b"), ErrPasswordMismatch)
+}
+
+func TestPasswordBcrypt(t *testing.T) {
+
+	password := RandomPassword()
+	hashBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	hashedPassword := string(hashBytes)
+
+	require.NoError(t, CheckPassword(hashedPassword, password))
+	require.ErrorIs(t, CheckPassword(hashedPassword, RandomPassword()), ErrPasswordMismatch)
+	require.True(t, NeedsRehash(hashedPassword))
+}
+
+func TestPasswordArgon2Params(t *testing.T) {
+
+	defer SetArgon2Params(DefaultArgon2Params)
+
+	password := RandomPassword()
+	oldHash, err := HashPassword(password)
+	require.NoError(t, err)
+
+	SetArgon2Params(Argon2Params{Memory: 8 * 1024, Iterations: 3})
+	newHash, err := HashPassword(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(newHash, "$argon2id$v=19$m=8192,t=3,p=1$"))
+
+	// hashes with other parameters still work, but should be upgraded
+	require.NoError(t, CheckPassword(oldHash, password))
+	require.True(t, NeedsRehash(oldHash))
+	require.NoError(t, CheckPassword(newHash, password))
+	require.False(t, NeedsRehash(newHash))
+}
+
+func TestPasswordInvalidHash(t *testing.T) {
+
+	for _, hash := range []string{
+		"",
+		"plain",
+		"$argon2id$v=19$m=19456,t=2,p=1$c2FsdA",
+		"$argon2id$v=16$m=19456,t=2,p=1$c2FsdHNhbHRzYWx0$a2V5",
+		"$argon2id$v=19$m=0,t=2,p=1$c2FsdHNhbHRzYWx0$a2V5",
+		"$argon2id$v=19$m=19456,t=2,p=1$!!!$a2V5",
+	} {
+		require.Error(t, CheckPassword(hash, "password"), hash)
+		require.True(t, NeedsRehash(hash), hash)
+	}
 }
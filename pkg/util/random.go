@@ -1,6 +1,8 @@
 package util
 
 import (
+	cryptorand "crypto/rand"
+	"encoding/hex"
 	"math/rand"
 	"strings"
 	"time"
@@ -46,3 +48,12 @@ func RandomUsername() string {
 func RandomPassword() string {
 	return RandomString(20, letters+specialCharacters+numbers)
 }
+
+// Return a cryptographically secure random string made of n random bytes, hex-encoded. Use this for secrets and tokens.
+func RandomSecret(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
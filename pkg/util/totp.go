@@ -0,0 +1,134 @@
+package util
+
+import (
+	"crypto/hmac"
+	cryptorand "crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters (RFC 6238). These are the defaults of authenticator apps, which often ignore other values.
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	// Number of periods before and after the current one that are accepted, to allow for clock drift
+	totpSkew = 1
+	// 160 bits, the size recommended by RFC 4226 for HMAC-SHA1
+	totpSecretSize = 20
+)
+
+// Characters used in recovery codes, without the ones easily confused with each other.
+// There are 32 of them so that random bytes map to them without bias.
+const recoveryCodeCharacters = "abcdefghjkmnpqrstuvwxyz123456789"
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Generates a new base32-encoded TOTP secret.
+func NewTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretSize)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// Returns the otpauth URI of a TOTP secret, which authenticator apps read from a QR code.
+func TOTPURI(issuer string, account string, secret string) string {
+
+	label := url.PathEscape(issuer + ":" + account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Returns the TOTP code of a secret at time t.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	return totpCode(secret, t.Unix()/totpPeriod)
+}
+
+// Reports whether code is the TOTP code of secret at time t, give or take the allowed clock skew.
+func ValidateTOTP(secret string, code string, t time.Time) bool {
+	_, ok := MatchTOTP(secret, code, t)
+	return ok
+}
+
+// Returns the time step of which code is the TOTP code of secret, within the allowed clock skew of time t. Steps only
+// grow, so that a code can be accepted once by remembering the step of the last one.
+func MatchTOTP(secret string, code string, t time.Time) (int64, bool) {
+
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	counter := t.Unix() / totpPeriod
+	for i := int64(-totpSkew); i <= totpSkew; i++ {
+		expected, err := totpCode(secret, counter+i)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return counter + i, true
+		}
+	}
+
+	return 0, false
+}
+
+// HOTP (RFC 4226) with the counter derived from the time.
+func totpCode(secret string, counter int64) (string, error) {
+
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %s", err)
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, value%mod), nil
+}
+
+// Generates n one-time recovery codes in the format xxxxx-xxxxx.
+func NewRecoveryCodes(n int) ([]string, error) {
+
+	codes := make([]string, n)
+	b := make([]byte, 10)
+	for i := range codes {
+		if _, err := cryptorand.Read(b); err != nil {
+			return nil, err
+		}
+		var sb strings.Builder
+		for j := range b {
+			if j == 5 {
+				sb.WriteByte('-')
+			}
+			sb.WriteByte(recoveryCodeCharacters[int(b[j])%len(recoveryCodeCharacters)])
+		}
+		codes[i] = sb.String()
+	}
+
+	return codes, nil
+}
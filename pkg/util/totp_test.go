@@ -0,0 +1,82 @@
+package util
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPCode(t *testing.T) {
+
+	// RFC 6238 Appendix B, SHA1 test vectors truncated to 6 digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for ts, expected := range vectors {
+		code, err := TOTPCode(secret, time.Unix(ts, 0))
+		require.NoError(t, err)
+		require.Equal(t, expected, code)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+
+	secret, err := NewTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := TOTPCode(secret, now)
+	require.NoError(t, err)
+
+	require.True(t, ValidateTOTP(secret, code, now))
+	require.True(t, ValidateTOTP(secret, code, now.Add(30*time.Second)))
+	require.False(t, ValidateTOTP(secret, code, now.Add(5*time.Minute)))
+	require.False(t, ValidateTOTP(secret, "12345", now))
+	require.False(t, ValidateTOTP("not base32!", code, now))
+}
+
+func TestMatchTOTP(t *testing.T) {
+
+	secret, err := NewTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	code, err := TOTPCode(secret, now)
+	require.NoError(t, err)
+
+	step, ok := MatchTOTP(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, now.Unix()/30, step)
+
+	// the same code a step later is still the step of its time
+	step, ok = MatchTOTP(secret, code, now.Add(30*time.Second))
+	require.True(t, ok)
+	require.Equal(t, now.Unix()/30, step)
+}
+
+func TestTOTPURI(t *testing.T) {
+
+	uri := TOTPURI("gobudget", "charles", "JBSWY3DPEHPK3PXP")
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/gobudget:charles?"))
+	require.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	require.Contains(t, uri, "issuer=gobudget")
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+
+	codes, err := NewRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+	for _, c := range codes {
+		require.Regexp(t, "^[a-z1-9]{5}-[a-z1-9]{5}$", c)
+	}
+	require.NotEqual(t, codes[0], codes[1])
+}
@@ -11,6 +11,7 @@ import (
 // Generic interface for a task distributor.
 type TaskDistributor interface {
 	DistributeSendEmail(ctx context.Context, payload *SendEmailPayload, emailTask string) error
+	DistributeDeliverWebhook(ctx context.Context, payload *DeliverWebhookPayload) error
 }
 
 // Implements the TaskDistributor interface
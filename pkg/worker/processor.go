@@ -2,6 +2,8 @@ package worker
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	"github.com/guerzon/gobudget-api/pkg/db"
 	"github.com/guerzon/gobudget-api/pkg/util"
@@ -20,6 +22,13 @@ type TaskProcessor interface {
 	Start() error
 	ProcessSendVerifyEmail(ctx context.Context, task *asynq.Task) error
 	ProcessSendAccountDeletedEmail(ctx context.Context, task *asynq.Task) error
+	ProcessSendPasswordResetEmail(ctx context.Context, task *asynq.Task) error
+	ProcessSendRefreshTokenReuseEmail(ctx context.Context, task *asynq.Task) error
+	ProcessSendAccountLockedEmail(ctx context.Context, task *asynq.Task) error
+	ProcessSendMagicLinkEmail(ctx context.Context, task *asynq.Task) error
+	ProcessSendEmailChangeEmail(ctx context.Context, task *asynq.Task) error
+	ProcessSendNewDeviceLoginEmail(ctx context.Context, task *asynq.Task) error
+	ProcessDeliverWebhook(ctx context.Context, task *asynq.Task) error
 }
 
 // Implements the TaskProcessor interface
@@ -28,6 +37,8 @@ type RedisTaskProcessor struct {
 	server *asynq.Server
 	store  db.Store
 	mailer util.EmailSender
+	// the HTTP client used to deliver webhooks
+	client *http.Client
 }
 
 // Creates a new Redis task processor.
@@ -38,12 +49,14 @@ func NewRedisTaskProcessor(redisOpts asynq.RedisClientOpt, store db.Store, maile
 			QueueDefault:  5,
 			QueueCritical: 10,
 		},
+		RetryDelayFunc: retryDelay,
 	})
 
 	return &RedisTaskProcessor{
 		server: server,
 		store:  store,
 		mailer: mailer,
+		client: &http.Client{Timeout: webhookTimeout},
 	}
 }
 
@@ -54,6 +67,22 @@ func (p *RedisTaskProcessor) Start() error {
 	// Register tasks here
 	mux.HandleFunc(TaskSendVerifyEmail, p.ProcessSendVerifyEmail)
 	mux.HandleFunc(TaskSendAccountDeletedEmail, p.ProcessSendAccountDeletedEmail)
+	mux.HandleFunc(TaskSendPasswordResetEmail, p.ProcessSendPasswordResetEmail)
+	mux.HandleFunc(TaskSendRefreshTokenReuseEmail, p.ProcessSendRefreshTokenReuseEmail)
+	mux.HandleFunc(TaskSendAccountLockedEmail, p.ProcessSendAccountLockedEmail)
+	mux.HandleFunc(TaskSendMagicLinkEmail, p.ProcessSendMagicLinkEmail)
+	mux.HandleFunc(TaskSendEmailChangeEmail, p.ProcessSendEmailChangeEmail)
+	mux.HandleFunc(TaskSendNewDeviceLoginEmail, p.ProcessSendNewDeviceLoginEmail)
+	mux.HandleFunc(TaskDeliverWebhook, p.ProcessDeliverWebhook)
 
 	return p.server.Start(mux)
 }
+
+// Returns how long to wait before retrying a failed task. Webhook deliveries back off exponentially,
+// everything else uses the asynq default.
+func retryDelay(n int, err error, task *asynq.Task) time.Duration {
+	if task.Type() == TaskDeliverWebhook {
+		return webhookRetryDelay(n)
+	}
+	return asynq.DefaultRetryDelayFunc(n, err, task)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 )
 
@@ -13,10 +14,20 @@ import (
 type SendEmailPayload struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	// Hash of the nonce of the browser that asked for a magic link
+	NonceHash string `json:"nonce_hash,omitempty"`
+	// Session of a login from a new device
+	SessionID uuid.UUID `json:"session_id"`
 }
 
 const TaskSendVerifyEmail = "task:send_verify_email"
 const TaskSendAccountDeletedEmail = "task:send_account_deleted_email"
+const TaskSendPasswordResetEmail = "task:send_password_reset_email"
+const TaskSendRefreshTokenReuseEmail = "task:send_refresh_token_reuse_email"
+const TaskSendAccountLockedEmail = "task:send_account_locked_email"
+const TaskSendMagicLinkEmail = "task:send_magic_link_email"
+const TaskSendEmailChangeEmail = "task:send_email_change_email"
+const TaskSendNewDeviceLoginEmail = "task:send_new_device_login_email"
 
 // DistributeSendEmail implements the TaskDistributor interface and distributes email sending tasks.
 func (d *RedisTaskDistributor) DistributeSendEmail(ctx context.Context, payload *SendEmailPayload, emailTask string) error {
@@ -5,17 +5,24 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"html"
 	"strconv"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/guerzon/gobudget-api/pkg/token"
 	"github.com/guerzon/gobudget-api/pkg/util"
 	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"golang.org/x/exp/slog"
 )
 
 const EmailVerificationExpiration = time.Duration(time.Minute * 15)
+const PasswordResetExpiration = time.Duration(time.Minute * 30)
+const MagicLinkExpiration = time.Duration(time.Minute * 15)
+const EmailChangeExpiration = time.Duration(time.Hour * 24)
 
 // ProcessSendVerifyEmail implements the TaskProcessor interface and processes the task task:send_verify_email from the background worker
 func (p *RedisTaskProcessor) ProcessSendVerifyEmail(ctx context.Context, task *asynq.Task) error {
@@ -101,3 +108,361 @@ func (p *RedisTaskProcessor) ProcessSendAccountDeletedEmail(ctx context.Context,
 
 	return nil
 }
+
+// ProcessSendPasswordResetEmail implements the TaskProcessor interface and processes the task task:send_password_reset_email from the background worker
+func (p *RedisTaskProcessor) ProcessSendPasswordResetEmail(ctx context.Context, task *asynq.Task) error {
+
+	var payload SendEmailPayload
+
+	// unmarshal the payload inside the task
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal task payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := p.store.GetUserByUsername(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("user does not exist: %w", asynq.SkipRetry) // don't retry
+		}
+		return fmt.Errorf("failed to get user: %w", err) // this will retry
+	}
+
+	// Create an entry in password_resets, only the hash of the token is stored
+	resetToken, resetTokenHash, err := token.NewOpaqueToken("")
+	if err != nil {
+		return fmt.Errorf("cannot generate password reset token: %w", err)
+	}
+	_, err = p.store.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+		Username:  user.Username,
+		TokenHash: resetTokenHash,
+		ExpiresAt: time.Now().Add(PasswordResetExpiration),
+	})
+	if err != nil {
+		slog.Error("cannot create record for password reset")
+		return fmt.Errorf("failed to create password reset record: %v", err)
+	}
+
+	// Send the email to the user, the link serves a page which posts the token to /password_reset/confirm
+	s := "Reset your gobudget password"
+	c := `
+	<p>Hello ` + user.Username + `,</p>
+	<br/>
+	<p>Someone asked to reset the password of your account. If it was you, you can set a new password <a href="` + "http://localhost:8080/beta/password_reset?token=" + resetToken + `">here.</a>
+	The link expires in ` + strconv.Itoa(int(PasswordResetExpiration.Minutes())) + ` minutes and can only be used once.
+	</p>
+	<p>If you did not ask for it, you can ignore this email. Your password has not been changed.</p>
+	<br/>
+	Thanks!
+	`
+	err = p.mailer.SendEmail(s, c, []string{user.Email}, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("cannot send password reset email: %w", err)
+	}
+
+	slog.Info(fmt.Sprintf("[processed_task] email=%s", user.Email))
+
+	return nil
+}
+
+// ProcessSendRefreshTokenReuseEmail implements the TaskProcessor interface and processes the task task:send_refresh_token_reuse_email from the background worker
+func (p *RedisTaskProcessor) ProcessSendRefreshTokenReuseEmail(ctx context.Context, task *asynq.Task) error {
+
+	var payload SendEmailPayload
+
+	// unmarshal the payload inside the task
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal task payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := p.store.GetUserByUsername(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("user does not exist: %w", asynq.SkipRetry) // don't retry
+		}
+		return fmt.Errorf("failed to get user: %w", err) // this will retry
+	}
+
+	// Send the email to the user
+	s := "Security alert for your gobudget account"
+	c := `
+	<p>Hello ` + user.Username + `,</p>
+	<br/>
+	<p>A refresh token of your account which had already been used was presented again. This can mean that someone copied it from one of your devices.
+	As a precaution, the session it belonged to has been logged out.
+	</p>
+	<p>If you don't recognize this, change your password and review your sessions.</p>
+	<br/>
+	Thanks!
+	`
+	err = p.mailer.SendEmail(s, c, []string{user.Email}, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("cannot send security alert email: %w", err)
+	}
+
+	slog.Info(fmt.Sprintf("[processed_task] email=%s", user.Email))
+
+	return nil
+}
+
+// ProcessSendAccountLockedEmail implements the TaskProcessor interface and processes the task task:send_account_locked_email from the background worker
+func (p *RedisTaskProcessor) ProcessSendAccountLockedEmail(ctx context.Context, task *asynq.Task) error {
+
+	var payload SendEmailPayload
+
+	// unmarshal the payload inside the task
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal task payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := p.store.GetUserByUsername(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("user does not exist: %w", asynq.SkipRetry) // don't retry
+		}
+		return fmt.Errorf("failed to get user: %w", err) // this will retry
+	}
+
+	// Send the email to the user
+	s := "Your gobudget account has been locked"
+	c := `
+	<p>Hello ` + user.Username + `,</p>
+	<br/>
+	<p>There were too many failed attempts to log in to your account, so logins have been blocked for a while.
+	They will be possible again later, or you can ask an administrator to unlock your account.
+	</p>
+	<p>If it was not you, someone might be guessing your password. Consider changing it and enabling two-factor authentication.</p>
+	<br/>
+	Thanks!
+	`
+	err = p.mailer.SendEmail(s, c, []string{user.Email}, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("cannot send account locked email: %w", err)
+	}
+
+	slog.Info(fmt.Sprintf("[processed_task] email=%s", user.Email))
+
+	return nil
+}
+
+// ProcessSendMagicLinkEmail implements the TaskProcessor interface and processes the task task:send_magic_link_email from the background worker
+func (p *RedisTaskProcessor) ProcessSendMagicLinkEmail(ctx context.Context, task *asynq.Task) error {
+
+	var payload SendEmailPayload
+
+	// unmarshal the payload inside the task
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal task payload: %w", asynq.SkipRetry)
+	}
+	if payload.NonceHash == "" {
+		return fmt.Errorf("magic link without a nonce: %w", asynq.SkipRetry)
+	}
+
+	user, err := p.store.GetUserByUsername(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("user does not exist: %w", asynq.SkipRetry) // don't retry
+		}
+		return fmt.Errorf("failed to get user: %w", err) // this will retry
+	}
+
+	// Create an entry in magic_links, bound to the browser which asked for it.
+	// Only the hashes of the token and the nonce are stored.
+	linkToken, linkTokenHash, err := token.NewOpaqueToken("")
+	if err != nil {
+		return fmt.Errorf("cannot generate magic link token: %w", err)
+	}
+	_, err = p.store.CreateMagicLink(ctx, db.CreateMagicLinkParams{
+		Username:  user.Username,
+		TokenHash: linkTokenHash,
+		NonceHash: payload.NonceHash,
+		ExpiresAt: time.Now().Add(MagicLinkExpiration),
+	})
+	if err != nil {
+		slog.Error("cannot create record for magic link")
+		return fmt.Errorf("failed to create magic link record: %v", err)
+	}
+
+	// Send the email to the user
+	s := "Sign in to gobudget"
+	c := `
+	<p>Hello ` + user.Username + `,</p>
+	<br/>
+	<p>You can sign in to your account <a href="` + "http://localhost:8080/beta/login/magic_link/verify?token=" + linkToken + `">here.</a>
+	The link expires in ` + strconv.Itoa(int(MagicLinkExpiration.Minutes())) + ` minutes, can only be used once, and only works in the browser where you asked for it.
+	</p>
+	<p>If you did not ask for it, you can ignore this email.</p>
+	<br/>
+	Thanks!
+	`
+	err = p.mailer.SendEmail(s, c, []string{user.Email}, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("cannot send magic link email: %w", err)
+	}
+
+	slog.Info(fmt.Sprintf("[processed_task] email=%s", user.Email))
+
+	return nil
+}
+
+// ProcessSendEmailChangeEmail implements the TaskProcessor interface and processes the task task:send_email_change_email from the background worker
+func (p *RedisTaskProcessor) ProcessSendEmailChangeEmail(ctx context.Context, task *asynq.Task) error {
+
+	var payload SendEmailPayload
+
+	// unmarshal the payload inside the task, the email is the new address
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal task payload: %w", asynq.SkipRetry)
+	}
+	if payload.Email == "" {
+		return fmt.Errorf("email change without a new address: %w", asynq.SkipRetry)
+	}
+
+	user, err := p.store.GetUserByUsername(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("user does not exist: %w", asynq.SkipRetry) // don't retry
+		}
+		return fmt.Errorf("failed to get user: %w", err) // this will retry
+	}
+
+	// Create an entry in email_changes, which replaces the pending ones. Only the hashes of the tokens are stored.
+	confirmToken, confirmTokenHash, err := token.NewOpaqueToken("")
+	if err != nil {
+		return fmt.Errorf("cannot generate email change token: %w", err)
+	}
+	cancelToken, cancelTokenHash, err := token.NewOpaqueToken("")
+	if err != nil {
+		return fmt.Errorf("cannot generate email change token: %w", err)
+	}
+	if err := p.store.UseEmailChanges(ctx, user.Username); err != nil {
+		return fmt.Errorf("failed to replace pending email changes: %v", err)
+	}
+	_, err = p.store.CreateEmailChange(ctx, db.CreateEmailChangeParams{
+		Username:        user.Username,
+		OldEmail:        user.Email,
+		NewEmail:        payload.Email,
+		TokenHash:       confirmTokenHash,
+		CancelTokenHash: cancelTokenHash,
+		ExpiresAt:       time.Now().Add(EmailChangeExpiration),
+	})
+	if err != nil {
+		slog.Error("cannot create record for email change")
+		return fmt.Errorf("failed to create email change record: %v", err)
+	}
+
+	// Notify the current address first, so that the change cannot be confirmed before its owner can cancel it
+	s := "Your gobudget email is being changed"
+	c := `
+	<p>Hello ` + user.Username + `,</p>
+	<br/>
+	<p>Someone asked to change the email of your account to ` + payload.Email + `. The change will be made once it is confirmed from the new address.
+	</p>
+	<p>If it was not you, cancel the change <a href="` + "http://localhost:8080/beta/email_change/cancel?token=" + cancelToken + `">here</a>, and change your password.</p>
+	<br/>
+	Thanks!
+	`
+	err = p.mailer.SendEmail(s, c, []string{user.Email}, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("cannot send email change notification: %w", err)
+	}
+
+	s = "Confirm your new gobudget email"
+	c = `
+	<p>Hello ` + user.Username + `,</p>
+	<br/>
+	<p>Kindly confirm that this is the new email of your account by clicking <a href="` + "http://localhost:8080/beta/email_change/confirm?token=" + confirmToken + `">here.</a>
+	The link expires in ` + strconv.Itoa(int(EmailChangeExpiration.Hours())) + ` hours and can only be used once.
+	</p>
+	<p>If you did not ask for it, you can ignore this email.</p>
+	<br/>
+	Thanks!
+	`
+	err = p.mailer.SendEmail(s, c, []string{payload.Email}, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("cannot send email change confirmation: %w", err)
+	}
+
+	slog.Info(fmt.Sprintf("[processed_task] email=%s", payload.Email))
+
+	return nil
+}
+
+// ProcessSendNewDeviceLoginEmail implements the TaskProcessor interface and processes the task task:send_new_device_login_email from the background worker
+func (p *RedisTaskProcessor) ProcessSendNewDeviceLoginEmail(ctx context.Context, task *asynq.Task) error {
+
+	var payload SendEmailPayload
+
+	// unmarshal the payload inside the task
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal task payload: %w", asynq.SkipRetry)
+	}
+	if payload.SessionID == uuid.Nil {
+		return fmt.Errorf("new device login without a session: %w", asynq.SkipRetry)
+	}
+
+	user, err := p.store.GetUserByUsername(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("user does not exist: %w", asynq.SkipRetry) // don't retry
+		}
+		return fmt.Errorf("failed to get user: %w", err) // this will retry
+	}
+	session, err := p.store.GetSession(ctx, payload.SessionID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("session does not exist: %w", asynq.SkipRetry) // don't retry
+		}
+		return fmt.Errorf("failed to get session: %w", err) // this will retry
+	}
+	if session.Username != user.Username {
+		return fmt.Errorf("session of another user: %w", asynq.SkipRetry)
+	}
+
+	// Link the login to a token which revokes its session. Only the hash of the token is stored,
+	// and a retry replaces it since the previous link was not sent.
+	revokeToken, revokeTokenHash, err := token.NewOpaqueToken("")
+	if err != nil {
+		return fmt.Errorf("cannot generate revoke token: %w", err)
+	}
+	n, err := p.store.SetLoginEventRevokeToken(ctx, db.SetLoginEventRevokeTokenParams{
+		SessionID:       pgtype.UUID{Bytes: session.ID, Valid: true},
+		RevokeTokenHash: pgtype.Text{String: revokeTokenHash, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save revoke token: %v", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("login of the session does not exist: %w", asynq.SkipRetry)
+	}
+
+	// the user agent is sent by the client, don't let it inject markup
+	s := "New sign-in to your gobudget account"
+	c := `
+	<p>Hello ` + user.Username + `,</p>
+	<br/>
+	<p>Your account was signed in to from a device or network which has not been used before:</p>
+	<ul>
+	<li>Time: ` + session.CreatedAt.UTC().Format(time.RFC1123) + `</li>
+	<li>IP address: ` + html.EscapeString(session.ClientIp) + `</li>
+	<li>Device: ` + html.EscapeString(session.UserAgent) + `</li>
+	</ul>
+	<p>If it was you, you can ignore this email.
+	If it was not, log out this session <a href="` + "http://localhost:8080/beta/sessions/revoke?token=" + revokeToken + `">here</a>, and change your password.</p>
+	<br/>
+	Thanks!
+	`
+	err = p.mailer.SendEmail(s, c, []string{user.Email}, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("cannot send new device login email: %w", err)
+	}
+
+	slog.Info(fmt.Sprintf("[processed_task] email=%s", user.Email))
+
+	return nil
+}
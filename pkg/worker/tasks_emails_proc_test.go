@@ -0,0 +1,59 @@
+package worker_test
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/token"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// Keeps the emails instead of sending them
+type testMailer struct {
+	contents []string
+}
+
+func (m *testMailer) SendEmail(subject string, content string, to []string, cc []string, bcc []string, attachFiles []string) error {
+	m.contents = append(m.contents, content)
+	return nil
+}
+
+func TestProcessSendPasswordResetEmail(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := db.User{Username: "charles", Email: "charlesleclerc@gmail.com"}
+
+	var tokenHash string
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUserByUsername(gomock.Any(), user.Username).Times(1).Return(user, nil)
+	store.EXPECT().
+		CreatePasswordReset(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+			tokenHash = arg.TokenHash
+			return db.PasswordReset{}, nil
+		})
+
+	mailer := &testMailer{}
+	payload, err := json.Marshal(worker.SendEmailPayload{Username: user.Username, Email: user.Email})
+	require.NoError(t, err)
+
+	processor := worker.NewRedisTaskProcessor(asynq.RedisClientOpt{}, store, mailer)
+	err = processor.ProcessSendPasswordResetEmail(context.Background(), asynq.NewTask(worker.TaskSendPasswordResetEmail, payload))
+	require.NoError(t, err)
+
+	// the link serves the password reset page of the API, with the token whose hash was stored
+	require.Len(t, mailer.contents, 1)
+	link := regexp.MustCompile(`href="http://localhost:8080/beta/password_reset\?token=([^"&]+)"`).FindStringSubmatch(mailer.contents[0])
+	require.NotNil(t, link)
+	require.Equal(t, tokenHash, token.HashOpaqueToken(link[1]))
+}
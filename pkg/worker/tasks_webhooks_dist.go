@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// DeliverWebhookPayload contains the webhook delivery to send. The event itself is stored in the delivery log.
+type DeliverWebhookPayload struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+const TaskDeliverWebhook = "task:deliver_webhook"
+
+// Number of times a failed webhook delivery is retried before giving up.
+const webhookMaxRetry = 8
+
+// DistributeDeliverWebhook implements the TaskDistributor interface and distributes webhook delivery tasks.
+func (d *RedisTaskDistributor) DistributeDeliverWebhook(ctx context.Context, payload *DeliverWebhookPayload) error {
+
+	j, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal task payload: %w", err)
+	}
+
+	// Initialize a new task
+	opts := []asynq.Option{
+		asynq.MaxRetry(webhookMaxRetry),
+		asynq.Queue(QueueDefault),
+	}
+	task := asynq.NewTask(TaskDeliverWebhook, j, opts...)
+
+	// Send the task
+	_, err = d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("cannot enqueue task: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/guerzon/gobudget-api/pkg/db"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// Headers sent along with every webhook delivery
+	WebhookEventHeader     = "X-Gobudget-Event"
+	WebhookDeliveryHeader  = "X-Gobudget-Delivery"
+	WebhookSignatureHeader = "X-Gobudget-Signature"
+
+	webhookTimeout       = 10 * time.Second
+	webhookBaseDelay     = 30 * time.Second
+	webhookMaxDelay      = 6 * time.Hour
+	webhookMaxErrorBytes = 512
+)
+
+// Returns the signature of a webhook body, which is the hex-encoded HMAC-SHA256 of the body using the webhook secret.
+// Receivers should compute the same value and compare it with the X-Gobudget-Signature header.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Returns the delay before the nth retry of a webhook delivery: 30s, 1m, 2m, 4m ... capped at 6 hours.
+func webhookRetryDelay(n int) time.Duration {
+	delay := webhookBaseDelay
+	for i := 0; i < n && delay < webhookMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxDelay)
+}
+
+// ProcessDeliverWebhook implements the TaskProcessor interface and processes the task task:deliver_webhook from the background worker.
+// The result of every attempt is written to the delivery log. Returning an error makes asynq retry the delivery.
+func (p *RedisTaskProcessor) ProcessDeliverWebhook(ctx context.Context, task *asynq.Task) error {
+
+	var payload DeliverWebhookPayload
+
+	// unmarshal the payload inside the task
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal task payload: %w", asynq.SkipRetry)
+	}
+
+	delivery, err := p.store.GetWebhookDelivery(ctx, payload.DeliveryID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("webhook delivery does not exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	webhook, err := p.store.GetWebhookById(ctx, delivery.WebhookID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("webhook does not exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if !webhook.Active {
+		slog.Info(fmt.Sprintf("[skipped_task] webhook=%s is disabled", webhook.ID))
+		return nil
+	}
+
+	// Send the event to the receiver
+	statusCode, deliveryErr := p.sendWebhook(ctx, webhook, delivery)
+
+	// Record the attempt in the delivery log
+	arg := db.UpdateWebhookDeliveryResultParams{
+		ID: delivery.ID,
+	}
+	if statusCode != 0 {
+		arg.StatusCode = pgtype.Int4{Int32: int32(statusCode), Valid: true}
+	}
+	if deliveryErr != nil {
+		arg.Error = pgtype.Text{String: deliveryErr.Error(), Valid: true}
+	} else {
+		arg.DeliveredAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	}
+	if _, err := p.store.UpdateWebhookDeliveryResult(ctx, arg); err != nil {
+		slog.Error("cannot update webhook delivery log", "delivery", delivery.ID, "errmsg", err)
+	}
+
+	if deliveryErr != nil {
+		return fmt.Errorf("cannot deliver webhook: %w", deliveryErr)
+	}
+
+	slog.Info(fmt.Sprintf("[processed_task] webhook=%s delivery=%s", webhook.ID, delivery.ID))
+
+	return nil
+}
+
+// Posts the signed delivery payload to the webhook URL. Returns the HTTP status code, if any, and an error if the
+// receiver could not be reached or did not respond with a 2xx status code.
+func (p *RedisTaskProcessor) sendWebhook(ctx context.Context, webhook db.Webhook, delivery db.WebhookDelivery) (int, error) {
+
+	rqst, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	rqst.Header.Set("Content-Type", "application/json")
+	rqst.Header.Set("User-Agent", "gobudget-webhooks")
+	rqst.Header.Set(WebhookEventHeader, delivery.Event)
+	rqst.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	rqst.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, delivery.Payload))
+
+	resp, err := p.client.Do(rqst)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxErrorBytes))
+		return resp.StatusCode, fmt.Errorf("receiver responded with %s: %s", resp.Status, body)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,160 @@
+package worker_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/guerzon/gobudget-api/pkg/db"
+	mockdb "github.com/guerzon/gobudget-api/pkg/mock"
+	"github.com/guerzon/gobudget-api/pkg/worker"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessDeliverWebhook(t *testing.T) {
+
+	secret := "s3cret"
+	body := []byte(`{"event":"transaction.created","data":{}}`)
+
+	testCases := []struct {
+		name          string
+		active        bool
+		statusCode    int
+		buildStubs    func(store *mockdb.MockStore, delivery db.WebhookDelivery, webhook db.Webhook)
+		checkResponse func(t *testing.T, err error, received int)
+	}{
+		{
+			name:       "OK",
+			active:     true,
+			statusCode: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore, delivery db.WebhookDelivery, webhook db.Webhook) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), delivery.ID).Times(1).Return(delivery, nil)
+				store.EXPECT().GetWebhookById(gomock.Any(), webhook.ID).Times(1).Return(webhook, nil)
+				store.EXPECT().
+					UpdateWebhookDeliveryResult(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateWebhookDeliveryResultParams) (db.WebhookDelivery, error) {
+						require.Equal(t, int32(http.StatusOK), arg.StatusCode.Int32)
+						require.False(t, arg.Error.Valid)
+						require.True(t, arg.DeliveredAt.Valid)
+						return delivery, nil
+					})
+			},
+			checkResponse: func(t *testing.T, err error, received int) {
+				require.NoError(t, err)
+				require.Equal(t, 1, received)
+			},
+		},
+		{
+			name:       "ReceiverError",
+			active:     true,
+			statusCode: http.StatusInternalServerError,
+			buildStubs: func(store *mockdb.MockStore, delivery db.WebhookDelivery, webhook db.Webhook) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), delivery.ID).Times(1).Return(delivery, nil)
+				store.EXPECT().GetWebhookById(gomock.Any(), webhook.ID).Times(1).Return(webhook, nil)
+				store.EXPECT().
+					UpdateWebhookDeliveryResult(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateWebhookDeliveryResultParams) (db.WebhookDelivery, error) {
+						require.Equal(t, int32(http.StatusInternalServerError), arg.StatusCode.Int32)
+						require.True(t, arg.Error.Valid)
+						require.False(t, arg.DeliveredAt.Valid)
+						return delivery, nil
+					})
+			},
+			checkResponse: func(t *testing.T, err error, received int) {
+				// the task must fail so that asynq retries it
+				require.Error(t, err)
+				require.NotErrorIs(t, err, asynq.SkipRetry)
+				require.Equal(t, 1, received)
+			},
+		},
+		{
+			name:   "WebhookDisabled",
+			active: false,
+			buildStubs: func(store *mockdb.MockStore, delivery db.WebhookDelivery, webhook db.Webhook) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), delivery.ID).Times(1).Return(delivery, nil)
+				store.EXPECT().GetWebhookById(gomock.Any(), webhook.ID).Times(1).Return(webhook, nil)
+				store.EXPECT().UpdateWebhookDeliveryResult(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, received int) {
+				require.NoError(t, err)
+				require.Zero(t, received)
+			},
+		},
+		{
+			name: "DeliveryNotFound",
+			buildStubs: func(store *mockdb.MockStore, delivery db.WebhookDelivery, webhook db.Webhook) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), delivery.ID).Times(1).Return(db.WebhookDelivery{}, pgx.ErrNoRows)
+				store.EXPECT().GetWebhookById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, received int) {
+				require.ErrorIs(t, err, asynq.SkipRetry)
+				require.Zero(t, received)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Local receiver which checks the signature the same way a real receiver would
+			received := 0
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received++
+				b, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, body, b)
+				require.Equal(t, "transaction.created", r.Header.Get(worker.WebhookEventHeader))
+				require.Equal(t, worker.SignWebhookPayload(secret, b), r.Header.Get(worker.WebhookSignatureHeader))
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer receiver.Close()
+
+			webhook := db.Webhook{
+				ID:     uuid.New(),
+				Url:    receiver.URL,
+				Secret: secret,
+				Events: []string{"transaction.created"},
+				Active: tc.active,
+			}
+			delivery := db.WebhookDelivery{
+				ID:        uuid.New(),
+				WebhookID: webhook.ID,
+				Event:     "transaction.created",
+				Payload:   body,
+			}
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, delivery, webhook)
+
+			payload, err := json.Marshal(worker.DeliverWebhookPayload{DeliveryID: delivery.ID})
+			require.NoError(t, err)
+
+			processor := worker.NewRedisTaskProcessor(asynq.RedisClientOpt{}, store, nil)
+			err = processor.ProcessDeliverWebhook(context.Background(), asynq.NewTask(worker.TaskDeliverWebhook, payload))
+			tc.checkResponse(t, err, received)
+		})
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+
+	body := []byte(`{"event":"account.reconciled"}`)
+
+	signature := worker.SignWebhookPayload("secret", body)
+	require.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+	require.Equal(t, signature, worker.SignWebhookPayload("secret", body))
+	require.NotEqual(t, signature, worker.SignWebhookPayload("another secret", body))
+}